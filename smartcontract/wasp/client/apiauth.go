@@ -0,0 +1,59 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/util/auth"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+)
+
+// NewAPIToken creates a new API token with the given role. The returned model contains the token itself,
+// which can't be retrieved later
+func (c *WaspClient) NewAPIToken(role auth.Role, description string) (*model.APIToken, error) {
+	res := &model.APIToken{}
+	req := &model.APITokenRequest{Role: role.String(), Description: description}
+	if err := c.do(http.MethodPost, routes.PostAPIToken(), req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetAPITokens fetches the list of API tokens of the node
+func (c *WaspClient) GetAPITokens() ([]*model.APIToken, error) {
+	var res []*model.APIToken
+	if err := c.do(http.MethodGet, routes.ListAPITokens(), nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DeleteAPIToken revokes the API token
+func (c *WaspClient) DeleteAPIToken(id string) error {
+	return c.do(http.MethodDelete, routes.DeleteAPIToken(id), nil, nil)
+}
+
+// PutAPIKey registers the ed25519 key (by its address) allowed to sign requests
+func (c *WaspClient) PutAPIKey(addr *address.Address, role auth.Role, description string) error {
+	req := &model.APIKey{
+		Address:     model.NewAddress(addr),
+		Role:        role.String(),
+		Description: description,
+	}
+	return c.do(http.MethodPost, routes.PutAPIKey(), req, nil)
+}
+
+// GetAPIKeys fetches the list of API keys registered in the node
+func (c *WaspClient) GetAPIKeys() ([]*model.APIKey, error) {
+	var res []*model.APIKey
+	if err := c.do(http.MethodGet, routes.ListAPIKeys(), nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DeleteAPIKey revokes the API key
+func (c *WaspClient) DeleteAPIKey(addr *address.Address) error {
+	return c.do(http.MethodDelete, routes.DeleteAPIKey(addr.String()), nil, nil)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/webapi/model"
 	"github.com/iotaledger/wasp/packages/webapi/routes"
 )
 
@@ -16,3 +17,13 @@ func (c *WaspClient) CallView(contractID coretypes.ContractID, fname string, arg
 	}
 	return res, nil
 }
+
+// CallViews sends a batch of view calls, which are executed against the same state of the chain.
+// Returns the results of all calls together with the index of that state
+func (c *WaspClient) CallViews(chainID *coretypes.ChainID, req *model.CallViewsRequest) (*model.CallViewsResponse, error) {
+	res := &model.CallViewsResponse{}
+	if err := c.do(http.MethodGet, routes.CallViews(chainID.String()), req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
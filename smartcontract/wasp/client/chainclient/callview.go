@@ -3,9 +3,20 @@ package chainclient
 import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/webapi/model"
 )
 
 // CallView sends a request to call a view function of a given contract, and returns the result of the call
 func (c *Client) CallView(contractHname coretypes.Hname, fname string, arguments dict.Dict) (dict.Dict, error) {
 	return c.WaspClient.CallView(coretypes.NewContractID(c.ChainID, contractHname), fname, arguments)
 }
+
+// CallViews calls several view functions of the chain against the same state. Returns the results
+// in the order of the calls and the index of the block the calls were executed against
+func (c *Client) CallViews(calls ...model.ViewCall) ([]dict.Dict, uint32, error) {
+	res, err := c.WaspClient.CallViews(&c.ChainID, &model.CallViewsRequest{Calls: calls})
+	if err != nil {
+		return nil, 0, err
+	}
+	return res.Results, res.StateIndex, nil
+}
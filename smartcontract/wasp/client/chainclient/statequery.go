@@ -1,5 +1,3 @@
-// +build ignore
-
 package chainclient
 
 import "github.com/iotaledger/wasp/packages/webapi/model/statequery"
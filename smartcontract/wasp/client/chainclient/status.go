@@ -1,36 +1,35 @@
-// +build ignore
-
 package chainclient
 
 import (
-	"github.com/iotaledger/wasp/packages/coretypes"
 	"time"
 
+	"github.com/iotaledger/wasp/packages/coretypes"
+
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/txutil"
-	"github.com/iotaledger/wasp/packages/vm/vmconst"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
 )
 
 type SCStatus struct {
 	StateIndex uint32
 	Timestamp  time.Time
-	StateHash  *hashing.HashValue
+	StateHash  hashing.HashValue
 	StateTxId  valuetransaction.ID
 	Requests   []*coretypes.RequestID
 
-	ProgramHash   *hashing.HashValue
-	Description   string
-	OwnerAddress  address.Address
-	SCAddress     address.Address
-	Balance       map[balance.Color]int64
-	MinimumReward int64
-	FetchedAt     time.Time
+	ChainOwnerID coretypes.AgentID
+	Description  string
+	SCAddress    address.Address
+	Balance      map[balance.Color]int64
+	FetchedAt    time.Time
 }
 
+// FetchSCStatus queries the general data of the chain state together with the custom queries
+// added by 'addCustomQueries'
 func (c *Client) FetchSCStatus(addCustomQueries func(query *statequery.Request)) (*SCStatus, *statequery.Results, error) {
 	balance, err := c.FetchBalance()
 	if err != nil {
@@ -39,10 +38,8 @@ func (c *Client) FetchSCStatus(addCustomQueries func(query *statequery.Request))
 
 	query := statequery.NewRequest()
 	query.AddGeneralData()
-	query.AddScalar(vmconst.VarNameOwnerAddress)
-	query.AddScalar(vmconst.VarNameProgramData)
-	query.AddScalar(vmconst.VarNameDescription)
-	query.AddScalar(vmconst.VarNameMinimumReward)
+	query.AddScalar(root.Interface.Hname(), root.VarChainOwnerID)
+	query.AddScalar(root.Interface.Hname(), root.VarDescription)
 	addCustomQueries(query)
 
 	res, err := c.WaspClient.StateQuery(&c.ChainID, query)
@@ -50,8 +47,8 @@ func (c *Client) FetchSCStatus(addCustomQueries func(query *statequery.Request))
 		return nil, nil, err
 	}
 
-	description, _ := res.Get(vmconst.VarNameDescription).MustString()
-	minReward, _ := res.Get(vmconst.VarNameMinimumReward).MustInt64()
+	ownerID, _ := res.Get(root.Interface.Hname(), root.VarChainOwnerID).MustAgentID()
+	description, _ := res.Get(root.Interface.Hname(), root.VarDescription).MustString()
 
 	return &SCStatus{
 		StateIndex: res.StateIndex,
@@ -60,13 +57,11 @@ func (c *Client) FetchSCStatus(addCustomQueries func(query *statequery.Request))
 		StateTxId:  res.StateTxId.ID(),
 		Requests:   res.Requests,
 
-		ProgramHash:   res.Get(vmconst.VarNameProgramData).MustHashValue(),
-		Description:   description,
-		OwnerAddress:  res.Get(vmconst.VarNameOwnerAddress).MustAddress(),
-		MinimumReward: minReward,
-		SCAddress:     (address.Address)(c.ChainID),
-		Balance:       balance,
-		FetchedAt:     time.Now().UTC(),
+		ChainOwnerID: ownerID,
+		Description:  description,
+		SCAddress:    (address.Address)(c.ChainID),
+		Balance:      balance,
+		FetchedAt:    time.Now().UTC(),
 	}, res, nil
 }
 
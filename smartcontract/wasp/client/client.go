@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/util/auth"
 	"github.com/iotaledger/wasp/packages/webapi/model"
 )
 
@@ -16,6 +18,8 @@ import (
 type WaspClient struct {
 	httpClient http.Client
 	baseURL    string
+	token      string
+	sigScheme  signaturescheme.SignatureScheme
 }
 
 // NewWaspClient returns a new *WaspClient with the given baseURL and httpClient.
@@ -29,6 +33,19 @@ func NewWaspClient(baseURL string, httpClient ...http.Client) *WaspClient {
 	return &WaspClient{baseURL: baseURL}
 }
 
+// WithToken makes the client authenticate its requests with the API token
+func (c *WaspClient) WithToken(token string) *WaspClient {
+	c.token = token
+	return c
+}
+
+// WithSigner makes the client sign its requests with the ed25519 key of the signature scheme.
+// The address of the key must be registered in the node
+func (c *WaspClient) WithSigner(sigScheme signaturescheme.SignatureScheme) *WaspClient {
+	c.sigScheme = sigScheme
+	return c
+}
+
 func processResponse(res *http.Response, decodeTo interface{}) error {
 	resBody, err := ioutil.ReadAll(res.Body)
 	if err != nil {
@@ -78,6 +95,12 @@ func (c *WaspClient) do(method string, route string, reqObj interface{}, resObj
 	if data != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if c.token != "" {
+		req.Header.Set(auth.HeaderAuthorization, "Bearer "+c.token)
+	}
+	if c.sigScheme != nil {
+		auth.SignRequest(req, data, c.sigScheme)
+	}
 
 	// make the request
 	res, err := c.httpClient.Do(req)
@@ -6,17 +6,32 @@ import (
 	"github.com/iotaledger/wasp/client"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/webapi/model"
 )
 
-// WaitUntilRequestProcessed blocks until the request has been processed by all nodes
-func (m *MultiClient) WaitUntilRequestProcessed(chainId *coretypes.ChainID, reqId *coretypes.RequestID, timeout time.Duration) error {
+// WaitUntilRequestProcessed blocks until the request has been processed by all nodes.
+// Returns the status of the processed request reported by the nodes
+func (m *MultiClient) WaitUntilRequestProcessed(chainId *coretypes.ChainID, reqId *coretypes.RequestID, timeout time.Duration) (*model.RequestStatusResponse, error) {
 	oldTimeout := m.Timeout
 	defer func() { m.Timeout = oldTimeout }()
 
 	m.Timeout = timeout + 10*time.Second
-	return m.Do(func(i int, w *client.WaspClient) error {
-		return w.WaitUntilRequestProcessed(chainId, reqId, timeout)
+	statuses := make([]*model.RequestStatusResponse, m.Len())
+	err := m.Do(func(i int, w *client.WaspClient) error {
+		var err error
+		statuses[i], err = w.WaitUntilRequestProcessed(chainId, reqId, timeout)
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+	// nodes which processed the request before receipts were stored know nothing about the details
+	for _, status := range statuses {
+		if status.HasReceipt {
+			return status, nil
+		}
+	}
+	return statuses[0], nil
 }
 
 // WaitUntilAllRequestsProcessed blocks until all requests in the given transaction have been processed
@@ -19,20 +19,22 @@ func (c *WaspClient) RequestStatus(chainId *coretypes.ChainID, reqId *coretypes.
 	return res, nil
 }
 
-// WaitUntilRequestProcessed blocks until the request has been processed by the node
-func (c *WaspClient) WaitUntilRequestProcessed(chainId *coretypes.ChainID, reqId *coretypes.RequestID, timeout time.Duration) error {
+// WaitUntilRequestProcessed blocks until the request has been processed by the node. Returns the status
+// of the processed request, including the error of the VM and the result of the call
+func (c *WaspClient) WaitUntilRequestProcessed(chainId *coretypes.ChainID, reqId *coretypes.RequestID, timeout time.Duration) (*model.RequestStatusResponse, error) {
 	if timeout == 0 {
 		timeout = model.WaitRequestProcessedDefaultTimeout
 	}
+	res := &model.RequestStatusResponse{}
 	if err := c.do(
 		http.MethodGet,
 		routes.WaitRequestProcessed(chainId.String(), reqId.Base58()),
 		&model.WaitRequestProcessedParams{Timeout: timeout},
-		nil,
+		res,
 	); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return res, nil
 }
 
 // WaitUntilAllRequestsProcessed blocks until all requests in the given transaction have been processed
@@ -41,7 +43,7 @@ func (c *WaspClient) WaitUntilAllRequestsProcessed(tx *sctransaction.Transaction
 	for i, req := range tx.Requests() {
 		chainId := req.Target().ChainID()
 		reqId := coretypes.NewRequestID(tx.ID(), uint16(i))
-		if err := c.WaitUntilRequestProcessed(&chainId, &reqId, timeout); err != nil {
+		if _, err := c.WaitUntilRequestProcessed(&chainId, &reqId, timeout); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+)
+
+// PutTrustedPeer trusts the public key of the committee peer in the node
+func (c *WaspClient) PutTrustedPeer(tp *peering.TrustedPeer) error {
+	req, err := model.NewTrustedPeer(tp)
+	if err != nil {
+		return err
+	}
+	return c.do(http.MethodPost, routes.PutTrustedPeer(), req, nil)
+}
+
+// GetTrustedPeers fetches the list of peers trusted by the node
+func (c *WaspClient) GetTrustedPeers() ([]*model.TrustedPeer, error) {
+	var res []*model.TrustedPeer
+	if err := c.do(http.MethodGet, routes.ListTrustedPeers(), nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
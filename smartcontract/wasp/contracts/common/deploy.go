@@ -1,6 +1,7 @@
 package common
 
 import (
+	"fmt"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/solo"
@@ -10,6 +11,10 @@ import (
 	"testing"
 )
 
+// goOnLoad is the on_load function of the Go build of the contract deployed by DeployContract.
+// It is set by RunGoAndWasm for the Go run of the test, otherwise DeployContract deploys the Wasm build
+var goOnLoad func()
+
 const (
 	Debug      = true
 	StackTrace = true
@@ -27,14 +32,36 @@ func DeployContract(t *testing.T, scName string) *solo.Chain {
 	env := solo.New(t, Debug, StackTrace)
 	CreatorWallet = env.NewSignatureSchemeWithFunds()
 	chain := env.NewChain(CreatorWallet, "chain1")
-	wasmFile := scName + "_bg.wasm"
-	exists, _ := util.ExistsFilePath("../pkg/" + wasmFile)
-	if exists {
-		wasmFile = "../pkg/" + wasmFile
+	var err error
+	if goOnLoad != nil {
+		err = chain.DeployGoContract(CreatorWallet, scName, goOnLoad)
+	} else {
+		wasmFile := scName + "_bg.wasm"
+		exists, _ := util.ExistsFilePath("../pkg/" + wasmFile)
+		if exists {
+			wasmFile = "../pkg/" + wasmFile
+		}
+		err = chain.DeployWasmContract(CreatorWallet, scName, wasmFile)
 	}
-	err := chain.DeployWasmContract(CreatorWallet, scName, wasmFile)
 	require.NoError(t, err)
 	ContractId = coretypes.NewContractID(chain.ChainID, coretypes.Hn(scName))
 	ContractAccount = coretypes.NewAgentIDFromContractID(ContractId)
 	return chain
 }
+
+// RunGoAndWasm runs the test against the Go build of the contract, with 'onLoad' as its on_load function,
+// and then against the Wasm build. The test deploys the contract with DeployContract as usual.
+// The parameter 'w' of the test tells it which build it runs against.
+// The on_load function is passed to DeployContract through the package-level goOnLoad, like the
+// deployed contract is returned in ContractId and ContractAccount, so RunGoAndWasm is not safe
+// for tests running in parallel: neither the test nor its subtests may call t.Parallel()
+func RunGoAndWasm(t *testing.T, onLoad func(), test func(t *testing.T, w bool)) {
+	t.Run(fmt.Sprintf("run Go version of %s", t.Name()), func(t *testing.T) {
+		goOnLoad = onLoad
+		defer func() { goOnLoad = nil }()
+		test(t, false)
+	})
+	t.Run(fmt.Sprintf("run Wasm version of %s", t.Name()), func(t *testing.T) {
+		test(t, true)
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package inccounter
+
+import "github.com/iotaledger/wasp/packages/vm/wasmlib"
+
+var localStateMustIncrement = false
+
+func funcCallIncrement(ctx *wasmlib.ScFuncContext) {
+	counter := ctx.State().GetInt(VarCounter)
+	value := counter.Value()
+	counter.SetValue(value + 1)
+	if value == 0 {
+		ctx.CallSelf(HFuncCallIncrement, nil, nil)
+	}
+}
+
+func funcCallIncrementRecurse5x(ctx *wasmlib.ScFuncContext) {
+	counter := ctx.State().GetInt(VarCounter)
+	value := counter.Value()
+	counter.SetValue(value + 1)
+	if value < 5 {
+		ctx.CallSelf(HFuncCallIncrementRecurse5x, nil, nil)
+	}
+}
+
+func funcIncrement(ctx *wasmlib.ScFuncContext) {
+	counter := ctx.State().GetInt(VarCounter)
+	counter.SetValue(counter.Value() + 1)
+}
+
+func funcInit(ctx *wasmlib.ScFuncContext) {
+	paramCounter := ctx.Params().GetInt(ParamCounter)
+	if !paramCounter.Exists() {
+		return
+	}
+	ctx.State().GetInt(VarCounter).SetValue(paramCounter.Value())
+}
+
+func funcLocalStateInternalCall(ctx *wasmlib.ScFuncContext) {
+	localStateMustIncrement = false
+	funcWhenMustIncrement(ctx)
+	localStateMustIncrement = true
+	funcWhenMustIncrement(ctx)
+	funcWhenMustIncrement(ctx)
+	// counter ends up as 2
+}
+
+func funcLocalStatePost(ctx *wasmlib.ScFuncContext) {
+	localStateMustIncrement = false
+	request := &wasmlib.PostRequestParams{
+		ContractId: ctx.ContractId(),
+		Function:   HFuncWhenMustIncrement,
+	}
+	ctx.Post(request)
+	localStateMustIncrement = true
+	ctx.Post(request)
+	ctx.Post(request)
+	// counter ends up as 0
+}
+
+func funcLocalStateSandboxCall(ctx *wasmlib.ScFuncContext) {
+	localStateMustIncrement = false
+	ctx.CallSelf(HFuncWhenMustIncrement, nil, nil)
+	localStateMustIncrement = true
+	ctx.CallSelf(HFuncWhenMustIncrement, nil, nil)
+	ctx.CallSelf(HFuncWhenMustIncrement, nil, nil)
+	// counter ends up as 0
+}
+
+func funcPostIncrement(ctx *wasmlib.ScFuncContext) {
+	counter := ctx.State().GetInt(VarCounter)
+	value := counter.Value()
+	counter.SetValue(value + 1)
+	if value == 0 {
+		ctx.Post(&wasmlib.PostRequestParams{
+			ContractId: ctx.ContractId(),
+			Function:   HFuncPostIncrement,
+		})
+	}
+}
+
+func funcRepeatMany(ctx *wasmlib.ScFuncContext) {
+	paramNumRepeats := ctx.Params().GetInt(ParamNumRepeats)
+	counter := ctx.State().GetInt(VarCounter)
+	counter.SetValue(counter.Value() + 1)
+	stateRepeats := ctx.State().GetInt(VarNumRepeats)
+	repeats := paramNumRepeats.Value()
+	if repeats == 0 {
+		repeats = stateRepeats.Value()
+		if repeats == 0 {
+			return
+		}
+	}
+	stateRepeats.SetValue(repeats - 1)
+	ctx.Post(&wasmlib.PostRequestParams{
+		ContractId: ctx.ContractId(),
+		Function:   HFuncRepeatMany,
+	})
+}
+
+func funcWhenMustIncrement(ctx *wasmlib.ScFuncContext) {
+	ctx.Log("when_must_increment called")
+	if !localStateMustIncrement {
+		return
+	}
+	counter := ctx.State().GetInt(VarCounter)
+	counter.SetValue(counter.Value() + 1)
+}
+
+// note that getCounter mirrors the state of the 'counter' state variable
+// which means that if the state variable was not present it also will not be present in the result
+func viewGetCounter(ctx *wasmlib.ScViewContext) {
+	counter := ctx.State().GetInt(VarCounter)
+	if counter.Exists() {
+		ctx.Results().GetInt(VarCounter).SetValue(counter.Value())
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package inccounter
+
+import "github.com/iotaledger/wasp/packages/vm/wasmlib"
+
+// OnLoad is the Go counterpart of on_load in lib.rs. Deploy it with solo.Chain.DeployGoContract
+func OnLoad() {
+	exports := wasmlib.NewScExports()
+	exports.AddFunc(FuncCallIncrement, funcCallIncrement)
+	exports.AddFunc(FuncCallIncrementRecurse5x, funcCallIncrementRecurse5x)
+	exports.AddFunc(FuncIncrement, funcIncrement)
+	exports.AddFunc(FuncInit, funcInit)
+	exports.AddFunc(FuncLocalStateInternalCall, funcLocalStateInternalCall)
+	exports.AddFunc(FuncLocalStatePost, funcLocalStatePost)
+	exports.AddFunc(FuncLocalStateSandboxCall, funcLocalStateSandboxCall)
+	exports.AddFunc(FuncPostIncrement, funcPostIncrement)
+	exports.AddFunc(FuncRepeatMany, funcRepeatMany)
+	exports.AddFunc(FuncWhenMustIncrement, funcWhenMustIncrement)
+	exports.AddView(ViewGetCounter, viewGetCounter)
+}
@@ -4,11 +4,14 @@
 package test
 
 import (
+	"fmt"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/contracts/common"
+	"github.com/iotaledger/wasp/contracts/rust/inccounter/go/inccounter"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/solo"
 	"github.com/stretchr/testify/require"
+	"sync"
 	"testing"
 )
 
@@ -17,58 +20,82 @@ func setupTest(t *testing.T) *solo.Chain {
 }
 
 func TestDeploy(t *testing.T) {
+	common.RunGoAndWasm(t, inccounter.OnLoad, testDeploy)
+}
+
+func testDeploy(t *testing.T, w bool) {
 	chain := common.DeployContract(t, ScName)
 	_, err := chain.FindContract(ScName)
 	require.NoError(t, err)
 }
 
 func TestStateAfterDeploy(t *testing.T) {
+	common.RunGoAndWasm(t, inccounter.OnLoad, testStateAfterDeploy)
+}
+
+func testStateAfterDeploy(t *testing.T, w bool) {
 	chain := common.DeployContract(t, ScName)
-	
+
 	checkStateCounter(t, chain, nil)
 }
 
 func TestIncrementOnce(t *testing.T) {
+	common.RunGoAndWasm(t, inccounter.OnLoad, testIncrementOnce)
+}
+
+func testIncrementOnce(t *testing.T, w bool) {
 	chain := setupTest(t)
-	
+
 	req := solo.NewCallParams(ScName, FuncIncrement)
 	_, err := chain.PostRequest(req, nil)
 	require.NoError(t, err)
-	
+
 	checkStateCounter(t, chain, 1)
 }
 
 func TestIncrementTwice(t *testing.T) {
+	common.RunGoAndWasm(t, inccounter.OnLoad, testIncrementTwice)
+}
+
+func testIncrementTwice(t *testing.T, w bool) {
 	chain := setupTest(t)
-	
+
 	req := solo.NewCallParams(ScName, FuncIncrement)
 	_, err := chain.PostRequest(req, nil)
 	require.NoError(t, err)
-	
+
 	req = solo.NewCallParams(ScName, FuncIncrement)
 	_, err = chain.PostRequest(req, nil)
 	require.NoError(t, err)
-	
+
 	checkStateCounter(t, chain, 2)
 }
 
 func TestIncrementRepeatThrice(t *testing.T) {
+	common.RunGoAndWasm(t, inccounter.OnLoad, testIncrementRepeatThrice)
+}
+
+func testIncrementRepeatThrice(t *testing.T, w bool) {
 	chain := setupTest(t)
-	
+
 	req := solo.NewCallParams(ScName, FuncRepeatMany,
 		ParamNumRepeats, 3,
 	).WithTransfer(balance.ColorIOTA, 1) // !!! posts to self
 	_, err := chain.PostRequest(req, nil)
 	require.NoError(t, err)
-	
+
 	chain.WaitForEmptyBacklog()
-	
+
 	checkStateCounter(t, chain, 4)
 }
 
 func TestIncrementCallIncrement(t *testing.T) {
+	common.RunGoAndWasm(t, inccounter.OnLoad, testIncrementCallIncrement)
+}
+
+func testIncrementCallIncrement(t *testing.T, w bool) {
 	chain := setupTest(t)
-	
+
 	req := solo.NewCallParams(ScName, FuncCallIncrement)
 	_, err := chain.PostRequest(req, nil)
 	require.NoError(t, err)
@@ -77,6 +104,10 @@ func TestIncrementCallIncrement(t *testing.T) {
 }
 
 func TestIncrementCallIncrementRecurse5x(t *testing.T) {
+	common.RunGoAndWasm(t, inccounter.OnLoad, testIncrementCallIncrementRecurse5x)
+}
+
+func testIncrementCallIncrementRecurse5x(t *testing.T, w bool) {
 	chain := setupTest(t)
 
 	req := solo.NewCallParams(ScName, FuncCallIncrementRecurse5x)
@@ -87,6 +118,10 @@ func TestIncrementCallIncrementRecurse5x(t *testing.T) {
 }
 
 func TestIncrementPostIncrement(t *testing.T) {
+	common.RunGoAndWasm(t, inccounter.OnLoad, testIncrementPostIncrement)
+}
+
+func testIncrementPostIncrement(t *testing.T, w bool) {
 	chain := setupTest(t)
 
 	req := solo.NewCallParams(ScName, FuncPostIncrement).WithTransfer(balance.ColorIOTA, 1) // !!! posts to self
@@ -99,6 +134,10 @@ func TestIncrementPostIncrement(t *testing.T) {
 }
 
 func TestIncrementLocalStateInternalCall(t *testing.T) {
+	common.RunGoAndWasm(t, inccounter.OnLoad, testIncrementLocalStateInternalCall)
+}
+
+func testIncrementLocalStateInternalCall(t *testing.T, w bool) {
 	chain := setupTest(t)
 
 	req := solo.NewCallParams(ScName, FuncLocalStateInternalCall)
@@ -109,17 +148,30 @@ func TestIncrementLocalStateInternalCall(t *testing.T) {
 }
 
 func TestIncrementLocalStateSandboxCall(t *testing.T) {
+	common.RunGoAndWasm(t, inccounter.OnLoad, testIncrementLocalStateSandboxCall)
+}
+
+func testIncrementLocalStateSandboxCall(t *testing.T, w bool) {
 	chain := setupTest(t)
 
 	req := solo.NewCallParams(ScName, FuncLocalStateSandboxCall)
 	_, err := chain.PostRequest(req, nil)
 	require.NoError(t, err)
 
-	// global var in wasm execution has no effect
-	checkStateCounter(t, chain, nil)
+	if w {
+		// global var in wasm execution has no effect
+		checkStateCounter(t, chain, nil)
+		return
+	}
+	// global var of the Go build keeps its value between calls
+	checkStateCounter(t, chain, 2)
 }
 
 func TestIncrementLocalStatePost(t *testing.T) {
+	common.RunGoAndWasm(t, inccounter.OnLoad, testIncrementLocalStatePost)
+}
+
+func testIncrementLocalStatePost(t *testing.T, w bool) {
 	chain := setupTest(t)
 
 	req := solo.NewCallParams(ScName, FuncLocalStatePost).WithTransfer(balance.ColorIOTA, 1) // !!! posts to self
@@ -128,8 +180,45 @@ func TestIncrementLocalStatePost(t *testing.T) {
 
 	chain.WaitForEmptyBacklog()
 
-	// global var in wasm execution has no effect
-	checkStateCounter(t, chain, nil)
+	if w {
+		// global var in wasm execution has no effect
+		checkStateCounter(t, chain, nil)
+		return
+	}
+	// global var of the Go build keeps its value until the posted request is run
+	// only the first post is funded by the transfer
+	checkStateCounter(t, chain, 1)
+}
+
+// TestIncrementGoChainsInParallel runs the Go build on several chains at once, the first
+// request recurses up to 6, the other 9 increment once.
+// The Go contracts share wasmlib, so the calls of the chains must not get mixed up
+func TestIncrementGoChainsInParallel(t *testing.T) {
+	env := solo.New(t, false, false)
+	chains := make([]*solo.Chain, 3)
+	for i := range chains {
+		chains[i] = env.NewChain(nil, fmt.Sprintf("chain%d", i))
+		err := chains[i].DeployGoContract(nil, ScName, inccounter.OnLoad)
+		require.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	for _, chain := range chains {
+		wg.Add(1)
+		go func(chain *solo.Chain) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				req := solo.NewCallParams(ScName, FuncCallIncrementRecurse5x)
+				_, err := chain.PostRequest(req, nil)
+				require.NoError(t, err)
+			}
+		}(chain)
+	}
+	wg.Wait()
+
+	for _, chain := range chains {
+		checkStateCounter(t, chain, 15)
+	}
 }
 
 func checkStateCounter(t *testing.T, chain *solo.Chain, expected interface{}) {
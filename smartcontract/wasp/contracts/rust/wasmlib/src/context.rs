@@ -191,6 +191,19 @@ pub trait ScBaseContext {
         ScBalances { balances: ROOT.get_map(&KEY_BALANCES).immutable() }
     }
 
+    // index of the block of the state the function is called on, for funcs the block the request will be stored in
+    fn block_index(&self) -> i64 {
+        ROOT.get_int(&KEY_BLOCK_INDEX).value()
+    }
+
+    // retrieve the agent id of the caller of the smart contract, the zero agent id for views called from outside of the chain
+    fn caller(&self) -> ScAgentId { ROOT.get_agent_id(&KEY_CALLER).value() }
+
+    // retrieve the id of the chain this contract lives on
+    fn chain_id(&self) -> ScChainId {
+        ROOT.get_chain_id(&KEY_CHAIN_ID).value()
+    }
+
     // retrieve the agent id of the owner of the chain this contract lives on
     fn chain_owner_id(&self) -> ScAgentId {
         ROOT.get_agent_id(&KEY_CHAIN_OWNER_ID).value()
@@ -278,9 +291,6 @@ impl ScFuncContext {
         ROOT.get_map(&KEY_RETURN).immutable()
     }
 
-    // retrieve the agent id of the caller of the smart contract
-    pub fn caller(&self) -> ScAgentId { ROOT.get_agent_id(&KEY_CALLER).value() }
-
     // shorthand to synchronously call a smart contract function on the current contract
     pub fn call_self(&self, hfunction: ScHname, params: Option<ScMutableMap>, transfer: Option<Box<dyn Balances>>) -> ScImmutableMap {
         self.call(self.contract_id().hname(), hfunction, params, transfer)
@@ -301,11 +311,29 @@ impl ScFuncContext {
         ROOT.get_bytes(&KEY_DEPLOY).set_value(&encode.data());
     }
 
+    // signals a typed event with the specified topic and parameters
+    // the event is stored in the eventlog and can be queried by topic, parameter and request
+    pub fn emit_event(&self, topic: &str, params: Option<ScMutableMap>) {
+        let mut encode = BytesEncoder::new();
+        encode.string(topic);
+        if let Some(params) = params {
+            encode.int(params.obj_id as i64);
+        } else {
+            encode.int(0);
+        }
+        ROOT.get_bytes(&KEY_EVENT).set_value(&encode.data());
+    }
+
     // signals an event on the node that external entities can subscribe to
     pub fn event(&self, text: &str) {
         ROOT.get_string(&KEY_EVENT).set_value(text)
     }
 
+    // 32 bytes of deterministic and unpredictably random data, based on the hash of the current state transaction
+    pub fn get_entropy(&self) -> ScHash {
+        ROOT.get_hash(&KEY_ENTROPY).value()
+    }
+
     // access the incoming balances for all token colors
     pub fn incoming(&self) -> ScBalances {
         ScBalances { balances: ROOT.get_map(&KEY_INCOMING).immutable() }
@@ -331,6 +359,11 @@ impl ScFuncContext {
         ROOT.get_bytes(&KEY_POST).set_value(&encode.data());
     }
 
+    // retrieve the id of the request the function is called for
+    pub fn request_id(&self) -> ScRequestId {
+        ScRequestId::from_bytes(&ROOT.get_bytes(&KEY_REQUEST_ID).value())
+    }
+
     // access to mutable state storage
     pub fn state(&self) -> ScMutableMap {
         ROOT.get_map(&KEY_STATE)
@@ -39,9 +39,11 @@ pub const CORE_ROOT_FUNC_GRANT_DEPLOY_PERMISSION: ScHname = ScHname(0xf440263a);
 pub const CORE_ROOT_FUNC_REVOKE_DEPLOY_PERMISSION: ScHname = ScHname(0x850744f1);
 pub const CORE_ROOT_FUNC_SET_CONTRACT_FEE: ScHname = ScHname(0x8421a42b);
 pub const CORE_ROOT_FUNC_SET_DEFAULT_FEE: ScHname = ScHname(0x3310ecd0);
+pub const CORE_ROOT_FUNC_UPGRADE_CONTRACT: ScHname = ScHname(0x00d30d5c);
 pub const CORE_ROOT_VIEW_FIND_CONTRACT: ScHname = ScHname(0xc145ca00);
 pub const CORE_ROOT_VIEW_GET_CHAIN_INFO: ScHname = ScHname(0x434477e2);
 pub const CORE_ROOT_VIEW_GET_FEE_INFO: ScHname = ScHname(0x9fe54b48);
+pub const CORE_ROOT_VIEW_GET_UPGRADE_HISTORY: ScHname = ScHname(0x09671a6a);
 
 pub const CORE_ROOT_PARAM_CHAIN_OWNER: &str = "$$owner$$";
 pub const CORE_ROOT_PARAM_DEPLOYER: &str = "$$deployer$$";
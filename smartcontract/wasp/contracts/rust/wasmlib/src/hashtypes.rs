@@ -294,3 +294,35 @@ impl MapKey for ScHname {
         get_key_id_from_bytes(&self.0.to_ne_bytes())
     }
 }
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
+// container object for 34-byte request id
+#[derive(PartialEq, Clone)]
+pub struct ScRequestId {
+    id: [u8; 34],
+}
+
+impl ScRequestId {
+    // construct from byte array
+    pub fn from_bytes(bytes: &[u8]) -> ScRequestId {
+        ScRequestId { id: bytes.try_into().expect("invalid request id length") }
+    }
+
+    // convert to byte array representation
+    pub fn to_bytes(&self) -> &[u8] {
+        &self.id
+    }
+
+    // human-readable string representation
+    pub fn to_string(&self) -> String {
+        base58_encode(&self.id)
+    }
+}
+
+// allow to be used as key in maps
+impl MapKey for ScRequestId {
+    fn get_id(&self) -> Key32 {
+        get_key_id_from_bytes(self.to_bytes())
+    }
+}
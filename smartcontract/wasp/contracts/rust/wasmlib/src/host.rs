@@ -7,6 +7,8 @@ use crate::keys::*;
 
 // all TYPE_* values should exactly match the counterpart OBJTYPE_* values on the host!
 pub const TYPE_ARRAY: i32 = 0x20;
+// combined with TYPE_ARRAY for an array of arrays
+pub const TYPE_ARRAY2: i32 = 0x40;
 
 pub const TYPE_ADDRESS: i32 = 1;
 pub const TYPE_AGENT_ID: i32 = 2;
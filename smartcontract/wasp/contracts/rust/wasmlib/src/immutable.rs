@@ -142,6 +142,25 @@ impl ScImmutableBytesArray {
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
+pub struct ScImmutableBytesArrayArray {
+    pub(crate) obj_id: i32
+}
+
+impl ScImmutableBytesArrayArray {
+    // index 0..length(), exclusive
+    pub fn get_bytes_array(&self, index: i32) -> ScImmutableBytesArray {
+        let arr_id = get_object_id(self.obj_id, Key32(index), TYPE_BYTES | TYPE_ARRAY);
+        ScImmutableBytesArray { obj_id: arr_id }
+    }
+
+    // number of items in array
+    pub fn length(&self) -> i32 {
+        get_length(self.obj_id)
+    }
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
 // proxy object for immutable ScChainId in host map
 pub struct ScImmutableChainId {
     obj_id: i32,
@@ -350,6 +369,25 @@ impl ScImmutableIntArray {
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
+pub struct ScImmutableIntArrayArray {
+    pub(crate) obj_id: i32
+}
+
+impl ScImmutableIntArrayArray {
+    // index 0..length(), exclusive
+    pub fn get_int_array(&self, index: i32) -> ScImmutableIntArray {
+        let arr_id = get_object_id(self.obj_id, Key32(index), TYPE_INT | TYPE_ARRAY);
+        ScImmutableIntArray { obj_id: arr_id }
+    }
+
+    // number of items in array
+    pub fn length(&self) -> i32 {
+        get_length(self.obj_id)
+    }
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
 pub struct ScImmutableMap {
     pub(crate) obj_id: i32
 }
@@ -388,6 +426,12 @@ impl ScImmutableMap {
         ScImmutableBytesArray { obj_id: arr_id }
     }
 
+    // get proxy for ScImmutableBytesArrayArray specified by key
+    pub fn get_bytes_array_array<T: MapKey + ?Sized>(&self, key: &T) -> ScImmutableBytesArrayArray {
+        let arr_id = get_object_id(self.obj_id, key.get_id(), TYPE_BYTES | TYPE_ARRAY | TYPE_ARRAY2);
+        ScImmutableBytesArrayArray { obj_id: arr_id }
+    }
+
     // get proxy for immutable ScChainId field specified by key
     pub fn get_chain_id<T: MapKey + ?Sized>(&self, key: &T) -> ScImmutableChainId {
         ScImmutableChainId { obj_id: self.obj_id, key_id: key.get_id() }
@@ -436,6 +480,12 @@ impl ScImmutableMap {
         ScImmutableIntArray { obj_id: arr_id }
     }
 
+    // get proxy for ScImmutableIntArrayArray specified by key
+    pub fn get_int_array_array<T: MapKey + ?Sized>(&self, key: &T) -> ScImmutableIntArrayArray {
+        let arr_id = get_object_id(self.obj_id, key.get_id(), TYPE_INT | TYPE_ARRAY | TYPE_ARRAY2);
+        ScImmutableIntArrayArray { obj_id: arr_id }
+    }
+
     // get proxy for ScImmutableMap specified by key
     pub fn get_map<T: MapKey + ?Sized>(&self, key: &T) -> ScImmutableMap {
         let map_id = get_object_id(self.obj_id, key.get_id(), TYPE_MAP);
@@ -458,6 +508,12 @@ impl ScImmutableMap {
         let arr_id = get_object_id(self.obj_id, key.get_id(), TYPE_STRING | TYPE_ARRAY);
         ScImmutableStringArray { obj_id: arr_id }
     }
+
+    // get proxy for ScImmutableStringArrayArray specified by key
+    pub fn get_string_array_array<T: MapKey + ?Sized>(&self, key: &T) -> ScImmutableStringArrayArray {
+        let arr_id = get_object_id(self.obj_id, key.get_id(), TYPE_STRING | TYPE_ARRAY | TYPE_ARRAY2);
+        ScImmutableStringArrayArray { obj_id: arr_id }
+    }
 }
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
@@ -524,3 +580,22 @@ impl ScImmutableStringArray {
         get_length(self.obj_id)
     }
 }
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
+pub struct ScImmutableStringArrayArray {
+    pub(crate) obj_id: i32
+}
+
+impl ScImmutableStringArrayArray {
+    // index 0..length(), exclusive
+    pub fn get_string_array(&self, index: i32) -> ScImmutableStringArray {
+        let arr_id = get_object_id(self.obj_id, Key32(index), TYPE_STRING | TYPE_ARRAY);
+        ScImmutableStringArray { obj_id: arr_id }
+    }
+
+    // number of items in array
+    pub fn length(&self) -> i32 {
+        get_length(self.obj_id)
+    }
+}
@@ -68,4 +68,10 @@ pub const KEY_VALID            : Key32 = Key32(-34);
 pub const KEY_VALID_BLS        : Key32 = Key32(-35);
 pub const KEY_VALID_ED25519    : Key32 = Key32(-36);
 pub const KEY_ZZZZZZZ          : Key32 = Key32(-37);
+
+// keys added after KEY_ZZZZZZZ, see wasmhost.KeyZzzzzzz
+pub const KEY_BLOCK_INDEX      : Key32 = Key32(-38);
+pub const KEY_CHAIN_ID         : Key32 = Key32(-39);
+pub const KEY_ENTROPY          : Key32 = Key32(-40);
+pub const KEY_REQUEST_ID       : Key32 = Key32(-41);
 // @formatter:on
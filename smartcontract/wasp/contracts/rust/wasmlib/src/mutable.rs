@@ -188,6 +188,35 @@ impl ScMutableBytesArray {
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
+pub struct ScMutableBytesArrayArray {
+    pub(crate) obj_id: i32
+}
+
+impl ScMutableBytesArrayArray {
+    // empty the array
+    pub fn clear(&self) {
+        clear(self.obj_id);
+    }
+
+    // index 0..length(), when length() a new one is appended
+    pub fn get_bytes_array(&self, index: i32) -> ScMutableBytesArray {
+        let arr_id = get_object_id(self.obj_id, Key32(index), TYPE_BYTES | TYPE_ARRAY);
+        ScMutableBytesArray { obj_id: arr_id }
+    }
+
+    // get immutable version of array
+    pub fn immutable(&self) -> ScImmutableBytesArrayArray {
+        ScImmutableBytesArrayArray { obj_id: self.obj_id }
+    }
+
+    // number of items in array
+    pub fn length(&self) -> i32 {
+        get_length(self.obj_id)
+    }
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
 // proxy object for mutable ScChainId in host map
 pub struct ScMutableChainId {
     obj_id: i32,
@@ -456,6 +485,35 @@ impl ScMutableIntArray {
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
+pub struct ScMutableIntArrayArray {
+    pub(crate) obj_id: i32
+}
+
+impl ScMutableIntArrayArray {
+    // empty the array
+    pub fn clear(&self) {
+        clear(self.obj_id);
+    }
+
+    // index 0..length(), when length() a new one is appended
+    pub fn get_int_array(&self, index: i32) -> ScMutableIntArray {
+        let arr_id = get_object_id(self.obj_id, Key32(index), TYPE_INT | TYPE_ARRAY);
+        ScMutableIntArray { obj_id: arr_id }
+    }
+
+    // get immutable version of array
+    pub fn immutable(&self) -> ScImmutableIntArrayArray {
+        ScImmutableIntArrayArray { obj_id: self.obj_id }
+    }
+
+    // number of items in array
+    pub fn length(&self) -> i32 {
+        get_length(self.obj_id)
+    }
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
 pub struct ScMutableMap {
     pub(crate) obj_id: i32
 }
@@ -504,6 +562,12 @@ impl ScMutableMap {
         ScMutableBytesArray { obj_id: arr_id }
     }
 
+    // get proxy for ScMutableBytesArrayArray specified by key
+    pub fn get_bytes_array_array<T: MapKey + ?Sized>(&self, key: &T) -> ScMutableBytesArrayArray {
+        let arr_id = get_object_id(self.obj_id, key.get_id(), TYPE_BYTES | TYPE_ARRAY | TYPE_ARRAY2);
+        ScMutableBytesArrayArray { obj_id: arr_id }
+    }
+
     // get proxy for mutable ScChainId field specified by key
     pub fn get_chain_id<T: MapKey + ?Sized>(&self, key: &T) -> ScMutableChainId {
         ScMutableChainId { obj_id: self.obj_id, key_id: key.get_id() }
@@ -552,6 +616,12 @@ impl ScMutableMap {
         ScMutableIntArray { obj_id: arr_id }
     }
 
+    // get proxy for ScMutableIntArrayArray specified by key
+    pub fn get_int_array_array<T: MapKey + ?Sized>(&self, key: &T) -> ScMutableIntArrayArray {
+        let arr_id = get_object_id(self.obj_id, key.get_id(), TYPE_INT | TYPE_ARRAY | TYPE_ARRAY2);
+        ScMutableIntArrayArray { obj_id: arr_id }
+    }
+
     // get proxy for ScMutableMap specified by key
     pub fn get_map<T: MapKey + ?Sized>(&self, key: &T) -> ScMutableMap {
         let map_id = get_object_id(self.obj_id, key.get_id(), TYPE_MAP);
@@ -575,6 +645,12 @@ impl ScMutableMap {
         ScMutableStringArray { obj_id: arr_id }
     }
 
+    // get proxy for ScMutableStringArrayArray specified by key
+    pub fn get_string_array_array<T: MapKey + ?Sized>(&self, key: &T) -> ScMutableStringArrayArray {
+        let arr_id = get_object_id(self.obj_id, key.get_id(), TYPE_STRING | TYPE_ARRAY | TYPE_ARRAY2);
+        ScMutableStringArrayArray { obj_id: arr_id }
+    }
+
     // get immutable version of map
     pub fn immutable(&self) -> ScImmutableMap {
         ScImmutableMap{obj_id:self.obj_id}
@@ -670,3 +746,32 @@ impl ScMutableStringArray {
         get_length(self.obj_id)
     }
 }
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
+pub struct ScMutableStringArrayArray {
+    pub(crate) obj_id: i32
+}
+
+impl ScMutableStringArrayArray {
+    // empty the array
+    pub fn clear(&self) {
+        clear(self.obj_id);
+    }
+
+    // index 0..length(), when length() a new one is appended
+    pub fn get_string_array(&self, index: i32) -> ScMutableStringArray {
+        let arr_id = get_object_id(self.obj_id, Key32(index), TYPE_STRING | TYPE_ARRAY);
+        ScMutableStringArray { obj_id: arr_id }
+    }
+
+    // get immutable version of array
+    pub fn immutable(&self) -> ScImmutableStringArrayArray {
+        ScImmutableStringArrayArray { obj_id: self.obj_id }
+    }
+
+    // number of items in array
+    pub fn length(&self) -> i32 {
+        get_length(self.obj_id)
+    }
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/iotaledger/wasp/plugins/globals"
 	"github.com/iotaledger/wasp/plugins/gracefulshutdown"
 	"github.com/iotaledger/wasp/plugins/logger"
+	"github.com/iotaledger/wasp/plugins/metrics"
+	"github.com/iotaledger/wasp/plugins/mqttpublisher"
 	"github.com/iotaledger/wasp/plugins/nodeconn"
 	"github.com/iotaledger/wasp/plugins/peering"
 	"github.com/iotaledger/wasp/plugins/publisher"
@@ -37,6 +39,7 @@ func main() {
 		logger.Init(),
 		gracefulshutdown.Init(),
 		webapi.Init(),
+		metrics.Init(),
 		cli.Init(),
 		database.Init(),
 		registry.Init(suite),
@@ -46,6 +49,7 @@ func main() {
 		dispatcher.Init(),
 		chains.Init(),
 		publisher.Init(),
+		mqttpublisher.Init(),
 		dashboard.Init(),
 		wasmtimevm.Init(),
 		globals.Init(),
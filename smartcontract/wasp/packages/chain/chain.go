@@ -40,6 +40,8 @@ type Chain interface {
 	// requests
 	GetRequestProcessingStatus(*coretypes.RequestID) RequestProcessingStatus
 	EventRequestProcessed() *events.Event
+	// RequestTraces returns traces of the latest requests. Nil if tracing of requests is not enabled on the node
+	RequestTraces() *RequestTraces
 	// chain processors
 	Processors() *processors.ProcessorCache
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/iotaledger/wasp/packages/chain/consensus"
 	"github.com/iotaledger/wasp/packages/chain/statemgr"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/parameters"
 	"github.com/iotaledger/wasp/packages/peering"
 	"github.com/iotaledger/wasp/packages/registry"
 	"github.com/iotaledger/wasp/packages/util"
@@ -48,6 +49,7 @@ type chainObj struct {
 	isCommitteeNode atomic.Bool
 	//
 	eventRequestProcessed *events.Event
+	requestTraces         *chain.RequestTraces
 	log                   *logger.Logger
 	netProvider           peering.NetworkProvider
 	peersAttachRef        interface{}
@@ -112,6 +114,9 @@ func newCommitteeObj(
 		dksProvider:  dksProvider,
 		blobProvider: blobProvider,
 	}
+	if parameters.GetBool(parameters.VMTraceRequests) {
+		ret.requestTraces = chain.NewRequestTraces(parameters.GetInt(parameters.VMMaxRequestTraces))
+	}
 	ret.peersAttachRef = peers.Attach(&ret.chainID, func(recv *peering.RecvEvent) {
 		ret.ReceiveMessage(recv.Msg)
 	})
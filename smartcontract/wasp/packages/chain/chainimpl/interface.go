@@ -101,7 +101,7 @@ func (c *chainObj) checkReady() bool {
 		c.onActivation()
 
 		c.log.Infof("committee now is fully initialized")
-		publisher.Publish("active_committee", c.chainID.String())
+		publisher.PublishMessage(publisher.NewCommitteeMessage(&c.chainID, true))
 	}
 	return c.isReady()
 }
@@ -132,7 +132,7 @@ func (c *chainObj) Dismiss() {
 		c.operator.Close()
 	})
 
-	publisher.Publish("dismissed_committee", c.chainID.String())
+	publisher.PublishMessage(publisher.NewCommitteeMessage(&c.chainID, false))
 }
 
 func (c *chainObj) IsDismissed() bool {
@@ -315,3 +315,7 @@ func (c *chainObj) Processors() *processors.ProcessorCache {
 func (c *chainObj) EventRequestProcessed() *events.Event {
 	return c.eventRequestProcessed
 }
+
+func (c *chainObj) RequestTraces() *chain.RequestTraces {
+	return c.requestTraces
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/metrics"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/util"
@@ -77,6 +78,7 @@ func (op *operator) rotateLeader() {
 	}
 	prevlead, _ := op.currentLeader()
 	leader := op.moveToNextLeader()
+	metrics.LeaderRotated(op.chain.ID().String())
 
 	// starting from scratch with the new leader
 	op.leaderStatus = nil
@@ -224,6 +226,7 @@ func (op *operator) checkQuorum() {
 		return
 	}
 	// quorum detected
+	metrics.QuorumReached(op.chain.ID().String(), time.Since(op.consensusStageStarted))
 
 	// finalizing result transaction with signatures
 	if err := op.aggregateSigShares(sigShares); err != nil {
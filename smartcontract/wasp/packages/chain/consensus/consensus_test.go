@@ -0,0 +1,302 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/utxodb"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/parameters"
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/sctransaction/origin"
+	"github.com/iotaledger/wasp/packages/sctransaction/txbuilder"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/processors"
+	"github.com/iotaledger/wasp/plugins/config"
+	"github.com/stretchr/testify/require"
+)
+
+const testSeed = "EFonzaUz5ngYeDxbRKu8qV5aoSogUQ5qVSTSjn7hJ8FQ"
+
+// TestMain points the database of the node to a temporary directory,
+// the operator checks there if the requests are already processed.
+func TestMain(m *testing.M) {
+	dbDir, err := ioutil.TempDir("", "consensus_test")
+	if err != nil {
+		panic(err)
+	}
+	config.Init()
+	if err := config.Node.Set(parameters.DatabaseDir, dbDir); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dbDir)
+	os.Exit(code)
+}
+
+// simChain is a committee node running the operator on the simulated network.
+// Messages are dispatched to the operator synchronously, in the goroutine of the test.
+// The color of the chain is not set, so the VM is not started: the scenarios end when
+// the leader starts the calculations.
+type simChain struct {
+	chainID  coretypes.ChainID
+	ownIndex uint16
+	quorum   uint16
+	peers    peering.GroupProvider
+	op       *operator
+	log      *logger.Logger
+}
+
+func (c *simChain) ID() *coretypes.ChainID { return &c.chainID }
+func (c *simChain) Color() *balance.Color  { return &balance.ColorIOTA }
+func (c *simChain) Address() address.Address {
+	return address.Address(c.chainID)
+}
+func (c *simChain) Size() uint16         { return uint16(len(c.peers.AllNodes())) }
+func (c *simChain) Quorum() uint16       { return c.quorum }
+func (c *simChain) OwnPeerIndex() uint16 { return c.ownIndex }
+func (c *simChain) NumPeers() uint16     { return uint16(len(c.peers.AllNodes())) }
+
+func (c *simChain) SendMsg(targetPeerIndex uint16, msgType byte, msgData []byte) error {
+	if peer, ok := c.peers.OtherNodes()[targetPeerIndex]; ok {
+		peer.SendMsg(&peering.PeerMessage{
+			ChainID:     c.chainID,
+			SenderIndex: c.ownIndex,
+			MsgType:     msgType,
+			MsgData:     msgData,
+		})
+		return nil
+	}
+	return fmt.Errorf("SendMsg: wrong peer index")
+}
+
+func (c *simChain) SendMsgToCommitteePeers(msgType byte, msgData []byte, ts int64) uint16 {
+	c.peers.Broadcast(&peering.PeerMessage{
+		ChainID:     c.chainID,
+		SenderIndex: c.ownIndex,
+		Timestamp:   ts,
+		MsgType:     msgType,
+		MsgData:     msgData,
+	}, false)
+	return uint16(len(c.peers.OtherNodes()))
+}
+
+func (c *simChain) IsAlivePeer(peerIndex uint16) bool {
+	if peerIndex == c.ownIndex {
+		return true
+	}
+	peer, ok := c.peers.AllNodes()[peerIndex]
+	return ok && peer.IsAlive()
+}
+
+func (c *simChain) HasQuorum() bool {
+	count := uint16(0)
+	for i := range c.peers.AllNodes() {
+		if c.IsAlivePeer(i) {
+			count++
+		}
+	}
+	return count >= c.quorum
+}
+
+func (c *simChain) ReceiveMessage(msg interface{})  {}
+func (c *simChain) InitTestRound()                  {}
+func (c *simChain) PeerStatus() []*chain.PeerStatus { return nil }
+func (c *simChain) BlobCache() coretypes.BlobCache  { return nil }
+func (c *simChain) SetReadyStateManager()           {}
+func (c *simChain) SetReadyConsensus()              {}
+func (c *simChain) Dismiss()                        {}
+func (c *simChain) IsDismissed() bool               { return false }
+func (c *simChain) GetRequestProcessingStatus(*coretypes.RequestID) chain.RequestProcessingStatus {
+	return chain.RequestProcessingStatusUnknown
+}
+func (c *simChain) EventRequestProcessed() *events.Event   { return nil }
+func (c *simChain) RequestTraces() *chain.RequestTraces    { return nil }
+func (c *simChain) Processors() *processors.ProcessorCache { return nil }
+
+// recv dispatches the peer message to the operator, like the dispatcher of the chain does.
+func (c *simChain) recv(recv *peering.RecvEvent) {
+	switch recv.Msg.MsgType {
+	case chain.MsgNotifyRequests:
+		msg := &chain.NotifyReqMsg{}
+		if err := msg.Read(bytes.NewReader(recv.Msg.MsgData)); err != nil {
+			c.log.Error(err)
+			return
+		}
+		msg.SenderIndex = recv.Msg.SenderIndex
+		c.op.eventNotifyReqMsg(msg)
+	}
+}
+
+type simCommittee struct {
+	sim     *testutil.PeeringSimulator
+	netIDs  []string
+	nodes   []*simChain
+	stateTx *sctransaction.Transaction
+	reqTx   *sctransaction.Transaction
+}
+
+// newSimCommittee creates the committee of the chain in the origin state on the simulated network
+// and the transaction with a request to the chain
+func newSimCommittee(t *testing.T, size, quorum uint16, seed int64) *simCommittee {
+	log := testutil.NewLogger(t)
+	netIDs := make([]string, size)
+	for i := range netIDs {
+		netIDs[i] = fmt.Sprintf("P%02d", i)
+	}
+	sim := testutil.NewPeeringSimulator(netIDs, seed, testutil.WithLevel(log, logger.LevelInfo, false))
+
+	// the chain address is derived from the seed, so the leader doesn't change between the runs
+	u := utxodb.New()
+	originator := utxodb.NewSigScheme(testSeed, 2*int(seed))
+	_, err := u.RequestFunds(originator.Address())
+	require.NoError(t, err)
+	chainAddr := utxodb.NewSigScheme(testSeed, 2*int(seed)+1).Address()
+	stateTx, err := origin.NewOriginTransaction(origin.NewOriginTransactionParams{
+		OriginAddress:             chainAddr,
+		OriginatorSignatureScheme: originator,
+		AllInputs:                 u.GetAddressOutputs(originator.Address()),
+	})
+	require.NoError(t, err)
+	require.NoError(t, u.AddTransaction(stateTx.Transaction))
+	chainID := coretypes.ChainID(chainAddr)
+
+	txb, err := txbuilder.NewFromOutputBalances(u.GetAddressOutputs(originator.Address()))
+	require.NoError(t, err)
+	contractID := coretypes.NewContractID(chainID, root.Interface.Hname())
+	require.NoError(t, txb.AddRequestSection(sctransaction.NewRequestSection(0, contractID, coretypes.Hn("test"))))
+	reqTx, err := txb.Build(false)
+	require.NoError(t, err)
+	reqTx.Sign(originator)
+
+	ret := &simCommittee{sim: sim, netIDs: netIDs, stateTx: stateTx, reqTx: reqTx}
+	netProviders := sim.NetworkProviders()
+	for i := range netIDs {
+		peers, err := netProviders[i].Group(netIDs)
+		require.NoError(t, err)
+		index := uint16(i)
+		node := &simChain{
+			chainID:  chainID,
+			ownIndex: index,
+			quorum:   quorum,
+			peers:    peers,
+			log:      log.Named(netIDs[i]),
+		}
+		node.op = NewOperator(node, &tcrypto.DKShare{Index: &index, N: size, T: quorum}, node.log)
+		netProviders[i].Attach(&chainID, node.recv)
+		ret.nodes = append(ret.nodes, node)
+	}
+	t.Cleanup(func() {
+		for _, node := range ret.nodes {
+			node.op.Close()
+		}
+	})
+	return ret
+}
+
+// syncState brings all operators to the origin state
+func (sc *simCommittee) syncState(t *testing.T) {
+	for _, node := range sc.nodes {
+		vs := state.NewVirtualState(nil, &node.chainID)
+		require.NoError(t, vs.ApplyBlock(state.MustNewOriginBlock(nil)))
+		node.op.eventStateTransitionMsg(&chain.StateTransitionMsg{
+			VariableState:     vs,
+			AnchorTransaction: sc.stateTx,
+			Synchronized:      true,
+		})
+	}
+}
+
+// postRequest gives the request to all operators
+func (sc *simCommittee) postRequest() {
+	for _, node := range sc.nodes {
+		node.op.eventRequestMsg(&chain.RequestMsg{Transaction: sc.reqTx, Index: 0})
+	}
+}
+
+// leader returns the leader of the current state
+func (sc *simCommittee) leader() *simChain {
+	idx, _ := sc.nodes[0].op.currentLeader()
+	return sc.nodes[idx]
+}
+
+func (sc *simCommittee) countTrace(event testutil.SimTraceEvent, msgType byte) int {
+	ret := 0
+	for _, e := range sc.sim.Trace() {
+		if e.Event == event && e.MsgType == msgType {
+			ret++
+		}
+	}
+	return ret
+}
+
+// TestSimulatedConsensusStart checks, that the leader starts the batch after the notifications
+// of the quorum of the committee, and that the run is reproducible.
+func TestSimulatedConsensusStart(t *testing.T) {
+	run := func() string {
+		sc := newSimCommittee(t, 4, 3, 42)
+		sc.sim.SetDelay(10*time.Millisecond, 100*time.Millisecond)
+		sc.syncState(t)
+		sc.postRequest()
+		sc.sim.RunUntilIdle(1000)
+
+		leader := sc.leader()
+		require.EqualValues(t, consensusStageLeaderCalculationsStarted, leader.op.consensusStage)
+		require.NotNil(t, leader.op.leaderStatus)
+		require.Len(t, leader.op.leaderStatus.reqs, 1)
+		require.EqualValues(t, 3, sc.countTrace(testutil.SimDelivered, chain.MsgNotifyRequests))
+		require.EqualValues(t, 3, sc.countTrace(testutil.SimSent, chain.MsgStartProcessingRequest))
+		for _, node := range sc.nodes {
+			if node != leader {
+				require.EqualValues(t, consensusStageSubNotificationsSent, node.op.consensusStage)
+			}
+		}
+		return sc.sim.TraceString()
+	}
+	require.Equal(t, run(), run())
+}
+
+// TestSimulatedConsensusPartition checks, that no batch is started while the committee is partitioned
+// and the leader starts it, when the network is healed.
+func TestSimulatedConsensusPartition(t *testing.T) {
+	sc := newSimCommittee(t, 4, 3, 43)
+	sc.syncState(t)
+	leader := sc.leader()
+	others := make([]string, 0)
+	for i, netID := range sc.netIDs {
+		if uint16(i) != leader.ownIndex {
+			others = append(others, netID)
+		}
+	}
+	// the leader stays with one peer only, no one has the quorum
+	sc.sim.Partition([]string{sc.netIDs[leader.ownIndex], others[0]}, others[1:])
+	sc.postRequest()
+	sc.sim.RunUntilIdle(1000)
+	require.EqualValues(t, consensusStageLeaderStarting, leader.op.consensusStage)
+	require.EqualValues(t, 0, sc.countTrace(testutil.SimSent, chain.MsgNotifyRequests))
+
+	// the notifications are sent on the next timer tick after the network is healed
+	sc.sim.Heal()
+	for _, node := range sc.nodes {
+		node.op.eventTimerMsg(2)
+	}
+	sc.sim.RunUntilIdle(1000)
+	require.EqualValues(t, consensusStageLeaderCalculationsStarted, leader.op.consensusStage)
+	require.EqualValues(t, 3, sc.countTrace(testutil.SimDelivered, chain.MsgNotifyRequests))
+}
@@ -78,11 +78,8 @@ func (op *operator) requestFromMsg(reqMsg *chain.RequestMsg) (*request, bool) {
 		}
 	}
 	if newMsg {
-		publisher.Publish("request_in",
-			op.chain.ID().String(),
-			reqMsg.Transaction.ID().String(),
-			fmt.Sprintf("%d", reqMsg.Index),
-		)
+		reqID := coretypes.NewRequestID(reqMsg.Transaction.ID(), reqMsg.Index)
+		publisher.PublishMessage(publisher.NewRequestInMessage(op.chain.ID(), &reqID, reqMsg.RequestBlock().Target().Hname()))
 	}
 
 	ret.notifications[op.peerIndex()] = true
@@ -53,6 +53,9 @@ func (op *operator) runCalculationsAsync(par runCalculationsParams) {
 			Leader: par.leaderPeerIndex,
 		})
 	}
+	if traces := op.chain.RequestTraces(); traces != nil {
+		ctx.OnRequestTrace = traces.Put
+	}
 	if err := runvm.RunComputationsAsync(ctx); err != nil {
 		op.log.Errorf("RunComputationsAsync: %v", err)
 	}
@@ -33,6 +33,7 @@ type operator struct {
 	// consensus stage
 	consensusStage         int
 	consensusStageDeadline time.Time
+	consensusStageStarted  time.Time
 	//
 	requestBalancesDeadline time.Time
 
@@ -117,6 +118,7 @@ func NewOperator(committee chain.Chain, dkshare *tcrypto.DKShare, log *logger.Lo
 		requestIdsProtected:                 make(map[coretypes.RequestID]bool),
 		peerPermutation:                     util.NewPermutation16(committee.Size(), nil),
 		log:                                 log.Named("c"),
+		consensusStageStarted:               time.Now(),
 		eventStateTransitionMsgCh:           make(chan *chain.StateTransitionMsg),
 		eventBalancesMsgCh:                  make(chan chain.BalancesMsg),
 		eventRequestMsgCh:                   make(chan *chain.RequestMsg),
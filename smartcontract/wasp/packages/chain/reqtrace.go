@@ -0,0 +1,51 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package chain
+
+import (
+	"sync"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/vm"
+)
+
+// RequestTraces keeps the traces of the latest requests run by the VM of the node, see vm.RequestTrace.
+// The oldest traces are dropped when the number of traces exceeds the limit
+type RequestTraces struct {
+	mutex     sync.RWMutex
+	maxTraces int
+	traces    map[coretypes.RequestID]*vm.RequestTrace
+	order     []coretypes.RequestID
+}
+
+func NewRequestTraces(maxTraces int) *RequestTraces {
+	return &RequestTraces{
+		maxTraces: maxTraces,
+		traces:    make(map[coretypes.RequestID]*vm.RequestTrace),
+		order:     make([]coretypes.RequestID, 0, maxTraces),
+	}
+}
+
+// Put stores the trace. The trace of the request which is run again replaces the previous one
+func (t *RequestTraces) Put(trace *vm.RequestTrace) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if _, ok := t.traces[trace.RequestID]; !ok {
+		t.order = append(t.order, trace.RequestID)
+	}
+	t.traces[trace.RequestID] = trace
+	for len(t.order) > t.maxTraces {
+		delete(t.traces, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+// Get returns the trace of the request or nil if it is not known
+func (t *RequestTraces) Get(reqID *coretypes.RequestID) *vm.RequestTrace {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.traces[*reqID]
+}
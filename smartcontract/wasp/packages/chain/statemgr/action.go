@@ -4,13 +4,12 @@
 package statemgr
 
 import (
-	"fmt"
-	"strconv"
 	"time"
 
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/chain"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/metrics"
 	"github.com/iotaledger/wasp/packages/publisher"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/util"
@@ -110,10 +109,12 @@ func (sm *stateManager) checkStateApproval() bool {
 				return false
 			}
 		}
+		commitStart := time.Now()
 		if err := pending.nextState.CommitToDb(pending.block); err != nil {
 			sm.log.Errorw("failed to save state at index #%d", pending.nextState.BlockIndex())
 			return false
 		}
+		metrics.BlockCommitted(sm.chain.ID().String(), pending.nextState.BlockIndex(), time.Since(commitStart))
 
 		if sm.solidState != nil {
 			sm.log.Infof("STATE TRANSITION TO #%d. Anchor transaction: %s, block size: %d",
@@ -135,6 +136,7 @@ func (sm *stateManager) checkStateApproval() bool {
 	}
 	sm.solidStateValid = true
 	sm.solidState = pending.nextState
+	sm.updateSyncLagMetric()
 
 	sm.approvingTransaction = sm.nextStateTransaction
 
@@ -146,27 +148,27 @@ func (sm *stateManager) checkStateApproval() bool {
 	sm.consensusNotifiedOnStateTransition = false
 
 	// publish state transition
-	publisher.Publish("state",
-		sm.chain.ID().String(),
-		strconv.Itoa(int(sm.solidState.BlockIndex())),
-		strconv.Itoa(int(pending.block.Size())),
-		sm.approvingTransaction.ID().String(),
-		varStateHash.String(),
-		fmt.Sprintf("%d", pending.block.Timestamp()),
-	)
+	stateTxID := sm.approvingTransaction.ID()
+	publisher.PublishMessage(publisher.NewStateMessage(
+		sm.chain.ID(),
+		sm.solidState.BlockIndex(),
+		pending.block.Size(),
+		&stateTxID,
+		&varStateHash,
+		pending.block.Timestamp(),
+	))
 	// publish processed requests
 	for i, reqid := range pending.block.RequestIDs() {
 
 		sm.chain.EventRequestProcessed().Trigger(*reqid)
 
-		publisher.Publish("request_out",
-			sm.chain.ID().String(),
-			reqid.TransactionID().String(),
-			fmt.Sprintf("%d", reqid.Index()),
-			strconv.Itoa(int(sm.solidState.BlockIndex())),
-			strconv.Itoa(i),
-			strconv.Itoa(int(pending.block.Size())),
-		)
+		publisher.PublishMessage(publisher.NewRequestOutMessage(
+			sm.chain.ID(),
+			reqid,
+			sm.solidState.BlockIndex(),
+			uint16(i),
+			pending.block.Size(),
+		))
 	}
 	return true
 }
@@ -213,6 +215,7 @@ func (sm *stateManager) evidenceStateIndex(stateIndex uint32) {
 
 	if stateIndex > sm.largestEvidencedStateIndex {
 		sm.largestEvidencedStateIndex = stateIndex
+		sm.updateSyncLagMetric()
 	}
 	switch {
 	case !sm.isSynchronized() && wasSynchronized:
@@ -224,6 +227,18 @@ func (sm *stateManager) evidenceStateIndex(stateIndex uint32) {
 	}
 }
 
+// updateSyncLagMetric records the number of blocks the solid state is behind the largest evidenced state index
+func (sm *stateManager) updateSyncLagMetric() {
+	var lag uint32
+	switch {
+	case sm.solidState == nil:
+		lag = sm.largestEvidencedStateIndex + 1
+	case sm.largestEvidencedStateIndex > sm.solidState.BlockIndex():
+		lag = sm.largestEvidencedStateIndex - sm.solidState.BlockIndex()
+	}
+	metrics.StateSyncLag(sm.chain.ID().String(), lag)
+}
+
 func (sm *stateManager) isSynchronized() bool {
 	if sm.solidState == nil {
 		return false // sm.largestEvidencedStateIndex == 0
@@ -22,6 +22,10 @@ type Sandbox interface {
 	ContractID() ContractID
 	// Caller is the agentID of the caller.
 	Caller() AgentID
+	// ChainID is the ID of the chain the contract lives on
+	ChainID() ChainID
+	// BlockIndex is the index of the block the current request will be stored in
+	BlockIndex() uint32
 	// Params of the current call
 	Params() dict.Dict
 	// State k/v store of the current call (in the context of the smart contract)
@@ -52,6 +56,13 @@ type Sandbox interface {
 	Log() LogInterface
 	// Event publishes "vmmsg" message through Publisher on nanomsg. It also logs locally, but it is not the same thing
 	Event(msg string)
+	// EmitEvent stores the typed event with the topic and parameters in the eventlog, indexed by topic and by request.
+	// It is also published and logged like Event
+	EmitEvent(topic string, params dict.Dict)
+	// BurnGas charges gas to the budget of the current request. Panics with ErrGasBudgetExceeded when exhausted
+	BurnGas(amount uint64)
+	// GasRemaining is the gas left in the budget of the current request
+	GasRemaining() uint64
 	//
 	Utils() Utils
 }
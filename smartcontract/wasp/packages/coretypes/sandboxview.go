@@ -16,6 +16,13 @@ type SandboxView interface {
 	ContractCreator() AgentID
 	// ContractID is the ID of the current contract
 	ContractID() ContractID
+	// Caller is the agentID of the caller. It is the zero AgentID when the view is called from outside of the chain
+	Caller() AgentID
+	// ChainID is the ID of the chain the contract lives on
+	ChainID() ChainID
+	// BlockIndex is the index of the last block of the state the view is called on.
+	// In the context of a request it is the index of the block the request will be stored in
+	BlockIndex() uint32
 	// GetTimestamp return timestamp of the current state
 	GetTimestamp() int64
 	// Params of the current call
@@ -28,6 +35,10 @@ type SandboxView interface {
 	Balances() ColoredBalances
 	// Log interface provides local logging on the machine. It includes Panicf method
 	Log() LogInterface
+	// BurnGas charges gas to the budget of the current call. Panics with ErrGasBudgetExceeded when exhausted
+	BurnGas(amount uint64)
+	// GasRemaining is the gas left in the budget of the current call
+	GasRemaining() uint64
 	//
 	Utils() Utils
 }
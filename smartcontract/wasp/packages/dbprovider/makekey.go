@@ -15,6 +15,9 @@ const (
 	ObjectTypeNodeIdentity
 	ObjectTypeBlobCache
 	ObjectTypeBlobCacheTTL
+	ObjectTypeAPIToken
+	ObjectTypeAPIKey
+	ObjectTypeTrustedPeer
 )
 
 // MakeKey makes key within the partition. It consists to one byte for object type
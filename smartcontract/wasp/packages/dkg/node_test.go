@@ -15,6 +15,7 @@ import (
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/dkg"
 	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/packages/testutil"
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/kyber/v3"
@@ -182,6 +183,79 @@ func TestUnreliableNet(t *testing.T) {
 	require.NotNil(t, dkShare.SharedPublic)
 }
 
+// TestSimulatedNet checks, if DKG runs on the simulated network with delays and lost messages.
+func TestSimulatedNet(t *testing.T) {
+	log := testutil.NewLogger(t)
+	defer log.Sync()
+	//
+	// Create a simulated network and keys for the tests.
+	var timeout = 100 * time.Second
+	var threshold uint16 = 3
+	var peerCount uint16 = 4
+	var peerNetIDs []string = make([]string, peerCount)
+	var suite = pairing.NewSuiteBn256() // That's from the Pairing Adapter.
+	for i := range peerNetIDs {
+		peerNetIDs[i] = fmt.Sprintf("P%02d", i)
+	}
+	sim := testutil.NewPeeringSimulatorWithSuite(peerNetIDs, suite, 42, testutil.WithLevel(log, logger.LevelWarn, false))
+	sim.SetDelay(10*time.Millisecond, 100*time.Millisecond)
+	sim.Drop(testutil.SimMsgFilter{From: peerNetIDs[1], To: peerNetIDs[2]}, 5) // Lost messages have to be retried.
+	var peerPubs []kyber.Point = sim.PubKeys()
+	var peerSecs []kyber.Scalar = sim.SecKeys()
+	var networkProviders []peering.NetworkProvider = sim.NetworkProviders()
+	//
+	// Initialize the DKG subsystem in each node.
+	var dkgNodes []*dkg.Node = make([]*dkg.Node, len(peerNetIDs))
+	for i := range peerNetIDs {
+		registry := testutil.NewDkgRegistryProvider(suite)
+		dkgNodes[i] = dkg.NewNode(
+			peerSecs[i], peerPubs[i], suite, networkProviders[i], registry,
+			testutil.WithLevel(log.With("NetID", peerNetIDs[i]), logger.LevelInfo, false),
+		)
+	}
+	//
+	// The nodes send messages from their own goroutines, so the key generation
+	// is started in the background and the simulation is stepped by the test
+	// goroutine whenever there are messages in flight.
+	type dkgResult struct {
+		dkShare *tcrypto.DKShare
+		err     error
+	}
+	resultCh := make(chan dkgResult, 1)
+	go func() {
+		dkShare, err := dkgNodes[0].GenerateDistributedKey(
+			peerNetIDs,
+			peerPubs,
+			threshold,
+			100*time.Millisecond, // Round retry.
+			500*time.Millisecond, // Step retry.
+			timeout,
+		)
+		resultCh <- dkgResult{dkShare: dkShare, err: err}
+	}()
+	var result dkgResult
+	for done := false; !done; {
+		select {
+		case result = <-resultCh:
+			done = true
+		case <-sim.Pending():
+			for sim.Step() {
+			}
+		}
+	}
+	dkShare, err := result.dkShare, result.err
+	require.Nil(t, err)
+	require.NotNil(t, dkShare.Address)
+	require.NotNil(t, dkShare.SharedPublic)
+	dropped := 0
+	for _, e := range sim.Trace() {
+		if e.Event == testutil.SimDropped {
+			dropped++
+		}
+	}
+	require.Equal(t, 5, dropped)
+}
+
 // TestLowN checks, if the DKG works with N=1 and other low values. N=1 is a special case.
 func TestLowN(t *testing.T) {
 	log := testutil.NewLogger(t)
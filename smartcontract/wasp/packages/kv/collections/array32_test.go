@@ -0,0 +1,121 @@
+package collections
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicArray32(t *testing.T) {
+	vars := dict.New()
+	arr := NewArray32(vars, "testArray")
+
+	d1 := []byte("datum1")
+	d2 := []byte("datum2")
+
+	arr.MustPush(d1)
+	assert.EqualValues(t, 1, arr.MustLen())
+	assert.EqualValues(t, d1, arr.MustGetAt(0))
+	assert.Panics(t, func() {
+		arr.MustGetAt(1)
+	})
+
+	arr.MustPush(d2)
+	assert.EqualValues(t, 2, arr.MustLen())
+	arr.MustSetAt(0, d2)
+	assert.EqualValues(t, d2, arr.MustGetAt(0))
+
+	arr2 := NewArray32(vars, "testArray2")
+	arr2.MustExtend(arr.Immutable())
+	assert.EqualValues(t, arr.MustLen(), arr2.MustLen())
+
+	arr.MustErase()
+	assert.EqualValues(t, 0, arr.MustLen())
+	assert.EqualValues(t, 2, arr2.MustLen())
+}
+
+func TestArray32Len(t *testing.T) {
+	vars := dict.New()
+	arr := NewArray32(vars, "testArray")
+
+	arr.setSize(70000)
+	assert.EqualValues(t, 70000, arr.MustLen())
+
+	arr.MustPush([]byte{1})
+	assert.EqualValues(t, 70001, arr.MustLen())
+	assert.EqualValues(t, []byte{1}, arr.MustGetAt(70000))
+}
+
+func TestArray32Grid(t *testing.T) {
+	vars := dict.New()
+	grid := NewArray32(vars, "grid")
+
+	for row := uint32(0); row < 3; row++ {
+		for col := uint32(0); col < 4; col++ {
+			grid.ArrayAt(row).MustPush([]byte{byte(row), byte(col)})
+		}
+	}
+	grid.ArrayAt(1).MustSetAt(2, []byte("hit"))
+
+	ro := NewArray32ReadOnly(vars, "grid")
+	assert.EqualValues(t, 0, ro.MustLen())
+	assert.EqualValues(t, 4, ro.ArrayAt(2).MustLen())
+	assert.EqualValues(t, []byte{2, 3}, ro.ArrayAt(2).MustGetAt(3))
+	assert.EqualValues(t, []byte("hit"), ro.ArrayAt(1).MustGetAt(2))
+	assert.EqualValues(t, []byte{1, 3}, ro.ArrayAt(1).MustGetAt(3))
+	assert.EqualValues(t, 0, ro.ArrayAt(3).MustLen())
+}
+
+func TestMapOfStructs(t *testing.T) {
+	vars := dict.New()
+	games := NewMap(vars, "games")
+
+	games.MapAt([]byte("game1")).MustSetAt([]byte("turn"), []byte{1})
+	games.MapAt([]byte("game1")).MustSetAt([]byte("player"), []byte("alice"))
+	games.MapAt([]byte("game2")).MustSetAt([]byte("turn"), []byte{7})
+	games.ArrayAt([]byte("moves")).MustPush([]byte("a1"))
+
+	ro := NewMapReadOnly(vars, "games")
+	assert.EqualValues(t, 2, ro.MapAt([]byte("game1")).MustLen())
+	assert.EqualValues(t, []byte{1}, ro.MapAt([]byte("game1")).MustGetAt([]byte("turn")))
+	assert.EqualValues(t, []byte("alice"), ro.MapAt([]byte("game1")).MustGetAt([]byte("player")))
+	assert.EqualValues(t, []byte{7}, ro.MapAt([]byte("game2")).MustGetAt([]byte("turn")))
+	assert.False(t, ro.MapAt([]byte("game2")).MustHasAt([]byte("player")))
+	assert.EqualValues(t, []byte("a1"), ro.ArrayAt([]byte("moves")).MustGetAt(0))
+}
+
+func TestNestedNames(t *testing.T) {
+	vars := dict.New()
+	m := NewMap(vars, "m")
+
+	// the key of the value looks like the key of an element of the nested map
+	m.MustSetAt([]byte("a\x01b"), []byte("value"))
+	m.MapAt([]byte("a")).MustSetAt([]byte("b"), []byte("nested"))
+	// the keys of the nested maps look like the keys of the maps nested in them
+	m.MapAt([]byte("c\x01d")).MustSetAt([]byte("e"), []byte("cd.e"))
+	m.MapAt([]byte("c")).MapAt([]byte("d")).MustSetAt([]byte("e"), []byte("c.d.e"))
+
+	assert.EqualValues(t, []byte("value"), m.MustGetAt([]byte("a\x01b")))
+	assert.EqualValues(t, []byte("nested"), m.MapAt([]byte("a")).MustGetAt([]byte("b")))
+	assert.EqualValues(t, []byte("cd.e"), m.MapAt([]byte("c\x01d")).MustGetAt([]byte("e")))
+	assert.EqualValues(t, []byte("c.d.e"), m.MapAt([]byte("c")).MapAt([]byte("d")).MustGetAt([]byte("e")))
+
+	// the nested maps are not elements of the outer one
+	assert.EqualValues(t, 1, m.MustLen())
+	n := 0
+	m.MustIterateKeys(func(elemKey []byte) bool {
+		assert.EqualValues(t, []byte("a\x01b"), elemKey)
+		n++
+		return true
+	})
+	assert.EqualValues(t, 1, n)
+
+	// the nested array is kept apart from the elements of the outer array
+	arr := NewArray32(vars, "arr")
+	arr.MustPush([]byte("elem"))
+	arr.ArrayAt(0).MustPush([]byte("nested"))
+	arr.MustErase()
+	assert.EqualValues(t, 0, arr.MustLen())
+	assert.EqualValues(t, []byte("nested"), arr.ArrayAt(0).MustGetAt(0))
+}
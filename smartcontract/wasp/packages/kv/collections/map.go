@@ -21,8 +21,9 @@ type ImmutableMap struct {
 }
 
 const (
-	mapSizeKeyCode = byte(0)
-	mapElemKeyCode = byte(1)
+	mapSizeKeyCode   = byte(0)
+	mapElemKeyCode   = byte(1)
+	mapNestedKeyCode = byte(2)
 )
 
 func NewMap(kv kv.KVStore, name string) *Map {
@@ -62,6 +63,16 @@ func (m *ImmutableMap) getElemKey(key []byte) kv.Key {
 	return kv.Key(buf.Bytes())
 }
 
+// getNestedName is the name of the collection nested under the key. The key is prefixed with its length
+// and the name has its own key code, so it can't collide with the elements of the map or with other nested names
+func (m *ImmutableMap) getNestedName(key []byte) string {
+	var buf bytes.Buffer
+	buf.Write([]byte(m.name))
+	buf.WriteByte(mapNestedKeyCode)
+	_ = util.WriteBytes32(&buf, key)
+	return buf.String()
+}
+
 func (m *Map) addToSize(amount int) error {
 	n, err := m.Len()
 	if err != nil {
@@ -175,6 +186,35 @@ func (m *Map) Erase() {
 	panic("implement me")
 }
 
+// MapAt is the nested map stored under the key, for example the fields of a struct kept in a map of structs.
+// The nested collections only use the name of the outer map as the namespace of their keys: they are
+// not counted by Len and not included in the iteration of the outer map. The map and the array nested
+// under the same key share their name, so a key should hold only one of them
+func (m *ImmutableMap) MapAt(key []byte) *ImmutableMap {
+	return NewMapReadOnly(m.kvr, m.getNestedName(key))
+}
+
+// MapAt is the nested map stored under the key, see ImmutableMap.MapAt
+func (m *Map) MapAt(key []byte) *Map {
+	return &Map{
+		ImmutableMap: m.ImmutableMap.MapAt(key),
+		kvw:          m.kvw,
+	}
+}
+
+// ArrayAt is the nested array stored under the key, see ImmutableMap.MapAt
+func (m *ImmutableMap) ArrayAt(key []byte) *ImmutableArray32 {
+	return NewArray32ReadOnly(m.kvr, m.getNestedName(key))
+}
+
+// ArrayAt is the nested array stored under the key, see ImmutableMap.MapAt
+func (m *Map) ArrayAt(key []byte) *Array32 {
+	return &Array32{
+		ImmutableArray32: m.ImmutableMap.ArrayAt(key),
+		kvw:              m.kvw,
+	}
+}
+
 // Iterate non-deterministic
 func (m *ImmutableMap) Iterate(f func(elemKey []byte, value []byte) bool) error {
 	prefix := m.getElemKey(nil)
@@ -0,0 +1,163 @@
+// Package metrics collects Prometheus metrics of the node: stages of the consensus, synchronization
+// of the state, runs of the VM and traffic between peers. The metrics are exposed by the Metrics plugin
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wasp"
+
+// Registry contains all metrics of the node together with the process and the Go runtime metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	consensusStageTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "consensus",
+		Name:      "stage_transitions_total",
+		Help:      "Number of transitions to the consensus stage",
+	}, []string{"chain", "stage"})
+
+	consensusStageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "consensus",
+		Name:      "stage_duration_seconds",
+		Help:      "Time spent in the consensus stage",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 15),
+	}, []string{"chain", "stage"})
+
+	consensusLeaderRotations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "consensus",
+		Name:      "leader_rotations_total",
+		Help:      "Number of leader rotations due to the timeout of the consensus stage",
+	}, []string{"chain"})
+
+	consensusQuorumDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "consensus",
+		Name:      "time_to_quorum_seconds",
+		Help:      "Time from the end of the calculations of the leader until the quorum of signatures is reached",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 15),
+	}, []string{"chain"})
+
+	stateSyncLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "state",
+		Name:      "sync_lag_blocks",
+		Help:      "Number of blocks the solid state is behind the largest state index evidenced by peers",
+	}, []string{"chain"})
+
+	stateBlockIndex = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "state",
+		Name:      "block_index",
+		Help:      "Index of the last committed block",
+	}, []string{"chain"})
+
+	stateCommitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "state",
+		Name:      "block_commit_duration_seconds",
+		Help:      "Time to commit the block to the database",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 15),
+	}, []string{"chain"})
+
+	vmRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "vm",
+		Name:      "request_duration_seconds",
+		Help:      "Time to run the request by the VM",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 18),
+	}, []string{"chain", "contract"})
+
+	vmRunFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "vm",
+		Name:      "request_failures_total",
+		Help:      "Number of requests which failed in the VM",
+	}, []string{"chain", "contract"})
+
+	peeringMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "peering",
+		Name:      "messages_total",
+		Help:      "Number of messages exchanged with the peer",
+	}, []string{"peer", "direction"})
+
+	peeringBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "peering",
+		Name:      "message_bytes_total",
+		Help:      "Size of data of messages exchanged with the peer",
+	}, []string{"peer", "direction"})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		prometheus.NewGoCollector(),
+		consensusStageTransitions,
+		consensusStageDuration,
+		consensusLeaderRotations,
+		consensusQuorumDuration,
+		stateSyncLag,
+		stateBlockIndex,
+		stateCommitDuration,
+		vmRunDuration,
+		vmRunFailures,
+		peeringMessages,
+		peeringBytes,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ConsensusStage records the transition of the consensus from the stage 'from', which lasted 'inFrom', to the stage 'to'
+func ConsensusStage(chainID string, from, to string, inFrom time.Duration) {
+	consensusStageDuration.WithLabelValues(chainID, from).Observe(inFrom.Seconds())
+	consensusStageTransitions.WithLabelValues(chainID, to).Inc()
+}
+
+func LeaderRotated(chainID string) {
+	consensusLeaderRotations.WithLabelValues(chainID).Inc()
+}
+
+func QuorumReached(chainID string, d time.Duration) {
+	consensusQuorumDuration.WithLabelValues(chainID).Observe(d.Seconds())
+}
+
+func StateSyncLag(chainID string, lag uint32) {
+	stateSyncLag.WithLabelValues(chainID).Set(float64(lag))
+}
+
+func BlockCommitted(chainID string, blockIndex uint32, d time.Duration) {
+	stateBlockIndex.WithLabelValues(chainID).Set(float64(blockIndex))
+	stateCommitDuration.WithLabelValues(chainID).Observe(d.Seconds())
+}
+
+// RequestRun records the run of the request to the contract (hname) by the VM
+func RequestRun(chainID string, contract string, d time.Duration, failed bool) {
+	vmRunDuration.WithLabelValues(chainID, contract).Observe(d.Seconds())
+	if failed {
+		vmRunFailures.WithLabelValues(chainID, contract).Inc()
+	}
+}
+
+func PeerMessageSent(peer string, size int) {
+	peeringMessages.WithLabelValues(peer, "out").Inc()
+	peeringBytes.WithLabelValues(peer, "out").Add(float64(size))
+}
+
+func PeerMessageReceived(peer string, size int) {
+	peeringMessages.WithLabelValues(peer, "in").Inc()
+	peeringBytes.WithLabelValues(peer, "in").Add(float64(size))
+}
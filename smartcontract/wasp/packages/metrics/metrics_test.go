@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	ConsensusStage("chain1", "NoSync", "LeaderStarting", time.Second)
+	ConsensusStage("chain1", "LeaderStarting", "LeaderCalculationsStarted", time.Millisecond)
+	LeaderRotated("chain1")
+	QuorumReached("chain1", 100*time.Millisecond)
+	StateSyncLag("chain1", 3)
+	BlockCommitted("chain1", 5, time.Millisecond)
+	RequestRun("chain1", "cebf5908", time.Millisecond, false)
+	RequestRun("chain1", "cebf5908", time.Millisecond, true)
+	PeerMessageSent("127.0.0.1:4001", 100)
+	PeerMessageSent("127.0.0.1:4001", 50)
+	PeerMessageReceived("127.0.0.1:4001", 10)
+
+	require.EqualValues(t, 1, testutil.ToFloat64(consensusStageTransitions.WithLabelValues("chain1", "LeaderStarting")))
+	require.EqualValues(t, 1, testutil.ToFloat64(consensusLeaderRotations.WithLabelValues("chain1")))
+	require.EqualValues(t, 3, testutil.ToFloat64(stateSyncLag.WithLabelValues("chain1")))
+	require.EqualValues(t, 5, testutil.ToFloat64(stateBlockIndex.WithLabelValues("chain1")))
+	require.EqualValues(t, 1, testutil.ToFloat64(vmRunFailures.WithLabelValues("chain1", "cebf5908")))
+	require.EqualValues(t, 2, testutil.ToFloat64(peeringMessages.WithLabelValues("127.0.0.1:4001", "out")))
+	require.EqualValues(t, 150, testutil.ToFloat64(peeringBytes.WithLabelValues("127.0.0.1:4001", "out")))
+	require.EqualValues(t, 10, testutil.ToFloat64(peeringBytes.WithLabelValues("127.0.0.1:4001", "in")))
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := ioutil.ReadAll(rec.Body)
+	require.NoError(t, err)
+	for _, name := range []string{
+		"wasp_consensus_stage_duration_seconds",
+		"wasp_consensus_time_to_quorum_seconds",
+		"wasp_state_block_commit_duration_seconds",
+		"wasp_vm_request_duration_seconds",
+		"wasp_peering_message_bytes_total",
+		"go_goroutines",
+	} {
+		require.Contains(t, string(body), name)
+	}
+}
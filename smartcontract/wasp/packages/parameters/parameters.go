@@ -19,6 +19,7 @@ const (
 	WebAPIBindAddress    = "webapi.bindAddress"
 	WebAPIAdminWhitelist = "webapi.adminWhitelist"
 	WebAPIAuth           = "webapi.auth"
+	WebAPIDefaultRole    = "webapi.defaultRole"
 
 	DashboardBindAddress       = "dashboard.bindAddress"
 	DashboardExploreAddressUrl = "dashboard.exploreAddressUrl"
@@ -29,7 +30,21 @@ const (
 	PeeringMyNetId = "peering.netid"
 	PeeringPort    = "peering.port"
 
-	NanomsgPublisherPort = "nanomsg.port"
+	NanomsgPublisherPort  = "nanomsg.port"
+	NanomsgLegacyMessages = "nanomsg.legacy"
+
+	MQTTBroker      = "mqtt.broker"
+	MQTTClientID    = "mqtt.clientID"
+	MQTTUsername    = "mqtt.username"
+	MQTTPassword    = "mqtt.password"
+	MQTTTopicPrefix = "mqtt.topicPrefix"
+	MQTTQoS         = "mqtt.qos"
+	MQTTRetainState = "mqtt.retainState"
+
+	MetricsBindAddress = "metrics.bindAddress"
+
+	VMTraceRequests    = "vm.traceRequests"
+	VMMaxRequestTraces = "vm.maxRequestTraces"
 )
 
 func InitFlags() {
@@ -46,6 +61,7 @@ func InitFlags() {
 	flag.String(WebAPIBindAddress, "127.0.0.1:8080", "the bind address for the web API")
 	flag.StringSlice(WebAPIAdminWhitelist, []string{}, "IP whitelist for /adm wndpoints")
 	flag.StringToString(WebAPIAuth, nil, "authentication scheme for web API")
+	flag.String(WebAPIDefaultRole, "client", "role of web API requests without token or signature: public, client or admin")
 
 	flag.String(DashboardBindAddress, "127.0.0.1:7000", "the bind address for the node dashboard")
 	flag.String(DashboardExploreAddressUrl, "", "URL to add as href to addresses in the dashboard [default: <nodeconn.address>:8081/explorer/address]")
@@ -57,6 +73,20 @@ func InitFlags() {
 	flag.String(PeeringMyNetId, "127.0.0.1:4000", "node host address as it is recognized by other peers")
 
 	flag.Int(NanomsgPublisherPort, 5550, "the port for nanomsg even publisher")
+	flag.Bool(NanomsgLegacyMessages, true, "publish messages in the legacy form too, for subscribers which don't perform the handshake")
+
+	flag.String(MQTTBroker, "tcp://127.0.0.1:1883", "URL of the MQTT broker for the MQTT publisher")
+	flag.String(MQTTClientID, "wasp", "MQTT client ID of the node")
+	flag.String(MQTTUsername, "", "username for the MQTT broker")
+	flag.String(MQTTPassword, "", "password for the MQTT broker")
+	flag.String(MQTTTopicPrefix, "wasp", "prefix of MQTT topics")
+	flag.Int(MQTTQoS, 0, "QoS of published MQTT messages (0, 1 or 2)")
+	flag.Bool(MQTTRetainState, true, "retain the latest 'state' message of each chain in the MQTT broker. Other types of messages are not retained")
+
+	flag.String(MetricsBindAddress, "127.0.0.1:2112", "the bind address for the Prometheus metrics endpoint")
+
+	flag.Bool(VMTraceRequests, false, "trace calls of requests run by the VM and return the traces in the request status")
+	flag.Int(VMMaxRequestTraces, 1000, "number of traces of the latest requests kept by each chain")
 }
 
 func GetBool(name string) bool {
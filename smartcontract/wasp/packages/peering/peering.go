@@ -131,6 +131,14 @@ type RecvEvent struct {
 	Msg  *PeerMessage
 }
 
+// TrustedPeer is the record of a committee peer and the public key trusted for it.
+// The peer may replace the key with the key rotation message only if KeyRotation is set.
+type TrustedPeer struct {
+	NetID       string
+	PubKey      kyber.Point
+	KeyRotation bool
+}
+
 // PeerMessage is an envelope for all the messages exchanged via
 // the peering module.
 type PeerMessage struct {
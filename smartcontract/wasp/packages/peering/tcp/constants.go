@@ -6,9 +6,10 @@ package tcp
 import "time"
 
 const (
-	msgTypeReserved  = byte(0)
-	msgTypeHandshake = byte(1)
-	msgTypeMsgChunk  = byte(2)
+	msgTypeReserved    = byte(0)
+	msgTypeHandshake   = byte(1)
+	msgTypeMsgChunk    = byte(2)
+	msgTypeKeyRotation = byte(3)
 
 	restartAfter = 1 * time.Second
 	dialTimeout  = 1 * time.Second
@@ -4,4 +4,22 @@
 // Package tcp provides a TCP based implementation of the
 // peering overlay network.
 //
+// Each connection starts with a handshake, which authenticates both
+// nodes by their key pairs and establishes the keys of the session.
+// All frames after the handshake are encrypted with AES-256-GCM.
+// The key proven by a peer must match the key trusted for it in the
+// registry, or set by NetImpl.TrustPeer, otherwise the connection is
+// closed. Peers without a trusted key are rejected. A node can replace
+// its key pair with NetImpl.RotateKeyPair, the new key is saved in the
+// registry and announced to the connected peers over the authenticated
+// channels, signed with the new private key. The peers accept the new key
+// only if the operator allows it with NetImpl.AllowKeyRotation.
+//
+// The node operator manages the trusted peers with the admin endpoints
+// of the web API: POST /adm/trustedpeer trusts the key of the peer (and
+// allows its rotation if 'keyRotation' is set), GET /adm/trustedpeers
+// lists the trusted peers. The same is available with PutTrustedPeer and
+// GetTrustedPeers of client.WaspClient. When the node runs another network
+// provider the keys are only saved in the registry, and they are loaded
+// by NewNetworkProvider once the node switches to this one.
 package tcp
@@ -0,0 +1,349 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package tcp
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/iotaledger/wasp/packages/util"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/util/key"
+	"go.dedis.ch/kyber/v3/util/random"
+)
+
+// The handshake follows the XX pattern of the Noise protocol framework,
+// with the Diffie-Hellman over the group of the node keys:
+//
+//	-> e                   handshakeInit:     peeringID, srcNetID, E_i
+//	<- e, ee, s, es        handshakeResponse: srcNetID, E_r, S_r, mac_r
+//	-> s, se               handshakeFinish:   S_i, mac_i
+//
+// E are ephemeral public keys, S are public keys of the nodes. Each MAC proves
+// the possession of the private key of the node and is computed over the
+// transcript of the handshake. The session keys are derived from all three
+// shared secrets, so the session is bound to the keys of both nodes.
+
+const (
+	handshakeInit     = byte(1)
+	handshakeResponse = byte(2)
+	handshakeFinish   = byte(3)
+
+	macLabelResponder = "wasp-peering-responder"
+	macLabelInitiator = "wasp-peering-initiator"
+	keyLabelI2R       = "wasp-peering-i2r"
+	keyLabelR2I       = "wasp-peering-r2i"
+)
+
+type handshakeMsg struct {
+	step      byte
+	peeringID string      // Pair of peer NetIDs, handshakeInit only.
+	srcNetID  string      // NetID of the sender, handshakeInit and handshakeResponse.
+	ephPubKey kyber.Point // Ephemeral key, handshakeInit and handshakeResponse.
+	pubKey    kyber.Point // Key of the node, handshakeResponse and handshakeFinish.
+	mac       []byte      // handshakeResponse and handshakeFinish.
+}
+
+// payload is the part of the message included in the transcript of the handshake.
+func (m *handshakeMsg) payload() ([]byte, error) {
+	var err error
+	var buf bytes.Buffer
+	buf.WriteByte(m.step)
+	if m.step == handshakeInit {
+		if err = util.WriteString16(&buf, m.peeringID); err != nil {
+			return nil, err
+		}
+	}
+	if m.step == handshakeInit || m.step == handshakeResponse {
+		if err = util.WriteString16(&buf, m.srcNetID); err != nil {
+			return nil, err
+		}
+		if err = util.WriteMarshaled(&buf, m.ephPubKey); err != nil {
+			return nil, err
+		}
+	}
+	if m.step == handshakeResponse || m.step == handshakeFinish {
+		if err = util.WriteMarshaled(&buf, m.pubKey); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *handshakeMsg) bytes() ([]byte, error) {
+	var err error
+	var payload []byte
+	if payload, err = m.payload(); err != nil {
+		return nil, err
+	}
+	if m.step == handshakeInit {
+		return payload, nil
+	}
+	buf := bytes.NewBuffer(payload)
+	if err = util.WriteBytes16(buf, m.mac); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func handshakeMsgFromBytes(buf []byte, suite kyber.Group) (*handshakeMsg, error) {
+	var err error
+	r := bytes.NewReader(buf)
+	m := handshakeMsg{}
+	if m.step, err = util.ReadByte(r); err != nil {
+		return nil, err
+	}
+	if m.step < handshakeInit || m.step > handshakeFinish {
+		return nil, fmt.Errorf("wrong handshake step %d", m.step)
+	}
+	if m.step == handshakeInit {
+		if m.peeringID, err = util.ReadString16(r); err != nil {
+			return nil, err
+		}
+	}
+	if m.step == handshakeInit || m.step == handshakeResponse {
+		if m.srcNetID, err = util.ReadString16(r); err != nil {
+			return nil, err
+		}
+		m.ephPubKey = suite.Point()
+		if err = util.ReadMarshaled(r, m.ephPubKey); err != nil {
+			return nil, err
+		}
+	}
+	if m.step == handshakeResponse || m.step == handshakeFinish {
+		m.pubKey = suite.Point()
+		if err = util.ReadMarshaled(r, m.pubKey); err != nil {
+			return nil, err
+		}
+		if m.mac, err = util.ReadBytes16(r); err != nil {
+			return nil, err
+		}
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("unexpected data after the handshake message")
+	}
+	return &m, nil
+}
+
+// handshakeState is the state of one side of the handshake in progress.
+type handshakeState struct {
+	suite      kyber.Group
+	initiator  bool
+	ephPriv    kyber.Scalar
+	ephPub     kyber.Point
+	remoteEph  kyber.Point
+	transcript hash.Hash
+	chainKey   []byte
+}
+
+func newHandshakeState(suite kyber.Group, initiator bool) *handshakeState {
+	ephPriv := suite.Scalar().Pick(random.New())
+	return &handshakeState{
+		suite:      suite,
+		initiator:  initiator,
+		ephPriv:    ephPriv,
+		ephPub:     suite.Point().Mul(ephPriv, nil),
+		transcript: sha256.New(),
+	}
+}
+
+// initMsg creates the first message of the handshake, sent by the initiator.
+func (hs *handshakeState) initMsg(peeringID, myNetID string) (*handshakeMsg, error) {
+	m := &handshakeMsg{
+		step:      handshakeInit,
+		peeringID: peeringID,
+		srcNetID:  myNetID,
+		ephPubKey: hs.ephPub,
+	}
+	if err := hs.mixMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// respond processes the first message and creates the response, proving the key of the responder.
+func (hs *handshakeState) respond(init *handshakeMsg, myNetID string, keyPair *key.Pair) (*handshakeMsg, error) {
+	var err error
+	if hs.initiator || init.step != handshakeInit {
+		return nil, errors.New("unexpected handshake message")
+	}
+	if err = hs.mixMsg(init); err != nil {
+		return nil, err
+	}
+	hs.remoteEph = init.ephPubKey
+	m := &handshakeMsg{
+		step:      handshakeResponse,
+		srcNetID:  myNetID,
+		ephPubKey: hs.ephPub,
+		pubKey:    keyPair.Public,
+	}
+	if err = hs.mixMsg(m); err != nil {
+		return nil, err
+	}
+	if hs.chainKey, err = hs.mixKey(nil, hs.dh(hs.ephPriv, init.ephPubKey), hs.dh(keyPair.Private, init.ephPubKey)); err != nil {
+		return nil, err
+	}
+	m.mac = hs.mac(macLabelResponder)
+	return m, nil
+}
+
+// finish verifies the response of the responder and creates the last message of the handshake,
+// proving the key of the initiator. The session is established for the initiator.
+func (hs *handshakeState) finish(resp *handshakeMsg, keyPair *key.Pair) (*handshakeMsg, *session, error) {
+	var err error
+	if !hs.initiator || resp.step != handshakeResponse {
+		return nil, nil, errors.New("unexpected handshake message")
+	}
+	if err = hs.mixMsg(resp); err != nil {
+		return nil, nil, err
+	}
+	hs.remoteEph = resp.ephPubKey
+	if hs.chainKey, err = hs.mixKey(nil, hs.dh(hs.ephPriv, resp.ephPubKey), hs.dh(hs.ephPriv, resp.pubKey)); err != nil {
+		return nil, nil, err
+	}
+	if !hmac.Equal(resp.mac, hs.mac(macLabelResponder)) {
+		return nil, nil, errors.New("responder failed to prove its key")
+	}
+	m := &handshakeMsg{
+		step:   handshakeFinish,
+		pubKey: keyPair.Public,
+	}
+	if err = hs.mixMsg(m); err != nil {
+		return nil, nil, err
+	}
+	if hs.chainKey, err = hs.mixKey(hs.chainKey, hs.dh(keyPair.Private, resp.ephPubKey)); err != nil {
+		return nil, nil, err
+	}
+	m.mac = hs.mac(macLabelInitiator)
+	sess, err := hs.session()
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, sess, nil
+}
+
+// complete verifies the last message of the handshake. The session is established for the responder.
+func (hs *handshakeState) complete(fin *handshakeMsg) (*session, error) {
+	var err error
+	if hs.initiator || hs.chainKey == nil || fin.step != handshakeFinish {
+		return nil, errors.New("unexpected handshake message")
+	}
+	if err = hs.mixMsg(fin); err != nil {
+		return nil, err
+	}
+	if hs.chainKey, err = hs.mixKey(hs.chainKey, hs.dh(hs.ephPriv, fin.pubKey)); err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(fin.mac, hs.mac(macLabelInitiator)) {
+		return nil, errors.New("initiator failed to prove its key")
+	}
+	return hs.session()
+}
+
+func (hs *handshakeState) mixMsg(m *handshakeMsg) error {
+	payload, err := m.payload()
+	if err != nil {
+		return err
+	}
+	_, _ = hs.transcript.Write(payload)
+	return nil
+}
+
+// mixKey derives the new chain key from the previous one, the shared secrets and the transcript.
+func (hs *handshakeState) mixKey(chainKey []byte, secrets ...kyber.Point) ([]byte, error) {
+	mac := hmac.New(sha256.New, chainKey)
+	for _, s := range secrets {
+		data, err := s.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		_, _ = mac.Write(data)
+	}
+	_, _ = mac.Write(hs.transcript.Sum(nil))
+	return mac.Sum(nil), nil
+}
+
+func (hs *handshakeState) dh(priv kyber.Scalar, pub kyber.Point) kyber.Point {
+	return hs.suite.Point().Mul(priv, pub)
+}
+
+func (hs *handshakeState) mac(label string) []byte {
+	return hmacSHA256(hs.chainKey, []byte(label))
+}
+
+func (hs *handshakeState) session() (*session, error) {
+	i2r := hmacSHA256(hs.chainKey, []byte(keyLabelI2R))
+	r2i := hmacSHA256(hs.chainKey, []byte(keyLabelR2I))
+	if hs.initiator {
+		return newSession(i2r, r2i)
+	}
+	return newSession(r2i, i2r)
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// session encrypts the frames of the connection after the handshake with AES-256-GCM.
+// The nonces are counters of frames in each direction, therefore frames must be
+// sealed in the order they are written to the connection.
+type session struct {
+	sendAEAD  cipher.AEAD
+	recvAEAD  cipher.AEAD
+	sendNonce uint64
+	recvNonce uint64
+}
+
+// sealOverhead is the number of bytes added to each frame by the encryption.
+const sealOverhead = 16
+
+func newSession(sendKey, recvKey []byte) (*session, error) {
+	var err error
+	s := session{}
+	if s.sendAEAD, err = newAEAD(sendKey); err != nil {
+		return nil, err
+	}
+	if s.recvAEAD, err = newAEAD(recvKey); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *session) seal(data []byte) []byte {
+	ret := s.sendAEAD.Seal(nil, counterNonce(s.sendNonce), data, nil)
+	s.sendNonce++
+	return ret
+}
+
+func (s *session) open(data []byte) ([]byte, error) {
+	ret, err := s.recvAEAD.Open(nil, counterNonce(s.recvNonce), data, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.recvNonce++
+	return ret, nil
+}
+
+func counterNonce(counter uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], counter)
+	return nonce
+}
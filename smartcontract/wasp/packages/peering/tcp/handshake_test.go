@@ -0,0 +1,109 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package tcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+// handshakeOverWire passes the message through its encoding, as if sent over the connection.
+func handshakeOverWire(t *testing.T, m *handshakeMsg) *handshakeMsg {
+	data, err := m.bytes()
+	require.NoError(t, err)
+	ret, err := handshakeMsgFromBytes(data, pairing.NewSuiteBn256())
+	require.NoError(t, err)
+	return ret
+}
+
+func TestHandshake(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	initKeys := key.NewKeyPair(suite)
+	respKeys := key.NewKeyPair(suite)
+
+	initiator := newHandshakeState(suite, true)
+	responder := newHandshakeState(suite, false)
+
+	init, err := initiator.initMsg("a<b", "a")
+	require.NoError(t, err)
+	init = handshakeOverWire(t, init)
+	require.EqualValues(t, "a<b", init.peeringID)
+	require.EqualValues(t, "a", init.srcNetID)
+
+	resp, err := responder.respond(init, "b", respKeys)
+	require.NoError(t, err)
+	resp = handshakeOverWire(t, resp)
+	require.True(t, resp.pubKey.Equal(respKeys.Public))
+
+	fin, initSession, err := initiator.finish(resp, initKeys)
+	require.NoError(t, err)
+	fin = handshakeOverWire(t, fin)
+	require.True(t, fin.pubKey.Equal(initKeys.Public))
+
+	respSession, err := responder.complete(fin)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		sealed := initSession.seal([]byte("ping"))
+		require.Len(t, sealed, len("ping")+sealOverhead)
+		data, err := respSession.open(sealed)
+		require.NoError(t, err)
+		require.EqualValues(t, "ping", string(data))
+
+		data, err = initSession.open(respSession.seal([]byte("pong")))
+		require.NoError(t, err)
+		require.EqualValues(t, "pong", string(data))
+	}
+	// replayed frame
+	sealed := initSession.seal([]byte("ping"))
+	_, err = respSession.open(sealed)
+	require.NoError(t, err)
+	_, err = respSession.open(sealed)
+	require.Error(t, err)
+}
+
+func TestHandshakeWrongKey(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	initKeys := key.NewKeyPair(suite)
+	respKeys := key.NewKeyPair(suite)
+	otherKeys := key.NewKeyPair(suite)
+
+	// the responder presents a key it does not own
+	initiator := newHandshakeState(suite, true)
+	responder := newHandshakeState(suite, false)
+	init, err := initiator.initMsg("a<b", "a")
+	require.NoError(t, err)
+	resp, err := responder.respond(handshakeOverWire(t, init), "b", respKeys)
+	require.NoError(t, err)
+	resp.pubKey = otherKeys.Public
+	_, _, err = initiator.finish(handshakeOverWire(t, resp), initKeys)
+	require.Error(t, err)
+
+	// the initiator presents a key it does not own
+	initiator = newHandshakeState(suite, true)
+	responder = newHandshakeState(suite, false)
+	init, err = initiator.initMsg("a<b", "a")
+	require.NoError(t, err)
+	resp, err = responder.respond(handshakeOverWire(t, init), "b", respKeys)
+	require.NoError(t, err)
+	fin, _, err := initiator.finish(handshakeOverWire(t, resp), initKeys)
+	require.NoError(t, err)
+	fin.pubKey = otherKeys.Public
+	_, err = responder.complete(handshakeOverWire(t, fin))
+	require.Error(t, err)
+
+	// the transcript is modified on the way
+	initiator = newHandshakeState(suite, true)
+	responder = newHandshakeState(suite, false)
+	init, err = initiator.initMsg("a<b", "a")
+	require.NoError(t, err)
+	init.peeringID = "a<c"
+	resp, err = responder.respond(handshakeOverWire(t, init), "b", respKeys)
+	require.NoError(t, err)
+	_, _, err = initiator.finish(handshakeOverWire(t, resp), initKeys)
+	require.Error(t, err)
+}
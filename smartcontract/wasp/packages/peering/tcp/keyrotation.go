@@ -0,0 +1,95 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package tcp
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"errors"
+
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/sign/schnorr"
+	"go.dedis.ch/kyber/v3/util/key"
+	"go.dedis.ch/kyber/v3/util/random"
+)
+
+// The key rotation message carries the new public key of the node and the Schnorr
+// signature made with the new private key over the old key, the new key and the NetID
+// of the node. It proves the possession of the new key, so a node can't announce
+// a key of another node as its own:
+//
+//	keyRotation: S_new, sig(s_new, label || S_old || S_new || srcNetID)
+
+const sigLabelKeyRotation = "wasp-peering-key-rotation"
+
+type keyRotationMsg struct {
+	pubKey    kyber.Point
+	signature []byte
+}
+
+// schnorrSuite adds the source of randomness to the group of the node keys.
+type schnorrSuite struct {
+	kyber.Group
+}
+
+func (schnorrSuite) RandomStream() cipher.Stream {
+	return random.New()
+}
+
+func keyRotationData(oldPubKey, newPubKey kyber.Point, srcNetID string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(sigLabelKeyRotation)
+	if _, err := oldPubKey.MarshalTo(&buf); err != nil {
+		return nil, err
+	}
+	if _, err := newPubKey.MarshalTo(&buf); err != nil {
+		return nil, err
+	}
+	buf.WriteString(srcNetID)
+	return buf.Bytes(), nil
+}
+
+// newKeyRotationMsg signs the rotation from the old key to the new key pair.
+func newKeyRotationMsg(suite kyber.Group, oldPubKey kyber.Point, newKeyPair *key.Pair, srcNetID string) (*keyRotationMsg, error) {
+	data, err := keyRotationData(oldPubKey, newKeyPair.Public, srcNetID)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := schnorr.Sign(schnorrSuite{suite}, newKeyPair.Private, data)
+	if err != nil {
+		return nil, err
+	}
+	return &keyRotationMsg{pubKey: newKeyPair.Public, signature: sig}, nil
+}
+
+// verify checks the signature of the rotation of the key of the peer with the NetID.
+func (m *keyRotationMsg) verify(suite kyber.Group, oldPubKey kyber.Point, srcNetID string) error {
+	data, err := keyRotationData(oldPubKey, m.pubKey, srcNetID)
+	if err != nil {
+		return err
+	}
+	if err = schnorr.Verify(suite, m.pubKey, data, m.signature); err != nil {
+		return errors.New("invalid signature of the key rotation")
+	}
+	return nil
+}
+
+func (m *keyRotationMsg) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := m.pubKey.MarshalTo(&buf); err != nil {
+		return nil, err
+	}
+	buf.Write(m.signature)
+	return buf.Bytes(), nil
+}
+
+func keyRotationMsgFromBytes(buf []byte, suite kyber.Group) (*keyRotationMsg, error) {
+	r := bytes.NewReader(buf)
+	ret := &keyRotationMsg{pubKey: suite.Point()}
+	if _, err := ret.pubKey.UnmarshalFrom(r); err != nil {
+		return nil, err
+	}
+	ret.signature = buf[len(buf)-r.Len():]
+	return ret, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package tcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+func TestKeyRotationMsg(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	oldKeys := key.NewKeyPair(suite)
+	newKeys := key.NewKeyPair(suite)
+	m, err := newKeyRotationMsg(suite, oldKeys.Public, newKeys, "localhost:4000")
+	require.NoError(t, err)
+	data, err := m.bytes()
+	require.NoError(t, err)
+	m, err = keyRotationMsgFromBytes(data, suite)
+	require.NoError(t, err)
+	require.True(t, m.pubKey.Equal(newKeys.Public))
+
+	require.NoError(t, m.verify(suite, oldKeys.Public, "localhost:4000"))
+	require.Error(t, m.verify(suite, key.NewKeyPair(suite).Public, "localhost:4000"))
+	require.Error(t, m.verify(suite, oldKeys.Public, "localhost:4001"))
+}
+
+func TestKeyRotationMsgForeignKey(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	oldKeys := key.NewKeyPair(suite)
+	foreignKeys := key.NewKeyPair(suite)
+	// the key of another node signed with the own key
+	m, err := newKeyRotationMsg(suite, oldKeys.Public, oldKeys, "localhost:4000")
+	require.NoError(t, err)
+	m.pubKey = foreignKeys.Public
+	require.Error(t, m.verify(suite, oldKeys.Public, "localhost:4000"))
+
+	_, err = keyRotationMsgFromBytes([]byte{1, 2, 3}, suite)
+	require.Error(t, err)
+}
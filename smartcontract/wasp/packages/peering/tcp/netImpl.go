@@ -5,6 +5,7 @@ package tcp
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -27,27 +28,43 @@ type NetImpl struct {
 	events     *events.Event
 
 	nodeKeyPair *key.Pair
+	trustedKeys map[string]kyber.Point // Public keys of the peers by their NetIDs.
+	keyRotation map[string]bool        // NetIDs of peers allowed to rotate the key.
+	keysMutex   *sync.RWMutex
+	registry    RegistryProvider
 	suite       kyber.Group
 	log         *logger.Logger
 }
 
 // NewNetworkProvider is a constructor for the TCP based
-// peering network implementation.
-func NewNetworkProvider(myNetID string, port int, nodeKeyPair *key.Pair, suite kyber.Group, log *logger.Logger) (*NetImpl, error) {
+// peering network implementation. Only the peers trusted in the registry are accepted.
+func NewNetworkProvider(myNetID string, port int, nodeKeyPair *key.Pair, registry RegistryProvider, suite kyber.Group, log *logger.Logger) (*NetImpl, error) {
 	if err := peering.CheckMyNetID(myNetID, port); err != nil {
 		// can't continue because NetID parameter is not correct
 		log.Panicf("checkMyNetworkID: '%v'. || Check the 'netid' parameter in config.json", err)
 		return nil, err
 	}
+	trustedPeers, err := registry.GetTrustedPeers()
+	if err != nil {
+		return nil, err
+	}
 	n := NetImpl{
 		myNetID:     myNetID,
 		port:        port,
 		peers:       make(map[string]*peer),
 		peersMutex:  &sync.RWMutex{},
 		nodeKeyPair: nodeKeyPair,
+		trustedKeys: make(map[string]kyber.Point),
+		keyRotation: make(map[string]bool),
+		keysMutex:   &sync.RWMutex{},
+		registry:    registry,
 		suite:       suite,
 		log:         log,
 	}
+	for _, tp := range trustedPeers {
+		n.trustedKeys[tp.NetID] = tp.PubKey
+		n.keyRotation[tp.NetID] = tp.KeyRotation
+	}
 	n.events = events.NewEvent(n.eventHandler)
 	return &n, nil
 }
@@ -119,14 +136,112 @@ func (n *NetImpl) PeerByNetID(peerNetID string) (peering.PeerSender, error) {
 
 // PeerByPubKey implements peering.NetworkProvider.
 // NOTE: For now, only known nodes can be looked up by PubKey.
+// The node is known if its key is trusted in the registry or by TrustPeer.
 func (n *NetImpl) PeerByPubKey(peerPub kyber.Point) (peering.PeerSender, error) {
-	for i := range n.peers {
-		pk := n.peers[i].PubKey()
-		if pk != nil && pk.Equal(peerPub) {
-			return n.PeerByNetID(n.peers[i].NetID())
+	if n.keyPair().Public.Equal(peerPub) {
+		return n, nil // Self
+	}
+	peerNetID := ""
+	n.keysMutex.RLock()
+	for netID, pk := range n.trustedKeys {
+		if pk.Equal(peerPub) {
+			peerNetID = netID
+			break
 		}
 	}
-	return nil, errors.New("known peer not found by pubKey")
+	n.keysMutex.RUnlock()
+	if peerNetID == "" {
+		return nil, errors.New("known peer not found by pubKey")
+	}
+	return n.PeerByNetID(peerNetID)
+}
+
+// TrustPeer sets the public key expected from the peer with the specified NetID and
+// saves it in the registry. Connections with the peer proving another key, as well as
+// connections with peers without a trusted key, are rejected.
+// The peer can't rotate the key unless AllowKeyRotation is called.
+func (n *NetImpl) TrustPeer(peerNetID string, peerPub kyber.Point) error {
+	n.keysMutex.Lock()
+	err := n.registry.SaveTrustedPeer(&peering.TrustedPeer{
+		NetID:       peerNetID,
+		PubKey:      peerPub,
+		KeyRotation: n.keyRotation[peerNetID],
+	})
+	if err == nil {
+		n.trustedKeys[peerNetID] = peerPub
+	}
+	n.keysMutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	n.iteratePeers(func(p *peer) {
+		if p.remoteNetID != peerNetID {
+			return
+		}
+		p.RLock()
+		mismatch := p.handshakeOk && !p.remotePubKey.Equal(peerPub)
+		p.RUnlock()
+		if mismatch {
+			n.log.Warnf("peer %s is connected with an untrusted key. Closing", p.peeringID())
+			p.closeConn()
+		}
+	})
+	return nil
+}
+
+// AllowKeyRotation allows the trusted peer to replace its key with the key rotation
+// message. The permission is saved in the registry and is valid for a single rotation.
+func (n *NetImpl) AllowKeyRotation(peerNetID string) error {
+	n.keysMutex.Lock()
+	defer n.keysMutex.Unlock()
+	peerPub, ok := n.trustedKeys[peerNetID]
+	if !ok {
+		return fmt.Errorf("peer %s is not trusted", peerNetID)
+	}
+	err := n.registry.SaveTrustedPeer(&peering.TrustedPeer{
+		NetID:       peerNetID,
+		PubKey:      peerPub,
+		KeyRotation: true,
+	})
+	if err != nil {
+		return err
+	}
+	n.keyRotation[peerNetID] = true
+	return nil
+}
+
+// RotateKeyPair replaces the key pair of this node and saves it in the registry.
+// The new public key, signed with the new private key, is sent to all connected peers
+// over the authenticated channels. The peers accept the new key only if they allowed
+// it with AllowKeyRotation. Peers not connected at the moment will reject the new key
+// unless it is set with TrustPeer.
+func (n *NetImpl) RotateKeyPair(nodeKeyPair *key.Pair) error {
+	n.keysMutex.Lock()
+	oldPubKey := n.nodeKeyPair.Public
+	n.keysMutex.Unlock()
+
+	msg, err := newKeyRotationMsg(n.suite, oldPubKey, nodeKeyPair, n.myNetID)
+	if err != nil {
+		return err
+	}
+	data, err := msg.bytes()
+	if err != nil {
+		return err
+	}
+	if err = n.registry.SaveNodeIdentity(nodeKeyPair); err != nil {
+		return err
+	}
+	n.keysMutex.Lock()
+	n.nodeKeyPair = nodeKeyPair
+	n.keysMutex.Unlock()
+
+	n.iteratePeers(func(p *peer) {
+		if err := p.sendKeyRotation(data); err != nil {
+			n.log.Warnf("failed to send the new key to peer %s: %v", p.peeringID(), err)
+		}
+	})
+	return nil
 }
 
 // PeerStatus implements peering.NetworkProvider.
@@ -145,7 +260,7 @@ func (n *NetImpl) NetID() string {
 
 // PubKey implements peering.PeerSender for the Self() node.
 func (n *NetImpl) PubKey() kyber.Point {
-	return n.nodeKeyPair.Public
+	return n.keyPair().Public
 }
 
 // SendMsg implements peering.PeerSender for the Self() node.
@@ -172,6 +287,63 @@ func (n *NetImpl) Close() {
 	// We will con close the connection of the own node.
 }
 
+func (n *NetImpl) keyPair() *key.Pair {
+	n.keysMutex.RLock()
+	defer n.keysMutex.RUnlock()
+	return n.nodeKeyPair
+}
+
+// checkPeerKey verifies the key proven by the peer in the handshake.
+// Peers without a trusted key are rejected
+func (n *NetImpl) checkPeerKey(peerNetID string, peerPub kyber.Point) error {
+	n.keysMutex.RLock()
+	defer n.keysMutex.RUnlock()
+
+	trusted, ok := n.trustedKeys[peerNetID]
+	if !ok {
+		return fmt.Errorf("peer %s is not trusted", peerNetID)
+	}
+	if !trusted.Equal(peerPub) {
+		return fmt.Errorf("peer %s presented an untrusted key", peerNetID)
+	}
+	return nil
+}
+
+// rotatePeerKey replaces the trusted key of the peer with the key from its key rotation message
+// and saves it in the registry. The rotation is rejected if it is not allowed with AllowKeyRotation,
+// if the peer doesn't use the trusted key or if the new key is known as a key of another node
+func (n *NetImpl) rotatePeerKey(peerNetID string, oldPub, newPub kyber.Point) error {
+	n.keysMutex.Lock()
+	defer n.keysMutex.Unlock()
+
+	if !n.keyRotation[peerNetID] {
+		return fmt.Errorf("key rotation of peer %s is not allowed", peerNetID)
+	}
+	if trusted, ok := n.trustedKeys[peerNetID]; !ok || !trusted.Equal(oldPub) {
+		return fmt.Errorf("peer %s is connected with an untrusted key", peerNetID)
+	}
+	if n.nodeKeyPair.Public.Equal(newPub) {
+		return fmt.Errorf("peer %s announced the key of this node", peerNetID)
+	}
+	for netID, pk := range n.trustedKeys {
+		if netID != peerNetID && pk.Equal(newPub) {
+			return fmt.Errorf("peer %s announced the key of peer %s", peerNetID, netID)
+		}
+	}
+	// the permission is for a single rotation
+	err := n.registry.SaveTrustedPeer(&peering.TrustedPeer{
+		NetID:       peerNetID,
+		PubKey:      newPub,
+		KeyRotation: false,
+	})
+	if err != nil {
+		return err
+	}
+	n.trustedKeys[peerNetID] = newPub
+	n.keyRotation[peerNetID] = false
+	return nil
+}
+
 func (n *NetImpl) isInbound(remoteNetID string) bool {
 	// if remoteNetID == n.myNetID {	// TODO: [KP] Do we need this?
 	// 	panic("remoteNetID == myNetID")
@@ -4,18 +4,35 @@
 package tcp_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dbprovider"
 	"github.com/iotaledger/wasp/packages/peering"
 	"github.com/iotaledger/wasp/packages/peering/tcp"
+	"github.com/iotaledger/wasp/packages/registry"
 	"github.com/iotaledger/wasp/packages/testutil"
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3"
 	"go.dedis.ch/kyber/v3/pairing"
 	"go.dedis.ch/kyber/v3/util/key"
 )
 
+// newRegistry creates the in-memory registry of the node, which trusts the keys of all other nodes
+func newRegistry(t *testing.T, log *logger.Logger, myIndex int, netIDs []string, pubKeys []kyber.Point) *registry.Impl {
+	reg := registry.NewRegistry(pairing.NewSuiteBn256(), log, dbprovider.NewInMemoryDBProvider(log))
+	for i := range netIDs {
+		if i == myIndex {
+			continue
+		}
+		require.NoError(t, reg.SaveTrustedPeer(&peering.TrustedPeer{NetID: netIDs[i], PubKey: pubKeys[i]}))
+	}
+	return reg
+}
+
 func TestBasic(t *testing.T) {
 	suite := pairing.NewSuiteBn256()
 	log := testutil.NewLogger(t)
@@ -25,10 +42,12 @@ func TestBasic(t *testing.T) {
 	chain1 := coretypes.NewRandomChainID()
 	chain2 := coretypes.NewRandomChainID()
 	netIDs := []string{"localhost:9017", "localhost:9018", "localhost:9019"}
+	keys := []*key.Pair{key.NewKeyPair(suite), key.NewKeyPair(suite), key.NewKeyPair(suite)}
+	pubKeys := []kyber.Point{keys[0].Public, keys[1].Public, keys[2].Public}
 	nodes := make([]peering.NetworkProvider, len(netIDs))
-	nodes[0], err0 = tcp.NewNetworkProvider(netIDs[0], 9017, key.NewKeyPair(suite), suite, log.Named("node0"))
-	nodes[1], err1 = tcp.NewNetworkProvider(netIDs[1], 9018, key.NewKeyPair(suite), suite, log.Named("node1"))
-	nodes[2], err2 = tcp.NewNetworkProvider(netIDs[2], 9019, key.NewKeyPair(suite), suite, log.Named("node2"))
+	nodes[0], err0 = tcp.NewNetworkProvider(netIDs[0], 9017, keys[0], newRegistry(t, log, 0, netIDs, pubKeys), suite, log.Named("node0"))
+	nodes[1], err1 = tcp.NewNetworkProvider(netIDs[1], 9018, keys[1], newRegistry(t, log, 1, netIDs, pubKeys), suite, log.Named("node1"))
+	nodes[2], err2 = tcp.NewNetworkProvider(netIDs[2], 9019, keys[2], newRegistry(t, log, 2, netIDs, pubKeys), suite, log.Named("node2"))
 	require.Nil(t, err0)
 	require.Nil(t, err1)
 	require.Nil(t, err2)
@@ -54,3 +73,265 @@ func TestBasic(t *testing.T) {
 
 	<-doneCh
 }
+
+func startPair(t *testing.T, ports [2]int, keys [2]*key.Pair) ([2]*tcp.NetImpl, [2]string, [2]*registry.Impl) {
+	suite := pairing.NewSuiteBn256()
+	log := testutil.NewLogger(t)
+	var nodes [2]*tcp.NetImpl
+	var netIDs [2]string
+	var regs [2]*registry.Impl
+	for i := range nodes {
+		netIDs[i] = fmt.Sprintf("localhost:%d", ports[i])
+	}
+	pubKeys := []kyber.Point{keys[0].Public, keys[1].Public}
+	for i := range nodes {
+		var err error
+		regs[i] = newRegistry(t, log, i, netIDs[:], pubKeys)
+		nodes[i], err = tcp.NewNetworkProvider(netIDs[i], ports[i], keys[i], regs[i], suite, log.Named(fmt.Sprintf("node%d", i)))
+		require.NoError(t, err)
+	}
+	return nodes, netIDs, regs
+}
+
+func TestAuthenticated(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	keys := [2]*key.Pair{key.NewKeyPair(suite), key.NewKeyPair(suite)}
+	nodes, netIDs, _ := startPair(t, [2]int{9027, 9028}, keys)
+	for i := range nodes {
+		go nodes[i].Run(make(<-chan struct{}))
+	}
+	n0p1, err := nodes[0].PeerByNetID(netIDs[1])
+	require.NoError(t, err)
+	n1p0, err := nodes[1].PeerByNetID(netIDs[0])
+	require.NoError(t, err)
+	require.NoError(t, n0p1.Await(5*time.Second))
+	require.NoError(t, n1p0.Await(5*time.Second))
+	require.True(t, n0p1.PubKey().Equal(keys[1].Public))
+	require.True(t, n1p0.PubKey().Equal(keys[0].Public))
+
+	byKey, err := nodes[0].PeerByPubKey(keys[1].Public)
+	require.NoError(t, err)
+	require.EqualValues(t, netIDs[1], byKey.NetID())
+
+	recvCh := make(chan *peering.RecvEvent, 1)
+	nodes[1].Attach(nil, func(recv *peering.RecvEvent) {
+		recvCh <- recv
+	})
+	chainID := coretypes.NewRandomChainID()
+	n0p1.SendMsg(&peering.PeerMessage{ChainID: chainID, MsgType: 125, MsgData: []byte("secret")})
+	select {
+	case recv := <-recvCh:
+		require.EqualValues(t, netIDs[0], recv.From.NetID())
+		require.EqualValues(t, []byte("secret"), recv.Msg.MsgData)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not received")
+	}
+
+	// chopped into several encrypted frames
+	bigData := make([]byte, 200*1024)
+	for i := range bigData {
+		bigData[i] = byte(i)
+	}
+	n0p1.SendMsg(&peering.PeerMessage{ChainID: chainID, MsgType: 125, MsgData: bigData})
+	select {
+	case recv := <-recvCh:
+		require.EqualValues(t, bigData, recv.Msg.MsgData)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not received")
+	}
+}
+
+func TestUntrustedKey(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	keys := [2]*key.Pair{key.NewKeyPair(suite), key.NewKeyPair(suite)}
+	nodes, netIDs, _ := startPair(t, [2]int{9029, 9030}, keys)
+	// node1 expects another key from node0
+	require.NoError(t, nodes[1].TrustPeer(netIDs[0], key.NewKeyPair(suite).Public))
+	for i := range nodes {
+		go nodes[i].Run(make(<-chan struct{}))
+	}
+	n0p1, err := nodes[0].PeerByNetID(netIDs[1])
+	require.NoError(t, err)
+	n1p0, err := nodes[1].PeerByNetID(netIDs[0])
+	require.NoError(t, err)
+
+	recvCh := make(chan *peering.RecvEvent, 1)
+	nodes[1].Attach(nil, func(recv *peering.RecvEvent) {
+		recvCh <- recv
+	})
+	chainID := coretypes.NewRandomChainID()
+	<-time.After(2 * time.Second)
+	n0p1.SendMsg(&peering.PeerMessage{ChainID: chainID, MsgType: 125})
+	select {
+	case <-recvCh:
+		t.Fatal("message from untrusted peer received")
+	case <-time.After(time.Second):
+	}
+	// the initiator completes its side before the key is checked by node1, so only node1 is checked
+	require.False(t, n1p0.IsAlive())
+	_, err = nodes[1].PeerByPubKey(keys[0].Public)
+	require.Error(t, err)
+}
+
+func TestKeyRotation(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	keys := [2]*key.Pair{key.NewKeyPair(suite), key.NewKeyPair(suite)}
+	nodes, netIDs, regs := startPair(t, [2]int{9031, 9032}, keys)
+	require.NoError(t, nodes[1].AllowKeyRotation(netIDs[0]))
+	for i := range nodes {
+		go nodes[i].Run(make(<-chan struct{}))
+	}
+	n0p1, err := nodes[0].PeerByNetID(netIDs[1])
+	require.NoError(t, err)
+	n1p0, err := nodes[1].PeerByNetID(netIDs[0])
+	require.NoError(t, err)
+	require.NoError(t, n0p1.Await(5*time.Second))
+	require.NoError(t, n1p0.Await(5*time.Second))
+
+	newKeys := key.NewKeyPair(suite)
+	require.NoError(t, nodes[0].RotateKeyPair(newKeys))
+	require.True(t, nodes[0].PubKey().Equal(newKeys.Public))
+	require.Eventually(t, func() bool {
+		p, err := nodes[1].PeerByPubKey(newKeys.Public)
+		return err == nil && p.NetID() == netIDs[0]
+	}, 5*time.Second, 10*time.Millisecond)
+	require.True(t, n1p0.PubKey().Equal(newKeys.Public))
+	_, err = nodes[1].PeerByPubKey(keys[0].Public)
+	require.Error(t, err)
+
+	// both keys are persisted in the registries, the rotation is allowed only once
+	identity, err := regs[0].GetNodeIdentity()
+	require.NoError(t, err)
+	require.True(t, identity.Public.Equal(newKeys.Public))
+	tp, err := regs[1].GetTrustedPeer(netIDs[0])
+	require.NoError(t, err)
+	require.True(t, tp.PubKey.Equal(newKeys.Public))
+	require.False(t, tp.KeyRotation)
+
+	recvCh := make(chan *peering.RecvEvent, 1)
+	nodes[0].Attach(nil, func(recv *peering.RecvEvent) {
+		recvCh <- recv
+	})
+	n1p0.SendMsg(&peering.PeerMessage{ChainID: coretypes.NewRandomChainID(), MsgType: 125})
+	select {
+	case recv := <-recvCh:
+		require.EqualValues(t, netIDs[1], recv.From.NetID())
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not received")
+	}
+}
+
+func TestPinnedKeyRotation(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	keys := [2]*key.Pair{key.NewKeyPair(suite), key.NewKeyPair(suite)}
+	nodes, netIDs, _ := startPair(t, [2]int{9033, 9034}, keys)
+	// the key of node0 is set by the operator of node1
+	require.NoError(t, nodes[1].TrustPeer(netIDs[0], keys[0].Public))
+	for i := range nodes {
+		go nodes[i].Run(make(<-chan struct{}))
+	}
+	n0p1, err := nodes[0].PeerByNetID(netIDs[1])
+	require.NoError(t, err)
+	n1p0, err := nodes[1].PeerByNetID(netIDs[0])
+	require.NoError(t, err)
+	require.NoError(t, n0p1.Await(5*time.Second))
+	require.NoError(t, n1p0.Await(5*time.Second))
+
+	newKeys := key.NewKeyPair(suite)
+	require.NoError(t, nodes[0].RotateKeyPair(newKeys))
+	require.Eventually(t, func() bool {
+		return !n1p0.IsAlive()
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = nodes[1].PeerByPubKey(newKeys.Public)
+	require.Error(t, err)
+	require.True(t, n1p0.PubKey().Equal(keys[0].Public))
+}
+
+func TestAllowedKeyRotation(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	keys := [2]*key.Pair{key.NewKeyPair(suite), key.NewKeyPair(suite)}
+	nodes, netIDs, _ := startPair(t, [2]int{9035, 9036}, keys)
+	require.NoError(t, nodes[1].TrustPeer(netIDs[0], keys[0].Public))
+	require.NoError(t, nodes[1].AllowKeyRotation(netIDs[0]))
+	for i := range nodes {
+		go nodes[i].Run(make(<-chan struct{}))
+	}
+	n0p1, err := nodes[0].PeerByNetID(netIDs[1])
+	require.NoError(t, err)
+	n1p0, err := nodes[1].PeerByNetID(netIDs[0])
+	require.NoError(t, err)
+	require.NoError(t, n0p1.Await(5*time.Second))
+	require.NoError(t, n1p0.Await(5*time.Second))
+
+	newKeys := key.NewKeyPair(suite)
+	require.NoError(t, nodes[0].RotateKeyPair(newKeys))
+	require.Eventually(t, func() bool {
+		p, err := nodes[1].PeerByPubKey(newKeys.Public)
+		return err == nil && p.NetID() == netIDs[0]
+	}, 5*time.Second, 10*time.Millisecond)
+	require.True(t, n1p0.IsAlive())
+}
+
+func TestDuplicateKeyRotation(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	keys := [2]*key.Pair{key.NewKeyPair(suite), key.NewKeyPair(suite)}
+	nodes, netIDs, _ := startPair(t, [2]int{9037, 9038}, keys)
+	// node1 knows the key of a third node
+	otherKeys := key.NewKeyPair(suite)
+	require.NoError(t, nodes[1].TrustPeer("localhost:9039", otherKeys.Public))
+	require.NoError(t, nodes[1].AllowKeyRotation(netIDs[0]))
+	for i := range nodes {
+		go nodes[i].Run(make(<-chan struct{}))
+	}
+	n0p1, err := nodes[0].PeerByNetID(netIDs[1])
+	require.NoError(t, err)
+	n1p0, err := nodes[1].PeerByNetID(netIDs[0])
+	require.NoError(t, err)
+	require.NoError(t, n0p1.Await(5*time.Second))
+	require.NoError(t, n1p0.Await(5*time.Second))
+
+	// node0 owns the key pair, so the signature is valid, but the key belongs to another NetID
+	require.NoError(t, nodes[0].RotateKeyPair(otherKeys))
+	require.Eventually(t, func() bool {
+		return !n1p0.IsAlive()
+	}, 5*time.Second, 10*time.Millisecond)
+	p, err := nodes[1].PeerByPubKey(otherKeys.Public)
+	if err == nil {
+		require.NotEqual(t, netIDs[0], p.NetID())
+	}
+}
+
+func TestUnknownPeer(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	log := testutil.NewLogger(t)
+	netIDs := []string{"localhost:9041", "localhost:9042"}
+	keys := []*key.Pair{key.NewKeyPair(suite), key.NewKeyPair(suite)}
+	// node0 trusts node1, but node1 doesn't know node0
+	reg0 := newRegistry(t, log, 0, netIDs, []kyber.Point{keys[0].Public, keys[1].Public})
+	reg1 := registry.NewRegistry(suite, log, dbprovider.NewInMemoryDBProvider(log))
+	node0, err := tcp.NewNetworkProvider(netIDs[0], 9041, keys[0], reg0, suite, log.Named("node0"))
+	require.NoError(t, err)
+	node1, err := tcp.NewNetworkProvider(netIDs[1], 9042, keys[1], reg1, suite, log.Named("node1"))
+	require.NoError(t, err)
+	go node0.Run(make(<-chan struct{}))
+	go node1.Run(make(<-chan struct{}))
+	n0p1, err := node0.PeerByNetID(netIDs[1])
+	require.NoError(t, err)
+	n1p0, err := node1.PeerByNetID(netIDs[0])
+	require.NoError(t, err)
+
+	recvCh := make(chan *peering.RecvEvent, 1)
+	node1.Attach(nil, func(recv *peering.RecvEvent) {
+		recvCh <- recv
+	})
+	<-time.After(2 * time.Second)
+	n0p1.SendMsg(&peering.PeerMessage{ChainID: coretypes.NewRandomChainID(), MsgType: 125})
+	select {
+	case <-recvCh:
+		t.Fatal("message from unknown peer received")
+	case <-time.After(time.Second):
+	}
+	require.False(t, n1p0.IsAlive())
+	_, err = node1.PeerByPubKey(keys[0].Public)
+	require.Error(t, err)
+}
@@ -10,9 +10,9 @@ import (
 	"sync"
 	"time"
 
-	"github.com/iotaledger/goshimmer/packages/tangle"
 	"github.com/iotaledger/hive.go/backoff"
 	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/metrics"
 	"github.com/iotaledger/wasp/packages/peering"
 	"go.dedis.ch/kyber/v3"
 	"go.uber.org/atomic"
@@ -37,6 +37,7 @@ type peer struct {
 	remotePubKey kyber.Point
 
 	startOnce *sync.Once
+	readyOnce *sync.Once
 	waitReady *sync.WaitGroup
 	numUsers  int
 	net       *NetImpl
@@ -50,6 +51,7 @@ func newPeer(remoteNetID string, net *NetImpl) *peer {
 		RWMutex:     &sync.RWMutex{},
 		remoteNetID: remoteNetID,
 		startOnce:   &sync.Once{},
+		readyOnce:   &sync.Once{},
 		waitReady:   &waitReady,
 		numUsers:    1,
 		net:         net,
@@ -63,9 +65,12 @@ func (p *peer) NetID() string {
 }
 
 // PubKey implements peering.PeerSender and peering.PeerStatusProvider interfaces for the remote peers.
+// The key is the one proven by the peer in the handshake or announced by it in the key rotation.
 func (p *peer) PubKey() kyber.Point {
 	p.log.Infof("Waiting for connection to become ready to get %v peer's public key, inbound=%v.", p.remoteNetID, p.IsInbound())
 	p.waitReady.Wait()
+	p.RLock()
+	defer p.RUnlock()
 	return p.remotePubKey
 }
 
@@ -123,6 +128,14 @@ func (p *peer) connStatus() (bool, bool) {
 	return p.peerconn != nil, p.handshakeOk
 }
 
+// setReady marks the peer as connected after the successful handshake.
+// Assumes the peer is locked
+func (p *peer) setReady(pubKey kyber.Point) {
+	p.remotePubKey = pubKey
+	p.handshakeOk = true
+	p.readyOnce.Do(p.waitReady.Done)
+}
+
 func (p *peer) closeConn() {
 	p.Lock()
 	defer p.Unlock()
@@ -186,27 +199,28 @@ func (p *peer) runOutbound() {
 	p.closeConn()
 }
 
-// sends handshake message. It contains myNetID
+// sends the first handshake message. It contains myNetID and the ephemeral key of the handshake
 func (p *peer) sendHandshake() error {
-	var err error
-	msg := handshakeMsg{
-		peeringID: p.peeringID(),
-		srcNetID:  p.net.Self().NetID(),
-		pubKey:    p.net.nodeKeyPair.Public,
-	}
-	var msgData []byte
-	if msgData, err = msg.bytes(); err != nil {
+	p.peerconn.handshake = newHandshakeState(p.net.suite, true)
+	msg, err := p.peerconn.handshake.initMsg(p.peeringID(), p.net.myNetID)
+	if err != nil {
 		return err
 	}
-	data := encodeMessage(&peering.PeerMessage{
-		MsgType: msgTypeHandshake,
-		MsgData: msgData,
-	}, time.Now().UnixNano())
-	_, err = p.peerconn.Write(data)
+	err = p.peerconn.sendHandshakeMsg(msg)
 	p.net.log.Debugf("sendHandshake '%s' --> '%s', id = %s", p.net.myNetID, p.remoteNetID, p.peeringID())
 	return err
 }
 
+// sends the signed new public key of this node to the peer over the authenticated channel
+func (p *peer) sendKeyRotation(data []byte) error {
+	p.RLock()
+	defer p.RUnlock()
+	return p.sendData(encodeMessage(&peering.PeerMessage{
+		MsgType: msgTypeKeyRotation,
+		MsgData: data,
+	}, time.Now().UnixNano()))
+}
+
 func (p *peer) doSendMsg(msg *peering.PeerMessage) error {
 	if msg.MsgType < peering.FirstUserMsgCode {
 		return errors.New("reserved message code")
@@ -217,18 +231,25 @@ func (p *peer) doSendMsg(msg *peering.PeerMessage) error {
 	}
 	data := encodeMessage(msg, ts)
 
-	choppedData, chopped, err := p.peerconn.msgChopper.ChopData(data, tangle.MaxMessageSize, chunkMessageOverhead)
-	if err != nil {
-		return err
-	}
-
 	p.RLock()
 	defer p.RUnlock()
 
+	if p.peerconn == nil {
+		return fmt.Errorf("no connection with %s", p.remoteNetID)
+	}
+	choppedData, chopped, err := p.peerconn.msgChopper.ChopData(data, maxFrameSize, chunkMessageOverhead)
+	if err != nil {
+		return err
+	}
 	if !chopped {
-		return p.sendData(data)
+		err = p.sendData(data)
+	} else {
+		err = p.sendChunks(choppedData)
+	}
+	if err == nil {
+		metrics.PeerMessageSent(p.remoteNetID, len(msg.MsgData))
 	}
-	return p.sendChunks(choppedData)
+	return err
 }
 
 func (p *peer) sendChunks(chopped [][]byte) error {
@@ -261,7 +282,7 @@ func SendMsgToPeers(msg *peering.PeerMessage, ts int64, peers ...*peer) uint16 {
 			continue
 		}
 		peer.RLock()
-		choppedData, chopped, err := peer.peerconn.msgChopper.ChopData(data, tangle.MaxMessageSize, chunkMessageOverhead)
+		choppedData, chopped, err := peer.peerconn.msgChopper.ChopData(data, maxFrameSize, chunkMessageOverhead)
 		if err != nil {
 			return 0
 		}
@@ -283,7 +304,11 @@ func (p *peer) sendData(data []byte) error {
 	if p.peerconn == nil {
 		return fmt.Errorf("no connection with %s", p.remoteNetID)
 	}
-	num, err := p.peerconn.Write(data)
+	if !p.handshakeOk {
+		// nothing is sent before the channel is authenticated and encrypted
+		return fmt.Errorf("handshake with %s is not completed", p.remoteNetID)
+	}
+	num, err := p.peerconn.writeFrame(data)
 	if num != len(data) {
 		return fmt.Errorf("not all bytes were written. err = %v", err)
 	}
@@ -4,25 +4,32 @@
 package tcp
 
 import (
+	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/chopper"
 	"github.com/iotaledger/goshimmer/packages/tangle"
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/netutil/buffconn"
+	"github.com/iotaledger/wasp/packages/metrics"
 	"github.com/iotaledger/wasp/packages/peering"
 )
 
 // extension of BufferedConnection from hive.go
 // BufferedConnection is a wrapper for net.Conn
 // peeredConnection first handles handshake and then links
-// with peer according to the handshake information
+// with peer according to the handshake information.
+// After the handshake all frames are encrypted with the keys of the session
 type peeredConnection struct {
 	*buffconn.BufferedConnection
-	peer        *peer
-	net         *NetImpl
-	msgChopper  *chopper.Chopper
-	handshakeOk bool
+	peer       *peer
+	net        *NetImpl
+	msgChopper *chopper.Chopper
+	handshake  *handshakeState // handshake in progress
+	session    *session        // nil until the handshake is completed
+	sendMutex  *sync.Mutex     // serializes encryption and writing of frames
 }
 
 // creates new peered connection and attach event handlers for received data and closing
@@ -32,6 +39,7 @@ func newPeeredConnection(conn net.Conn, net *NetImpl, peer *peer) *peeredConnect
 		peer:               peer, // may be nil
 		net:                net,
 		msgChopper:         chopper.NewChopper(),
+		sendMutex:          &sync.Mutex{},
 	}
 	c.Events.ReceiveMessage.Attach(events.NewClosure(func(data []byte) {
 		c.receiveData(data)
@@ -39,8 +47,11 @@ func newPeeredConnection(conn net.Conn, net *NetImpl, peer *peer) *peeredConnect
 	c.Events.Close.Attach(events.NewClosure(func() {
 		if c.peer != nil {
 			c.peer.Lock()
-			c.peer.peerconn = nil
-			c.peer.handshakeOk = false
+			// inbound connection is linked with the peer only after the handshake
+			if c.peer.peerconn == c {
+				c.peer.peerconn = nil
+				c.peer.handshakeOk = false
+			}
 			c.peer.Unlock()
 		}
 		net.log.Debugw("closed buff connection", "conn", conn.RemoteAddr().String())
@@ -48,123 +59,234 @@ func newPeeredConnection(conn net.Conn, net *NetImpl, peer *peer) *peeredConnect
 	return c
 }
 
+// writeFrame encrypts the frame if the session is established and writes it to the connection
+func (c *peeredConnection) writeFrame(data []byte) (int, error) {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+
+	if c.session == nil {
+		return c.Write(data)
+	}
+	sealed := c.session.seal(data)
+	num, err := c.Write(sealed)
+	if num == len(sealed) {
+		num = len(data)
+	}
+	return num, err
+}
+
+// setSession switches the connection to the encrypted mode.
+// The last handshake message, if any, is the last frame written in cleartext
+func (c *peeredConnection) setSession(sess *session, lastHandshakeFrame []byte) error {
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+
+	if lastHandshakeFrame != nil {
+		if _, err := c.Write(lastHandshakeFrame); err != nil {
+			return err
+		}
+	}
+	c.session = sess
+	return nil
+}
+
 // receive data handler for peered connection
 func (c *peeredConnection) receiveData(data []byte) {
+	if c.session != nil {
+		var err error
+		if data, err = c.session.open(data); err != nil {
+			c.net.log.Errorf("peeredConnection.receiveData: failed to decrypt frame: %v. Closing", err)
+			_ = c.Close()
+			return
+		}
+	}
+	c.receiveFrame(data)
+}
+
+func (c *peeredConnection) receiveFrame(data []byte) {
 	msg, err := decodeMessage(data)
 	if err != nil {
 		// gross violation of the protocol
 		c.net.log.Errorf("!!!!! peeredConnection.receiveData.decodeMessage: %v", err)
-		c.Close()
+		_ = c.Close()
 		return
 	}
-	if msg.MsgType == peering.MsgTypeMsgChunk {
-		finalMsg, err := c.msgChopper.IncomingChunk(msg.MsgData, tangle.MaxMessageSize, chunkMessageOverhead)
-		if err != nil {
-			c.net.log.Errorf("peeredConnection.receiveData: %v", err)
+	if c.session == nil {
+		// only the handshake is accepted in cleartext
+		if msg.MsgType != msgTypeHandshake {
+			c.net.log.Errorf("peeredConnection.receiveData: unexpected message during handshake. Closing")
+			_ = c.Close()
 			return
 		}
-		if finalMsg != nil {
-			c.receiveData(finalMsg)
+		if err = c.processHandshake(msg); err != nil {
+			c.net.log.Errorf("peeredConnection.receiveData: handshake failed: %v. Closing", err)
+			_ = c.Close()
 		}
+		return
 	}
-	if c.peer != nil {
-		// it is peered but maybe not handshaked yet (can only be outbound)
-		if c.peer.handshakeOk {
-			// it is handshake-ed
-			c.net.events.Trigger(&peering.RecvEvent{
-				From: c.peer,
-				Msg:  msg,
-			})
-		} else {
-			// expected handshake msg
-			if msg.MsgType != peering.MsgTypeHandshake {
-				c.net.log.Errorf("peeredConnection.receiveData: unexpected message during handshake 1")
-				return
-			}
-			// not handshaked => do handshake
-			c.processHandShakeOutbound(msg)
-		}
-	} else {
-		// can only be inbound
-		// expected handshake msg
-		if msg.MsgType != peering.MsgTypeHandshake {
-			c.net.log.Errorf("peeredConnection.receiveData: unexpected message during handshake 2")
+	switch msg.MsgType {
+	case msgTypeHandshake:
+		c.net.log.Errorf("peeredConnection.receiveData: unexpected handshake message. Closing")
+		_ = c.Close()
+
+	case msgTypeMsgChunk:
+		finalMsg, err := c.msgChopper.IncomingChunk(msg.MsgData, maxFrameSize, chunkMessageOverhead)
+		if err != nil {
+			c.net.log.Errorf("peeredConnection.receiveData: %v", err)
 			return
 		}
-		// not peered yet can be only inbound
-		// peer up and do handshake
-		c.processHandShakeInbound(msg)
-	}
-}
+		if finalMsg != nil {
+			c.receiveFrame(finalMsg)
+		}
 
-// receives handshake response from the outbound peer
-// assumes the connection is already peered (i can be only for outbound peers)
-func (c *peeredConnection) processHandShakeOutbound(msg *peering.PeerMessage) {
-	var err error
-	var hMsg *handshakeMsg
-	if hMsg, err = handshakeMsgFromBytes(msg.MsgData, c.net.suite); err != nil {
-		c.net.log.Errorf(
-			"closeConn the peer connection: wrong handshake message from outbound peer %v, error: %v",
-			c.peer.peeringID(), err,
-		)
-		panic(err)
-	}
-	c.net.log.Debugf("received handshake from outbound %s", hMsg.peeringID)
-	if hMsg.peeringID != c.peer.peeringID() {
-		c.net.log.Errorf(
-			"closeConn the peer connection: wrong handshake message from outbound peer: expected %s got '%s'",
-			c.peer.peeringID(), hMsg.peeringID,
-		)
-		if c.peer != nil {
-			// may ne be peered yet
-			c.peer.closeConn()
+	case msgTypeKeyRotation:
+		if err = c.processKeyRotation(msg); err != nil {
+			c.net.log.Errorf("peeredConnection.receiveData: key rotation failed: %v. Closing", err)
+			_ = c.Close()
 		}
-	} else {
-		c.net.log.Infof("CONNECTED WITH PEER %s (outbound)", hMsg.peeringID)
-		c.peer.remotePubKey = hMsg.pubKey
-		c.peer.handshakeOk = true
-		c.peer.waitReady.Done()
+
+	default:
+		metrics.PeerMessageReceived(c.peer.remoteNetID, len(msg.MsgData))
+		c.net.events.Trigger(&peering.RecvEvent{
+			From: c.peer,
+			Msg:  msg,
+		})
 	}
 }
 
-// receives handshake from the inbound peer
-// links connection with the peer
-// sends response back to finish the handshake
-func (c *peeredConnection) processHandShakeInbound(msg *peering.PeerMessage) {
-	var err error
-	var hMsg *handshakeMsg
-	if hMsg, err = handshakeMsgFromBytes(msg.MsgData, c.net.suite); err != nil {
-		c.net.log.Errorf(
-			"closeConn the peer connection: wrong handshake message from outbound peer %v, error: %v",
-			c.peer.peeringID(), err,
-		)
-		panic(err)
+func (c *peeredConnection) processHandshake(msg *peering.PeerMessage) error {
+	hMsg, err := handshakeMsgFromBytes(msg.MsgData, c.net.suite)
+	if err != nil {
+		return err
 	}
+	switch {
+	case hMsg.step == handshakeInit && c.peer == nil && c.handshake == nil:
+		return c.processHandshakeInit(hMsg)
+	case hMsg.step == handshakeResponse && c.handshake != nil && c.handshake.initiator:
+		return c.processHandshakeResponse(hMsg)
+	case hMsg.step == handshakeFinish && c.handshake != nil && !c.handshake.initiator:
+		return c.processHandshakeFinish(hMsg)
+	}
+	return fmt.Errorf("unexpected handshake step %d", hMsg.step)
+}
 
-	c.net.log.Infof("received handshake from inbound id = %s, peers=%+v", hMsg.peeringID, c.net.peers)
+// receives the first handshake message from the inbound peer
+// and sends the response with the proof of the key of this node.
+// The connection is linked with the peer only when the handshake is finished
+func (c *peeredConnection) processHandshakeInit(hMsg *handshakeMsg) error {
+	c.net.log.Debugf("received handshake from inbound id = %s", hMsg.peeringID)
 
 	c.net.peersMutex.RLock()
 	peer, ok := c.net.peers[hMsg.peeringID]
 	c.net.peersMutex.RUnlock()
 
-	if !ok || !peer.IsInbound() {
-		c.net.log.Warnf("inbound connection from unexpected peer id %s. Closing..", hMsg.peeringID)
-		_ = c.Close()
-		return
+	if !ok || !peer.IsInbound() || peer.remoteNetID != hMsg.srcNetID {
+		return fmt.Errorf("inbound connection from unexpected peer id %s", hMsg.peeringID)
 	}
 	c.peer = peer
+	c.handshake = newHandshakeState(c.net.suite, false)
+	resp, err := c.handshake.respond(hMsg, c.net.myNetID, c.net.keyPair())
+	if err != nil {
+		return err
+	}
+	return c.sendHandshakeMsg(resp)
+}
+
+// receives the handshake response from the outbound peer, verifies its key
+// and sends the last message of the handshake
+func (c *peeredConnection) processHandshakeResponse(hMsg *handshakeMsg) error {
+	if hMsg.srcNetID != c.peer.remoteNetID {
+		return fmt.Errorf("wrong handshake response from outbound peer %s: got NetID '%s'", c.peer.peeringID(), hMsg.srcNetID)
+	}
+	fin, sess, err := c.handshake.finish(hMsg, c.net.keyPair())
+	if err != nil {
+		return err
+	}
+	if err = c.net.checkPeerKey(c.peer.remoteNetID, hMsg.pubKey); err != nil {
+		return err
+	}
+	var finData []byte
+	if finData, err = encodeHandshakeMsg(fin); err != nil {
+		return err
+	}
+	if err = c.setSession(sess, finData); err != nil {
+		return err
+	}
+	c.handshake = nil
+	c.peer.Lock()
+	c.peer.setReady(hMsg.pubKey)
+	c.peer.Unlock()
+	c.net.log.Infof("CONNECTED WITH PEER %s (outbound)", c.peer.peeringID())
+	return nil
+}
+
+// receives the last handshake message from the inbound peer, verifies its key
+// and links the connection with the peer
+func (c *peeredConnection) processHandshakeFinish(hMsg *handshakeMsg) error {
+	sess, err := c.handshake.complete(hMsg)
+	if err != nil {
+		return err
+	}
+	if err = c.net.checkPeerKey(c.peer.remoteNetID, hMsg.pubKey); err != nil {
+		return err
+	}
+	if err = c.setSession(sess, nil); err != nil {
+		return err
+	}
+	c.handshake = nil
 
-	peer.Lock()
-	peer.peerconn = c
-	peer.remotePubKey = hMsg.pubKey
-	peer.handshakeOk = true
-	peer.waitReady.Done()
-	peer.Unlock()
+	c.peer.Lock()
+	if c.peer.peerconn != nil && c.peer.peerconn != c {
+		// the new connection replaces the previous one
+		_ = c.peer.peerconn.Close()
+	}
+	c.peer.peerconn = c
+	c.peer.setReady(hMsg.pubKey)
+	c.peer.Unlock()
 
-	c.net.log.Infof("CONNECTED WITH PEER %s (inbound)", hMsg.peeringID)
+	c.net.log.Infof("CONNECTED WITH PEER %s (inbound)", c.peer.peeringID())
+	return nil
+}
 
-	if err := peer.sendHandshake(); err != nil {
-		c.net.log.Errorf("error while responding to handshake: %v. Closing connection", err)
-		_ = c.Close()
+// receives the new public key of the peer over the authenticated channel.
+// The key is accepted if it is signed by the new private key and the rotation is allowed
+func (c *peeredConnection) processKeyRotation(msg *peering.PeerMessage) error {
+	rMsg, err := keyRotationMsgFromBytes(msg.MsgData, c.net.suite)
+	if err != nil {
+		return err
+	}
+	c.peer.RLock()
+	oldPubKey := c.peer.remotePubKey
+	c.peer.RUnlock()
+	if err = rMsg.verify(c.net.suite, oldPubKey, c.peer.remoteNetID); err != nil {
+		return err
+	}
+	if err = c.net.rotatePeerKey(c.peer.remoteNetID, oldPubKey, rMsg.pubKey); err != nil {
+		return err
+	}
+	c.peer.Lock()
+	c.peer.remotePubKey = rMsg.pubKey
+	c.peer.Unlock()
+	c.net.log.Infof("peer %s has rotated its key", c.peer.peeringID())
+	return nil
+}
+
+func (c *peeredConnection) sendHandshakeMsg(hMsg *handshakeMsg) error {
+	data, err := encodeHandshakeMsg(hMsg)
+	if err != nil {
+		return err
+	}
+	_, err = c.writeFrame(data)
+	return err
+}
+
+func encodeHandshakeMsg(hMsg *handshakeMsg) ([]byte, error) {
+	msgData, err := hMsg.bytes()
+	if err != nil {
+		return nil, err
 	}
+	return encodeMessage(&peering.PeerMessage{
+		MsgType: msgTypeHandshake,
+		MsgData: msgData,
+	}, time.Now().UnixNano()), nil
 }
@@ -39,7 +39,11 @@ func (n *NetImpl) connectOutboundLoop() {
 		time.Sleep(100 * time.Millisecond)
 		n.peersMutex.Lock()
 		for _, c := range n.peers {
-			c.startOnce.Do(func() {
+			// startOnce is replaced by the peer when it reconnects
+			c.RLock()
+			startOnce := c.startOnce
+			c.RUnlock()
+			startOnce.Do(func() {
 				go c.runOutbound()
 			})
 		}
@@ -0,0 +1,17 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package tcp
+
+import (
+	"github.com/iotaledger/wasp/packages/peering"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+// RegistryProvider stands for a partial registry interface, needed for this package.
+// It should be implemented by registry.impl
+type RegistryProvider interface {
+	GetTrustedPeers() ([]*peering.TrustedPeer, error)
+	SaveTrustedPeer(tp *peering.TrustedPeer) error
+	SaveNodeIdentity(pair *key.Pair) error
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/metrics"
 	"github.com/iotaledger/wasp/packages/peering"
 	"github.com/iotaledger/wasp/packages/peering/group"
 	"go.dedis.ch/kyber/v3"
@@ -313,6 +314,7 @@ func (n *NetImpl) receiveUserMsg(msg *peering.PeerMessage, peerUDPAddr *net.UDPA
 	if p, ok := n.peersByAddr[remoteUDPAddrStr]; ok {
 		n.peersLock.RUnlock()
 		p.noteReceived()
+		metrics.PeerMessageReceived(p.NetID(), len(msg.MsgData))
 		n.recvQueue <- &peering.RecvEvent{
 			From: p,
 			Msg:  msg,
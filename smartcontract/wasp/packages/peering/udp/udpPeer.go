@@ -12,6 +12,7 @@ import (
 
 	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/chopper"
 	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/metrics"
 	"github.com/iotaledger/wasp/packages/peering"
 	"github.com/iotaledger/wasp/packages/util"
 	"go.dedis.ch/kyber/v3"
@@ -201,6 +202,8 @@ func (p *peer) SendMsg(msg *peering.PeerMessage) {
 		}
 	}
 
+	metrics.PeerMessageSent(p.NetID(), len(msg.MsgData))
+
 	p.accessLock.Lock()
 	defer p.accessLock.Unlock()
 	p.lastMsgSent = time.Now()
@@ -0,0 +1,82 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.nanomsg.org/mangos/v3"
+	"go.nanomsg.org/mangos/v3/protocol"
+	"go.nanomsg.org/mangos/v3/protocol/pub"
+)
+
+// HelloTopic is the nanomsg topic of the handshake message. The publisher sends it to every newly
+// connected subscriber (see NewPubSocket). The subscriber selects the version of the schema from the
+// versions announced in the message and subscribes to topics of that version (see Topic)
+const HelloTopic = "hello "
+
+type Hello struct {
+	Versions []uint16 `json:"versions"`
+}
+
+// EncodeHello encodes the handshake message announcing SupportedVersions
+func EncodeHello() []byte {
+	data, err := json.Marshal(&Hello{Versions: SupportedVersions})
+	if err != nil {
+		panic(err)
+	}
+	return append([]byte(HelloTopic), data...)
+}
+
+func DecodeHello(data []byte) (*Hello, error) {
+	if !strings.HasPrefix(string(data), HelloTopic) {
+		return nil, fmt.Errorf("not a hello message")
+	}
+	ret := &Hello{}
+	if err := json.Unmarshal(data[len(HelloTopic):], ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// NegotiateVersion returns the highest version of the schema supported both by the publisher and
+// by this package
+func (h *Hello) NegotiateVersion() (uint16, error) {
+	var ret uint16
+	found := false
+	for _, v := range h.Versions {
+		for _, own := range SupportedVersions {
+			if v == own && (!found || v > ret) {
+				ret = v
+				found = true
+			}
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("no common version of messages: publisher supports %v, client supports %v", h.Versions, SupportedVersions)
+	}
+	return ret, nil
+}
+
+// NewPubSocket creates the PUB socket which sends the handshake message to each newly connected
+// subscriber before any other message. Subscribers connected before don't receive it again
+func NewPubSocket() mangos.Socket {
+	return protocol.MakeSocket(&helloProtocol{
+		Protocol: pub.NewProtocol(),
+		hello:    EncodeHello(),
+	})
+}
+
+type helloProtocol struct {
+	protocol.Protocol
+	hello []byte
+}
+
+func (p *helloProtocol) AddPipe(pipe protocol.Pipe) error {
+	msg := mangos.NewMessage(len(p.hello))
+	msg.Body = append(msg.Body, p.hello...)
+	if err := pipe.SendMsg(msg); err != nil {
+		return err
+	}
+	return p.Protocol.AddPipe(pipe)
+}
@@ -0,0 +1,50 @@
+package publisher
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.nanomsg.org/mangos/v3"
+	"go.nanomsg.org/mangos/v3/protocol/sub"
+	_ "go.nanomsg.org/mangos/v3/transport/all"
+)
+
+func TestPubSocketHello(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := fmt.Sprintf("tcp://127.0.0.1:%d", l.Addr().(*net.TCPAddr).Port)
+	require.NoError(t, l.Close())
+
+	socket := NewPubSocket()
+	defer socket.Close()
+	require.NoError(t, socket.Listen(url))
+
+	dial := func() mangos.Socket {
+		s, err := sub.NewSocket()
+		require.NoError(t, err)
+		require.NoError(t, s.SetOption(mangos.OptionSubscribe, []byte(HelloTopic)))
+		require.NoError(t, s.SetOption(mangos.OptionRecvDeadline, 2*time.Second))
+		require.NoError(t, s.Dial(url))
+		return s
+	}
+	first := dial()
+	defer first.Close()
+	data, err := first.Recv()
+	require.NoError(t, err)
+	hello, err := DecodeHello(data)
+	require.NoError(t, err)
+	require.EqualValues(t, SupportedVersions, hello.Versions)
+
+	second := dial()
+	defer second.Close()
+	_, err = second.Recv()
+	require.NoError(t, err)
+
+	// the hello to the second subscriber is not broadcast
+	require.NoError(t, first.SetOption(mangos.OptionRecvDeadline, 200*time.Millisecond))
+	_, err = first.Recv()
+	require.Equal(t, mangos.ErrRecvTimeout, err)
+}
@@ -0,0 +1,189 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+)
+
+// Version is the version of the message schema. It must be incremented with every incompatible
+// change of the Message or of its encoding
+const Version uint16 = 1
+
+// SupportedVersions are versions of the schema the node is able to publish
+var SupportedVersions = []uint16{Version}
+
+// types of published messages
+const (
+	MsgRequestIn          = "request_in"
+	MsgRequestOut         = "request_out"
+	MsgState              = "state"
+	MsgVM                 = "vmmsg"
+	MsgActiveCommittee    = "active_committee"
+	MsgDismissedCommittee = "dismissed_committee"
+	MsgChainRecord        = "chainrec"
+)
+
+// keys of the payload
+const (
+	PayloadMessage      = "message"
+	PayloadRequestIndex = "requestIndex"
+	PayloadBlockSize    = "blockSize"
+	PayloadStateTxID    = "stateTxID"
+	PayloadStateHash    = "stateHash"
+	PayloadTimestamp    = "timestamp"
+	PayloadColor        = "color"
+)
+
+// Message is the typed message published by the node. Fields which are not relevant for the type
+// of the message are empty. Values specific to the type are in the Payload
+type Message struct {
+	Version    uint16            `json:"version"`
+	Type       string            `json:"type"`
+	ChainID    string            `json:"chainID"`
+	BlockIndex *uint32           `json:"blockIndex,omitempty"`
+	RequestID  string            `json:"requestID,omitempty"`
+	Contract   string            `json:"contract,omitempty"`
+	Payload    map[string]string `json:"payload,omitempty"`
+
+	// parts of the message in the legacy form, without the type
+	legacy []string
+}
+
+func newMessage(msgType string, chainID *coretypes.ChainID) *Message {
+	return &Message{
+		Version: Version,
+		Type:    msgType,
+		ChainID: chainID.String(),
+		Payload: make(map[string]string),
+	}
+}
+
+// NewRequestInMessage creates the message of the request received by the node. The contract is the target of the request
+func NewRequestInMessage(chainID *coretypes.ChainID, reqID *coretypes.RequestID, contract coretypes.Hname) *Message {
+	ret := newMessage(MsgRequestIn, chainID)
+	ret.RequestID = reqID.Base58()
+	ret.Contract = contract.String()
+	ret.legacy = []string{ret.ChainID, reqID.TransactionID().String(), strconv.Itoa(int(reqID.Index()))}
+	return ret
+}
+
+func NewRequestOutMessage(chainID *coretypes.ChainID, reqID *coretypes.RequestID, blockIndex uint32, reqIndex uint16, blockSize uint16) *Message {
+	ret := newMessage(MsgRequestOut, chainID)
+	ret.RequestID = reqID.Base58()
+	ret.BlockIndex = &blockIndex
+	ret.Payload[PayloadRequestIndex] = strconv.Itoa(int(reqIndex))
+	ret.Payload[PayloadBlockSize] = strconv.Itoa(int(blockSize))
+	ret.legacy = []string{
+		ret.ChainID,
+		reqID.TransactionID().String(),
+		strconv.Itoa(int(reqID.Index())),
+		strconv.Itoa(int(blockIndex)),
+		ret.Payload[PayloadRequestIndex],
+		ret.Payload[PayloadBlockSize],
+	}
+	return ret
+}
+
+func NewStateMessage(chainID *coretypes.ChainID, blockIndex uint32, blockSize uint16, stateTxID *valuetransaction.ID, stateHash *hashing.HashValue, timestamp int64) *Message {
+	ret := newMessage(MsgState, chainID)
+	ret.BlockIndex = &blockIndex
+	ret.Payload[PayloadBlockSize] = strconv.Itoa(int(blockSize))
+	ret.Payload[PayloadStateTxID] = stateTxID.String()
+	ret.Payload[PayloadStateHash] = stateHash.String()
+	ret.Payload[PayloadTimestamp] = strconv.FormatInt(timestamp, 10)
+	ret.legacy = []string{
+		ret.ChainID,
+		strconv.Itoa(int(blockIndex)),
+		ret.Payload[PayloadBlockSize],
+		ret.Payload[PayloadStateTxID],
+		ret.Payload[PayloadStateHash],
+		ret.Payload[PayloadTimestamp],
+	}
+	return ret
+}
+
+// NewVMMessage creates the message emitted by the contract. The request ID is nil for messages emitted by views
+func NewVMMessage(chainID *coretypes.ChainID, contract coretypes.Hname, reqID *coretypes.RequestID, msg string) *Message {
+	ret := newMessage(MsgVM, chainID)
+	ret.Contract = contract.String()
+	if reqID != nil {
+		ret.RequestID = reqID.Base58()
+	}
+	ret.Payload[PayloadMessage] = msg
+	ret.legacy = []string{ret.ChainID, ret.Contract, msg}
+	return ret
+}
+
+func NewCommitteeMessage(chainID *coretypes.ChainID, active bool) *Message {
+	msgType := MsgDismissedCommittee
+	if active {
+		msgType = MsgActiveCommittee
+	}
+	ret := newMessage(msgType, chainID)
+	ret.legacy = []string{ret.ChainID}
+	return ret
+}
+
+func NewChainRecordMessage(chainID *coretypes.ChainID, color *balance.Color) *Message {
+	ret := newMessage(MsgChainRecord, chainID)
+	ret.Payload[PayloadColor] = color.String()
+	ret.legacy = []string{ret.ChainID, ret.Payload[PayloadColor]}
+	return ret
+}
+
+// Topic returns the nanomsg topic of messages of the type encoded with the version of the schema.
+// The topic of all messages of the version is returned for the empty type
+func Topic(version uint16, msgType string) string {
+	if msgType == "" {
+		return fmt.Sprintf("v%d/", version)
+	}
+	return fmt.Sprintf("v%d/%s ", version, msgType)
+}
+
+// Encode encodes the message as the topic followed by the JSON of the message
+func (m *Message) Encode() ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(Topic(m.Version, m.Type)), data...), nil
+}
+
+// DecodeMessage decodes the message encoded by Encode. Messages of unsupported versions are rejected
+func DecodeMessage(data []byte) (*Message, error) {
+	for _, v := range SupportedVersions {
+		prefix := Topic(v, "")
+		if len(data) < len(prefix) || string(data[:len(prefix)]) != prefix {
+			continue
+		}
+		ret := &Message{}
+		if err := json.Unmarshal(skipTopic(data), ret); err != nil {
+			return nil, err
+		}
+		if ret.Version != v {
+			return nil, fmt.Errorf("message version %d doesn't match the topic %s", ret.Version, prefix)
+		}
+		return ret, nil
+	}
+	return nil, fmt.Errorf("unsupported version of the message")
+}
+
+// Uint returns the numeric value of the payload
+func (m *Message) Uint(key string) (uint64, error) {
+	return strconv.ParseUint(m.Payload[key], 10, 64)
+}
+
+func skipTopic(data []byte) []byte {
+	for i, b := range data {
+		if b == ' ' {
+			return data[i+1:]
+		}
+	}
+	return nil
+}
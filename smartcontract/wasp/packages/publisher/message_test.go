@@ -0,0 +1,66 @@
+package publisher
+
+import (
+	"testing"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	chainID := coretypes.ChainID{1, 2, 3}
+	txid := valuetransaction.ID{4, 5, 6}
+	rid := coretypes.NewRequestID(txid, 2)
+
+	msg := NewRequestOutMessage(&chainID, &rid, 17, 1, 3)
+	require.EqualValues(t, []string{chainID.String(), txid.String(), "2", "17", "1", "3"}, msg.legacy)
+
+	data, err := msg.Encode()
+	require.NoError(t, err)
+	require.Contains(t, string(data), Topic(Version, MsgRequestOut))
+
+	back, err := DecodeMessage(data)
+	require.NoError(t, err)
+	require.EqualValues(t, Version, back.Version)
+	require.EqualValues(t, MsgRequestOut, back.Type)
+	require.EqualValues(t, chainID.String(), back.ChainID)
+	require.EqualValues(t, rid.Base58(), back.RequestID)
+	require.EqualValues(t, 17, *back.BlockIndex)
+	n, err := back.Uint(PayloadBlockSize)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+
+	// the message with spaces survives the encoding
+	msg = NewVMMessage(&chainID, coretypes.Hn("game"), nil, "player moved")
+	data, err = msg.Encode()
+	require.NoError(t, err)
+	back, err = DecodeMessage(data)
+	require.NoError(t, err)
+	require.EqualValues(t, "player moved", back.Payload[PayloadMessage])
+	require.Empty(t, back.RequestID)
+}
+
+func TestUnsupportedVersion(t *testing.T) {
+	chainID := coretypes.ChainID{1}
+	msg := NewCommitteeMessage(&chainID, true)
+	msg.Version = Version + 1
+	data, err := msg.Encode()
+	require.NoError(t, err)
+	_, err = DecodeMessage(data)
+	require.Error(t, err)
+
+	_, err = DecodeMessage([]byte("state 1 2 3"))
+	require.Error(t, err)
+}
+
+func TestHello(t *testing.T) {
+	hello, err := DecodeHello(EncodeHello())
+	require.NoError(t, err)
+	v, err := hello.NegotiateVersion()
+	require.NoError(t, err)
+	require.EqualValues(t, Version, v)
+
+	_, err = (&Hello{Versions: []uint16{Version + 1}}).NegotiateVersion()
+	require.Error(t, err)
+}
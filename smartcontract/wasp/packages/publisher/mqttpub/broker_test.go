@@ -0,0 +1,244 @@
+package mqttpub
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+// MQTT 3.1.1 control packet types
+const (
+	pktConnect     = 1
+	pktConnack     = 2
+	pktPublish     = 3
+	pktPuback      = 4
+	pktPubrec      = 5
+	pktPubrel      = 6
+	pktPubcomp     = 7
+	pktSubscribe   = 8
+	pktSuback      = 9
+	pktUnsubscribe = 10
+	pktUnsuback    = 11
+	pktPingreq     = 12
+	pktPingresp    = 13
+	pktDisconnect  = 14
+)
+
+// testBroker is the minimal embedded MQTT broker. It acknowledges QoS 1 and 2 publishes,
+// keeps retained messages and forwards messages to subscribers with QoS 0
+type testBroker struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	retained map[string][]byte
+	subs     map[*brokerConn][]string
+}
+
+type brokerConn struct {
+	conn  net.Conn
+	mutex sync.Mutex
+}
+
+func startTestBroker() (*testBroker, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	b := &testBroker{
+		listener: l,
+		retained: make(map[string][]byte),
+		subs:     make(map[*brokerConn][]string),
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(&brokerConn{conn: conn})
+		}
+	}()
+	return b, nil
+}
+
+func (b *testBroker) url() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+func (b *testBroker) close() {
+	b.listener.Close()
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for c := range b.subs {
+		c.conn.Close()
+	}
+}
+
+func (b *testBroker) serve(c *brokerConn) {
+	defer func() {
+		b.mutex.Lock()
+		delete(b.subs, c)
+		b.mutex.Unlock()
+		c.conn.Close()
+	}()
+	r := bufio.NewReader(c.conn)
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch header >> 4 {
+		case pktConnect:
+			b.mutex.Lock()
+			b.subs[c] = nil
+			b.mutex.Unlock()
+			c.write(pktConnack<<4, []byte{0, 0})
+		case pktPublish:
+			qos := (header >> 1) & 0x03
+			topic, rest := readString(body)
+			if qos > 0 {
+				id := rest[:2]
+				rest = rest[2:]
+				if qos == 1 {
+					c.write(pktPuback<<4, id)
+				} else {
+					c.write(pktPubrec<<4, id)
+				}
+			}
+			b.publish(topic, rest, header&0x01 != 0)
+		case pktPubrel:
+			c.write(pktPubcomp<<4, body[:2])
+		case pktSubscribe:
+			id, rest := body[:2], body[2:]
+			granted := make([]byte, 0)
+			filters := make([]string, 0)
+			for len(rest) > 0 {
+				var filter string
+				filter, rest = readString(rest)
+				rest = rest[1:]
+				filters = append(filters, filter)
+				granted = append(granted, 0)
+			}
+			b.mutex.Lock()
+			b.subs[c] = append(b.subs[c], filters...)
+			retained := make(map[string][]byte)
+			for topic, payload := range b.retained {
+				if matchesAny(filters, topic) {
+					retained[topic] = payload
+				}
+			}
+			b.mutex.Unlock()
+			c.write(pktSuback<<4, append(id, granted...))
+			for topic, payload := range retained {
+				c.write(pktPublish<<4|0x01, encodePublish(topic, payload))
+			}
+		case pktUnsubscribe:
+			c.write(pktUnsuback<<4, body[:2])
+		case pktPingreq:
+			c.write(pktPingresp<<4, nil)
+		case pktDisconnect:
+			return
+		}
+	}
+}
+
+func (b *testBroker) publish(topic string, payload []byte, retain bool) {
+	b.mutex.Lock()
+	if retain {
+		if len(payload) == 0 {
+			delete(b.retained, topic)
+		} else {
+			b.retained[topic] = payload
+		}
+	}
+	receivers := make([]*brokerConn, 0)
+	for c, filters := range b.subs {
+		if matchesAny(filters, topic) {
+			receivers = append(receivers, c)
+		}
+	}
+	b.mutex.Unlock()
+	for _, c := range receivers {
+		c.write(pktPublish<<4, encodePublish(topic, payload))
+	}
+}
+
+func (c *brokerConn) write(header byte, body []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	buf := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if n == 0 {
+			break
+		}
+	}
+	_, _ = c.conn.Write(append(buf, body...))
+}
+
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, mul := 0, 1
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * mul
+		mul *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func readString(data []byte) (string, []byte) {
+	n := binary.BigEndian.Uint16(data)
+	return string(data[2 : 2+n]), data[2+n:]
+}
+
+func encodePublish(topic string, payload []byte) []byte {
+	buf := make([]byte, 2, 2+len(topic)+len(payload))
+	binary.BigEndian.PutUint16(buf, uint16(len(topic)))
+	buf = append(buf, topic...)
+	return append(buf, payload...)
+}
+
+func matchesAny(filters []string, topic string) bool {
+	for _, f := range filters {
+		if topicMatches(f, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+func topicMatches(filter, topic string) bool {
+	fl := strings.Split(filter, "/")
+	tl := strings.Split(topic, "/")
+	for i, f := range fl {
+		if f == "#" {
+			return true
+		}
+		if i >= len(tl) || (f != "+" && f != tl[i]) {
+			return false
+		}
+	}
+	return len(fl) == len(tl)
+}
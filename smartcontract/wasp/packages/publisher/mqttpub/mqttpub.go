@@ -0,0 +1,109 @@
+// Package mqttpub publishes messages of the node (see publisher.Message) to an MQTT broker.
+// Messages of contracts are published to the topic <prefix>/<chainID>/<hname>/vmmsg, other messages
+// to <prefix>/<chainID>/<type>. The payload is the JSON encoding of the message. Messages of the
+// 'state' type can be retained, so a new subscriber immediately receives the latest state of the chain
+package mqttpub
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/publisher"
+)
+
+const (
+	DefaultTopicPrefix = "wasp"
+	DefaultClientID    = "wasp"
+
+	connectTimeout = 10 * time.Second
+	publishTimeout = 5 * time.Second
+)
+
+type Config struct {
+	// URL of the broker, e.g. tcp://127.0.0.1:1883
+	BrokerURL   string
+	ClientID    string
+	Username    string
+	Password    string
+	TopicPrefix string
+	// QoS of published messages: 0, 1 or 2
+	QoS byte
+	// RetainState makes the broker retain the latest 'state' message of each chain
+	RetainState bool
+}
+
+type Publisher struct {
+	client mqtt.Client
+	cfg    Config
+	log    *logger.Logger
+}
+
+// New connects to the broker. The client reconnects automatically when the connection is lost
+func New(cfg Config, log *logger.Logger) (*Publisher, error) {
+	if cfg.BrokerURL == "" {
+		return nil, fmt.Errorf("MQTT broker URL is not specified")
+	}
+	if cfg.QoS > 2 {
+		return nil, fmt.Errorf("invalid MQTT QoS: %d", cfg.QoS)
+	}
+	if cfg.ClientID == "" {
+		cfg.ClientID = DefaultClientID
+	}
+	if cfg.TopicPrefix == "" {
+		cfg.TopicPrefix = DefaultTopicPrefix
+	}
+	opts := mqtt.NewClientOptions().
+		AddBroker(cfg.BrokerURL).
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetConnectTimeout(connectTimeout).
+		SetAutoReconnect(true).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			log.Warnf("connection to MQTT broker %s lost: %v", cfg.BrokerURL, err)
+		})
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(connectTimeout) {
+		return nil, fmt.Errorf("timeout while connecting to MQTT broker %s", cfg.BrokerURL)
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("can't connect to MQTT broker %s: %v", cfg.BrokerURL, err)
+	}
+	return &Publisher{
+		client: client,
+		cfg:    cfg,
+		log:    log,
+	}, nil
+}
+
+// Topic returns the topic of the message
+func Topic(prefix string, msg *publisher.Message) string {
+	parts := []string{prefix, msg.ChainID}
+	if msg.Contract != "" {
+		parts = append(parts, msg.Contract)
+	}
+	return strings.Join(append(parts, msg.Type), "/")
+}
+
+// Publish publishes the message and waits until it is delivered according to the QoS
+func (p *Publisher) Publish(msg *publisher.Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	retain := p.cfg.RetainState && msg.Type == publisher.MsgState
+	token := p.client.Publish(Topic(p.cfg.TopicPrefix, msg), p.cfg.QoS, retain, data)
+	if !token.WaitTimeout(publishTimeout) {
+		return fmt.Errorf("timeout while publishing to MQTT broker %s", p.cfg.BrokerURL)
+	}
+	return token.Error()
+}
+
+func (p *Publisher) Close() {
+	p.client.Disconnect(250)
+}
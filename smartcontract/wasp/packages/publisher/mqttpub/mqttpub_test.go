@@ -0,0 +1,96 @@
+package mqttpub
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/publisher"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopic(t *testing.T) {
+	chainID := coretypes.ChainID{1, 2, 3}
+	game := coretypes.Hn("game")
+	require.EqualValues(t, "wasp/"+chainID.String()+"/"+game.String()+"/vmmsg",
+		Topic("wasp", publisher.NewVMMessage(&chainID, game, nil, "moved")))
+	require.EqualValues(t, "wasp/"+chainID.String()+"/active_committee",
+		Topic("wasp", publisher.NewCommitteeMessage(&chainID, true)))
+}
+
+type received struct {
+	topic    string
+	retained bool
+	msg      *publisher.Message
+	err      error
+}
+
+func subscribe(t *testing.T, url, clientID, filter string) chan *received {
+	ret := make(chan *received, 10)
+	client := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(url).SetClientID(clientID))
+	token := client.Connect()
+	require.True(t, token.WaitTimeout(5*time.Second))
+	require.NoError(t, token.Error())
+	t.Cleanup(func() { client.Disconnect(0) })
+
+	// the callback runs in the goroutine of the client, errors are checked by receive
+	token = client.Subscribe(filter, 0, func(_ mqtt.Client, m mqtt.Message) {
+		msg := &publisher.Message{}
+		err := json.Unmarshal(m.Payload(), msg)
+		ret <- &received{topic: m.Topic(), retained: m.Retained(), msg: msg, err: err}
+	})
+	require.True(t, token.WaitTimeout(5*time.Second))
+	require.NoError(t, token.Error())
+	return ret
+}
+
+func receive(t *testing.T, ch chan *received) *received {
+	select {
+	case r := <-ch:
+		require.NoError(t, r.err)
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not received")
+		return nil
+	}
+}
+
+func TestPublish(t *testing.T) {
+	broker, err := startTestBroker()
+	require.NoError(t, err)
+	defer broker.close()
+
+	p, err := New(Config{BrokerURL: broker.url(), QoS: 1, RetainState: true}, testutil.NewLogger(t))
+	require.NoError(t, err)
+	defer p.Close()
+
+	chainID := coretypes.ChainID{1, 2, 3}
+	game := coretypes.Hn("game")
+	txid := valuetransaction.ID{4, 5, 6}
+	hash := hashing.HashStrings("state")
+	require.NoError(t, p.Publish(publisher.NewStateMessage(&chainID, 3, 1, &txid, &hash, 100)))
+	require.NoError(t, p.Publish(publisher.NewStateMessage(&chainID, 4, 1, &txid, &hash, 200)))
+	// not retained
+	require.NoError(t, p.Publish(publisher.NewVMMessage(&chainID, game, nil, "moved")))
+
+	// the new subscriber receives the latest state only
+	all := subscribe(t, broker.url(), "all", "wasp/"+chainID.String()+"/#")
+	r := receive(t, all)
+	require.True(t, r.retained)
+	require.EqualValues(t, "wasp/"+chainID.String()+"/state", r.topic)
+	require.EqualValues(t, 4, *r.msg.BlockIndex)
+
+	contract := subscribe(t, broker.url(), "contract", "wasp/+/"+game.String()+"/vmmsg")
+	require.NoError(t, p.Publish(publisher.NewVMMessage(&chainID, game, nil, "moved again")))
+	for _, ch := range []chan *received{all, contract} {
+		r = receive(t, ch)
+		require.False(t, r.retained)
+		require.EqualValues(t, publisher.MsgVM, r.msg.Type)
+		require.EqualValues(t, "moved again", r.msg.Payload[publisher.PayloadMessage])
+	}
+}
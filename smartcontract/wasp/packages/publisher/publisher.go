@@ -4,6 +4,7 @@ import (
 	"github.com/iotaledger/hive.go/events"
 )
 
+// Event is triggered with the type of the message and its parts in the legacy (space separated) form
 var Event = events.NewEvent(func(handler interface{}, params ...interface{}) {
 	callback := handler.(func(msgType string, parts []string))
 	msgType := params[0].(string)
@@ -11,6 +12,19 @@ var Event = events.NewEvent(func(handler interface{}, params ...interface{}) {
 	callback(msgType, parts)
 })
 
+// MessageEvent is triggered with the typed message for each message published with PublishMessage
+var MessageEvent = events.NewEvent(func(handler interface{}, params ...interface{}) {
+	handler.(func(msg *Message))(params[0].(*Message))
+})
+
+// Publish publishes the untyped message. It is only delivered to handlers of Event
 func Publish(msgType string, parts ...string) {
 	Event.Trigger(msgType, parts)
 }
+
+// PublishMessage publishes the typed message both to handlers of MessageEvent and,
+// in the legacy form, to handlers of Event
+func PublishMessage(msg *Message) {
+	Event.Trigger(msg.Type, msg.legacy)
+	MessageEvent.Trigger(msg)
+}
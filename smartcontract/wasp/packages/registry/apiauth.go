@@ -0,0 +1,262 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/util/auth"
+	"github.com/mr-tron/base58"
+)
+
+// implements auth.CredentialStore interface
+
+const (
+	apiTokenIDLength     = 8
+	apiTokenSecretLength = 32
+)
+
+// APIToken is the record of the web API token. The token itself is '<ID>.<secret>', only the hash of
+// the secret is stored in the registry
+type APIToken struct {
+	ID          string
+	Role        auth.Role
+	Description string
+	SecretHash  hashing.HashValue
+}
+
+// APIKey is the record of the ed25519 key which may sign requests to the web API
+type APIKey struct {
+	Address     address.Address
+	Role        auth.Role
+	Description string
+}
+
+func dbKeyForAPIToken(id string) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeAPIToken, []byte(id))
+}
+
+func dbKeyForAPIKey(addr *address.Address) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeAPIKey, addr[:])
+}
+
+// NewAPIToken creates and stores new API token. The returned token is not stored and can't be recovered
+func (r *Impl) NewAPIToken(role auth.Role, description string) (*APIToken, string, error) {
+	idBytes := make([]byte, apiTokenIDLength)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, "", err
+	}
+	secret := make([]byte, apiTokenSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	rec := &APIToken{
+		ID:          base58.Encode(idBytes),
+		Role:        role,
+		Description: description,
+		SecretHash:  hashing.HashData(secret),
+	}
+	var buf bytes.Buffer
+	if err := rec.Write(&buf); err != nil {
+		return nil, "", err
+	}
+	if err := r.dbProvider.GetRegistryPartition().Set(dbKeyForAPIToken(rec.ID), buf.Bytes()); err != nil {
+		return nil, "", err
+	}
+	r.log.Infof("API token %s with role '%s' has been created", rec.ID, role)
+	return rec, rec.ID + "." + base58.Encode(secret), nil
+}
+
+func (r *Impl) GetAPIToken(id string) (*APIToken, error) {
+	data, err := r.dbProvider.GetRegistryPartition().Get(dbKeyForAPIToken(id))
+	if err == kvstore.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ret := new(APIToken)
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *Impl) GetAPITokens() ([]*APIToken, error) {
+	ret := make([]*APIToken, 0)
+	err := r.dbProvider.GetRegistryPartition().Iterate([]byte{dbprovider.ObjectTypeAPIToken}, func(key kvstore.Key, value kvstore.Value) bool {
+		rec := new(APIToken)
+		if err := rec.Read(bytes.NewReader(value)); err == nil {
+			ret = append(ret, rec)
+		} else {
+			r.log.Warnf("corrupted API token record with key %s", base58.Encode(key))
+		}
+		return true
+	})
+	return ret, err
+}
+
+// DeleteAPIToken revokes the token. Returns false if the token does not exist
+func (r *Impl) DeleteAPIToken(id string) (bool, error) {
+	partition := r.dbProvider.GetRegistryPartition()
+	exists, err := partition.Has(dbKeyForAPIToken(id))
+	if err != nil || !exists {
+		return false, err
+	}
+	if err := partition.Delete(dbKeyForAPIToken(id)); err != nil {
+		return false, err
+	}
+	r.log.Infof("API token %s has been revoked", id)
+	return true, nil
+}
+
+// TokenRole implements auth.CredentialStore
+func (r *Impl) TokenRole(token string) (auth.Role, bool, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return 0, false, nil
+	}
+	secret, err := base58.Decode(parts[1])
+	if err != nil {
+		return 0, false, nil
+	}
+	rec, err := r.GetAPIToken(parts[0])
+	if err != nil || rec == nil {
+		return 0, false, err
+	}
+	h := hashing.HashData(secret)
+	if subtle.ConstantTimeCompare(h[:], rec.SecretHash[:]) != 1 {
+		return 0, false, nil
+	}
+	return rec.Role, true, nil
+}
+
+// SaveAPIKey registers the key or updates its record
+func (r *Impl) SaveAPIKey(rec *APIKey) error {
+	if rec.Address.Version() != address.VersionED25519 {
+		return fmt.Errorf("%s is not an ed25519 address", rec.Address.String())
+	}
+	var buf bytes.Buffer
+	if err := rec.Write(&buf); err != nil {
+		return err
+	}
+	if err := r.dbProvider.GetRegistryPartition().Set(dbKeyForAPIKey(&rec.Address), buf.Bytes()); err != nil {
+		return err
+	}
+	r.log.Infof("API key of %s with role '%s' has been saved", rec.Address.String(), rec.Role)
+	return nil
+}
+
+func (r *Impl) GetAPIKey(addr *address.Address) (*APIKey, error) {
+	data, err := r.dbProvider.GetRegistryPartition().Get(dbKeyForAPIKey(addr))
+	if err == kvstore.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ret := new(APIKey)
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *Impl) GetAPIKeys() ([]*APIKey, error) {
+	ret := make([]*APIKey, 0)
+	err := r.dbProvider.GetRegistryPartition().Iterate([]byte{dbprovider.ObjectTypeAPIKey}, func(key kvstore.Key, value kvstore.Value) bool {
+		rec := new(APIKey)
+		if err := rec.Read(bytes.NewReader(value)); err == nil {
+			ret = append(ret, rec)
+		} else {
+			r.log.Warnf("corrupted API key record with key %s", base58.Encode(key))
+		}
+		return true
+	})
+	return ret, err
+}
+
+// DeleteAPIKey revokes the key. Returns false if the key is not registered
+func (r *Impl) DeleteAPIKey(addr *address.Address) (bool, error) {
+	partition := r.dbProvider.GetRegistryPartition()
+	exists, err := partition.Has(dbKeyForAPIKey(addr))
+	if err != nil || !exists {
+		return false, err
+	}
+	if err := partition.Delete(dbKeyForAPIKey(addr)); err != nil {
+		return false, err
+	}
+	r.log.Infof("API key of %s has been revoked", addr.String())
+	return true, nil
+}
+
+// AddressRole implements auth.CredentialStore
+func (r *Impl) AddressRole(addr *address.Address) (auth.Role, bool, error) {
+	rec, err := r.GetAPIKey(addr)
+	if err != nil || rec == nil {
+		return 0, false, err
+	}
+	return rec.Role, true, nil
+}
+
+func (t *APIToken) Write(w io.Writer) error {
+	if err := util.WriteString16(w, t.ID); err != nil {
+		return err
+	}
+	if err := util.WriteByte(w, byte(t.Role)); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, t.Description); err != nil {
+		return err
+	}
+	_, err := w.Write(t.SecretHash[:])
+	return err
+}
+
+func (t *APIToken) Read(r io.Reader) error {
+	var err error
+	if t.ID, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	role, err := util.ReadByte(r)
+	if err != nil {
+		return err
+	}
+	t.Role = auth.Role(role)
+	if t.Description, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	return util.ReadHashValue(r, &t.SecretHash)
+}
+
+func (k *APIKey) Write(w io.Writer) error {
+	if _, err := w.Write(k.Address[:]); err != nil {
+		return err
+	}
+	if err := util.WriteByte(w, byte(k.Role)); err != nil {
+		return err
+	}
+	return util.WriteString16(w, k.Description)
+}
+
+func (k *APIKey) Read(r io.Reader) error {
+	var err error
+	if _, err = io.ReadFull(r, k.Address[:]); err != nil {
+		return err
+	}
+	role, err := util.ReadByte(r)
+	if err != nil {
+		return err
+	}
+	k.Role = auth.Role(role)
+	k.Description, err = util.ReadString16(r)
+	return err
+}
@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/util/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIToken(t *testing.T) {
+	log := testutil.NewLogger(t)
+	reg := NewRegistry(nil, log, dbprovider.NewInMemoryDBProvider(log))
+
+	rec, token, err := reg.NewAPIToken(auth.RoleClient, "test")
+	require.NoError(t, err)
+
+	role, ok, err := reg.TokenRole(token)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.EqualValues(t, auth.RoleClient, role)
+
+	_, ok, err = reg.TokenRole(rec.ID + ".wrongsecret")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	tokens, err := reg.GetAPITokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.EqualValues(t, *rec, *tokens[0])
+
+	ok, err = reg.DeleteAPIToken(rec.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, ok, err = reg.TokenRole(token)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestAPIKey(t *testing.T) {
+	log := testutil.NewLogger(t)
+	reg := NewRegistry(nil, log, dbprovider.NewInMemoryDBProvider(log))
+
+	addr := signaturescheme.ED25519(ed25519.GenerateKeyPair()).Address()
+	_, ok, err := reg.AddressRole(&addr)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	err = reg.SaveAPIKey(&APIKey{Address: addr, Role: auth.RoleAdmin, Description: "test"})
+	require.NoError(t, err)
+
+	role, ok, err := reg.AddressRole(&addr)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.EqualValues(t, auth.RoleAdmin, role)
+
+	keys, err := reg.GetAPIKeys()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.EqualValues(t, addr, keys[0].Address)
+
+	ok, err = reg.DeleteAPIKey(&addr)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, ok, err = reg.AddressRole(&addr)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	blsAddr := signaturescheme.RandBLS().Address()
+	err = reg.SaveAPIKey(&APIKey{Address: blsAddr, Role: auth.RoleAdmin})
+	require.Error(t, err)
+}
@@ -42,7 +42,7 @@ func SaveChainRecord(bd *ChainRecord) error {
 	if err := database.GetRegistryPartition().Set(dbkeyChainRecord(&bd.ChainID), buf.Bytes()); err != nil {
 		return err
 	}
-	publisher.Publish("chainrec", bd.ChainID.String(), bd.Color.String())
+	publisher.PublishMessage(publisher.NewChainRecordMessage(&bd.ChainID, &bd.Color))
 	return nil
 }
 
@@ -46,6 +46,19 @@ func (r *Impl) GetNodeIdentity() (*key.Pair, error) {
 	return pair, nil
 }
 
+// SaveNodeIdentity replaces the key pair of the node, e.g. after the key rotation.
+func (r *Impl) SaveNodeIdentity(pair *key.Pair) error {
+	data, err := keyPairToBytes(pair)
+	if err != nil {
+		return err
+	}
+	if err = r.dbProvider.GetRegistryPartition().Set(dbKeyForNodeIdentity(), data); err != nil {
+		return err
+	}
+	r.log.Info("Node identity key pair saved.")
+	return nil
+}
+
 // GetNodePublicKey implements NodeIdentityProvider.
 func (r *Impl) GetNodePublicKey() (kyber.Point, error) {
 	var err error
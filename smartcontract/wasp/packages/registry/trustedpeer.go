@@ -0,0 +1,89 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"bytes"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/mr-tron/base58"
+)
+
+// implements tcp.RegistryProvider interface
+
+func dbKeyForTrustedPeer(netID string) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeTrustedPeer, []byte(netID))
+}
+
+// SaveTrustedPeer stores the record of the committee peer or updates it
+func (r *Impl) SaveTrustedPeer(tp *peering.TrustedPeer) error {
+	data, err := r.trustedPeerToBytes(tp)
+	if err != nil {
+		return err
+	}
+	if err := r.dbProvider.GetRegistryPartition().Set(dbKeyForTrustedPeer(tp.NetID), data); err != nil {
+		return err
+	}
+	r.log.Infof("trusted key of peer %s has been saved", tp.NetID)
+	return nil
+}
+
+// GetTrustedPeer returns the record of the peer or nil if the peer is not trusted
+func (r *Impl) GetTrustedPeer(netID string) (*peering.TrustedPeer, error) {
+	data, err := r.dbProvider.GetRegistryPartition().Get(dbKeyForTrustedPeer(netID))
+	if err == kvstore.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.trustedPeerFromBytes(data)
+}
+
+// GetTrustedPeers returns records of all trusted peers
+func (r *Impl) GetTrustedPeers() ([]*peering.TrustedPeer, error) {
+	ret := make([]*peering.TrustedPeer, 0)
+	err := r.dbProvider.GetRegistryPartition().Iterate([]byte{dbprovider.ObjectTypeTrustedPeer}, func(key kvstore.Key, value kvstore.Value) bool {
+		if tp, err := r.trustedPeerFromBytes(value); err == nil {
+			ret = append(ret, tp)
+		} else {
+			r.log.Warnf("corrupted trusted peer record with key %s", base58.Encode(key))
+		}
+		return true
+	})
+	return ret, err
+}
+
+func (r *Impl) trustedPeerToBytes(tp *peering.TrustedPeer) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := util.WriteString16(&buf, tp.NetID); err != nil {
+		return nil, err
+	}
+	if err := util.WriteMarshaled(&buf, tp.PubKey); err != nil {
+		return nil, err
+	}
+	if err := util.WriteBoolByte(&buf, tp.KeyRotation); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *Impl) trustedPeerFromBytes(data []byte) (*peering.TrustedPeer, error) {
+	var err error
+	rd := bytes.NewReader(data)
+	tp := &peering.TrustedPeer{PubKey: r.suite.Point()}
+	if tp.NetID, err = util.ReadString16(rd); err != nil {
+		return nil, err
+	}
+	if err = util.ReadMarshaled(rd, tp.PubKey); err != nil {
+		return nil, err
+	}
+	if err = util.ReadBoolByte(rd, &tp.KeyRotation); err != nil {
+		return nil, err
+	}
+	return tp, nil
+}
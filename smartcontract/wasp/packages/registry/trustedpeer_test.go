@@ -0,0 +1,40 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+func TestTrustedPeer(t *testing.T) {
+	log := testutil.NewLogger(t)
+	suite := pairing.NewSuiteBn256()
+	reg := NewRegistry(suite, log, dbprovider.NewInMemoryDBProvider(log))
+
+	tp, err := reg.GetTrustedPeer("localhost:4000")
+	require.NoError(t, err)
+	require.Nil(t, tp)
+
+	pubKey := key.NewKeyPair(suite).Public
+	require.NoError(t, reg.SaveTrustedPeer(&peering.TrustedPeer{NetID: "localhost:4000", PubKey: pubKey, KeyRotation: true}))
+	tp, err = reg.GetTrustedPeer("localhost:4000")
+	require.NoError(t, err)
+	require.EqualValues(t, "localhost:4000", tp.NetID)
+	require.True(t, tp.PubKey.Equal(pubKey))
+	require.True(t, tp.KeyRotation)
+
+	tps, err := reg.GetTrustedPeers()
+	require.NoError(t, err)
+	require.Len(t, tps, 1)
+
+	pair := key.NewKeyPair(suite)
+	require.NoError(t, reg.SaveNodeIdentity(pair))
+	identity, err := reg.GetNodeIdentity()
+	require.NoError(t, err)
+	require.True(t, identity.Public.Equal(pair.Public))
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+)
+
+// Backend is the chain on which the generated client calls the contract
+type Backend interface {
+	// PostRequest posts the request to the full entry point of the contract and waits until it is processed.
+	// Returns the result of the call or nil if the backend can't provide it
+	PostRequest(ctx context.Context, contract string, function string, params dict.Dict, transfer map[balance.Color]int64) (dict.Dict, error)
+	// CallView calls the view entry point of the contract
+	CallView(ctx context.Context, contract string, function string, params dict.Dict) (dict.Dict, error)
+}
+
+// DecodeResult decodes the value of the key in the result of the call into the variable pointed to by 'v'.
+// Returns false if the key is not in the result. It is used by the generated clients
+func DecodeResult(res dict.Dict, key string, v interface{}) (bool, error) {
+	data, err := res.Get(kv.Key(key))
+	if err != nil {
+		return false, err
+	}
+	if data == nil {
+		return false, nil
+	}
+	var exists bool
+	switch vt := v.(type) {
+	case *int64:
+		*vt, exists, err = codec.DecodeInt64(data)
+	case *string:
+		*vt, exists, err = codec.DecodeString(data)
+	case *[]byte:
+		*vt, exists = data, true
+	case *hashing.HashValue:
+		var h *hashing.HashValue
+		if h, exists, err = codec.DecodeHashValue(data); err == nil && exists {
+			*vt = *h
+		}
+	case *coretypes.Hname:
+		*vt, exists, err = codec.DecodeHname(data)
+	case *coretypes.AgentID:
+		*vt, exists, err = codec.DecodeAgentID(data)
+	case *address.Address:
+		*vt, exists, err = codec.DecodeAddress(data)
+	case *balance.Color:
+		*vt, exists, err = codec.DecodeColor(data)
+	case *coretypes.ChainID:
+		*vt, exists, err = codec.DecodeChainID(data)
+	case *coretypes.ContractID:
+		*vt, exists, err = codec.DecodeContractID(data)
+	default:
+		return false, fmt.Errorf("can't decode result '%s' into %T", key, v)
+	}
+	if err != nil {
+		return false, fmt.Errorf("can't decode result '%s': %v", key, err)
+	}
+	return exists, nil
+}
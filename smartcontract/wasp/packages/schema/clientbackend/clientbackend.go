@@ -0,0 +1,66 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clientbackend makes the generated contract clients call the contract on the chain
+// run by Wasp nodes, through the chainclient.Client
+package clientbackend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+)
+
+// Backend posts requests and calls views through the chain client
+type Backend struct {
+	client *chainclient.Client
+}
+
+// New creates the backend
+func New(client *chainclient.Client) *Backend {
+	return &Backend{client: client}
+}
+
+// PostRequest posts the request transaction and waits until the request is processed by the node.
+// The deadline of the context, if set, limits the waiting time. The result of the call and the error
+// of the VM are taken from the receipt of the request. The result is nil if it is too large to be kept in the receipt
+func (b *Backend) PostRequest(ctx context.Context, contract string, function string, params dict.Dict, transfer map[balance.Color]int64) (dict.Dict, error) {
+	tx, err := b.client.PostRequest(coretypes.Hn(contract), coretypes.Hn(function), chainclient.PostRequestParams{
+		Transfer: cbalances.NewFromMap(transfer),
+		Args:     requestargs.New(nil).AddEncodeSimpleMany(params),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var timeout time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if err = b.client.WaspClient.WaitUntilAllRequestsProcessed(tx, timeout); err != nil {
+		return nil, err
+	}
+	reqID := coretypes.NewRequestID(tx.ID(), 0)
+	status, err := b.client.WaspClient.RequestStatus(&b.client.ChainID, &reqID)
+	if err != nil {
+		return nil, err
+	}
+	if !status.HasReceipt {
+		return nil, fmt.Errorf("receipt of request %s is not available", reqID.String())
+	}
+	if err = status.Err(); err != nil {
+		return nil, err
+	}
+	return status.Result, nil
+}
+
+// CallView calls the view through the webapi of the node
+func (b *Backend) CallView(_ context.Context, contract string, function string, params dict.Dict) (dict.Dict, error) {
+	return b.client.CallView(coretypes.Hn(contract), function, params)
+}
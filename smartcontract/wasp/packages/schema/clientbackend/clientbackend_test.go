@@ -0,0 +1,94 @@
+package clientbackend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/utxodb"
+	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/wasp/client"
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/stretchr/testify/require"
+)
+
+// level1 is the ledger of the test, the transactions are confirmed immediately
+type level1 struct {
+	utxodb *utxodb.UtxoDB
+}
+
+func (l *level1) RequestFunds(targetAddress *address.Address) error {
+	_, err := l.utxodb.RequestFunds(*targetAddress)
+	return err
+}
+
+func (l *level1) GetConfirmedAccountOutputs(address *address.Address) (map[transaction.OutputID][]*balance.Balance, error) {
+	return l.utxodb.GetAddressOutputs(*address), nil
+}
+
+func (l *level1) PostTransaction(tx *transaction.Transaction) error {
+	return l.utxodb.AddTransaction(tx)
+}
+
+func (l *level1) PostAndWaitForConfirmation(tx *transaction.Transaction) error {
+	return l.utxodb.AddTransaction(tx)
+}
+
+func (l *level1) WaitForConfirmation(transaction.ID) error {
+	return nil
+}
+
+// newBackend creates the backend with the node which answers the status of every request with 'status'
+func newBackend(t *testing.T, status *model.RequestStatusResponse) *Backend {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.True(t, strings.HasSuffix(r.URL.Path, "/wait") || strings.HasSuffix(r.URL.Path, "/status"))
+		require.NoError(t, json.NewEncoder(w).Encode(status))
+	}))
+	t.Cleanup(node.Close)
+
+	ledger := &level1{utxodb: utxodb.New()}
+	sigScheme := signaturescheme.ED25519(ed25519.GenerateKeyPair())
+	addr := sigScheme.Address()
+	require.NoError(t, ledger.RequestFunds(&addr))
+	chainID := coretypes.ChainID(signaturescheme.ED25519(ed25519.GenerateKeyPair()).Address())
+	return New(chainclient.New(ledger, client.NewWaspClient(node.URL), chainID, sigScheme))
+}
+
+func TestPostRequestResult(t *testing.T) {
+	b := newBackend(t, &model.RequestStatusResponse{
+		IsProcessed: true,
+		HasReceipt:  true,
+		Result:      dict.Dict{"counter": codec.EncodeInt64(42)},
+	})
+	res, err := b.PostRequest(context.Background(), "inccounter", "increment", nil, nil)
+	require.NoError(t, err)
+	counter, _, err := codec.DecodeInt64(res.MustGet("counter"))
+	require.NoError(t, err)
+	require.EqualValues(t, 42, counter)
+}
+
+func TestPostRequestError(t *testing.T) {
+	b := newBackend(t, &model.RequestStatusResponse{
+		IsProcessed: true,
+		HasReceipt:  true,
+		Error:       "panic in VM: not authorized",
+	})
+	_, err := b.PostRequest(context.Background(), "inccounter", "increment", nil, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not authorized")
+
+	b = newBackend(t, &model.RequestStatusResponse{IsProcessed: true})
+	_, err = b.PostRequest(context.Background(), "inccounter", "increment", nil, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,297 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+type goType struct {
+	name       string
+	importPath string
+}
+
+// goTypes maps types of the schema to Go types of the generated code
+var goTypes = map[string]goType{
+	"address":    {"address.Address", "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"},
+	"agentid":    {"coretypes.AgentID", "github.com/iotaledger/wasp/packages/coretypes"},
+	"bytes":      {"[]byte", ""},
+	"chainid":    {"coretypes.ChainID", "github.com/iotaledger/wasp/packages/coretypes"},
+	"color":      {"balance.Color", "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"},
+	"contractid": {"coretypes.ContractID", "github.com/iotaledger/wasp/packages/coretypes"},
+	"hash":       {"hashing.HashValue", "github.com/iotaledger/wasp/packages/hashing"},
+	"hname":      {"coretypes.Hname", "github.com/iotaledger/wasp/packages/coretypes"},
+	"int64":      {"int64", ""},
+	"string":     {"string", ""},
+}
+
+// Generate generates the source of the Go package with the typed client of the contract.
+// The client has a method for each entry point, with structures for parameters and results
+func Generate(s *Schema, pkgName string) ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	data := &genContract{
+		Schema:  s,
+		Package: pkgName,
+		Funcs:   genFuncs(s.Funcs, "Func"),
+		Views:   genFuncs(s.Views, "View"),
+	}
+	data.Imports = genImports(data)
+
+	var buf bytes.Buffer
+	if err := clientTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	ret, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated code is not valid: %v", err)
+	}
+	return ret, nil
+}
+
+type genContract struct {
+	*Schema
+	Package string
+	Imports []string
+	Funcs   []*genFunc
+	Views   []*genFunc
+}
+
+type genFunc struct {
+	*FuncDef
+	GoName  string
+	Const   string
+	Params  []*genField
+	Results []*genField
+}
+
+type genField struct {
+	*FieldDef
+	GoName string
+	GoType string
+}
+
+func genFuncs(funcs []*FuncDef, prefix string) []*genFunc {
+	ret := make([]*genFunc, len(funcs))
+	for i, f := range funcs {
+		goName := exportedName(f.Name)
+		ret[i] = &genFunc{
+			FuncDef: f,
+			GoName:  goName,
+			Const:   prefix + goName,
+			Params:  genFields(f.Params),
+			Results: genFields(f.Results),
+		}
+	}
+	return ret
+}
+
+func genFields(fields []*FieldDef) []*genField {
+	ret := make([]*genField, len(fields))
+	for i, fld := range fields {
+		ret[i] = &genField{
+			FieldDef: fld,
+			GoName:   exportedName(fld.Name),
+			GoType:   goTypes[fld.Type].name,
+		}
+	}
+	return ret
+}
+
+func genImports(data *genContract) []string {
+	imports := map[string]bool{
+		"context": true,
+		"github.com/iotaledger/wasp/packages/kv/dict": true,
+		"github.com/iotaledger/wasp/packages/schema":  true,
+	}
+	if len(data.Funcs) > 0 {
+		imports["github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"] = true
+	}
+	for _, f := range append(append([]*genFunc{}, data.Funcs...), data.Views...) {
+		if len(f.Params) > 0 {
+			imports["github.com/iotaledger/wasp/packages/kv/codec"] = true
+		}
+		for _, fld := range append(append([]*genField{}, f.Params...), f.Results...) {
+			if path := goTypes[fld.Type].importPath; path != "" {
+				imports[path] = true
+			}
+		}
+		for _, fld := range f.Results {
+			if !fld.Optional {
+				imports["fmt"] = true
+			}
+		}
+	}
+	ret := make([]string, 0, len(imports))
+	for path := range imports {
+		ret = append(ret, path)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// exportedName converts names like 'create_game' or 'createGame' to 'CreateGame'
+func exportedName(name string) string {
+	var buf strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		buf.WriteRune(r)
+	}
+	ret := buf.String()
+	if ret == "" || unicode.IsDigit(rune(ret[0])) {
+		ret = "X" + ret
+	}
+	return ret
+}
+
+var clientTemplate = template.Must(template.New("client").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`// Code generated from the schema of the '{{.Name}}' contract. DO NOT EDIT.
+
+// Package {{.Package}} is the typed client of the '{{.Name}}' contract{{if .Description}}: {{.Description}}{{end}}
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{quote .}}
+{{- end}}
+)
+
+// ScName is the name of the contract
+const ScName = {{quote .Name}}
+
+{{if or .Funcs .Views}}
+const (
+{{- range .Funcs}}
+	{{.Const}} = {{quote .Name}}
+{{- end}}
+{{- range .Views}}
+	{{.Const}} = {{quote .Name}}
+{{- end}}
+)
+{{end}}
+
+// Client calls the entry points of the contract through the backend
+type Client struct {
+	backend schema.Backend
+	scName  string
+}
+
+// New creates the client of the contract deployed on the chain with the name ScName
+func New(backend schema.Backend) *Client {
+	return NewWithName(backend, ScName)
+}
+
+// NewWithName creates the client of the contract deployed on the chain with another name
+func NewWithName(backend schema.Backend, scName string) *Client {
+	return &Client{backend: backend, scName: scName}
+}
+
+{{define "types"}}
+{{- if .Params}}
+// {{.GoName}}Params are the parameters of '{{.Name}}'
+type {{.GoName}}Params struct {
+{{- range .Params}}
+	{{.GoName}} {{if .Optional}}*{{end}}{{.GoType}}
+{{- end}}
+}
+
+func (p *{{.GoName}}Params) encode() dict.Dict {
+	ret := dict.New()
+{{- range .Params}}
+{{- if .Optional}}
+	if p.{{.GoName}} != nil {
+		ret.Set({{quote .KeyOrName}}, codec.Encode(*p.{{.GoName}}))
+	}
+{{- else}}
+	ret.Set({{quote .KeyOrName}}, codec.Encode(p.{{.GoName}}))
+{{- end}}
+{{- end}}
+	return ret
+}
+{{end}}
+{{- if .Results}}
+// {{.GoName}}Results are the results of '{{.Name}}'
+type {{.GoName}}Results struct {
+{{- range .Results}}
+	{{.GoName}} {{if .Optional}}*{{end}}{{.GoType}}
+{{- end}}
+}
+
+func decode{{.GoName}}Results(res dict.Dict) (*{{.GoName}}Results, error) {
+	ret := &{{.GoName}}Results{}
+	if res == nil {
+		return ret, nil
+	}
+{{- range .Results}}
+{{- if .Optional}}
+	{
+		var v {{.GoType}}
+		exists, err := schema.DecodeResult(res, {{quote .KeyOrName}}, &v)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			ret.{{.GoName}} = &v
+		}
+	}
+{{- else}}
+	if exists, err := schema.DecodeResult(res, {{quote .KeyOrName}}, &ret.{{.GoName}}); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", {{quote .KeyOrName}})
+	}
+{{- end}}
+{{- end}}
+	return ret, nil
+}
+{{end}}
+{{- end}}
+
+{{- range .Funcs}}
+{{template "types" .}}
+// {{.GoName}} posts the request to the entry point '{{.Name}}' and waits until it is processed.
+// The first of optional transfers is attached to the request.
+{{- if .Results}} The results are empty if the backend does not provide them{{end}}
+func (c *Client) {{.GoName}}(ctx context.Context{{if .Params}}, params {{.GoName}}Params{{end}}, transfer ...map[balance.Color]int64) {{if .Results}}(*{{.GoName}}Results, error){{else}}error{{end}} {
+	var tr map[balance.Color]int64
+	if len(transfer) > 0 {
+		tr = transfer[0]
+	}
+	{{if .Results}}res{{else}}_{{end}}, err := c.backend.PostRequest(ctx, c.scName, {{.Const}}, {{if .Params}}params.encode(){{else}}dict.New(){{end}}, tr)
+	if err != nil {
+		return {{if .Results}}nil, {{end}}err
+	}
+	return {{if .Results}}decode{{.GoName}}Results(res){{else}}nil{{end}}
+}
+{{end}}
+
+{{- range .Views}}
+{{template "types" .}}
+// {{.GoName}} calls the view '{{.Name}}'
+func (c *Client) {{.GoName}}(ctx context.Context{{if .Params}}, params {{.GoName}}Params{{end}}) {{if .Results}}(*{{.GoName}}Results, error){{else}}error{{end}} {
+	{{if .Results}}res{{else}}_{{end}}, err := c.backend.CallView(ctx, c.scName, {{.Const}}, {{if .Params}}params.encode(){{else}}dict.New(){{end}})
+	if err != nil {
+		return {{if .Results}}nil, {{end}}err
+	}
+	return {{if .Results}}decode{{.GoName}}Results(res){{else}}nil{{end}}
+}
+{{end}}
+`))
@@ -0,0 +1,8 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package rootclient
+
+// the schema is seeded with 'schema init root', deployContract is left out because of its dynamic init parameters.
+// The result of getUpgradeHistory is an Array32 of records, which the schema can't describe, so it is not decoded
+//go:generate go run ../../../tools/schema gen root.json rootclient rootclient.go
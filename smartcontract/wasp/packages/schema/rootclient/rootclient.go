@@ -0,0 +1,410 @@
+// Code generated from the schema of the 'root' contract. DO NOT EDIT.
+
+// Package rootclient is the typed client of the 'root' contract: Root Contract
+package rootclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/schema"
+)
+
+// ScName is the name of the contract
+const ScName = "root"
+
+const (
+	FuncClaimChainOwnership    = "claimChainOwnership"
+	FuncDelegateChainOwnership = "delegateChainOwnership"
+	FuncGrantDeployPermission  = "grantDeployPermission"
+	FuncRevokeDeployPermission = "revokeDeployPermission"
+	FuncSetContractFee         = "setContractFee"
+	FuncSetDefaultFee          = "setDefaultFee"
+	FuncUpgradeContract        = "upgradeContract"
+	ViewFindContract           = "findContract"
+	ViewGetChainInfo           = "getChainInfo"
+	ViewGetFeeInfo             = "getFeeInfo"
+	ViewGetUpgradeHistory      = "getUpgradeHistory"
+)
+
+// Client calls the entry points of the contract through the backend
+type Client struct {
+	backend schema.Backend
+	scName  string
+}
+
+// New creates the client of the contract deployed on the chain with the name ScName
+func New(backend schema.Backend) *Client {
+	return NewWithName(backend, ScName)
+}
+
+// NewWithName creates the client of the contract deployed on the chain with another name
+func NewWithName(backend schema.Backend, scName string) *Client {
+	return &Client{backend: backend, scName: scName}
+}
+
+// ClaimChainOwnership posts the request to the entry point 'claimChainOwnership' and waits until it is processed.
+// The first of optional transfers is attached to the request.
+func (c *Client) ClaimChainOwnership(ctx context.Context, transfer ...map[balance.Color]int64) error {
+	var tr map[balance.Color]int64
+	if len(transfer) > 0 {
+		tr = transfer[0]
+	}
+	_, err := c.backend.PostRequest(ctx, c.scName, FuncClaimChainOwnership, dict.New(), tr)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DelegateChainOwnershipParams are the parameters of 'delegateChainOwnership'
+type DelegateChainOwnershipParams struct {
+	ChainOwner coretypes.AgentID
+}
+
+func (p *DelegateChainOwnershipParams) encode() dict.Dict {
+	ret := dict.New()
+	ret.Set("$$owner$$", codec.Encode(p.ChainOwner))
+	return ret
+}
+
+// DelegateChainOwnership posts the request to the entry point 'delegateChainOwnership' and waits until it is processed.
+// The first of optional transfers is attached to the request.
+func (c *Client) DelegateChainOwnership(ctx context.Context, params DelegateChainOwnershipParams, transfer ...map[balance.Color]int64) error {
+	var tr map[balance.Color]int64
+	if len(transfer) > 0 {
+		tr = transfer[0]
+	}
+	_, err := c.backend.PostRequest(ctx, c.scName, FuncDelegateChainOwnership, params.encode(), tr)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GrantDeployPermissionParams are the parameters of 'grantDeployPermission'
+type GrantDeployPermissionParams struct {
+	Deployer coretypes.AgentID
+}
+
+func (p *GrantDeployPermissionParams) encode() dict.Dict {
+	ret := dict.New()
+	ret.Set("$$deployer$$", codec.Encode(p.Deployer))
+	return ret
+}
+
+// GrantDeployPermission posts the request to the entry point 'grantDeployPermission' and waits until it is processed.
+// The first of optional transfers is attached to the request.
+func (c *Client) GrantDeployPermission(ctx context.Context, params GrantDeployPermissionParams, transfer ...map[balance.Color]int64) error {
+	var tr map[balance.Color]int64
+	if len(transfer) > 0 {
+		tr = transfer[0]
+	}
+	_, err := c.backend.PostRequest(ctx, c.scName, FuncGrantDeployPermission, params.encode(), tr)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RevokeDeployPermissionParams are the parameters of 'revokeDeployPermission'
+type RevokeDeployPermissionParams struct {
+	Deployer coretypes.AgentID
+}
+
+func (p *RevokeDeployPermissionParams) encode() dict.Dict {
+	ret := dict.New()
+	ret.Set("$$deployer$$", codec.Encode(p.Deployer))
+	return ret
+}
+
+// RevokeDeployPermission posts the request to the entry point 'revokeDeployPermission' and waits until it is processed.
+// The first of optional transfers is attached to the request.
+func (c *Client) RevokeDeployPermission(ctx context.Context, params RevokeDeployPermissionParams, transfer ...map[balance.Color]int64) error {
+	var tr map[balance.Color]int64
+	if len(transfer) > 0 {
+		tr = transfer[0]
+	}
+	_, err := c.backend.PostRequest(ctx, c.scName, FuncRevokeDeployPermission, params.encode(), tr)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetContractFeeParams are the parameters of 'setContractFee'
+type SetContractFeeParams struct {
+	Hname        coretypes.Hname
+	OwnerFee     *int64
+	ValidatorFee *int64
+}
+
+func (p *SetContractFeeParams) encode() dict.Dict {
+	ret := dict.New()
+	ret.Set("$$hname$$", codec.Encode(p.Hname))
+	if p.OwnerFee != nil {
+		ret.Set("$$ownerfee$$", codec.Encode(*p.OwnerFee))
+	}
+	if p.ValidatorFee != nil {
+		ret.Set("$$validatorfee$$", codec.Encode(*p.ValidatorFee))
+	}
+	return ret
+}
+
+// SetContractFee posts the request to the entry point 'setContractFee' and waits until it is processed.
+// The first of optional transfers is attached to the request.
+func (c *Client) SetContractFee(ctx context.Context, params SetContractFeeParams, transfer ...map[balance.Color]int64) error {
+	var tr map[balance.Color]int64
+	if len(transfer) > 0 {
+		tr = transfer[0]
+	}
+	_, err := c.backend.PostRequest(ctx, c.scName, FuncSetContractFee, params.encode(), tr)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetDefaultFeeParams are the parameters of 'setDefaultFee'
+type SetDefaultFeeParams struct {
+	OwnerFee     *int64
+	ValidatorFee *int64
+}
+
+func (p *SetDefaultFeeParams) encode() dict.Dict {
+	ret := dict.New()
+	if p.OwnerFee != nil {
+		ret.Set("$$ownerfee$$", codec.Encode(*p.OwnerFee))
+	}
+	if p.ValidatorFee != nil {
+		ret.Set("$$validatorfee$$", codec.Encode(*p.ValidatorFee))
+	}
+	return ret
+}
+
+// SetDefaultFee posts the request to the entry point 'setDefaultFee' and waits until it is processed.
+// The first of optional transfers is attached to the request.
+func (c *Client) SetDefaultFee(ctx context.Context, params SetDefaultFeeParams, transfer ...map[balance.Color]int64) error {
+	var tr map[balance.Color]int64
+	if len(transfer) > 0 {
+		tr = transfer[0]
+	}
+	_, err := c.backend.PostRequest(ctx, c.scName, FuncSetDefaultFee, params.encode(), tr)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpgradeContractParams are the parameters of 'upgradeContract'
+type UpgradeContractParams struct {
+	Name        string
+	ProgramHash hashing.HashValue
+	Description *string
+}
+
+func (p *UpgradeContractParams) encode() dict.Dict {
+	ret := dict.New()
+	ret.Set("$$name$$", codec.Encode(p.Name))
+	ret.Set("$$proghash$$", codec.Encode(p.ProgramHash))
+	if p.Description != nil {
+		ret.Set("$$description$$", codec.Encode(*p.Description))
+	}
+	return ret
+}
+
+// UpgradeContract posts the request to the entry point 'upgradeContract' and waits until it is processed.
+// The first of optional transfers is attached to the request.
+func (c *Client) UpgradeContract(ctx context.Context, params UpgradeContractParams, transfer ...map[balance.Color]int64) error {
+	var tr map[balance.Color]int64
+	if len(transfer) > 0 {
+		tr = transfer[0]
+	}
+	_, err := c.backend.PostRequest(ctx, c.scName, FuncUpgradeContract, params.encode(), tr)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindContractParams are the parameters of 'findContract'
+type FindContractParams struct {
+	Hname coretypes.Hname
+}
+
+func (p *FindContractParams) encode() dict.Dict {
+	ret := dict.New()
+	ret.Set("$$hname$$", codec.Encode(p.Hname))
+	return ret
+}
+
+// FindContractResults are the results of 'findContract'
+type FindContractResults struct {
+	Data []byte
+}
+
+func decodeFindContractResults(res dict.Dict) (*FindContractResults, error) {
+	ret := &FindContractResults{}
+	if res == nil {
+		return ret, nil
+	}
+	if exists, err := schema.DecodeResult(res, "$$data$$", &ret.Data); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "$$data$$")
+	}
+	return ret, nil
+}
+
+// FindContract calls the view 'findContract'
+func (c *Client) FindContract(ctx context.Context, params FindContractParams) (*FindContractResults, error) {
+	res, err := c.backend.CallView(ctx, c.scName, ViewFindContract, params.encode())
+	if err != nil {
+		return nil, err
+	}
+	return decodeFindContractResults(res)
+}
+
+// GetChainInfoResults are the results of 'getChainInfo'
+type GetChainInfoResults struct {
+	ChainID             coretypes.ChainID
+	ChainOwnerID        coretypes.AgentID
+	ChainColor          balance.Color
+	ChainAddress        address.Address
+	Description         string
+	FeeColor            balance.Color
+	DefaultOwnerFee     int64
+	DefaultValidatorFee int64
+}
+
+func decodeGetChainInfoResults(res dict.Dict) (*GetChainInfoResults, error) {
+	ret := &GetChainInfoResults{}
+	if res == nil {
+		return ret, nil
+	}
+	if exists, err := schema.DecodeResult(res, "c", &ret.ChainID); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "c")
+	}
+	if exists, err := schema.DecodeResult(res, "o", &ret.ChainOwnerID); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "o")
+	}
+	if exists, err := schema.DecodeResult(res, "co", &ret.ChainColor); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "co")
+	}
+	if exists, err := schema.DecodeResult(res, "ad", &ret.ChainAddress); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "ad")
+	}
+	if exists, err := schema.DecodeResult(res, "d", &ret.Description); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "d")
+	}
+	if exists, err := schema.DecodeResult(res, "f", &ret.FeeColor); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "f")
+	}
+	if exists, err := schema.DecodeResult(res, "do", &ret.DefaultOwnerFee); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "do")
+	}
+	if exists, err := schema.DecodeResult(res, "dv", &ret.DefaultValidatorFee); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "dv")
+	}
+	return ret, nil
+}
+
+// GetChainInfo calls the view 'getChainInfo'
+func (c *Client) GetChainInfo(ctx context.Context) (*GetChainInfoResults, error) {
+	res, err := c.backend.CallView(ctx, c.scName, ViewGetChainInfo, dict.New())
+	if err != nil {
+		return nil, err
+	}
+	return decodeGetChainInfoResults(res)
+}
+
+// GetFeeInfoParams are the parameters of 'getFeeInfo'
+type GetFeeInfoParams struct {
+	Hname coretypes.Hname
+}
+
+func (p *GetFeeInfoParams) encode() dict.Dict {
+	ret := dict.New()
+	ret.Set("$$hname$$", codec.Encode(p.Hname))
+	return ret
+}
+
+// GetFeeInfoResults are the results of 'getFeeInfo'
+type GetFeeInfoResults struct {
+	FeeColor     balance.Color
+	OwnerFee     int64
+	ValidatorFee int64
+}
+
+func decodeGetFeeInfoResults(res dict.Dict) (*GetFeeInfoResults, error) {
+	ret := &GetFeeInfoResults{}
+	if res == nil {
+		return ret, nil
+	}
+	if exists, err := schema.DecodeResult(res, "$$feecolor$$", &ret.FeeColor); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "$$feecolor$$")
+	}
+	if exists, err := schema.DecodeResult(res, "$$ownerfee$$", &ret.OwnerFee); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "$$ownerfee$$")
+	}
+	if exists, err := schema.DecodeResult(res, "$$validatorfee$$", &ret.ValidatorFee); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("result '%s' is missing", "$$validatorfee$$")
+	}
+	return ret, nil
+}
+
+// GetFeeInfo calls the view 'getFeeInfo'
+func (c *Client) GetFeeInfo(ctx context.Context, params GetFeeInfoParams) (*GetFeeInfoResults, error) {
+	res, err := c.backend.CallView(ctx, c.scName, ViewGetFeeInfo, params.encode())
+	if err != nil {
+		return nil, err
+	}
+	return decodeGetFeeInfoResults(res)
+}
+
+// GetUpgradeHistoryParams are the parameters of 'getUpgradeHistory'
+type GetUpgradeHistoryParams struct {
+	Hname coretypes.Hname
+}
+
+func (p *GetUpgradeHistoryParams) encode() dict.Dict {
+	ret := dict.New()
+	ret.Set("$$hname$$", codec.Encode(p.Hname))
+	return ret
+}
+
+// GetUpgradeHistory calls the view 'getUpgradeHistory'
+func (c *Client) GetUpgradeHistory(ctx context.Context, params GetUpgradeHistoryParams) error {
+	_, err := c.backend.CallView(ctx, c.scName, ViewGetUpgradeHistory, params.encode())
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package rootclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/schema/solobackend"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootClientSolo(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	root := New(solobackend.New(chain, nil))
+	ctx := context.Background()
+
+	info, err := root.GetChainInfo(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, chain.ChainID, info.ChainID)
+	require.EqualValues(t, chain.OriginatorAgentID, info.ChainOwnerID)
+	require.EqualValues(t, 0, info.DefaultOwnerFee)
+
+	ownerFee := int64(5)
+	err = root.SetDefaultFee(ctx, SetDefaultFeeParams{OwnerFee: &ownerFee})
+	require.NoError(t, err)
+
+	fees, err := root.GetFeeInfo(ctx, GetFeeInfoParams{Hname: blob.Interface.Hname()})
+	require.NoError(t, err)
+	require.EqualValues(t, 5, fees.OwnerFee)
+	require.EqualValues(t, 0, fees.ValidatorFee)
+
+	rec, err := root.FindContract(ctx, FindContractParams{Hname: blob.Interface.Hname()})
+	require.NoError(t, err)
+	require.NotEmpty(t, rec.Data)
+
+	err = root.GetUpgradeHistory(ctx, GetUpgradeHistoryParams{Hname: blob.Interface.Hname()})
+	require.NoError(t, err)
+
+	user := env.NewSignatureSchemeWithFunds()
+	err = New(solobackend.New(chain, user)).SetDefaultFee(ctx, SetDefaultFeeParams{OwnerFee: &ownerFee})
+	require.Error(t, err)
+}
@@ -0,0 +1,161 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+// Package schema describes the interface of a smart contract: its full entry points (funcs) and views,
+// keys and types of their parameters and results. The schema is kept in JSON format and is used to
+// generate a typed Go client of the contract (see Generate). The client calls the contract through
+// a Backend, such as the 'solo' chain or the chainclient.Client of the Wasp node
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
+)
+
+// Schema describes the interface of the smart contract
+type Schema struct {
+	// Name is the name of the contract on the chain
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Funcs are the full entry points, called by requests
+	Funcs []*FuncDef `json:"funcs"`
+	// Views are the view entry points
+	Views []*FuncDef `json:"views"`
+}
+
+// FuncDef describes the entry point of the contract
+type FuncDef struct {
+	Name    string      `json:"name"`
+	Params  []*FieldDef `json:"params,omitempty"`
+	Results []*FieldDef `json:"results,omitempty"`
+}
+
+// FieldDef describes the parameter or the result of the entry point
+type FieldDef struct {
+	// Name is used to name the field in the generated code
+	Name string `json:"name"`
+	// Key is the key of the value in the dictionary of parameters or results. It is equal to Name if empty
+	Key string `json:"key,omitempty"`
+	// Type is one of the types returned by Types
+	Type string `json:"type"`
+	// Optional values may be omitted. Mandatory results missing in the result of the call are reported as errors
+	Optional bool `json:"optional,omitempty"`
+}
+
+// Types returns the types of parameters and results supported by the schema
+func Types() []string {
+	ret := make([]string, 0, len(goTypes))
+	for t := range goTypes {
+		ret = append(ret, t)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// FromContractInterface creates the schema of the contract with the entry points declared in the interface.
+// The 'init' entry point is skipped. The interface does not declare parameters and results, so they have
+// to be added to the schema
+func FromContractInterface(ci *coreutil.ContractInterface) *Schema {
+	ret := &Schema{
+		Name:        ci.Name,
+		Description: ci.Description,
+		Funcs:       make([]*FuncDef, 0),
+		Views:       make([]*FuncDef, 0),
+	}
+	for hname, f := range ci.Functions {
+		if hname == coretypes.EntryPointInit {
+			continue
+		}
+		if f.IsView() {
+			ret.Views = append(ret.Views, &FuncDef{Name: f.Name})
+		} else {
+			ret.Funcs = append(ret.Funcs, &FuncDef{Name: f.Name})
+		}
+	}
+	sort.Slice(ret.Funcs, func(i, j int) bool { return ret.Funcs[i].Name < ret.Funcs[j].Name })
+	sort.Slice(ret.Views, func(i, j int) bool { return ret.Views[i].Name < ret.Views[j].Name })
+	return ret
+}
+
+// Load reads the schema from the JSON file and validates it
+func Load(fname string) (*Schema, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	ret := &Schema{}
+	if err = json.Unmarshal(data, ret); err != nil {
+		return nil, fmt.Errorf("%s: %v", fname, err)
+	}
+	if err = ret.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", fname, err)
+	}
+	return ret, nil
+}
+
+// Write writes the schema in JSON format
+func (s *Schema) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Validate checks that names are not empty and unique in the generated code and that the types are known
+func (s *Schema) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("contract name is empty")
+	}
+	names := make(map[string]bool)
+	for _, f := range append(append([]*FuncDef{}, s.Funcs...), s.Views...) {
+		if f.Name == "" {
+			return fmt.Errorf("function name is empty")
+		}
+		if names[exportedName(f.Name)] {
+			return fmt.Errorf("duplicate function '%s'", f.Name)
+		}
+		names[exportedName(f.Name)] = true
+		if err := validateFields(f.Params); err != nil {
+			return fmt.Errorf("params of '%s': %v", f.Name, err)
+		}
+		if err := validateFields(f.Results); err != nil {
+			return fmt.Errorf("results of '%s': %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateFields(fields []*FieldDef) error {
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
+	for _, fld := range fields {
+		if fld.Name == "" {
+			return fmt.Errorf("field name is empty")
+		}
+		if names[exportedName(fld.Name)] {
+			return fmt.Errorf("duplicate field '%s'", fld.Name)
+		}
+		names[exportedName(fld.Name)] = true
+		if keys[fld.KeyOrName()] {
+			return fmt.Errorf("duplicate key '%s'", fld.KeyOrName())
+		}
+		keys[fld.KeyOrName()] = true
+		if _, ok := goTypes[fld.Type]; !ok {
+			return fmt.Errorf("field '%s': unknown type '%s'", fld.Name, fld.Type)
+		}
+	}
+	return nil
+}
+
+// KeyOrName returns the key of the field in the dictionary
+func (fld *FieldDef) KeyOrName() string {
+	if fld.Key != "" {
+		return fld.Key
+	}
+	return fld.Name
+}
@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContractInterface(t *testing.T) {
+	s := FromContractInterface(root.Interface)
+	require.EqualValues(t, root.Name, s.Name)
+	require.EqualValues(t, 8, len(s.Funcs))
+	require.EqualValues(t, 4, len(s.Views))
+	require.EqualValues(t, root.FuncClaimChainOwnership, s.Funcs[0].Name)
+	require.NoError(t, s.Validate())
+
+	_, err := Generate(s, "rootclient")
+	require.NoError(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	s := &Schema{
+		Name:  "test",
+		Funcs: []*FuncDef{{Name: "create_game"}, {Name: "createGame"}},
+	}
+	require.Error(t, s.Validate())
+
+	s.Funcs = []*FuncDef{{Name: "createGame", Params: []*FieldDef{{Name: "player", Type: "float"}}}}
+	require.Error(t, s.Validate())
+
+	s.Funcs[0].Params[0].Type = "string"
+	require.NoError(t, s.Validate())
+}
+
+func TestGeneratedClientIsUpToDate(t *testing.T) {
+	s, err := Load("rootclient/root.json")
+	require.NoError(t, err)
+	src, err := Generate(s, "rootclient")
+	require.NoError(t, err)
+	existing, err := ioutil.ReadFile("rootclient/rootclient.go")
+	require.NoError(t, err)
+	require.Equal(t, string(existing), string(src), "run 'go generate' in rootclient")
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+// Package solobackend makes the generated contract clients call the contract on the 'solo' chain
+package solobackend
+
+import (
+	"context"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/solo"
+)
+
+// Backend posts requests to the 'solo' chain, signed with the signature scheme
+type Backend struct {
+	chain     *solo.Chain
+	sigScheme signaturescheme.SignatureScheme
+}
+
+// New creates the backend. If 'sigScheme' is nil, the requests are signed by the originator of the chain
+func New(chain *solo.Chain, sigScheme signaturescheme.SignatureScheme) *Backend {
+	return &Backend{chain: chain, sigScheme: sigScheme}
+}
+
+// PostRequest runs the request synchronously with solo.Chain.PostRequest and returns the result of the call
+func (b *Backend) PostRequest(_ context.Context, contract string, function string, params dict.Dict, transfer map[balance.Color]int64) (dict.Dict, error) {
+	req := solo.NewCallParamsFromDic(contract, function, params)
+	if len(transfer) > 0 {
+		req.WithTransfers(transfer)
+	}
+	return b.chain.PostRequest(req, b.sigScheme)
+}
+
+// CallView calls the view with solo.Chain.CallView
+func (b *Backend) CallView(_ context.Context, contract string, function string, params dict.Dict) (dict.Dict, error) {
+	args := make([]interface{}, 0, 2*len(params))
+	for _, key := range params.KeysSorted() {
+		args = append(args, string(key), params.MustGet(key))
+	}
+	return b.chain.CallView(contract, function, args...)
+}
@@ -0,0 +1,302 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/vm"
+)
+
+// RequestHandle refers to the request in the backlog of the chain. It is returned by PostRequestAsync and BacklogHandles.
+// The handle is used to run the request with RunBatch and to obtain the result when the request is processed
+type RequestHandle struct {
+	chain      *Chain
+	ref        sctransaction.RequestRef
+	processed  chan struct{}
+	blockIndex uint32
+	result     dict.Dict
+	err        error
+}
+
+// BatchOrder is the order of the requests in the batch run by RunBatchInOrder
+type BatchOrder int
+
+const (
+	// BatchOrderAsGiven runs the requests in the order of the handles
+	BatchOrderAsGiven = BatchOrder(iota)
+	// BatchOrderBacklog runs the requests in the order they were added to the backlog
+	BatchOrderBacklog
+	// BatchOrderReverse runs the requests in the reverse order of the handles
+	BatchOrderReverse
+)
+
+// MaxNotSolidAttempts is the number of attempts to run a request with arguments which can't be solidified.
+// After that the request is removed from the backlog and its handle is completed with the error
+const MaxNotSolidAttempts = 20
+
+type requestResult struct {
+	reqID  coretypes.RequestID
+	result dict.Dict
+	err    error
+}
+
+// PostRequestAsync creates the request transaction like PostRequest, adds it to the UTXODB and puts the request
+// into the backlog of the chain without running it. The request is processed by the backlog processing
+// thread together with other requests, or with RunBatch if automatic batching is turned off (see SetAutoBatching).
+// Returns the handle of the request
+func (ch *Chain) PostRequestAsync(req *CallParams, sigScheme signaturescheme.SignatureScheme) (*RequestHandle, error) {
+	tx, err := ch.requestTransaction(req, sigScheme)
+	if err != nil {
+		return nil, err
+	}
+	ref := sctransaction.RequestRef{Tx: tx, Index: 0}
+	ch.Log.Infof("PostRequestAsync: %s::%s -- %s", req.targetName, req.epName, ref.RequestID().String())
+
+	ch.backlogMutex.Lock()
+	ret := ch.handleNoLock(ref)
+	ch.backlogMutex.Unlock()
+
+	ch.chPosted.Add(1)
+	ch.addToBacklog(ref)
+	return ret, nil
+}
+
+// BacklogHandles returns handles of all requests in the backlog, in the order they were added to the backlog.
+// It includes requests posted to the chain by smart contracts
+func (ch *Chain) BacklogHandles() []*RequestHandle {
+	ch.chPosted.Wait()
+	ch.backlogMutex.Lock()
+	defer ch.backlogMutex.Unlock()
+
+	ret := make([]*RequestHandle, len(ch.backlog))
+	for i, ref := range ch.backlog {
+		ret[i] = ch.handleNoLock(ref)
+	}
+	return ret
+}
+
+// SetAutoBatching turns on and off automatic batching of the requests in the backlog. It is on by default.
+// When it is off, the requests posted with PostRequestAsync and requests posted to the chain by smart contracts
+// stay in the backlog until they are run with RunBatch. It makes it possible to stage the backlog and to run
+// the requests in the chosen order. PostRequest is not affected, it always runs the request immediately
+func (ch *Chain) SetAutoBatching(on bool) {
+	ch.backlogMutex.Lock()
+	defer ch.backlogMutex.Unlock()
+	ch.manualBatching = !on
+}
+
+// RunBatch takes the requests from the backlog and runs them in one batch in the order of the handles.
+// See RunBatchInOrder
+func (ch *Chain) RunBatch(handles ...*RequestHandle) error {
+	return ch.RunBatchInOrder(BatchOrderAsGiven, handles...)
+}
+
+// RunBatchInOrder takes the requests from the backlog and runs them in one batch in the specified order.
+// If no handles are given, all requests in the backlog which are not time locked are run.
+// Nothing is run and the error is returned if any of the requests is not in the backlog or is time locked,
+// or if the arguments of the requests can't be solidified. Otherwise the error returned by the first request
+// of the batch is returned, like in PostRequest. The results of individual requests are available from the handles
+func (ch *Chain) RunBatchInOrder(order BatchOrder, handles ...*RequestHandle) error {
+	ch.chPosted.Wait()
+	batch, err := ch.takeFromBacklog(order, handles)
+	if err != nil {
+		return err
+	}
+	ch.Log.Infof("RunBatch: %d request(s)", len(batch))
+	_, err = ch.runFromBacklog(batch, "RunBatch")
+	if err == errArgsNotSolid {
+		return fmt.Errorf("RunBatch: arguments of the requests can't be solidified, see MaxNotSolidAttempts")
+	}
+	return err
+}
+
+// takeFromBacklog removes the requests from the backlog and returns them in the order
+func (ch *Chain) takeFromBacklog(order BatchOrder, handles []*RequestHandle) ([]vm.RequestRefWithFreeTokens, error) {
+	ch.backlogMutex.Lock()
+	defer ch.backlogMutex.Unlock()
+
+	now := ch.Env.LogicalTime().Unix()
+	position := make(map[coretypes.RequestID]int)
+	for i, ref := range ch.backlog {
+		position[*ref.RequestID()] = i
+	}
+	if len(handles) == 0 {
+		for _, ref := range ch.backlog {
+			if int64(ref.RequestSection().Timelock()) <= now {
+				handles = append(handles, ch.handleNoLock(ref))
+			}
+		}
+		if len(handles) == 0 {
+			return nil, fmt.Errorf("RunBatch: no requests to run in the backlog")
+		}
+	}
+	taken := make(map[int]bool)
+	for _, h := range handles {
+		if h.chain != ch {
+			return nil, fmt.Errorf("RunBatch: request %s belongs to another chain", h.ref.RequestID().String())
+		}
+		i, ok := position[h.RequestID()]
+		if !ok {
+			return nil, fmt.Errorf("RunBatch: request %s is not in the backlog", h.ref.RequestID().String())
+		}
+		if taken[i] {
+			return nil, fmt.Errorf("RunBatch: duplicate request %s", h.ref.RequestID().String())
+		}
+		if int64(h.ref.RequestSection().Timelock()) > now {
+			return nil, fmt.Errorf("RunBatch: request %s is time locked", h.ref.RequestID().String())
+		}
+		taken[i] = true
+	}
+
+	ordered := append([]*RequestHandle{}, handles...)
+	switch order {
+	case BatchOrderAsGiven:
+	case BatchOrderBacklog:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return position[ordered[i].RequestID()] < position[ordered[j].RequestID()]
+		})
+	case BatchOrderReverse:
+		for i, j := 0, len(ordered)-1; i < j; i, j = i+1, j-1 {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		}
+	default:
+		return nil, fmt.Errorf("RunBatch: unknown batch order %d", order)
+	}
+
+	remain := ch.backlog[:0]
+	for i, ref := range ch.backlog {
+		if !taken[i] {
+			remain = append(remain, ref)
+		}
+	}
+	ch.backlog = remain
+
+	ret := make([]vm.RequestRefWithFreeTokens, len(ordered))
+	for i, h := range ordered {
+		ret[i] = vm.RequestRefWithFreeTokens{RequestRef: h.ref}
+	}
+	return ret, nil
+}
+
+// runFromBacklog runs the batch of requests taken from the backlog.
+// If the batch can't be run, the requests are returned to the front of the backlog in the order of the batch.
+// A request which can't be solidified in MaxNotSolidAttempts is removed and its handle is completed with the error
+func (ch *Chain) runFromBacklog(batch []vm.RequestRefWithFreeTokens, trace string) (dict.Dict, error) {
+	res, err := ch.runBatch(batch, trace)
+	if err != errArgsNotSolid {
+		return res, err
+	}
+	ch.backlogMutex.Lock()
+	defer ch.backlogMutex.Unlock()
+
+	backlog := make([]sctransaction.RequestRef, 0, len(batch)+len(ch.backlog))
+	for _, ref := range batch {
+		if ok, _ := ref.RequestSection().SolidifyArgs(ch.Env.registry); !ok {
+			reqID := *ref.RequestID()
+			ch.notSolidAttempts[reqID]++
+			if ch.notSolidAttempts[reqID] >= MaxNotSolidAttempts {
+				ch.Log.Errorf("request %s removed from the backlog: %v", reqID.String(), err)
+				delete(ch.notSolidAttempts, reqID)
+				ch.completeHandleNoLock(reqID, 0, nil, err)
+				continue
+			}
+		}
+		backlog = append(backlog, ref.RequestRef)
+	}
+	ch.backlog = append(backlog, ch.backlog...)
+	return nil, err
+}
+
+// handleNoLock returns the handle of the request, creating it if needed. Must be called with backlogMutex locked
+func (ch *Chain) handleNoLock(ref sctransaction.RequestRef) *RequestHandle {
+	reqID := *ref.RequestID()
+	if ret, ok := ch.handles[reqID]; ok {
+		return ret
+	}
+	ret := &RequestHandle{
+		chain:     ch,
+		ref:       ref,
+		processed: make(chan struct{}),
+	}
+	ch.handles[reqID] = ret
+	return ret
+}
+
+// requestsProcessed completes handles of the requests processed in the block
+func (ch *Chain) requestsProcessed(results []requestResult, blockIndex uint32) {
+	ch.backlogMutex.Lock()
+	defer ch.backlogMutex.Unlock()
+
+	for _, res := range results {
+		delete(ch.notSolidAttempts, res.reqID)
+		ch.completeHandleNoLock(res.reqID, blockIndex, res.result, res.err)
+	}
+}
+
+// completeHandleNoLock completes the handle of the request, if any, and forgets it. Must be called with backlogMutex locked
+func (ch *Chain) completeHandleNoLock(reqID coretypes.RequestID, blockIndex uint32, result dict.Dict, err error) {
+	h, ok := ch.handles[reqID]
+	if !ok {
+		return
+	}
+	h.blockIndex = blockIndex
+	h.result = result
+	h.err = err
+	close(h.processed)
+	delete(ch.handles, reqID)
+}
+
+// RequestID returns the ID of the request
+func (h *RequestHandle) RequestID() coretypes.RequestID {
+	return *h.ref.RequestID()
+}
+
+// IsProcessed returns true if the request was run by the VM, or was removed from the backlog
+// because its arguments can't be solidified (see MaxNotSolidAttempts)
+func (h *RequestHandle) IsProcessed() bool {
+	select {
+	case <-h.processed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait waits until the request is processed. Returns false if the request was not processed in 'maxWait', if specified
+func (h *RequestHandle) Wait(maxWait ...time.Duration) bool {
+	if len(maxWait) == 0 {
+		<-h.processed
+		return true
+	}
+	select {
+	case <-h.processed:
+		return true
+	case <-time.After(maxWait[0]):
+		return false
+	}
+}
+
+// Result returns the result of the call to the entry point and the error returned by the request.
+// Returns an error if the request is not processed yet or was removed from the backlog
+func (h *RequestHandle) Result() (dict.Dict, error) {
+	if !h.IsProcessed() {
+		return nil, fmt.Errorf("request %s is not processed yet", h.ref.RequestID().String())
+	}
+	return h.result, h.err
+}
+
+// BlockIndex returns the index of the block which contains the request, or 0 if the request is not processed yet
+func (h *RequestHandle) BlockIndex() uint32 {
+	if !h.IsProcessed() {
+		return 0
+	}
+	return h.blockIndex
+}
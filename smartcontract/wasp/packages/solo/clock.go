@@ -12,11 +12,14 @@ func (env *Solo) LogicalTime() time.Time {
 	return env.logicalTime
 }
 
-// AdvanceClockTo advances logical clock to the specific time moment in the (logical) future
+// AdvanceClockTo advances logical clock to the specific time moment in the (logical) future.
+// The time locked requests unlocked by the new time are run before the call returns
 func (env *Solo) AdvanceClockTo(ts time.Time) {
 	env.glbMutex.Lock()
-	defer env.glbMutex.Unlock()
 	env.advanceClockTo(ts)
+	env.glbMutex.Unlock()
+
+	env.runUnlockedRequests()
 }
 
 func (env *Solo) advanceClockTo(ts time.Time) {
@@ -24,15 +27,18 @@ func (env *Solo) advanceClockTo(ts time.Time) {
 		env.logger.Panic("can'T advance clock to the past")
 	}
 	env.logicalTime = ts
+	env.persistEnv()
 }
 
-// AdvanceClockBy advances logical clock by time step
+// AdvanceClockBy advances logical clock by time step.
+// The time locked requests unlocked by the new time are run before the call returns
 func (env *Solo) AdvanceClockBy(step time.Duration) {
 	env.glbMutex.Lock()
-	defer env.glbMutex.Unlock()
-
 	env.advanceClockTo(env.logicalTime.Add(step))
 	env.logger.Infof("AdvanceClockBy: logical clock advanced by %v", step)
+	env.glbMutex.Unlock()
+
+	env.runUnlockedRequests()
 }
 
 // ClockStep advances logical clock by time step set by SetTimeStep
@@ -49,4 +55,5 @@ func (env *Solo) SetTimeStep(step time.Duration) {
 	env.glbMutex.Lock()
 	defer env.glbMutex.Unlock()
 	env.timeStep = step
+	env.persistEnv()
 }
@@ -41,6 +41,26 @@
 // the development and debugging of the smart contract logic in IDE such as GoLand, before writing it as
 // a Rust/Wasm smart contract.
 //
+// By default the environment lives in memory only. An environment created with solo.NewPersistent
+// keeps the UTXO ledger, the registry and the state of all chains on disk, so that a later test run can
+// continue from the same point with solo.Open.
+//
+// The environment created with WithSeed is deterministic: keys, logical time and the entropy of blocks
+// are derived from the seed. Its run can be recorded with StartRecording and repeated with solo.Replay,
+// which reproduces the same batches and checks that the state hashes are the same.
+//
+// Requests posted with Chain.PostRequestAsync are put into the backlog of the chain. With automatic batching
+// turned off by Chain.SetAutoBatching the test decides which requests are run together and in which order
+// with Chain.RunBatch.
+//
+// Time locked requests stay in the backlog until the logical clock passes their unlock time. Solo.AdvanceClockBy and
+// Solo.AdvanceClockTo run the requests unlocked by the new time before returning. Pending time locked requests
+// are reported by Chain.TimeLockedRequests.
+//
+// Chain.InspectContractState returns the state of the contract with keys of arrays, maps and timestamped logs grouped
+// into variables. Values are decoded by codecs registered with Chain.RegisterStateCodec. The state can be printed as
+// a tree or in JSON and compared between two blocks with Chain.DiffContractState.
+//
 // Example test
 //
 // The following example deploys chain and retrieves basic info from the deployed chain.
@@ -21,6 +21,7 @@ import (
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
 	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/wasmproc"
 	"github.com/iotaledger/wasp/plugins/wasmtimevm"
 	"github.com/stretchr/testify/require"
 	"io/ioutil"
@@ -253,6 +254,65 @@ func (ch *Chain) DeployWasmContract(sigScheme signaturescheme.SignatureScheme, n
 	return ch.DeployContract(sigScheme, name, hprog, params...)
 }
 
+// UploadGoContract registers the 'onLoad' function of the Go contract written against wasmlib under the
+// name of the contract and uploads the blob of the Go contract to the chain. The contract is run by
+// wasmhost.WasmGoVM without a Wasm build, as if it was Wasm. The hash of the blob is the program hash of the contract
+func (ch *Chain) UploadGoContract(sigScheme signaturescheme.SignatureScheme, name string, onLoad func()) (ret hashing.HashValue, err error) {
+	wasmproc.RegisterGoContract(name, onLoad)
+	return ch.UploadBlob(sigScheme,
+		blob.VarFieldVMType, wasmproc.GoVMType,
+		blob.VarFieldProgramBinary, wasmproc.GoContractBinary(name),
+	)
+}
+
+// DeployGoContract is syntactic sugar for uploading the Go contract and deploying it in one call.
+// See UploadGoContract
+func (ch *Chain) DeployGoContract(sigScheme signaturescheme.SignatureScheme, name string, onLoad func(), params ...interface{}) error {
+	hprog, err := ch.UploadGoContract(sigScheme, name, onLoad)
+	if err != nil {
+		return err
+	}
+	return ch.DeployContract(sigScheme, name, hprog, params...)
+}
+
+// UpgradeContract replaces the program of the deployed contract with the one with the given 'programHash'.
+// The state and the accounts of the contract are kept. 'sigScheme' must be of the creator of the
+// contract or of the chain owner (nil defaults to chain originator). Optional 'params' are passed to
+// the 'root' contract, for example the new description with root.ParamDescription
+func (ch *Chain) UpgradeContract(sigScheme signaturescheme.SignatureScheme, name string, programHash hashing.HashValue, params ...interface{}) error {
+	par := []interface{}{root.ParamProgramHash, programHash, root.ParamName, name}
+	par = append(par, params...)
+	req := NewCallParams(root.Interface.Name, root.FuncUpgradeContract, par...)
+	_, err := ch.PostRequest(req, sigScheme)
+	return err
+}
+
+// UpgradeWasmContract is syntactic sugar for uploading Wasm binary from file and
+// upgrading the smart contract to it in one call
+func (ch *Chain) UpgradeWasmContract(sigScheme signaturescheme.SignatureScheme, name string, fname string, params ...interface{}) error {
+	hprog, err := ch.UploadWasmFromFile(sigScheme, fname)
+	if err != nil {
+		return err
+	}
+	return ch.UpgradeContract(sigScheme, name, hprog, params...)
+}
+
+// GetUpgradeHistory returns the upgrades of the contract with the given name, the oldest first
+func (ch *Chain) GetUpgradeHistory(scName string) ([]*root.ContractUpgrade, error) {
+	res, err := ch.CallView(root.Interface.Name, root.FuncGetUpgradeHistory, root.ParamHname, coretypes.Hn(scName))
+	if err != nil {
+		return nil, err
+	}
+	recs := collections.NewArray32ReadOnly(res, root.ParamData)
+	ret := make([]*root.ContractUpgrade, recs.MustLen())
+	for i := range ret {
+		if ret[i], err = root.DecodeContractUpgrade(recs.MustGetAt(uint32(i))); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
 type ChainInfo struct {
 	ChainID      coretypes.ChainID
 	ChainOwnerID coretypes.AgentID
@@ -433,3 +493,27 @@ func (ch *Chain) GetEventLogNumRecords(name string) int {
 	require.True(ch.Env.T, ok)
 	return int(ret)
 }
+
+// GetEvents calls the view in the 'eventlog' core smart contract to retrieve latest up to 50 typed events
+// with the topic emitted by the given contract, in time-descending order.
+// Optional params are additional filter parameters of the view, such as eventlog.ParamKey and eventlog.ParamValue
+func (ch *Chain) GetEvents(name string, topic string, params ...interface{}) ([]*eventlog.Event, error) {
+	params = append(params, eventlog.ParamContractHname, coretypes.Hn(name), eventlog.ParamTopic, topic)
+	res, err := ch.CallView(eventlog.Interface.Name, eventlog.FuncGetEvents, params...)
+	if err != nil {
+		return nil, err
+	}
+	return eventlog.DecodeEvents(res)
+}
+
+// GetEventsByRequest returns typed events emitted by the given contract while processing the request
+func (ch *Chain) GetEventsByRequest(name string, reqID coretypes.RequestID) ([]*eventlog.Event, error) {
+	res, err := ch.CallView(eventlog.Interface.Name, eventlog.FuncGetEvents,
+		eventlog.ParamContractHname, coretypes.Hn(name),
+		eventlog.ParamRequestID, reqID[:],
+	)
+	if err != nil {
+		return nil, err
+	}
+	return eventlog.DecodeEvents(res)
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm/wasmlib"
+	"github.com/iotaledger/wasp/packages/vm/wasmproc"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	loopName   = "loop"
+	keyCounter = wasmlib.Key("counter")
+)
+
+func loopOnLoad() {
+	exports := wasmlib.NewScExports()
+	exports.AddFunc("increment", func(ctx *wasmlib.ScFuncContext) {
+		counter := ctx.State().GetInt(keyCounter)
+		counter.SetValue(counter.Value() + 1)
+	})
+	exports.AddFunc("loopForever", func(ctx *wasmlib.ScFuncContext) {
+		counter := ctx.State().GetInt(keyCounter)
+		counter.SetValue(counter.Value() + 1)
+		for {
+			counter.Value()
+		}
+	})
+	exports.AddView("getCounter", func(ctx *wasmlib.ScViewContext) {
+		ctx.Results().GetInt(keyCounter).SetValue(ctx.State().GetInt(keyCounter).Value())
+	})
+}
+
+func getCounter(t *testing.T, chain *Chain) int64 {
+	res, err := chain.CallView(loopName, "getCounter")
+	require.NoError(t, err)
+	counter, _, err := codec.DecodeInt64(res.MustGet(kv.Key(keyCounter)))
+	require.NoError(t, err)
+	return counter
+}
+
+func TestGasBudgetExceeded(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	err := chain.DeployGoContract(nil, loopName, loopOnLoad)
+	require.NoError(t, err)
+
+	user := env.NewSignatureSchemeWithFunds()
+	_, err = chain.PostRequest(NewCallParams(loopName, "increment"), user)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, getCounter(t, chain))
+
+	chain.GasBudget = 100000
+	balance0 := env.GetAddressBalance(user.Address(), balance.ColorIOTA)
+	req := NewCallParams(loopName, "loopForever").WithTransfer(balance.ColorIOTA, 42)
+	receipt, err := chain.PostRequestWithReceipt(req, user)
+	require.NoError(t, err)
+	require.EqualError(t, receipt.Error, coretypes.ErrGasBudgetExceeded.Error())
+	require.EqualValues(t, chain.GasBudget, receipt.GasUsed)
+
+	// the state update of the request is rolled back and the transfer is returned to the sender,
+	// only the request token is spent
+	require.EqualValues(t, 1, getCounter(t, chain))
+	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, balance0-1)
+
+	recs, err := chain.GetEventLogRecordsString(loopName)
+	require.NoError(t, err)
+	require.Contains(t, recs, receipt.RequestID.String()+": "+coretypes.ErrGasBudgetExceeded.Error())
+	require.EqualValues(t, 2, strings.Count(recs, "[req]"))
+}
+
+// loopWat is a Wasm contract with the functions 'loopForever' (index 0) and 'increment' (index 1),
+// written directly against the host interface. Both set the state variable 'counter',
+// to 2 and 1 respectively, then 'loopForever' loops until it runs out of gas
+const loopWat = `(module
+  (import "wasplib" "hostGetKeyId" (func $getKeyId (param i32 i32) (result i32)))
+  (import "wasplib" "hostGetObjectId" (func $getObjectId (param i32 i32 i32) (result i32)))
+  (import "wasplib" "hostSetBytes" (func $setBytes (param i32 i32 i32 i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 0) "loopForever")
+  (data (i32.const 16) "increment")
+  (data (i32.const 32) "counter")
+  (data (i32.const 48) "\01\00\00\00\00\00\00\00")
+  (data (i32.const 56) "\02\00\00\00\00\00\00\00")
+  (func (export "on_load")
+    (local $exports i32)
+    ;; root object 1, KeyExports, string array
+    (local.set $exports (call $getObjectId (i32.const 1) (i32.const -14) (i32.const 43)))
+    (call $setBytes (local.get $exports) (i32.const 0) (i32.const 11) (i32.const 0) (i32.const 11))
+    (call $setBytes (local.get $exports) (i32.const 1) (i32.const 11) (i32.const 16) (i32.const 9)))
+  (func $setCounter (param $ref i32)
+    ;; root object 1, KeyState, map
+    (call $setBytes
+      (call $getObjectId (i32.const 1) (i32.const -29) (i32.const 10))
+      (call $getKeyId (i32.const 32) (i32.const 7))
+      (i32.const 9) (local.get $ref) (i32.const 8)))
+  (func (export "on_call_entrypoint") (param $index i32)
+    (if (i32.eqz (local.get $index))
+      (then
+        (call $setCounter (i32.const 56))
+        (loop $forever (br $forever))))
+    (call $setCounter (i32.const 48))))`
+
+func TestGasBudgetExceededWasm(t *testing.T) {
+	wasmData, err := wasmtime.Wat2Wasm(loopWat)
+	require.NoError(t, err)
+
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	hprog, err := chain.UploadWasm(nil, wasmData)
+	require.NoError(t, err)
+	err = chain.DeployContract(nil, loopName, hprog)
+	require.NoError(t, err)
+
+	getState := func() dict.Dict {
+		res, err := chain.CallView(loopName, wasmproc.ViewCopyAllState)
+		require.NoError(t, err)
+		return res
+	}
+	user := env.NewSignatureSchemeWithFunds()
+	_, err = chain.PostRequest(NewCallParams(loopName, "increment"), user)
+	require.NoError(t, err)
+	require.EqualValues(t, codec.EncodeInt64(1), getState().MustGet(kv.Key(keyCounter)))
+
+	// the instrumented loop traps when the gas counter runs out
+	chain.GasBudget = 100000
+	balance0 := env.GetAddressBalance(user.Address(), balance.ColorIOTA)
+	req := NewCallParams(loopName, "loopForever").WithTransfer(balance.ColorIOTA, 42)
+	receipt, err := chain.PostRequestWithReceipt(req, user)
+	require.NoError(t, err)
+	require.EqualError(t, receipt.Error, coretypes.ErrGasBudgetExceeded.Error())
+	require.EqualValues(t, chain.GasBudget, receipt.GasUsed)
+
+	// the counter set before the loop is rolled back and the transfer is returned to the sender
+	require.EqualValues(t, codec.EncodeInt64(1), getState().MustGet(kv.Key(keyCounter)))
+	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, balance0-1)
+}
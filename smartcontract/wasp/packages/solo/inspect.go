@@ -0,0 +1,561 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/stretchr/testify/require"
+)
+
+// StateVarKind is the kind of the variable in the state of the contract
+type StateVarKind string
+
+const (
+	StateScalar         = StateVarKind("scalar")
+	StateArray          = StateVarKind("array")
+	StateArray32        = StateVarKind("array32")
+	StateMap            = StateVarKind("map")
+	StateTimestampedLog = StateVarKind("timestamped_log")
+)
+
+// StateCodec decodes the value of the state variable, or values of the elements if the variable is a collection
+type StateCodec func(value []byte) (interface{}, error)
+
+// codecs for the values encoded with the 'codec' package and for JSON values
+var (
+	StateCodecInt64 = StateCodec(func(value []byte) (interface{}, error) {
+		ret, _, err := codec.DecodeInt64(value)
+		return ret, err
+	})
+	StateCodecString = StateCodec(func(value []byte) (interface{}, error) {
+		ret, _, err := codec.DecodeString(value)
+		return ret, err
+	})
+	StateCodecAgentID = StateCodec(func(value []byte) (interface{}, error) {
+		ret, _, err := codec.DecodeAgentID(value)
+		return ret, err
+	})
+	StateCodecHname = StateCodec(func(value []byte) (interface{}, error) {
+		ret, _, err := codec.DecodeHname(value)
+		return ret, err
+	})
+	StateCodecJSON = StateCodec(func(value []byte) (interface{}, error) {
+		if !json.Valid(value) {
+			return nil, fmt.Errorf("not a JSON value")
+		}
+		return json.RawMessage(value), nil
+	})
+)
+
+// ContractState is the state of the contract returned by InspectContractState. The keys are without the hname
+// prefix of the contract partition. Keys of collections are grouped into one variable
+type ContractState struct {
+	Contract   string      `json:"contract"`
+	BlockIndex uint32      `json:"blockIndex"`
+	Vars       []*StateVar `json:"vars"`
+}
+
+// StateVar is a scalar variable or a collection in the state of the contract
+type StateVar struct {
+	Name  string       `json:"name"`
+	Kind  StateVarKind `json:"kind"`
+	Value interface{}  `json:"value,omitempty"`
+	Elems []*StateElem `json:"elems,omitempty"`
+}
+
+// StateElem is the element of the collection. Key is the index for arrays and timestamped logs
+type StateElem struct {
+	Key       string      `json:"key"`
+	Timestamp string      `json:"timestamp,omitempty"`
+	Value     interface{} `json:"value"`
+}
+
+// StateDiff is the difference between states of the contract at two block indices returned by DiffContractState
+type StateDiff struct {
+	Contract  string         `json:"contract"`
+	FromBlock uint32         `json:"fromBlock"`
+	ToBlock   uint32         `json:"toBlock"`
+	Changes   []*StateChange `json:"changes"`
+}
+
+// StateChange is the change of the scalar variable or of the element of the collection.
+// Old is nil for added values, New is nil for deleted values
+type StateChange struct {
+	Var  string       `json:"var"`
+	Kind StateVarKind `json:"kind"`
+	Key  string       `json:"key,omitempty"`
+	Old  interface{}  `json:"old,omitempty"`
+	New  interface{}  `json:"new,omitempty"`
+}
+
+type stateInspectConfig struct {
+	codecs  map[string]StateCodec
+	layouts map[string]StateVarKind
+}
+
+// stateReplay is the state of the contract rebuilt from the blocks before the block index 'next'
+type stateReplay struct {
+	next uint32
+	vars map[string][]byte
+}
+
+// RegisterStateCodec registers the codec used by InspectContractState to decode the value of the state variable
+// of the contract. For collections the codec decodes values of the elements
+func (ch *Chain) RegisterStateCodec(contract, varName string, stateCodec StateCodec) {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+	ch.inspectConfig(contract).codecs[varName] = stateCodec
+}
+
+// RegisterStateLayout declares the kind of the state variable of the contract. Collections are recognized
+// in the state automatically, the layout is needed only when the automatic recognition is ambiguous,
+// for example for maps with 4 bytes keys. The declared layout is always used instead of the automatic
+// recognition: StateScalar keeps the keys of the variable from being recognized as a collection
+func (ch *Chain) RegisterStateLayout(contract, varName string, kind StateVarKind) {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+	ch.inspectConfig(contract).layouts[varName] = kind
+}
+
+// InspectContractState returns the current state of the contract with keys grouped into variables
+// and values decoded with the registered codecs
+func (ch *Chain) InspectContractState(contract string) *ContractState {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+	return ch.inspectConfig(contract).contractState(contract, ch.State.BlockIndex(), ch.contractPartitionNoLock(contract))
+}
+
+// InspectContractStateAt returns the state of the contract at the block index like InspectContractState.
+// The state is rebuilt from the blocks of the chain. The chain keeps the last rebuilt state of each contract,
+// so inspecting the blocks in ascending order, for example with DiffContractState, applies each block once
+func (ch *Chain) InspectContractStateAt(contract string, blockIndex uint32) *ContractState {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+	raw := ch.contractPartitionAtNoLock(contract, blockIndex)
+	return ch.inspectConfig(contract).contractState(contract, blockIndex, raw)
+}
+
+// DiffContractState returns changes of the state of the contract between two block indices
+func (ch *Chain) DiffContractState(contract string, fromBlock, toBlock uint32) *StateDiff {
+	from := ch.InspectContractStateAt(contract, fromBlock)
+	to := ch.InspectContractStateAt(contract, toBlock)
+	ret := &StateDiff{
+		Contract:  contract,
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Changes:   make([]*StateChange, 0),
+	}
+	oldValues := from.flatten()
+	newValues := to.flatten()
+	for _, c := range newValues {
+		old, ok := oldValues[c.id()]
+		switch {
+		case !ok:
+			ret.Changes = append(ret.Changes, c)
+		case !sameValue(old.New, c.New):
+			c.Old = old.New
+			ret.Changes = append(ret.Changes, c)
+		}
+	}
+	for id, c := range oldValues {
+		if _, ok := newValues[id]; !ok {
+			c.Old, c.New = c.New, nil
+			ret.Changes = append(ret.Changes, c)
+		}
+	}
+	sort.Slice(ret.Changes, func(i, j int) bool {
+		return ret.Changes[i].id() < ret.Changes[j].id()
+	})
+	return ret
+}
+
+// inspectConfig must be called with runVMMutex locked
+func (ch *Chain) inspectConfig(contract string) *stateInspectConfig {
+	if ch.inspect == nil {
+		ch.inspect = make(map[string]*stateInspectConfig)
+	}
+	ret, ok := ch.inspect[contract]
+	if !ok {
+		ret = &stateInspectConfig{
+			codecs:  make(map[string]StateCodec),
+			layouts: make(map[string]StateVarKind),
+		}
+		ch.inspect[contract] = ret
+	}
+	return ret
+}
+
+// contractPartitionNoLock returns key/values of the current state of the contract without the hname prefix
+func (ch *Chain) contractPartitionNoLock(contract string) map[string][]byte {
+	prefix := kv.Key(coretypes.Hn(contract).Bytes())
+	ret := make(map[string][]byte)
+	err := ch.State.Variables().Iterate(prefix, func(key kv.Key, value []byte) bool {
+		ret[string(key[len(prefix):])] = value
+		return true
+	})
+	require.NoError(ch.Env.T, err)
+	return ret
+}
+
+// contractPartitionAtNoLock returns key/values of the state of the contract at the block index.
+// The state is rebuilt from the blocks of the chain, continuing from the last rebuilt state if possible
+func (ch *Chain) contractPartitionAtNoLock(contract string, blockIndex uint32) map[string][]byte {
+	require.True(ch.Env.T, blockIndex <= ch.State.BlockIndex(), "block #%d does not exist", blockIndex)
+	if blockIndex == ch.State.BlockIndex() {
+		return ch.contractPartitionNoLock(contract)
+	}
+	if ch.inspectReplay == nil {
+		ch.inspectReplay = make(map[string]*stateReplay)
+	}
+	replay, ok := ch.inspectReplay[contract]
+	if !ok || replay.next > blockIndex+1 {
+		replay = &stateReplay{vars: make(map[string][]byte)}
+		ch.inspectReplay[contract] = replay
+	}
+	prefix := string(coretypes.Hn(contract).Bytes())
+	ret := replay.vars
+	for i := replay.next; i <= blockIndex; i++ {
+		block, err := state.LoadBlockFromDB(ch.db, i)
+		require.NoError(ch.Env.T, err)
+		require.NotNil(ch.Env.T, block, "block #%d not found", i)
+		block.ForEach(func(_ uint16, stateUpd state.StateUpdate) bool {
+			stateUpd.Mutations().IterateLatest(func(key kv.Key, mut buffered.Mutation) bool {
+				if !strings.HasPrefix(string(key), prefix) {
+					return true
+				}
+				if mut.Value() == nil {
+					delete(ret, string(key)[len(prefix):])
+				} else {
+					ret[string(key)[len(prefix):]] = mut.Value()
+				}
+				return true
+			})
+			return true
+		})
+	}
+	replay.next = blockIndex + 1
+
+	ret = make(map[string][]byte, len(replay.vars))
+	for key, value := range replay.vars {
+		ret[key] = value
+	}
+	return ret
+}
+
+func (cfg *stateInspectConfig) contractState(contract string, blockIndex uint32, raw map[string][]byte) *ContractState {
+	ret := &ContractState{
+		Contract:   contract,
+		BlockIndex: blockIndex,
+		Vars:       make([]*StateVar, 0),
+	}
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	// the variables with declared layouts go first, other collections are recognized by their size keys
+	names := make([]string, 0, len(cfg.layouts))
+	for name := range cfg.layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, key := range keys {
+		if len(key) == 0 || key[len(key)-1] != 0 {
+			continue
+		}
+		if _, ok := cfg.layouts[key[:len(key)-1]]; !ok {
+			names = append(names, key[:len(key)-1])
+		}
+	}
+	consumed := make(map[string]bool)
+	for _, name := range names {
+		if consumed[name+"\x00"] {
+			continue
+		}
+		if v := cfg.collection(raw, name, cfg.collectionKind(raw, name, consumed), consumed); v != nil {
+			ret.Vars = append(ret.Vars, v)
+		}
+	}
+	for _, key := range keys {
+		if consumed[key] {
+			continue
+		}
+		ret.Vars = append(ret.Vars, &StateVar{
+			Name:  displayKey(key),
+			Kind:  StateScalar,
+			Value: cfg.decode(key, raw[key]),
+		})
+	}
+	sort.Slice(ret.Vars, func(i, j int) bool {
+		return ret.Vars[i].Name < ret.Vars[j].Name
+	})
+	return ret
+}
+
+// collectionElems returns values of the elements by key suffixes after the name and the element key code
+func collectionElems(raw map[string][]byte, name string, consumed map[string]bool) map[string][]byte {
+	prefix := name + "\x01"
+	ret := make(map[string][]byte)
+	for key, value := range raw {
+		if !consumed[key] && strings.HasPrefix(key, prefix) {
+			ret[key[len(prefix):]] = value
+		}
+	}
+	return ret
+}
+
+// collectionKind returns the declared layout of the variable. Only if no layout is declared,
+// the kind is recognized from the keys in the state with detectCollection
+func (cfg *stateInspectConfig) collectionKind(raw map[string][]byte, name string, consumed map[string]bool) StateVarKind {
+	if kind, ok := cfg.layouts[name]; ok {
+		return kind
+	}
+	return detectCollection(raw, name, consumed)
+}
+
+// detectCollection recognizes the layout of the collection by the size key and the keys of the elements
+func detectCollection(raw map[string][]byte, name string, consumed map[string]bool) StateVarKind {
+	size := raw[name+"\x00"]
+	elems := collectionElems(raw, name, consumed)
+	switch len(size) {
+	case 2:
+		n := util.MustUint16From2Bytes(size)
+		if len(elems) != int(n) {
+			return StateScalar
+		}
+		for suffix := range elems {
+			if len(suffix) != 2 || util.MustUint16From2Bytes([]byte(suffix)) >= n {
+				return StateScalar
+			}
+		}
+		return StateArray
+	case 4:
+		// maps, arrays with 32 bit indices and timestamped logs have the size of 4 bytes.
+		// Elements of timestamped logs are records with timestamps, which don't decrease with the index
+		n := util.MustUint32From4Bytes(size)
+		if len(elems) != int(n) {
+			return StateScalar
+		}
+		timestamps := make([]int64, n)
+		isLog := true
+		for suffix, value := range elems {
+			if len(suffix) != 4 || util.MustUint32From4Bytes([]byte(suffix)) >= n {
+				return StateMap
+			}
+			if rec, err := collections.ParseRawLogRecord(value); err == nil && rec.Timestamp > 0 {
+				timestamps[util.MustUint32From4Bytes([]byte(suffix))] = rec.Timestamp
+			} else {
+				isLog = false
+			}
+		}
+		for i := 1; isLog && i < len(timestamps); i++ {
+			isLog = timestamps[i-1] <= timestamps[i]
+		}
+		if isLog {
+			return StateTimestampedLog
+		}
+		return StateArray32
+	}
+	return StateScalar
+}
+
+func (cfg *stateInspectConfig) collection(raw map[string][]byte, name string, kind StateVarKind, consumed map[string]bool) *StateVar {
+	elems := collectionElems(raw, name, consumed)
+	sizeKey := name + "\x00"
+	if _, ok := raw[sizeKey]; !ok && len(elems) == 0 {
+		return nil
+	}
+	if kind == StateScalar {
+		return nil
+	}
+	consumed[sizeKey] = true
+	for suffix := range elems {
+		consumed[name+"\x01"+suffix] = true
+	}
+	ret := &StateVar{
+		Name:  displayKey(name),
+		Kind:  kind,
+		Elems: make([]*StateElem, 0, len(elems)),
+	}
+	suffixes := make([]string, 0, len(elems))
+	for suffix := range elems {
+		suffixes = append(suffixes, suffix)
+	}
+	// the indices are little endian, so the elements of arrays and logs are sorted by the decoded index
+	sort.Slice(suffixes, func(i, j int) bool {
+		idx1, ok1 := elemIndex(kind, suffixes[i])
+		idx2, ok2 := elemIndex(kind, suffixes[j])
+		if ok1 && ok2 {
+			return idx1 < idx2
+		}
+		if ok1 != ok2 {
+			return ok1
+		}
+		return suffixes[i] < suffixes[j]
+	})
+	for _, suffix := range suffixes {
+		value := elems[suffix]
+		elem := &StateElem{Key: displayKey(suffix)}
+		if idx, ok := elemIndex(kind, suffix); ok {
+			elem.Key = fmt.Sprintf("%d", idx)
+		}
+		if kind == StateTimestampedLog && len(suffix) == 4 {
+			if rec, err := collections.ParseRawLogRecord(value); err == nil {
+				elem.Timestamp = time.Unix(0, rec.Timestamp).UTC().Format(time.RFC3339Nano)
+				value = rec.Data
+			}
+		}
+		elem.Value = cfg.decode(name, value)
+		ret.Elems = append(ret.Elems, elem)
+	}
+	return ret
+}
+
+// elemIndex decodes the index of the element of the array or the timestamped log from the key suffix
+func elemIndex(kind StateVarKind, suffix string) (uint32, bool) {
+	switch {
+	case kind == StateArray && len(suffix) == 2:
+		return uint32(util.MustUint16From2Bytes([]byte(suffix))), true
+	case (kind == StateArray32 || kind == StateTimestampedLog) && len(suffix) == 4:
+		return util.MustUint32From4Bytes([]byte(suffix)), true
+	}
+	return 0, false
+}
+
+func (cfg *stateInspectConfig) decode(name string, value []byte) interface{} {
+	stateCodec, ok := cfg.codecs[name]
+	if !ok {
+		return displayKey(string(value))
+	}
+	v, err := stateCodec(value)
+	if err != nil {
+		return fmt.Sprintf("%s (can't decode: %v)", displayBytes(value), err)
+	}
+	switch vt := v.(type) {
+	case nil, bool, int, int32, int64, uint16, uint32, uint64, string, json.RawMessage:
+		return vt
+	case []byte:
+		return displayBytes(vt)
+	case fmt.Stringer:
+		return vt.String()
+	default:
+		return fmt.Sprintf("%v", vt)
+	}
+}
+
+// displayKey returns printable strings as they are and other data in hex
+func displayKey(s string) string {
+	if s == "" || !utf8.ValidString(s) {
+		return displayBytes([]byte(s))
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return displayBytes([]byte(s))
+		}
+	}
+	return s
+}
+
+func displayBytes(data []byte) string {
+	return "0x" + hex.EncodeToString(data)
+}
+
+// String renders the state as a tree
+func (s *ContractState) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "contract '%s' at block #%d:\n", s.Contract, s.BlockIndex)
+	for _, v := range s.Vars {
+		if v.Kind == StateScalar {
+			fmt.Fprintf(&buf, "    %s: %s\n", v.Name, renderValue(v.Value))
+			continue
+		}
+		fmt.Fprintf(&buf, "    %s (%s, %d):\n", v.Name, v.Kind, len(v.Elems))
+		for _, e := range v.Elems {
+			if e.Timestamp != "" {
+				fmt.Fprintf(&buf, "        %s [%s]: %s\n", e.Key, e.Timestamp, renderValue(e.Value))
+			} else {
+				fmt.Fprintf(&buf, "        %s: %s\n", e.Key, renderValue(e.Value))
+			}
+		}
+	}
+	return buf.String()
+}
+
+// JSON renders the state in JSON format
+func (s *ContractState) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// String renders the changes one per line
+func (d *StateDiff) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "contract '%s' changes from block #%d to #%d:\n", d.Contract, d.FromBlock, d.ToBlock)
+	for _, c := range d.Changes {
+		name := c.Var
+		if c.Kind != StateScalar {
+			name = fmt.Sprintf("%s[%s]", c.Var, c.Key)
+		}
+		switch {
+		case c.Old == nil:
+			fmt.Fprintf(&buf, "  + %s: %s\n", name, renderValue(c.New))
+		case c.New == nil:
+			fmt.Fprintf(&buf, "  - %s: %s\n", name, renderValue(c.Old))
+		default:
+			fmt.Fprintf(&buf, "  ~ %s: %s -> %s\n", name, renderValue(c.Old), renderValue(c.New))
+		}
+	}
+	return buf.String()
+}
+
+// JSON renders the diff in JSON format
+func (d *StateDiff) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// flatten returns values of scalars and elements as changes from nothing, by variable and key
+func (s *ContractState) flatten() map[string]*StateChange {
+	ret := make(map[string]*StateChange)
+	for _, v := range s.Vars {
+		if v.Kind == StateScalar {
+			c := &StateChange{Var: v.Name, Kind: v.Kind, New: v.Value}
+			ret[c.id()] = c
+			continue
+		}
+		for _, e := range v.Elems {
+			c := &StateChange{Var: v.Name, Kind: v.Kind, Key: e.Key, New: e.Value}
+			ret[c.id()] = c
+		}
+	}
+	return ret
+}
+
+func (c *StateChange) id() string {
+	return c.Var + "\x00" + c.Key
+}
+
+func renderValue(v interface{}) string {
+	if raw, ok := v.(json.RawMessage); ok {
+		return string(raw)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func sameValue(v1, v2 interface{}) bool {
+	return renderValue(v1) == renderValue(v2)
+}
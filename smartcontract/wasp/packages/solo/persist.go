@@ -0,0 +1,409 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/utxodb"
+	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/stretchr/testify/require"
+)
+
+// object types of the records 'solo' keeps in its own realm of the registry partition
+const (
+	objectTypeSoloEnv byte = iota
+	objectTypeSoloLedgerTx
+	objectTypeSoloKeyPair
+	objectTypeSoloChain
+)
+
+var soloRealm = []byte("solo")
+
+// NewPersistent creates an instance of the `solo` environment which is backed by the on-disk
+// key/value store in the directory 'dir'. The UTXO ledger, the registry blob cache and the
+// state and blocks of each chain are kept there, so that the environment can be restored
+// later with Open. The directory must not contain another 'solo' environment.
+// The database is closed automatically when the test finishes.
+func NewPersistent(t *testing.T, dir string, debug bool, printStackTrace bool) *Solo {
+	require.False(t, soloEnvExists(dir), "solo environment already exists in '%s'", dir)
+	env := newSolo(t, debug, printStackTrace, dir)
+	env.glbMutex.Lock()
+	env.persistEnv()
+	env.glbMutex.Unlock()
+	return env
+}
+
+// Open restores the `solo` environment which was saved in the directory 'dir' by NewPersistent.
+// The UTXO ledger is rebuilt from the saved transactions and every chain is restored with its
+// state, anchor transaction (StateTx), backlog and GasBudget. The logical clock continues where it stopped.
+// Signature schemes generated by the environment, including the originators of chains,
+// can be recovered with GetSignatureScheme.
+func Open(t *testing.T, dir string, debug bool, printStackTrace bool) *Solo {
+	require.True(t, soloEnvExists(dir), "no solo environment in '%s'", dir)
+	env := newSolo(t, debug, printStackTrace, dir)
+
+	data, err := env.store.Get(dbprovider.MakeKey(objectTypeSoloEnv))
+	require.NoError(t, err)
+	rdr := bytes.NewReader(data)
+	require.NoError(t, util.ReadTime(rdr, &env.logicalTime))
+	var step int64
+	require.NoError(t, util.ReadInt64(rdr, &step))
+	env.timeStep = time.Duration(step)
+
+	env.restoreLedger()
+	env.restoreKeyPairs()
+	env.restoreChains()
+	env.logger.Infof("solo environment restored from '%s'. Chains: %d", dir, len(env.chains))
+	return env
+}
+
+// Close closes the on-disk database of a persistent environment. The environment must not be used afterwards.
+// It does nothing for in-memory environments
+func (env *Solo) Close() {
+	if env.dbProvider == nil {
+		return
+	}
+	if !env.closed.CAS(false, true) {
+		return
+	}
+	env.glbMutex.Lock()
+	chains := make([]*Chain, 0, len(env.chains))
+	for _, ch := range env.chains {
+		chains = append(chains, ch)
+	}
+	env.glbMutex.Unlock()
+
+	// wait for batches in progress. The records are saved once more, because the fields
+	// like GasBudget can be changed without running a batch
+	for _, ch := range chains {
+		ch.runVMMutex.Lock()
+		ch.saveRecord()
+	}
+	env.dbProvider.Close()
+	for _, ch := range chains {
+		ch.runVMMutex.Unlock()
+	}
+}
+
+func (env *Solo) isClosed() bool {
+	return env.closed.Load()
+}
+
+// GetSignatureScheme returns the signature scheme of the address if it was generated by the environment
+func (env *Solo) GetSignatureScheme(addr address.Address) (signaturescheme.SignatureScheme, bool) {
+	env.glbMutex.Lock()
+	defer env.glbMutex.Unlock()
+	keyPair, ok := env.keyPairs[addr]
+	if !ok {
+		return nil, false
+	}
+	return signaturescheme.ED25519(keyPair), true
+}
+
+func soloEnvExists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "MANIFEST"))
+	return err == nil
+}
+
+func (env *Solo) isPersistent() bool {
+	return env.store != nil
+}
+
+// newKeyPair generates a new key pair and remembers it, so that it can be restored by Open
+func (env *Solo) newKeyPair() ed25519.KeyPair {
+	env.glbMutex.Lock()
+	defer env.glbMutex.Unlock()
+
+	keyPair := env.generateKeyPair()
+	addr := address.FromED25519PubKey(keyPair.PublicKey)
+	env.keyPairs[addr] = keyPair
+	if env.isPersistent() {
+		err := env.store.Set(dbprovider.MakeKey(objectTypeSoloKeyPair, addr[:]), keyPair.PrivateKey.Bytes())
+		require.NoError(env.T, err)
+	}
+	return keyPair
+}
+
+// addTransaction adds the transaction to the UTXODB and appends it to the saved ledger
+func (env *Solo) addTransaction(tx *valuetransaction.Transaction) error {
+	env.glbMutex.Lock()
+	defer env.glbMutex.Unlock()
+
+	if err := env.utxoDB.AddTransaction(tx); err != nil {
+		return err
+	}
+	env.appendLedger(tx)
+	return nil
+}
+
+// requestFunds requests funds from the UTXODB faucet and appends the transaction to the saved ledger
+func (env *Solo) requestFunds(addr address.Address) error {
+	env.glbMutex.Lock()
+	defer env.glbMutex.Unlock()
+
+	tx, err := env.utxoDB.RequestFunds(addr)
+	if err != nil {
+		return err
+	}
+	env.appendLedger(tx)
+	return nil
+}
+
+func (env *Solo) appendLedger(tx *valuetransaction.Transaction) {
+	env.ledger = append(env.ledger, tx)
+	env.record(&recordedStep{stepType: stepTransaction, tx: tx})
+	env.persistLedgerTx(uint32(len(env.ledger)-1), tx)
+}
+
+func (env *Solo) persistLedgerTx(seq uint32, tx *valuetransaction.Transaction) {
+	if !env.isPersistent() {
+		return
+	}
+	err := env.store.Set(dbprovider.MakeKey(objectTypeSoloLedgerTx, util.Uint32To4Bytes(seq)), tx.Bytes())
+	require.NoError(env.T, err)
+}
+
+// resetLedger replaces the UTXODB with the one built from the transactions. Must be called with glbMutex locked
+func (env *Solo) resetLedger(txs []*valuetransaction.Transaction) {
+	utxoDB := utxodb.New()
+	for _, tx := range txs {
+		err := utxoDB.AddTransaction(tx)
+		require.NoError(env.T, err)
+	}
+	env.utxoDB = utxoDB
+	env.ledger = txs
+	if !env.isPersistent() {
+		return
+	}
+	err := env.store.DeletePrefix([]byte{objectTypeSoloLedgerTx})
+	require.NoError(env.T, err)
+	for i, tx := range txs {
+		env.persistLedgerTx(uint32(i), tx)
+	}
+}
+
+// persistEnv saves the logical clock. Must be called with glbMutex locked
+func (env *Solo) persistEnv() {
+	if !env.isPersistent() || env.isClosed() {
+		return
+	}
+	var buf bytes.Buffer
+	_ = util.WriteTime(&buf, env.logicalTime)
+	_ = util.WriteInt64(&buf, int64(env.timeStep))
+	err := env.store.Set(dbprovider.MakeKey(objectTypeSoloEnv), buf.Bytes())
+	require.NoError(env.T, err)
+}
+
+func (env *Solo) restoreLedger() {
+	txs := make(map[uint32][]byte)
+	err := env.store.Iterate([]byte{objectTypeSoloLedgerTx}, func(key kvstore.Key, value kvstore.Value) bool {
+		txs[util.MustUint32From4Bytes(key[1:])] = value
+		return true
+	})
+	require.NoError(env.T, err)
+	// transactions are replayed in the original order, so all inputs are known when validated
+	for seq := uint32(0); seq < uint32(len(txs)); seq++ {
+		data, ok := txs[seq]
+		require.True(env.T, ok, "missing ledger transaction #%d", seq)
+		tx, _, err := valuetransaction.FromBytes(data)
+		require.NoError(env.T, err)
+		err = env.utxoDB.AddTransaction(tx)
+		require.NoError(env.T, err)
+		env.ledger = append(env.ledger, tx)
+	}
+}
+
+func (env *Solo) restoreKeyPairs() {
+	err := env.store.Iterate([]byte{objectTypeSoloKeyPair}, func(key kvstore.Key, value kvstore.Value) bool {
+		privateKey, err, _ := ed25519.PrivateKeyFromBytes(value)
+		require.NoError(env.T, err)
+		keyPair := ed25519.KeyPair{PrivateKey: privateKey, PublicKey: privateKey.Public()}
+		env.keyPairs[address.FromED25519PubKey(keyPair.PublicKey)] = keyPair
+		return true
+	})
+	require.NoError(env.T, err)
+}
+
+// chainRecord is what is saved about the chain besides its state
+type chainRecord struct {
+	name               string
+	chainAddress       address.Address
+	originatorAddress  address.Address
+	validatorFeeTarget coretypes.AgentID
+	gasBudget          uint64
+	stateTxID          valuetransaction.ID
+	backlog            []sctransaction.RequestRef
+}
+
+func (rec *chainRecord) Write(w io.Writer) error {
+	if err := util.WriteString16(w, rec.name); err != nil {
+		return err
+	}
+	if _, err := w.Write(rec.chainAddress[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(rec.originatorAddress[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(rec.validatorFeeTarget[:]); err != nil {
+		return err
+	}
+	if err := util.WriteUint64(w, rec.gasBudget); err != nil {
+		return err
+	}
+	if _, err := w.Write(rec.stateTxID[:]); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(rec.backlog))); err != nil {
+		return err
+	}
+	for _, ref := range rec.backlog {
+		txid := ref.Tx.ID()
+		if _, err := w.Write(txid[:]); err != nil {
+			return err
+		}
+		if err := util.WriteUint16(w, ref.Index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read reads the record. The transactions of the backlog are only known by ID,
+// they are looked up in the ledger by the caller
+func (rec *chainRecord) Read(r io.Reader, backlogTxIDs *[]valuetransaction.ID) error {
+	var err error
+	if rec.name, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	if _, err = io.ReadFull(r, rec.chainAddress[:]); err != nil {
+		return err
+	}
+	if _, err = io.ReadFull(r, rec.originatorAddress[:]); err != nil {
+		return err
+	}
+	if err = coretypes.ReadAgentID(r, &rec.validatorFeeTarget); err != nil {
+		return err
+	}
+	if err = util.ReadUint64(r, &rec.gasBudget); err != nil {
+		return err
+	}
+	if err = util.ReadTransactionId(r, &rec.stateTxID); err != nil {
+		return err
+	}
+	var size uint16
+	if err = util.ReadUint16(r, &size); err != nil {
+		return err
+	}
+	rec.backlog = make([]sctransaction.RequestRef, size)
+	*backlogTxIDs = make([]valuetransaction.ID, size)
+	for i := range rec.backlog {
+		if err = util.ReadTransactionId(r, &(*backlogTxIDs)[i]); err != nil {
+			return err
+		}
+		if err = util.ReadUint16(r, &rec.backlog[i].Index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// persist saves the chain record with the current StateTx and backlog
+func (ch *Chain) persist() {
+	if !ch.Env.isPersistent() || ch.Env.isClosed() {
+		return
+	}
+	ch.saveRecord()
+}
+
+// saveRecord saves the chain record, the database must be open
+func (ch *Chain) saveRecord() {
+	ch.backlogMutex.Lock()
+	rec := &chainRecord{
+		name:               ch.Name,
+		chainAddress:       ch.ChainAddress,
+		originatorAddress:  ch.OriginatorAddress,
+		validatorFeeTarget: ch.ValidatorFeeTarget,
+		gasBudget:          ch.GasBudget,
+		stateTxID:          ch.StateTx.ID(),
+		backlog:            append([]sctransaction.RequestRef{}, ch.backlog...),
+	}
+	ch.backlogMutex.Unlock()
+
+	data, err := util.Bytes(rec)
+	require.NoError(ch.Env.T, err)
+	err = ch.Env.store.Set(dbprovider.MakeKey(objectTypeSoloChain, ch.ChainID[:]), data)
+	require.NoError(ch.Env.T, err)
+}
+
+func (env *Solo) restoreChains() {
+	err := env.store.Iterate([]byte{objectTypeSoloChain}, func(key kvstore.Key, value kvstore.Value) bool {
+		env.restoreChain(value)
+		return true
+	})
+	require.NoError(env.T, err)
+}
+
+func (env *Solo) restoreChain(data []byte) {
+	rec := &chainRecord{}
+	var backlogTxIDs []valuetransaction.ID
+	err := rec.Read(bytes.NewReader(data), &backlogTxIDs)
+	require.NoError(env.T, err)
+
+	chainSig, ok := env.GetSignatureScheme(rec.chainAddress)
+	require.True(env.T, ok, "key pair of chain '%s' not found", rec.name)
+	originatorSig, ok := env.GetSignatureScheme(rec.originatorAddress)
+	if !ok {
+		env.logger.Warnf("originator of chain '%s' was not generated by solo. OriginatorSigScheme will be nil", rec.name)
+	}
+	ch := env.newChainInstance(rec.name, chainSig, originatorSig, rec.originatorAddress, rec.validatorFeeTarget)
+	ch.db = env.chainPartition(ch.ChainID)
+	ch.GasBudget = rec.gasBudget
+
+	vs, _, ok, err := state.LoadSolidStateFromDB(ch.db, &ch.ChainID)
+	require.NoError(env.T, err)
+	require.True(env.T, ok, "solid state of chain '%s' not found", rec.name)
+	ch.State = vs
+	ch.StateTx = env.mustGetSCTransaction(rec.stateTxID)
+	ch.ChainColor = ch.StateTx.MustState().Color()
+	if ch.ChainColor == balance.ColorNew {
+		ch.ChainColor = balance.Color(ch.StateTx.ID())
+	}
+
+	for i := range rec.backlog {
+		rec.backlog[i].Tx = env.mustGetSCTransaction(backlogTxIDs[i])
+	}
+	ch.backlog = rec.backlog
+
+	ch.Log.Infof("chain '%s' restored. Chain ID: %s, block index: %d, backlog: %d",
+		ch.Name, ch.ChainID, ch.State.BlockIndex(), len(ch.backlog))
+
+	env.chains[ch.ChainID] = ch
+	go ch.readRequestsLoop()
+	go ch.batchLoop()
+}
+
+func (env *Solo) mustGetSCTransaction(txid valuetransaction.ID) *sctransaction.Transaction {
+	vtx, ok := env.utxoDB.GetTransaction(txid)
+	require.True(env.T, ok, "transaction %s not found in the ledger", txid.String())
+	tx, err := sctransaction.ParseValueTransaction(vtx)
+	require.NoError(env.T, err)
+	return tx
+}
@@ -0,0 +1,221 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/publisher"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/stretchr/testify/require"
+)
+
+// Receipt describes what the request did on the chain. It is returned by PostRequestWithReceipt
+type Receipt struct {
+	// RequestID is the ID of the request
+	RequestID coretypes.RequestID
+	// BlockIndex is the index of the block which contains the request
+	BlockIndex uint32
+	// Result is the result returned by the entry point, Error is the error returned by the request, if any
+	Result dict.Dict
+	Error  error
+	// GasUsed is the gas used by the request, see Chain.GasBudget
+	GasUsed uint64
+	// FeeColor, OwnerFee and ValidatorFee are the fees charged for the request.
+	// The fees are not charged if the sender is the chain owner or the transfer is not enough to cover them
+	FeeColor     balance.Color
+	OwnerFee     int64
+	ValidatorFee int64
+	// BalanceDeltas are the changes of balances of on-chain accounts. Only non-zero changes are included
+	BalanceDeltas map[coretypes.AgentID]map[balance.Color]int64
+	// StateWrites are the keys of the state written by the request, with new values. StateDeletes are the deleted keys.
+	// The keys include the hname prefix of the contract partition
+	StateWrites  map[kv.Key][]byte
+	StateDeletes []kv.Key
+	// Events are the events published through Sandbox.Event, in the order of publishing
+	Events []ReceiptEvent
+	// PostedRequests are the requests posted onward by the request with Sandbox.PostRequest
+	PostedRequests []sctransaction.RequestRef
+	// Trace is the trace of calls of the request. Nil if the call tracing is off, see Chain.SetCallTracing
+	Trace *vm.RequestTrace
+}
+
+// ReceiptEvent is the event published by the contract while processing the request
+type ReceiptEvent struct {
+	Contract coretypes.Hname
+	Message  string
+}
+
+// PostRequestWithReceipt posts the request like PostRequest and returns the receipt with the effects
+// of the request on the chain. The error is returned if the request was not run by the VM,
+// the error of the request itself is in Receipt.Error
+func (ch *Chain) PostRequestWithReceipt(req *CallParams, sigScheme signaturescheme.SignatureScheme) (*Receipt, error) {
+	receipt := &Receipt{}
+	res, err := ch.postRequest(req, sigScheme, receipt)
+	if receipt.BlockIndex == 0 {
+		return nil, err
+	}
+	receipt.Result = res
+	receipt.Error = err
+	return receipt, nil
+}
+
+// BalanceDelta returns the change of the balance of the account in tokens of the color
+func (r *Receipt) BalanceDelta(agentID coretypes.AgentID, col balance.Color) int64 {
+	return r.BalanceDeltas[agentID][col]
+}
+
+// String returns human readable form of the receipt
+func (r *Receipt) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Request ID: %s, block index: #%d\n", r.RequestID.String(), r.BlockIndex)
+	if r.Error != nil {
+		fmt.Fprintf(&buf, "Error: %v\n", r.Error)
+	}
+	fmt.Fprintf(&buf, "Gas used: %d\n", r.GasUsed)
+	fmt.Fprintf(&buf, "Fees: owner %d, validator %d of color %s\n", r.OwnerFee, r.ValidatorFee, r.FeeColor.String())
+	fmt.Fprintf(&buf, "Balance deltas:\n")
+	for agentID, deltas := range r.BalanceDeltas {
+		fmt.Fprintf(&buf, "    %s:\n", agentID.String())
+		for col, delta := range deltas {
+			fmt.Fprintf(&buf, "        %s: %+d\n", col.String(), delta)
+		}
+	}
+	fmt.Fprintf(&buf, "State writes: %d, deletes: %d\n", len(r.StateWrites), len(r.StateDeletes))
+	for _, e := range r.Events {
+		fmt.Fprintf(&buf, "Event %s: %s\n", e.Contract.String(), e.Message)
+	}
+	for _, ref := range r.PostedRequests {
+		fmt.Fprintf(&buf, "Posted request %s to %s\n", ref.RequestID().String(), ref.RequestSection().Target().String())
+	}
+	if r.Trace != nil {
+		buf.WriteString(r.Trace.String())
+	}
+	return buf.String()
+}
+
+// startReceipt starts to collect the receipt of the request run by the task. Must be called with runVMMutex locked.
+// The returned function completes the receipt after the state transition is settled
+func (ch *Chain) startReceipt(receipt *Receipt, task *vm.VMTask, reqRef *vm.RequestRefWithFreeTokens) func(dict.Dict, error, state.Block) {
+	receipt.RequestID = *reqRef.RequestID()
+	task.OnRequestFees = func(reqID *coretypes.RequestID, feeColor balance.Color, ownerFee, validatorFee int64) {
+		if *reqID == receipt.RequestID {
+			receipt.FeeColor, receipt.OwnerFee, receipt.ValidatorFee = feeColor, ownerFee, validatorFee
+		}
+	}
+	balancesBefore := ch.accountBalancesNoLock()
+
+	// a fork of the chain has the same chain ID, so the events are also filtered by the request
+	chainIDStr := ch.ChainID.String()
+	reqIDStr := receipt.RequestID.Base58()
+	receipt.Events = make([]ReceiptEvent, 0)
+	onEvent := events.NewClosure(func(msg *publisher.Message) {
+		if msg.Type != publisher.MsgVM || msg.ChainID != chainIDStr || msg.RequestID != reqIDStr {
+			return
+		}
+		hname, err := coretypes.HnameFromString(msg.Contract)
+		require.NoError(ch.Env.T, err)
+		receipt.Events = append(receipt.Events, ReceiptEvent{
+			Contract: hname,
+			Message:  msg.Payload[publisher.PayloadMessage],
+		})
+	})
+	publisher.MessageEvent.Attach(onEvent)
+
+	return func(_ dict.Dict, _ error, block state.Block) {
+		publisher.MessageEvent.Detach(onEvent)
+
+		receipt.BlockIndex = block.StateIndex()
+		partition := subrealm.New(ch.State.Variables(), kv.Key(eventlog.Interface.Hname().Bytes()))
+		rec, err := eventlog.GetRequestReceipt(partition, &receipt.RequestID)
+		require.NoError(ch.Env.T, err)
+		if rec != nil {
+			receipt.GasUsed = rec.GasUsed
+		}
+		receipt.BalanceDeltas = balanceDeltas(balancesBefore, ch.accountBalancesNoLock())
+		receipt.StateWrites = make(map[kv.Key][]byte)
+		receipt.StateDeletes = make([]kv.Key, 0)
+		block.ForEach(func(_ uint16, stateUpd state.StateUpdate) bool {
+			stateUpd.Mutations().IterateLatest(func(key kv.Key, mut buffered.Mutation) bool {
+				if mut.Value() == nil {
+					receipt.StateDeletes = append(receipt.StateDeletes, key)
+				} else {
+					receipt.StateWrites[key] = mut.Value()
+				}
+				return true
+			})
+			return true
+		})
+		sort.Slice(receipt.StateDeletes, func(i, j int) bool {
+			return receipt.StateDeletes[i] < receipt.StateDeletes[j]
+		})
+		receipt.PostedRequests = make([]sctransaction.RequestRef, len(ch.StateTx.Requests()))
+		for i := range receipt.PostedRequests {
+			receipt.PostedRequests[i] = sctransaction.RequestRef{Tx: ch.StateTx, Index: uint16(i)}
+		}
+		receipt.Trace = ch.callTraces[receipt.RequestID]
+	}
+}
+
+// accountBalancesNoLock returns balances of all on-chain accounts. Must be called with runVMMutex locked
+func (ch *Chain) accountBalancesNoLock() map[coretypes.AgentID]map[balance.Color]int64 {
+	res, err := ch.callViewNoLock(accounts.Interface.Name, accounts.FuncAccounts, nil)
+	require.NoError(ch.Env.T, err)
+	ret := make(map[coretypes.AgentID]map[balance.Color]int64)
+	for _, key := range res.KeysSorted() {
+		agentID, _, err := codec.DecodeAgentID([]byte(key))
+		require.NoError(ch.Env.T, err)
+		bals, err := ch.callViewNoLock(accounts.Interface.Name, accounts.FuncBalance, codec.MakeDict(map[string]interface{}{
+			accounts.ParamAgentID: agentID,
+		}))
+		require.NoError(ch.Env.T, err)
+		ret[agentID], err = accounts.DecodeBalances(bals)
+		require.NoError(ch.Env.T, err)
+	}
+	return ret
+}
+
+func balanceDeltas(before, after map[coretypes.AgentID]map[balance.Color]int64) map[coretypes.AgentID]map[balance.Color]int64 {
+	ret := make(map[coretypes.AgentID]map[balance.Color]int64)
+	add := func(agentID coretypes.AgentID, bals map[balance.Color]int64, sign int64) {
+		for col, bal := range bals {
+			if _, ok := ret[agentID]; !ok {
+				ret[agentID] = make(map[balance.Color]int64)
+			}
+			ret[agentID][col] += sign * bal
+		}
+	}
+	for agentID, bals := range after {
+		add(agentID, bals, 1)
+	}
+	for agentID, bals := range before {
+		add(agentID, bals, -1)
+	}
+	for agentID, deltas := range ret {
+		for col, delta := range deltas {
+			if delta == 0 {
+				delete(deltas, col)
+			}
+		}
+		if len(deltas) == 0 {
+			delete(ret, agentID)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,433 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/stretchr/testify/require"
+)
+
+// SeededLogicalTime is the initial logical time of an environment with a seed
+var SeededLogicalTime = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// WithSeed makes the environment deterministic. All key pairs generated by the environment
+// are derived from the seed, the logical clock starts at SeededLogicalTime and the entropy
+// of each batch is derived from the seed, the chain ID and the block index.
+// Two runs of the same test with the same seed produce the same chains with the same state hashes,
+// as long as the requests are processed in the same batches.
+// Must be called before any chain or signature scheme is created
+func (env *Solo) WithSeed(seed []byte) *Solo {
+	env.glbMutex.Lock()
+	defer env.glbMutex.Unlock()
+
+	require.True(env.T, len(env.chains) == 0 && len(env.keyPairs) == 0, "WithSeed must be called on a new environment")
+	env.seed = append([]byte{}, seed...)
+	env.keySeed = ed25519.NewSeed(hashing.HashData(seed).Bytes())
+	env.logicalTime = SeededLogicalTime
+	env.persistEnv()
+	return env
+}
+
+// generateKeyPair returns the next key pair derived from the seed, or a random one if there is no seed.
+// Must be called with glbMutex locked
+func (env *Solo) generateKeyPair() ed25519.KeyPair {
+	if env.keySeed == nil {
+		return ed25519.GenerateKeyPair()
+	}
+	ret := env.keySeed.KeyPair(env.keyIndex)
+	env.keyIndex++
+	if env.recording != nil {
+		env.recording.numKeyPairs = env.keyIndex
+	}
+	return *ret
+}
+
+// entropy returns the entropy for the next block of the chain
+func (ch *Chain) entropy() hashing.HashValue {
+	if ch.Env.seed == nil {
+		return hashing.RandomHash(nil)
+	}
+	return hashing.HashData(ch.Env.seed, ch.ChainID[:], util.Uint32To4Bytes(ch.State.BlockIndex()+1))
+}
+
+// Recording is the record of everything which happened in the environment which influences the state of chains:
+// transactions added to the UTXODB ledger, chains deployed, blobs put into the registry and
+// the batches of requests run by the VM, together with the logical time of each batch.
+// The recording can be saved with Bytes and replayed with Replay to reproduce the run,
+// including the order in which requests were put into batches
+type Recording struct {
+	seed        []byte
+	numKeyPairs uint64
+	steps       []*recordedStep
+}
+
+const (
+	stepTransaction byte = iota
+	stepNewChain
+	stepBlob
+	stepBatch
+)
+
+type recordedStep struct {
+	stepType byte
+	// stepTransaction
+	tx *valuetransaction.Transaction
+	// stepNewChain
+	name               string
+	chainAddress       address.Address
+	originatorAddress  address.Address
+	validatorFeeTarget coretypes.AgentID
+	originTxID         valuetransaction.ID
+	// stepBlob
+	data []byte
+	// stepBatch
+	chainID    coretypes.ChainID
+	requests   []coretypes.RequestID
+	timestamp  int64
+	blockIndex uint32
+	stateHash  hashing.HashValue
+}
+
+// StartRecording starts to record the run. The environment must have a seed (see WithSeed),
+// the recording must be started before any chain is deployed.
+// The returned recording grows while the test runs
+func (env *Solo) StartRecording() *Recording {
+	env.glbMutex.Lock()
+	defer env.glbMutex.Unlock()
+
+	require.NotNil(env.T, env.seed, "recording requires the environment with a seed")
+	require.True(env.T, len(env.chains) == 0, "recording must be started before chains are deployed")
+	env.recording = &Recording{
+		seed:        env.seed,
+		numKeyPairs: env.keyIndex,
+		steps:       make([]*recordedStep, 0),
+	}
+	for _, tx := range env.ledger {
+		env.recording.steps = append(env.recording.steps, &recordedStep{stepType: stepTransaction, tx: tx})
+	}
+	return env.recording
+}
+
+// record appends the step to the recording if it is on. Must be called with glbMutex locked
+func (env *Solo) record(step *recordedStep) {
+	if env.recording != nil {
+		env.recording.steps = append(env.recording.steps, step)
+	}
+}
+
+func (env *Solo) recordNewChain(ch *Chain) {
+	env.glbMutex.Lock()
+	defer env.glbMutex.Unlock()
+	env.record(&recordedStep{
+		stepType:           stepNewChain,
+		name:               ch.Name,
+		chainAddress:       ch.ChainAddress,
+		originatorAddress:  ch.OriginatorAddress,
+		validatorFeeTarget: ch.ValidatorFeeTarget,
+		originTxID:         ch.StateTx.ID(),
+	})
+}
+
+func (env *Solo) recordBlob(data []byte) {
+	env.glbMutex.Lock()
+	defer env.glbMutex.Unlock()
+	env.record(&recordedStep{stepType: stepBlob, data: data})
+}
+
+// recordBatch records the batch before it is run. The result is filled in by the returned function
+func (ch *Chain) recordBatch(batch []vm.RequestRefWithFreeTokens, timestamp int64) func() {
+	ch.Env.glbMutex.Lock()
+	defer ch.Env.glbMutex.Unlock()
+
+	if ch.Env.recording == nil {
+		return func() {}
+	}
+	step := &recordedStep{
+		stepType:  stepBatch,
+		chainID:   ch.ChainID,
+		requests:  make([]coretypes.RequestID, len(batch)),
+		timestamp: timestamp,
+	}
+	for i := range batch {
+		step.requests[i] = *batch[i].RequestID()
+	}
+	ch.Env.record(step)
+	return func() {
+		step.blockIndex = ch.State.BlockIndex()
+		step.stateHash = ch.State.Hash()
+	}
+}
+
+// ChainIDs returns IDs of all chains deployed in the recorded run
+func (rec *Recording) ChainIDs() []coretypes.ChainID {
+	ret := make([]coretypes.ChainID, 0)
+	for _, step := range rec.steps {
+		if step.stepType == stepNewChain {
+			ret = append(ret, coretypes.ChainID(step.chainAddress))
+		}
+	}
+	return ret
+}
+
+// Bytes returns the recording in binary form, which can be stored and restored with RecordingFromBytes
+func (rec *Recording) Bytes() []byte {
+	return util.MustBytes(rec)
+}
+
+// RecordingFromBytes restores the recording saved with Recording.Bytes
+func RecordingFromBytes(data []byte) (*Recording, error) {
+	ret := &Recording{}
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (rec *Recording) Write(w io.Writer) error {
+	if err := util.WriteBytes16(w, rec.seed); err != nil {
+		return err
+	}
+	if err := util.WriteUint64(w, rec.numKeyPairs); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, uint32(len(rec.steps))); err != nil {
+		return err
+	}
+	for _, step := range rec.steps {
+		if err := step.Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rec *Recording) Read(r io.Reader) error {
+	var err error
+	if rec.seed, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	if err = util.ReadUint64(r, &rec.numKeyPairs); err != nil {
+		return err
+	}
+	var size uint32
+	if err = util.ReadUint32(r, &size); err != nil {
+		return err
+	}
+	rec.steps = make([]*recordedStep, size)
+	for i := range rec.steps {
+		rec.steps[i] = &recordedStep{}
+		if err = rec.steps[i].Read(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (step *recordedStep) Write(w io.Writer) error {
+	if err := util.WriteByte(w, step.stepType); err != nil {
+		return err
+	}
+	switch step.stepType {
+	case stepTransaction:
+		return util.WriteBytes32(w, step.tx.Bytes())
+	case stepNewChain:
+		if err := util.WriteString16(w, step.name); err != nil {
+			return err
+		}
+		if _, err := w.Write(step.chainAddress[:]); err != nil {
+			return err
+		}
+		if _, err := w.Write(step.originatorAddress[:]); err != nil {
+			return err
+		}
+		if _, err := w.Write(step.validatorFeeTarget[:]); err != nil {
+			return err
+		}
+		_, err := w.Write(step.originTxID[:])
+		return err
+	case stepBlob:
+		return util.WriteBytes32(w, step.data)
+	case stepBatch:
+		if _, err := w.Write(step.chainID[:]); err != nil {
+			return err
+		}
+		if err := util.WriteUint16(w, uint16(len(step.requests))); err != nil {
+			return err
+		}
+		for i := range step.requests {
+			if err := step.requests[i].Write(w); err != nil {
+				return err
+			}
+		}
+		if err := util.WriteInt64(w, step.timestamp); err != nil {
+			return err
+		}
+		if err := util.WriteUint32(w, step.blockIndex); err != nil {
+			return err
+		}
+		_, err := w.Write(step.stateHash[:])
+		return err
+	}
+	return fmt.Errorf("wrong step type %d", step.stepType)
+}
+
+func (step *recordedStep) Read(r io.Reader) error {
+	var err error
+	if step.stepType, err = util.ReadByte(r); err != nil {
+		return err
+	}
+	switch step.stepType {
+	case stepTransaction:
+		var data []byte
+		if data, err = util.ReadBytes32(r); err != nil {
+			return err
+		}
+		step.tx, _, err = valuetransaction.FromBytes(data)
+		return err
+	case stepNewChain:
+		if step.name, err = util.ReadString16(r); err != nil {
+			return err
+		}
+		if _, err = io.ReadFull(r, step.chainAddress[:]); err != nil {
+			return err
+		}
+		if _, err = io.ReadFull(r, step.originatorAddress[:]); err != nil {
+			return err
+		}
+		if err = coretypes.ReadAgentID(r, &step.validatorFeeTarget); err != nil {
+			return err
+		}
+		return util.ReadTransactionId(r, &step.originTxID)
+	case stepBlob:
+		step.data, err = util.ReadBytes32(r)
+		return err
+	case stepBatch:
+		if _, err = io.ReadFull(r, step.chainID[:]); err != nil {
+			return err
+		}
+		var size uint16
+		if err = util.ReadUint16(r, &size); err != nil {
+			return err
+		}
+		step.requests = make([]coretypes.RequestID, size)
+		for i := range step.requests {
+			if err = step.requests[i].Read(r); err != nil {
+				return err
+			}
+		}
+		if err = util.ReadInt64(r, &step.timestamp); err != nil {
+			return err
+		}
+		if err = util.ReadUint32(r, &step.blockIndex); err != nil {
+			return err
+		}
+		return util.ReadHashValue(r, &step.stateHash)
+	}
+	return fmt.Errorf("wrong step type %d", step.stepType)
+}
+
+// Replay creates a new environment with the seed of the recording and repeats the recorded run in it:
+// the same chains are deployed and the same batches of requests are run at the same logical time.
+// The state hash of every chain is checked after each batch, so the replay fails at the first batch
+// where the state differs from the recorded one.
+// When the replay is finished the chains in the environment are fully functional
+func Replay(t *testing.T, rec *Recording, debug bool, printStackTrace bool) *Solo {
+	env := New(t, debug, printStackTrace).WithSeed(rec.seed)
+	for i := uint64(0); i < rec.numKeyPairs; i++ {
+		env.newKeyPair()
+	}
+	processed := make(map[coretypes.RequestID]bool)
+	for i, step := range rec.steps {
+		switch step.stepType {
+		case stepTransaction:
+			// state transactions are already in the ledger, produced by the replayed batches
+			txid := step.tx.ID()
+			if !env.utxoDB.IsConfirmed(&txid) {
+				err := env.addTransaction(step.tx)
+				require.NoError(t, err, "replay step #%d", i)
+			}
+		case stepNewChain:
+			env.replayNewChain(step)
+		case stepBlob:
+			env.PutBlobDataIntoRegistry(step.data)
+		case stepBatch:
+			ch := env.GetChain(step.chainID)
+			require.NotNil(t, ch, "replay step #%d: unknown chain", i)
+			batch := make([]vm.RequestRefWithFreeTokens, len(step.requests))
+			for j, reqID := range step.requests {
+				batch[j].Tx = env.mustGetSCTransaction(*reqID.TransactionID())
+				batch[j].Index = reqID.Index()
+				processed[reqID] = true
+			}
+			env.glbMutex.Lock()
+			env.logicalTime = time.Unix(0, step.timestamp)
+			env.glbMutex.Unlock()
+
+			_, _ = ch.runBatch(batch, "replay")
+			require.EqualValues(t, step.blockIndex, ch.State.BlockIndex(), "replay step #%d", i)
+			require.EqualValues(t, step.stateHash, ch.State.Hash(), "replay step #%d: state hash differs", i)
+		}
+	}
+	// requests dispatched to chains during replay and processed by the replayed batches are removed from backlogs
+	for _, ch := range env.chains {
+		ch.chPosted.Wait()
+		ch.backlogMutex.Lock()
+		backlog := ch.backlog[:0]
+		for _, ref := range ch.backlog {
+			if !processed[*ref.RequestID()] {
+				backlog = append(backlog, ref)
+			}
+		}
+		ch.backlog = backlog
+		ch.backlogMutex.Unlock()
+		go ch.batchLoop()
+	}
+	env.logger.Infof("replayed %d steps", len(rec.steps))
+	return env
+}
+
+// replayNewChain deploys the chain from the recorded origin transaction.
+// Batches are not run automatically during replay
+func (env *Solo) replayNewChain(step *recordedStep) {
+	chainSig, ok := env.GetSignatureScheme(step.chainAddress)
+	require.True(env.T, ok, "replay: key pair of chain '%s' not found", step.name)
+	originatorSig, _ := env.GetSignatureScheme(step.originatorAddress)
+	ch := env.newChainInstance(step.name, chainSig, originatorSig, step.originatorAddress, step.validatorFeeTarget)
+	ch.db = env.chainPartition(ch.ChainID)
+	ch.State = state.NewVirtualState(ch.db, &ch.ChainID)
+	ch.setOrigin(env.mustGetSCTransaction(step.originTxID))
+
+	env.glbMutex.Lock()
+	env.chains[ch.ChainID] = ch
+	env.glbMutex.Unlock()
+
+	go ch.readRequestsLoop()
+}
+
+// setOrigin makes the origin transaction the anchor of the empty state and commits the origin block
+func (ch *Chain) setOrigin(originTx *sctransaction.Transaction) {
+	ch.StateTx = originTx
+	ch.ChainColor = balance.Color(originTx.ID())
+
+	originBlock := state.MustNewOriginBlock(&ch.ChainColor)
+	err := ch.State.ApplyBlock(originBlock)
+	require.NoError(ch.Env.T, err)
+	err = ch.State.CommitToDb(originBlock)
+	require.NoError(ch.Env.T, err)
+}
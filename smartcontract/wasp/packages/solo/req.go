@@ -29,6 +29,7 @@ type CallParams struct {
 	entryPoint coretypes.Hname
 	transfer   coretypes.ColoredBalances
 	args       requestargs.RequestArgs
+	timelock   uint32
 }
 
 func NewCallParamsFromDic(scName, funName string, par dict.Dict) *CallParams {
@@ -86,6 +87,13 @@ func (r *CallParams) WithTransfers(transfer map[balance.Color]int64) *CallParams
 	return r
 }
 
+// WithTimeLock complements CallParams structure with the time lock. The request stays in the backlog of the chain
+// until the logical clock reaches the deadline. Time locked requests must be posted with PostRequestAsync
+func (r *CallParams) WithTimeLock(deadline time.Time) *CallParams {
+	r.timelock = uint32(deadline.Unix())
+	return r
+}
+
 // makes map without hashing
 func toMap(params ...interface{}) map[string]interface{} {
 	par := make(map[string]interface{})
@@ -120,6 +128,27 @@ func toMap(params ...interface{}) map[string]interface{} {
 // Unlike the real Wasp environment, the 'solo' environment makes PostRequest a synchronous call.
 // It makes it possible step-by-step debug of the smart contract logic.
 func (ch *Chain) PostRequest(req *CallParams, sigScheme signaturescheme.SignatureScheme) (dict.Dict, error) {
+	return ch.postRequest(req, sigScheme, nil)
+}
+
+func (ch *Chain) postRequest(req *CallParams, sigScheme signaturescheme.SignatureScheme, receipt *Receipt) (dict.Dict, error) {
+	if int64(req.timelock) > ch.Env.LogicalTime().Unix() {
+		return nil, fmt.Errorf("PostRequest: time locked request must be posted with PostRequestAsync")
+	}
+	tx, err := ch.requestTransaction(req, sigScheme)
+	if err != nil {
+		return nil, err
+	}
+	reqID := coretypes.NewRequestID(tx.ID(), 0)
+	ch.Log.Infof("PostRequest: %s::%s -- %s", req.targetName, req.epName, reqID.String())
+
+	r := vm.RequestRefWithFreeTokens{}
+	r.Tx = tx
+	return ch.runBatchWithReceipt([]vm.RequestRefWithFreeTokens{r}, "post", receipt)
+}
+
+// requestTransaction creates the request transaction and adds it to the UTXODB
+func (ch *Chain) requestTransaction(req *CallParams, sigScheme signaturescheme.SignatureScheme) (*sctransaction.Transaction, error) {
 	if sigScheme == nil {
 		sigScheme = ch.OriginatorSigScheme
 	}
@@ -129,7 +158,8 @@ func (ch *Chain) PostRequest(req *CallParams, sigScheme signaturescheme.Signatur
 
 	reqSect := sctransaction.NewRequestSectionByWallet(coretypes.NewContractID(ch.ChainID, req.target), req.entryPoint).
 		WithTransfer(req.transfer).
-		WithArgs(req.args)
+		WithArgs(req.args).
+		WithTimelock(req.timelock)
 
 	err = txb.AddRequestSection(reqSect)
 	require.NoError(ch.Env.T, err)
@@ -138,17 +168,11 @@ func (ch *Chain) PostRequest(req *CallParams, sigScheme signaturescheme.Signatur
 	require.NoError(ch.Env.T, err)
 
 	tx.Sign(sigScheme)
-	err = ch.Env.utxoDB.AddTransaction(tx.Transaction)
+	err = ch.Env.addTransaction(tx.Transaction)
 	if err != nil {
 		return nil, err
 	}
-
-	reqID := coretypes.NewRequestID(tx.ID(), 0)
-	ch.Log.Infof("PostRequest: %s::%s -- %s", req.targetName, req.epName, reqID.String())
-
-	r := vm.RequestRefWithFreeTokens{}
-	r.Tx = tx
-	return ch.runBatch([]vm.RequestRefWithFreeTokens{r}, "post")
+	return tx, nil
 }
 
 // callViewFull calls the view entry point of the smart contract
@@ -157,7 +181,7 @@ func (ch *Chain) callViewFull(req *CallParams) (dict.Dict, error) {
 	ch.runVMMutex.Lock()
 	defer ch.runVMMutex.Unlock()
 
-	vctx := viewcontext.New(ch.ChainID, ch.State.Variables(), ch.State.Timestamp(), ch.proc, ch.Log)
+	vctx := viewcontext.New(ch.ChainID, ch.State.Variables(), ch.State.Timestamp(), ch.State.BlockIndex(), ch.proc, ch.Log)
 	a, ok, err := req.args.SolidifyRequestArguments(ch.Env.registry)
 	if err != nil || !ok {
 		return nil, fmt.Errorf("solo.internal error: can't solidify args")
@@ -176,13 +200,19 @@ func (ch *Chain) CallView(scName string, funName string, params ...interface{})
 	ch.runVMMutex.Lock()
 	defer ch.runVMMutex.Unlock()
 
-	vctx := viewcontext.New(ch.ChainID, ch.State.Variables(), ch.State.Timestamp(), ch.proc, ch.Log)
-	return vctx.CallView(coretypes.Hn(scName), coretypes.Hn(funName), p)
+	return ch.callViewNoLock(scName, funName, p)
+}
+
+// callViewNoLock calls the view on the current state. Must be called with runVMMutex locked
+func (ch *Chain) callViewNoLock(scName string, funName string, params dict.Dict) (dict.Dict, error) {
+	vctx := viewcontext.New(ch.ChainID, ch.State.Variables(), ch.State.Timestamp(), ch.State.BlockIndex(), ch.proc, ch.Log)
+	return vctx.CallView(coretypes.Hn(scName), coretypes.Hn(funName), params)
 }
 
 // WaitForEmptyBacklog waits until the backlog queue of the chain becomes empty.
 // It is useful when smart contract(s) in the test are posting asynchronous requests
-// between chains.
+// between chains. Time locked requests are not waited for: they stay in the backlog
+// until the logical clock is advanced with AdvanceClockBy or AdvanceClockTo.
 //
 // The call is needed in order to prevent finishing the test before all
 // asynchronous request between chains are processed.
@@ -200,7 +230,7 @@ func (ch *Chain) WaitForEmptyBacklog(maxWait ...time.Duration) {
 			ch.Log.Infof("backlog length = %d", ch.backlogLen())
 		}
 		counter++
-		if ch.backlogLen() > 0 {
+		if ch.unlockedBacklogLen() > 0 {
 			time.Sleep(50 * time.Millisecond)
 			if maxDurationSet && deadline.Before(time.Now()) {
 				ch.Log.Warnf("exit due to timeout of max wait for %v", maxWait[0])
@@ -208,7 +238,7 @@ func (ch *Chain) WaitForEmptyBacklog(maxWait ...time.Duration) {
 			}
 		} else {
 			time.Sleep(10 * time.Millisecond)
-			if ch.backlogLen() == 0 {
+			if ch.unlockedBacklogLen() == 0 {
 				break
 			}
 		}
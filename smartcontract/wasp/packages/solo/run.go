@@ -4,10 +4,10 @@
 package solo
 
 import (
+	"errors"
 	"fmt"
 	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/waspconn"
 	"github.com/iotaledger/wasp/packages/coretypes"
-	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
@@ -18,7 +18,16 @@ import (
 	"sync"
 )
 
+// errArgsNotSolid is returned when the batch is not run because arguments of a request can't be solidified
+var errArgsNotSolid = errors.New("solo inconsistency: failed to solidify request args")
+
 func (ch *Chain) runBatch(batch []vm.RequestRefWithFreeTokens, trace string) (dict.Dict, error) {
+	return ch.runBatchWithReceipt(batch, trace, nil)
+}
+
+// runBatchWithReceipt runs the batch. If 'receipt' is not nil, it is filled with the effects of the
+// batch, which is expected to contain one request
+func (ch *Chain) runBatchWithReceipt(batch []vm.RequestRefWithFreeTokens, trace string, receipt *Receipt) (dict.Dict, error) {
 	ch.Log.Debugf("runBatch ('%s')", trace)
 	ch.runVMMutex.Lock()
 	defer ch.runVMMutex.Unlock()
@@ -26,19 +35,21 @@ func (ch *Chain) runBatch(batch []vm.RequestRefWithFreeTokens, trace string) (di
 	// solidify arguments
 	for _, reqRef := range batch {
 		if ok, err := reqRef.RequestSection().SolidifyArgs(ch.Env.registry); err != nil || !ok {
-			return nil, fmt.Errorf("solo inconsistency: failed to solidify request args")
+			return nil, errArgsNotSolid
 		}
 	}
 
+	entropy := ch.entropy()
 	task := &vm.VMTask{
 		Processors:         ch.proc,
 		ChainID:            ch.ChainID,
 		Color:              ch.ChainColor,
-		Entropy:            hashing.RandomHash(nil),
+		Entropy:            entropy,
 		ValidatorFeeTarget: ch.ValidatorFeeTarget,
 		Balances:           waspconn.OutputsToBalances(ch.Env.utxoDB.GetAddressOutputs(ch.ChainAddress)),
 		Requests:           batch,
 		Timestamp:          ch.Env.LogicalTime().UnixNano(),
+		GasBudget:          ch.GasBudget,
 		VirtualState:       ch.State.Clone(),
 		Log:                ch.Log,
 	}
@@ -52,6 +63,17 @@ func (ch *Chain) runBatch(batch []vm.RequestRefWithFreeTokens, trace string) (di
 		callErr = callError
 		wg.Done()
 	}
+	results := make([]requestResult, 0, len(batch))
+	task.OnRequestFinish = func(reqID *coretypes.RequestID, callResult dict.Dict, callError error) {
+		results = append(results, requestResult{reqID: *reqID, result: callResult, err: callError})
+	}
+	ch.traceTask(task)
+
+	recorded := ch.recordBatch(batch, task.Timestamp)
+	var receiptDone func(callRes dict.Dict, callErr error, block state.Block)
+	if receipt != nil {
+		receiptDone = ch.startReceipt(receipt, task, &batch[0])
+	}
 
 	wg.Add(1)
 	err = runvm.RunComputationsAsync(task)
@@ -61,11 +83,16 @@ func (ch *Chain) runBatch(batch []vm.RequestRefWithFreeTokens, trace string) (di
 	task.ResultTransaction.Sign(ch.ChainSigScheme)
 
 	ch.settleStateTransition(task.VirtualState, task.ResultBlock, task.ResultTransaction)
+	recorded()
+	ch.requestsProcessed(results, task.ResultBlock.StateIndex())
+	if receiptDone != nil {
+		receiptDone(callRes, callErr, task.ResultBlock)
+	}
 	return callRes, callErr
 }
 
 func (ch *Chain) settleStateTransition(newState state.VirtualState, block state.Block, stateTx *sctransaction.Transaction) {
-	err := ch.Env.utxoDB.AddTransaction(stateTx.Transaction)
+	err := ch.Env.addTransaction(stateTx.Transaction)
 	require.NoError(ch.Env.T, err)
 
 	err = newState.ApplyBlock(block)
@@ -78,6 +105,7 @@ func (ch *Chain) settleStateTransition(newState state.VirtualState, block state.
 
 	ch.StateTx = stateTx
 	ch.State = newState
+	ch.persist()
 
 	ch.Log.Infof("state transition #%d --> #%d. Requests in the block: %d. Posted: %d",
 		prevBlockIndex, ch.State.BlockIndex(), len(block.RequestIDs()), len(ch.StateTx.Requests()))
@@ -85,28 +113,25 @@ func (ch *Chain) settleStateTransition(newState state.VirtualState, block state.
 
 	ch.Env.ClockStep()
 
-	// dispatch requests among chains
+	// dispatch requests among chains. The requests are sent after glbMutex is released,
+	// because the loops reading them may need glbMutex to persist the backlog
 	ch.Env.glbMutex.Lock()
-	defer ch.Env.glbMutex.Unlock()
-
-	reqRefByChain := make(map[coretypes.ChainID][]sctransaction.RequestRef)
+	reqRefByChain := make(map[*Chain][]sctransaction.RequestRef)
 	for i, rsect := range ch.StateTx.Requests() {
 		chid := rsect.Target().ChainID()
-		_, ok := reqRefByChain[chid]
+		chain, ok := ch.Env.chains[chid]
 		if !ok {
-			reqRefByChain[chid] = make([]sctransaction.RequestRef, 0)
+			ch.Log.Infof("dispatching requests. Unknown chain: %s", chid.String())
+			continue
 		}
-		reqRefByChain[chid] = append(reqRefByChain[chid], sctransaction.RequestRef{
+		reqRefByChain[chain] = append(reqRefByChain[chain], sctransaction.RequestRef{
 			Tx:    stateTx,
 			Index: uint16(i),
 		})
 	}
-	for chid, reqs := range reqRefByChain {
-		chain, ok := ch.Env.chains[chid]
-		if !ok {
-			ch.Log.Infof("dispatching requests. Unknown chain: %s", chid.String())
-			continue
-		}
+	ch.Env.glbMutex.Unlock()
+
+	for chain, reqs := range reqRefByChain {
 		chain.chPosted.Add(len(reqs))
 		for _, reqRef := range reqs {
 			chain.chInRequest <- reqRef
@@ -0,0 +1,228 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/stretchr/testify/require"
+)
+
+// ChainSnapshot is the saved state of the chain taken by Chain.Snapshot
+type ChainSnapshot struct {
+	chainID     coretypes.ChainID
+	blockIndex  uint32
+	stateHash   hashing.HashValue
+	db          kvstore.KVStore
+	stateTx     *sctransaction.Transaction
+	backlog     []sctransaction.RequestRef
+	ledgerLen   int
+	logicalTime time.Time
+}
+
+// BlockIndex returns the block index of the state in the snapshot
+func (s *ChainSnapshot) BlockIndex() uint32 {
+	return s.blockIndex
+}
+
+// StateHash returns the hash of the state in the snapshot
+func (s *ChainSnapshot) StateHash() hashing.HashValue {
+	return s.stateHash
+}
+
+// Snapshot saves the current state of the chain: the state with all blocks, the anchor transaction (StateTx),
+// the backlog and the logical clock. The chain can be returned to the snapshot with Restore any number of times
+func (ch *Chain) Snapshot() *ChainSnapshot {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	ret := &ChainSnapshot{
+		chainID:    ch.ChainID,
+		blockIndex: ch.State.BlockIndex(),
+		stateHash:  ch.State.Hash(),
+		db:         copyPartition(ch.Env.T, ch.db, mapdb.NewMapDB()),
+		stateTx:    ch.StateTx,
+	}
+	ch.backlogMutex.Lock()
+	ret.backlog = append([]sctransaction.RequestRef{}, ch.backlog...)
+	ch.backlogMutex.Unlock()
+
+	ch.Env.glbMutex.Lock()
+	ret.ledgerLen = len(ch.Env.ledger)
+	ret.logicalTime = ch.Env.logicalTime
+	ch.Env.glbMutex.Unlock()
+
+	ch.Log.Infof("snapshot taken at block index #%d", ret.blockIndex)
+	return ret
+}
+
+// Restore returns the chain to the state saved in the snapshot. The logical clock is set back to the time of the snapshot.
+// The state transitions of the chain and the requests posted to it after the snapshot are removed from the UTXODB
+// ledger together with all transactions which depend on them, so the chain address owns the same outputs
+// as at the moment of the snapshot and the tokens of the requests are returned to the senders.
+// The other transactions of the other chains and wallets stay in the ledger. Restore fails if a state transition
+// of another chain depends on the transactions being removed. The handles of the requests which are
+// not in the restored backlog are completed with an error
+func (ch *Chain) Restore(snapshot *ChainSnapshot) {
+	require.EqualValues(ch.Env.T, ch.ChainID, snapshot.chainID, "snapshot was taken from another chain")
+
+	// the requests posted before the restore have to reach the backlog first
+	ch.chPosted.Wait()
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	ch.Env.glbMutex.Lock()
+	ch.Env.resetLedger(ch.Env.ledgerWithoutChainTransactions(ch, snapshot.ledgerLen))
+	ch.Env.logicalTime = snapshot.logicalTime
+	ch.Env.persistEnv()
+	ch.Env.glbMutex.Unlock()
+
+	err := ch.db.Clear()
+	require.NoError(ch.Env.T, err)
+	copyPartition(ch.Env.T, snapshot.db, ch.db)
+	vs, _, ok, err := state.LoadSolidStateFromDB(ch.db, &ch.ChainID)
+	require.NoError(ch.Env.T, err)
+	require.True(ch.Env.T, ok)
+	ch.State = vs
+	ch.StateTx = snapshot.stateTx
+	// the blocks after the snapshot are gone
+	ch.inspectReplay = nil
+
+	ch.backlogMutex.Lock()
+	ch.backlog = append([]sctransaction.RequestRef{}, snapshot.backlog...)
+	// the requests posted after the snapshot are gone, their handles are completed with the error
+	inBacklog := make(map[coretypes.RequestID]bool)
+	for _, ref := range ch.backlog {
+		inBacklog[*ref.RequestID()] = true
+	}
+	for reqID := range ch.handles {
+		if !inBacklog[reqID] {
+			ch.completeHandleNoLock(reqID, 0, nil, fmt.Errorf("request %s was removed by the restore of the snapshot", reqID.String()))
+		}
+	}
+	ch.notSolidAttempts = make(map[coretypes.RequestID]int)
+	ch.backlogMutex.Unlock()
+
+	ch.persist()
+	ch.Log.Infof("restored snapshot at block index #%d", snapshot.blockIndex)
+}
+
+// ForkChain creates an independent copy of the chain which can be driven separately from the original.
+// The fork has the same chain ID and keys, and the same state, anchor transaction and backlog as
+// the original at the moment of the call. It lives in its own 'solo' environment (see Chain.Env) with
+// a copy of the UTXODB ledger and of the blob cache of the registry, so the same wallets can be used
+// with both chains without conflicts. The other chains are not copied into the new environment.
+// The new environment is always kept in memory
+func (env *Solo) ForkChain(ch *Chain, name string) *Chain {
+	snapshot := ch.Snapshot()
+
+	dbp := dbprovider.NewInMemoryDBProvider(env.logger)
+	registryDB := dbp.GetRegistryPartition()
+	for _, objType := range []byte{dbprovider.ObjectTypeBlobCache, dbprovider.ObjectTypeBlobCacheTTL} {
+		copyPartitionPrefix(env.T, env.registryDB, registryDB, []byte{objType})
+	}
+
+	env.glbMutex.Lock()
+	fork := &Solo{
+		T:           env.T,
+		logger:      env.logger,
+		registry:    registry.NewRegistry(nil, env.logger.Named("registry"), dbp),
+		registryDB:  registryDB,
+		glbMutex:    &sync.Mutex{},
+		logicalTime: env.logicalTime,
+		timeStep:    env.timeStep,
+		chains:      make(map[coretypes.ChainID]*Chain),
+		keyPairs:    make(map[address.Address]ed25519.KeyPair),
+		seed:        env.seed,
+		keySeed:     env.keySeed,
+		keyIndex:    env.keyIndex,
+	}
+	for addr, keyPair := range env.keyPairs {
+		fork.keyPairs[addr] = keyPair
+	}
+	fork.resetLedger(append([]*valuetransaction.Transaction{}, env.ledger...))
+	env.glbMutex.Unlock()
+
+	ret := fork.newChainInstance(name, ch.ChainSigScheme, ch.OriginatorSigScheme, ch.OriginatorAddress, ch.ValidatorFeeTarget)
+	ret.ChainColor = ch.ChainColor
+	ret.db = copyPartition(env.T, snapshot.db, mapdb.NewMapDB())
+	vs, _, ok, err := state.LoadSolidStateFromDB(ret.db, &ret.ChainID)
+	require.NoError(env.T, err)
+	require.True(env.T, ok)
+	ret.State = vs
+	ret.StateTx = snapshot.stateTx
+	ret.backlog = snapshot.backlog
+
+	fork.chains[ret.ChainID] = ret
+	go ret.readRequestsLoop()
+	go ret.batchLoop()
+
+	ret.Log.Infof("chain '%s' forked from '%s' at block index #%d", ret.Name, ch.Name, snapshot.blockIndex)
+	return ret
+}
+
+// ledgerWithoutChainTransactions returns the ledger without state transitions of the chain and requests
+// to the chain added after the first 'ledgerLen' transactions and without transactions which spend their outputs
+func (env *Solo) ledgerWithoutChainTransactions(ch *Chain, ledgerLen int) []*valuetransaction.Transaction {
+	otherStateTxs := make(map[valuetransaction.ID]string)
+	for _, other := range env.chains {
+		if other != ch {
+			otherStateTxs[other.StateTx.ID()] = other.Name
+		}
+	}
+	removed := make(map[valuetransaction.ID]bool)
+	ret := append([]*valuetransaction.Transaction{}, env.ledger[:ledgerLen]...)
+	for _, tx := range env.ledger[ledgerLen:] {
+		remove := false
+		tx.Inputs().ForEach(func(outputID valuetransaction.OutputID) bool {
+			// only the chain can spend outputs of the chain address
+			remove = outputID.Address() == ch.ChainAddress || removed[outputID.TransactionID()]
+			return !remove
+		})
+		if !remove {
+			// requests and other transfers to the chain
+			tx.Outputs().ForEach(func(addr address.Address, _ []*balance.Balance) bool {
+				remove = addr == ch.ChainAddress
+				return !remove
+			})
+		}
+		if !remove {
+			ret = append(ret, tx)
+			continue
+		}
+		name, ok := otherStateTxs[tx.ID()]
+		require.False(env.T, ok, "can't restore the chain: state of the chain '%s' depends on it", name)
+		removed[tx.ID()] = true
+	}
+	return ret
+}
+
+func copyPartition(t *testing.T, from, to kvstore.KVStore) kvstore.KVStore {
+	return copyPartitionPrefix(t, from, to, kvstore.EmptyPrefix)
+}
+
+func copyPartitionPrefix(t *testing.T, from, to kvstore.KVStore, prefix kvstore.KeyPrefix) kvstore.KVStore {
+	err := from.Iterate(prefix, func(key kvstore.Key, value kvstore.Value) bool {
+		err := to.Set(key, value)
+		require.NoError(t, err)
+		return true
+	})
+	require.NoError(t, err)
+	return to
+}
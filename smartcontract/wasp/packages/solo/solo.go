@@ -11,8 +11,10 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/utxodb"
 	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/hive.go/kvstore"
 	"github.com/iotaledger/hive.go/kvstore/mapdb"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/coretypes"
@@ -30,6 +32,7 @@ import (
 	"github.com/iotaledger/wasp/packages/vm/wasmproc"
 	"github.com/iotaledger/wasp/plugins/wasmtimevm"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -47,11 +50,25 @@ type Solo struct {
 	logger      *logger.Logger
 	utxoDB      *utxodb.UtxoDB
 	registry    coretypes.BlobCacheFull
+	registryDB  kvstore.KVStore
 	glbMutex    *sync.Mutex
 	logicalTime time.Time
 	timeStep    time.Duration
 	chains      map[coretypes.ChainID]*Chain
 	doOnce      sync.Once
+	keyPairs    map[address.Address]ed25519.KeyPair
+	// key pairs and entropy are derived from the seed, if set
+	seed      []byte
+	keySeed   *ed25519.Seed
+	keyIndex  uint64
+	recording *Recording
+	// all transactions added to the UTXODB, in order
+	ledger []*valuetransaction.Transaction
+	// on-disk database of a persistent environment, nil otherwise
+	dbProvider *dbprovider.DBProvider
+	store      kvstore.KVStore
+	// closed is read without glbMutex, because chains are persisted also while glbMutex is held
+	closed atomic.Bool
 }
 
 // Chain represents state of individual chain.
@@ -90,6 +107,9 @@ type Chain struct {
 	// ValidatorFeeTarget is the agent ID to which all fees are accrued. By default is its equal to OriginatorAddress
 	ValidatorFeeTarget coretypes.AgentID
 
+	// GasBudget is the gas budget of each request run on the chain. 0 means vm.DefaultGasBudget
+	GasBudget uint64
+
 	// StateTx is the anchor transaction of the current state of the chain
 	StateTx *sctransaction.Transaction
 
@@ -102,14 +122,30 @@ type Chain struct {
 	// processor cache
 	proc *processors.ProcessorCache
 
+	// partition which contains state and blocks of the chain
+	db kvstore.KVStore
+
 	// related to asynchronous backlog processing
 	runVMMutex   *sync.Mutex
 	chPosted     sync.WaitGroup
 	chInRequest  chan sctransaction.RequestRef
 	backlog      []sctransaction.RequestRef
 	backlogMutex *sync.Mutex
-	batch        []*sctransaction.RequestRef
-	batchMutex   *sync.Mutex
+	// requests in the backlog are not batched automatically, see SetAutoBatching
+	manualBatching bool
+	// handles of requests which are not processed yet, see PostRequestAsync
+	handles map[coretypes.RequestID]*RequestHandle
+	// number of failed attempts to run requests with arguments which can't be solidified, see MaxNotSolidAttempts
+	notSolidAttempts map[coretypes.RequestID]int
+	batch            []*sctransaction.RequestRef
+	batchMutex       *sync.Mutex
+	// codecs and layouts of contract state variables, see InspectContractState
+	inspect map[string]*stateInspectConfig
+	// states of contracts rebuilt from the blocks, see InspectContractStateAt
+	inspectReplay map[string]*stateReplay
+	// traces of requests, see SetCallTracing
+	callTracing bool
+	callTraces  map[coretypes.RequestID]*vm.RequestTrace
 }
 
 var (
@@ -118,9 +154,15 @@ var (
 )
 
 // New creates an instance of the `solo` environment for the test instances.
-//   'debug' parameter 'true' means logging level is 'debug', otherwise 'info'
-//   'printStackTrace' controls printing stack trace in case of errors
+//
+//	'debug' parameter 'true' means logging level is 'debug', otherwise 'info'
+//	'printStackTrace' controls printing stack trace in case of errors
 func New(t *testing.T, debug bool, printStackTrace bool) *Solo {
+	return newSolo(t, debug, printStackTrace, "")
+}
+
+// newSolo creates the environment. With empty 'dir' everything is kept in memory
+func newSolo(t *testing.T, debug bool, printStackTrace bool, dir string) *Solo {
 	doOnce.Do(func() {
 		glbLogger = testutil.NewLogger(t, "04:05.000")
 		if !debug {
@@ -131,89 +173,94 @@ func New(t *testing.T, debug bool, printStackTrace bool) *Solo {
 		}
 		err := processors.RegisterVMType(wasmtimevm.VMType, wasmtimeConstructor)
 		require.NoError(t, err)
+		goConstructor := func(binary []byte) (coretypes.Processor, error) {
+			return wasmproc.GetGoProcessor(binary, glbLogger)
+		}
+		err = processors.RegisterVMType(wasmproc.GoVMType, goConstructor)
+		require.NoError(t, err)
 	})
-	reg := registry.NewRegistry(nil, glbLogger.Named("registry"), dbprovider.NewInMemoryDBProvider(glbLogger))
+	var dbp *dbprovider.DBProvider
+	if dir == "" {
+		dbp = dbprovider.NewInMemoryDBProvider(glbLogger)
+	} else {
+		dbp = dbprovider.NewPersistentDBProvider(dir, glbLogger)
+	}
+	reg := registry.NewRegistry(nil, glbLogger.Named("registry"), dbp)
 	ret := &Solo{
 		T:           t,
 		logger:      glbLogger,
 		utxoDB:      utxodb.New(),
 		registry:    reg,
+		registryDB:  dbp.GetRegistryPartition(),
 		glbMutex:    &sync.Mutex{},
 		logicalTime: time.Now(),
 		timeStep:    DefaultTimeStep,
 		chains:      make(map[coretypes.ChainID]*Chain),
+		keyPairs:    make(map[address.Address]ed25519.KeyPair),
+	}
+	if dir != "" {
+		ret.dbProvider = dbp
+		registryPartition := dbp.GetRegistryPartition()
+		ret.store = registryPartition.WithRealm(append(append([]byte{}, registryPartition.Realm()...), soloRealm...))
+		t.Cleanup(ret.Close)
 	}
 	return ret
 }
 
+// chainPartition returns the key/value store for the state of the chain
+func (env *Solo) chainPartition(chainID coretypes.ChainID) kvstore.KVStore {
+	if env.dbProvider == nil {
+		return mapdb.NewMapDB()
+	}
+	return env.dbProvider.GetPartition(&chainID)
+}
+
 // NewChain deploys new chain instance.
 //
-//   If 'chainOriginator' is nil, new one is generated and solo.Supply (=1337) iotas are loaded from the UTXODB faucet.
-//   If 'validatorFeeTarget' is skipped, it is assumed equal to OriginatorAgentID
+//	If 'chainOriginator' is nil, new one is generated and solo.Supply (=1337) iotas are loaded from the UTXODB faucet.
+//	If 'validatorFeeTarget' is skipped, it is assumed equal to OriginatorAgentID
+//
 // To deploy the chai instance the following steps are performed:
-//    - chain signature scheme (private key), chain address and chain ID are created
-//    - empty virtual state is initialized
-//    - origin transaction is created by the originator and added to the UTXODB
-//    - 'init' request transaction to the 'root' contract is created and added to UTXODB
-//    - backlog processing threads (goroutines) are started
-//    - VM processor cache is initialized
-//    - 'init' request is run by the VM. The 'root' contracts deploys the rest of the core contracts:
-//      'blob', 'accountsc', 'chainlog'
+//   - chain signature scheme (private key), chain address and chain ID are created
+//   - empty virtual state is initialized
+//   - origin transaction is created by the originator and added to the UTXODB
+//   - 'init' request transaction to the 'root' contract is created and added to UTXODB
+//   - backlog processing threads (goroutines) are started
+//   - VM processor cache is initialized
+//   - 'init' request is run by the VM. The 'root' contracts deploys the rest of the core contracts:
+//     'blob', 'accountsc', 'chainlog'
+//
 // Upon return, the chain is fully functional to process requests
 func (env *Solo) NewChain(chainOriginator signaturescheme.SignatureScheme, name string, validatorFeeTarget ...coretypes.AgentID) *Chain {
 	env.logger.Infof("deploying new chain '%s'", name)
-	chSig := signaturescheme.ED25519(ed25519.GenerateKeyPair()) // chain address will be ED25519, not BLS
+	chSig := signaturescheme.ED25519(env.newKeyPair()) // chain address will be ED25519, not BLS
 	if chainOriginator == nil {
-		chainOriginator = signaturescheme.ED25519(ed25519.GenerateKeyPair())
-		_, err := env.utxoDB.RequestFunds(chainOriginator.Address())
+		chainOriginator = signaturescheme.ED25519(env.newKeyPair())
+		err := env.requestFunds(chainOriginator.Address())
 		require.NoError(env.T, err)
 	}
-	chainID := coretypes.ChainID(chSig.Address())
-	originatorAgentID := coretypes.NewAgentIDFromAddress(chainOriginator.Address())
-	feeTarget := originatorAgentID
+	feeTarget := coretypes.NewAgentIDFromAddress(chainOriginator.Address())
 	if len(validatorFeeTarget) > 0 {
 		feeTarget = validatorFeeTarget[0]
 	}
-	ret := &Chain{
-		Env:                 env,
-		Name:                name,
-		ChainSigScheme:      chSig,
-		OriginatorSigScheme: chainOriginator,
-		ChainAddress:        chSig.Address(),
-		OriginatorAddress:   chainOriginator.Address(),
-		OriginatorAgentID:   originatorAgentID,
-		ValidatorFeeTarget:  feeTarget,
-		ChainID:             chainID,
-		State:               state.NewVirtualState(mapdb.NewMapDB(), &chainID),
-		proc:                processors.MustNew(),
-		Log:                 env.logger.Named(name),
-		//
-		runVMMutex:   &sync.Mutex{},
-		chInRequest:  make(chan sctransaction.RequestRef),
-		backlog:      make([]sctransaction.RequestRef, 0),
-		backlogMutex: &sync.Mutex{},
-		batch:        nil,
-		batchMutex:   &sync.Mutex{},
-	}
+	ret := env.newChainInstance(name, chSig, chainOriginator, chainOriginator.Address(), feeTarget)
+	chainID := ret.ChainID
+	ret.db = env.chainPartition(chainID)
+	ret.State = state.NewVirtualState(ret.db, &chainID)
+
 	env.AssertAddressBalance(ret.OriginatorAddress, balance.ColorIOTA, testutil.RequestFundsAmount)
-	var err error
-	ret.StateTx, err = origin.NewOriginTransaction(origin.NewOriginTransactionParams{
+	originTx, err := origin.NewOriginTransaction(origin.NewOriginTransactionParams{
 		OriginAddress:             ret.ChainAddress,
 		OriginatorSignatureScheme: ret.OriginatorSigScheme,
 		AllInputs:                 env.utxoDB.GetAddressOutputs(ret.OriginatorAddress),
 	})
 	require.NoError(env.T, err)
-	require.NotNil(env.T, ret.StateTx)
-	err = env.utxoDB.AddTransaction(ret.StateTx.Transaction)
+	require.NotNil(env.T, originTx)
+	err = env.addTransaction(originTx.Transaction)
 	require.NoError(env.T, err)
 
-	ret.ChainColor = balance.Color(ret.StateTx.ID())
-
-	originBlock := state.MustNewOriginBlock(&ret.ChainColor)
-	err = ret.State.ApplyBlock(originBlock)
-	require.NoError(env.T, err)
-	err = ret.State.CommitToDb(originBlock)
-	require.NoError(env.T, err)
+	ret.setOrigin(originTx)
+	env.recordNewChain(ret)
 
 	initTx, err := origin.NewRootInitRequestTransaction(origin.NewRootInitRequestTransactionParams{
 		ChainID:              chainID,
@@ -226,7 +273,7 @@ func (env *Solo) NewChain(chainOriginator signaturescheme.SignatureScheme, name
 	require.NoError(env.T, err)
 	require.NotNil(env.T, initTx)
 
-	err = env.utxoDB.AddTransaction(initTx.Transaction)
+	err = env.addTransaction(initTx.Transaction)
 	require.NoError(env.T, err)
 
 	env.glbMutex.Lock()
@@ -241,10 +288,45 @@ func (env *Solo) NewChain(chainOriginator signaturescheme.SignatureScheme, name
 	_, err = ret.runBatch([]vm.RequestRefWithFreeTokens{r}, "new")
 	require.NoError(env.T, err)
 
+	ret.persist()
 	ret.Log.Infof("chain '%s' deployed. Chain ID: %s", ret.Name, ret.ChainID)
 	return ret
 }
 
+// GetChain returns the chain deployed or restored in the environment, or nil if the chain is not known
+func (env *Solo) GetChain(chainID coretypes.ChainID) *Chain {
+	env.glbMutex.Lock()
+	defer env.glbMutex.Unlock()
+	return env.chains[chainID]
+}
+
+// newChainInstance creates the Chain structure without state
+func (env *Solo) newChainInstance(name string, chSig, originator signaturescheme.SignatureScheme, originatorAddress address.Address, feeTarget coretypes.AgentID) *Chain {
+	return &Chain{
+		Env:                 env,
+		Name:                name,
+		ChainSigScheme:      chSig,
+		OriginatorSigScheme: originator,
+		ChainAddress:        chSig.Address(),
+		OriginatorAddress:   originatorAddress,
+		OriginatorAgentID:   coretypes.NewAgentIDFromAddress(originatorAddress),
+		ValidatorFeeTarget:  feeTarget,
+		ChainID:             coretypes.ChainID(chSig.Address()),
+		proc:                processors.MustNew(),
+		Log:                 env.logger.Named(name),
+		//
+		runVMMutex:   &sync.Mutex{},
+		chInRequest:  make(chan sctransaction.RequestRef),
+		backlog:      make([]sctransaction.RequestRef, 0),
+		backlogMutex: &sync.Mutex{},
+		handles:      make(map[coretypes.RequestID]*RequestHandle),
+		batch:        nil,
+		batchMutex:   &sync.Mutex{},
+		//
+		notSolidAttempts: make(map[coretypes.RequestID]int),
+	}
+}
+
 func (ch *Chain) readRequestsLoop() {
 	for r := range ch.chInRequest {
 		ch.addToBacklog(r)
@@ -255,6 +337,7 @@ func (ch *Chain) addToBacklog(r sctransaction.RequestRef) {
 	ch.backlogMutex.Lock()
 	defer func() {
 		ch.backlogMutex.Unlock()
+		ch.persist()
 		ch.chPosted.Done()
 	}()
 	ch.backlog = append(ch.backlog, r)
@@ -275,6 +358,9 @@ func (ch *Chain) collateBatch() []vm.RequestRefWithFreeTokens {
 	defer ch.backlogMutex.Unlock()
 
 	ret := make([]vm.RequestRefWithFreeTokens, 0)
+	if ch.manualBatching {
+		return ret
+	}
 	remain := ch.backlog[:0]
 	for _, ref := range ch.backlog {
 		// using logical clock
@@ -293,12 +379,14 @@ func (ch *Chain) collateBatch() []vm.RequestRefWithFreeTokens {
 
 // batchLoop mimics leaders's behavior in the Wasp committee
 func (ch *Chain) batchLoop() {
-	for {
+	for !ch.Env.isClosed() {
 		batch := ch.collateBatch()
 		if len(batch) > 0 {
-			_, err := ch.runBatch(batch, "batchLoop")
+			_, err := ch.runFromBacklog(batch, "batchLoop")
 			if err != nil {
 				ch.Log.Errorf("runBatch: %v", err)
+				// the requests are back in the backlog, don't retry immediately
+				time.Sleep(50 * time.Millisecond)
 			}
 			continue
 		}
@@ -326,7 +414,7 @@ func (env *Solo) NewSignatureSchemeWithFunds() signaturescheme.SignatureScheme {
 // Returns signature scheme interface and public key in binary form
 func (env *Solo) NewSignatureSchemeWithFundsAndPubKey() (signaturescheme.SignatureScheme, []byte) {
 	ret, pubKeyBytes := env.NewSignatureSchemeAndPubKey()
-	_, err := env.utxoDB.RequestFunds(ret.Address())
+	err := env.requestFunds(ret.Address())
 	require.NoError(env.T, err)
 	return ret, pubKeyBytes
 }
@@ -340,7 +428,7 @@ func (env *Solo) NewSignatureScheme() signaturescheme.SignatureScheme {
 // NewSignatureSchemeAndPubKey generates new ed25519 signature scheme
 // Returns signature scheme interface and public key in binary form
 func (env *Solo) NewSignatureSchemeAndPubKey() (signaturescheme.SignatureScheme, []byte) {
-	keypair := ed25519.GenerateKeyPair()
+	keypair := env.newKeyPair()
 	ret := signaturescheme.ED25519(keypair)
 	env.AssertAddressBalance(ret.Address(), balance.ColorIOTA, 0)
 	return ret, keypair.PublicKey.Bytes()
@@ -359,7 +447,7 @@ func (env *Solo) MintTokens(wallet signaturescheme.SignatureScheme, amount int64
 	tx := txb.BuildValueTransactionOnly(false)
 	tx.Sign(wallet)
 
-	if err = env.utxoDB.AddTransaction(tx); err != nil {
+	if err = env.addTransaction(tx); err != nil {
 		return balance.Color{}, err
 	}
 	return balance.Color(tx.ID()), nil
@@ -377,12 +465,13 @@ func (env *Solo) DestroyColoredTokens(wallet signaturescheme.SignatureScheme, co
 	tx := txb.BuildValueTransactionOnly(false)
 	tx.Sign(wallet)
 
-	return env.utxoDB.AddTransaction(tx)
+	return env.addTransaction(tx)
 }
 
 func (env *Solo) PutBlobDataIntoRegistry(data []byte) hashing.HashValue {
 	h, err := env.registry.PutBlob(data)
 	require.NoError(env.T, err)
+	env.recordBlob(data)
 	env.logger.Infof("Solo::PutBlobDataIntoRegistry: len = %d, hash = %s", len(data), h)
 	return h
 }
@@ -1,10 +1,25 @@
 package solo
 
 import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/wasmlib"
 	"github.com/stretchr/testify/require"
-	"testing"
 )
 
 func TestPutBlobData(t *testing.T) {
@@ -23,3 +38,596 @@ func TestPutBlobData(t *testing.T) {
 	require.Len(env.T, sargs, 1)
 	require.EqualValues(env.T, data, sargs.MustGet("dataName"))
 }
+
+func TestPersistentReopen(t *testing.T) {
+	dir := t.TempDir()
+	env := NewPersistent(t, dir, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user := env.NewSignatureSchemeWithFunds()
+	data := []byte("data-datadatadatadatadatadatadatadata")
+	blobHash, err := chain.UploadBlob(user, "dataName", data)
+	require.NoError(t, err)
+	env.AdvanceClockBy(time.Hour)
+	chain.GasBudget = 123456
+
+	chainID := chain.ChainID
+	stateHash := chain.State.Hash()
+	blockIndex := chain.State.BlockIndex()
+	stateTxID := chain.StateTx.ID()
+	logicalTime := env.LogicalTime()
+	env.Close()
+
+	env = Open(t, dir, false, false)
+	chain = env.GetChain(chainID)
+	require.NotNil(t, chain)
+	require.EqualValues(t, "chain1", chain.Name)
+	require.EqualValues(t, stateHash, chain.State.Hash())
+	require.EqualValues(t, blockIndex, chain.State.BlockIndex())
+	require.EqualValues(t, stateTxID, chain.StateTx.ID())
+	require.True(t, logicalTime.Equal(env.LogicalTime()))
+	require.NotNil(t, chain.OriginatorSigScheme)
+	require.EqualValues(t, 123456, chain.GasBudget)
+
+	sigScheme, ok := env.GetSignatureScheme(user.Address())
+	require.True(t, ok)
+	require.EqualValues(t, user.Address(), sigScheme.Address())
+
+	info, ok := chain.GetBlobInfo(blobHash)
+	require.True(t, ok)
+	require.EqualValues(t, len(data), info["dataName"])
+
+	// the chain continues from the restored state
+	_, err = chain.UploadBlob(sigScheme, "dataName", []byte("other data"))
+	require.NoError(t, err)
+	require.EqualValues(t, blockIndex+1, chain.State.BlockIndex())
+}
+
+func postManyOnLoad() {
+	exports := wasmlib.NewScExports()
+	exports.AddFunc("postMany", func(ctx *wasmlib.ScFuncContext) {
+		for i := 0; i < 3; i++ {
+			ctx.Post(&wasmlib.PostRequestParams{
+				ContractId: ctx.ContractId(),
+				Function:   wasmlib.NewScHname("increment"),
+				Transfer:   wasmlib.NewScTransfer(wasmlib.IOTA, 1),
+			})
+		}
+	})
+	exports.AddFunc("increment", func(ctx *wasmlib.ScFuncContext) {
+		counter := ctx.State().GetInt(keyCounter)
+		counter.SetValue(counter.Value() + 1)
+	})
+	exports.AddView("getCounter", func(ctx *wasmlib.ScViewContext) {
+		ctx.Results().GetInt(keyCounter).SetValue(ctx.State().GetInt(keyCounter).Value())
+	})
+}
+
+func TestPersistentPostMany(t *testing.T) {
+	dir := t.TempDir()
+	env := NewPersistent(t, dir, false, false)
+	chain := env.NewChain(nil, "chain1")
+	err := chain.DeployGoContract(nil, "postMany", postManyOnLoad)
+	require.NoError(t, err)
+	getCounter := func() int64 {
+		res, err := chain.CallView("postMany", "getCounter")
+		require.NoError(t, err)
+		counter, _, err := codec.DecodeInt64(res.MustGet(kv.Key(keyCounter)))
+		require.NoError(t, err)
+		return counter
+	}
+
+	// the block posts several requests, which are added to the persisted backlog one by one
+	user := env.NewSignatureSchemeWithFunds()
+	req := NewCallParams("postMany", "postMany").WithTransfer(balance.ColorIOTA, 6)
+	_, err = chain.PostRequest(req, user)
+	require.NoError(t, err)
+	chain.WaitForEmptyBacklog()
+	require.EqualValues(t, 3, getCounter())
+
+	chainID := chain.ChainID
+	env.Close()
+	env = Open(t, dir, false, false)
+	chain = env.GetChain(chainID)
+	require.EqualValues(t, 3, getCounter())
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user := env.NewSignatureSchemeWithFunds()
+
+	hash1, err := chain.UploadBlob(user, "dataName", []byte("data1"))
+	require.NoError(t, err)
+	snapshot := chain.Snapshot()
+	require.EqualValues(t, chain.State.BlockIndex(), snapshot.BlockIndex())
+	logicalTime := env.LogicalTime()
+
+	hash2, err := chain.UploadBlob(user, "dataName", []byte("data2"))
+	require.NoError(t, err)
+	_, ok := chain.GetBlobInfo(hash2)
+	require.True(t, ok)
+
+	for i := 0; i < 2; i++ {
+		chain.Restore(snapshot)
+		require.EqualValues(t, snapshot.StateHash(), chain.State.Hash())
+		require.EqualValues(t, snapshot.BlockIndex(), chain.State.BlockIndex())
+		require.True(t, logicalTime.Equal(env.LogicalTime()))
+		_, ok = chain.GetBlobInfo(hash1)
+		require.True(t, ok)
+		_, ok = chain.GetBlobInfo(hash2)
+		require.False(t, ok)
+
+		// another continuation from the same position
+		hash3, err := chain.UploadBlob(user, "dataName", []byte("data3"))
+		require.NoError(t, err)
+		_, ok = chain.GetBlobInfo(hash3)
+		require.True(t, ok)
+		require.EqualValues(t, snapshot.BlockIndex()+1, chain.State.BlockIndex())
+	}
+}
+
+func TestRestoreRequests(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user := env.NewSignatureSchemeWithFunds()
+
+	snapshot := chain.Snapshot()
+	chainBalance := env.GetAddressBalance(chain.ChainAddress, balance.ColorIOTA)
+
+	req := NewCallParams(accounts.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequest(req, user)
+	require.NoError(t, err)
+	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, Supply-43)
+
+	// the request is removed from the ledger together with the state transition
+	chain.Restore(snapshot)
+	env.AssertAddressBalance(chain.ChainAddress, balance.ColorIOTA, chainBalance)
+	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, Supply)
+
+	_, err = chain.PostRequest(req, user)
+	require.NoError(t, err)
+	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, Supply-43)
+}
+
+func TestRestoreAsyncRequest(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	snapshot := chain.Snapshot()
+	// the time lock keeps the request in the backlog until the restore
+	req := NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 5).
+		WithTimeLock(env.LogicalTime().Add(10 * time.Minute))
+	h, err := chain.PostRequestAsync(req, nil)
+	require.NoError(t, err)
+	chain.WaitForEmptyBacklog()
+	require.False(t, h.IsProcessed())
+
+	chain.Restore(snapshot)
+	require.True(t, h.Wait(time.Second))
+	_, err = h.Result()
+	require.Error(t, err)
+	require.EqualValues(t, 0, len(chain.BacklogHandles()))
+
+	chain.backlogMutex.Lock()
+	defer chain.backlogMutex.Unlock()
+	require.EqualValues(t, 0, len(chain.handles))
+	require.EqualValues(t, 0, len(chain.notSolidAttempts))
+}
+
+func TestForkChain(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user := env.NewSignatureSchemeWithFunds()
+
+	hash1, err := chain.UploadBlob(user, "dataName", []byte("data1"))
+	require.NoError(t, err)
+
+	fork := env.ForkChain(chain, "fork1")
+	require.EqualValues(t, chain.ChainID, fork.ChainID)
+	require.EqualValues(t, chain.State.Hash(), fork.State.Hash())
+	_, ok := fork.GetBlobInfo(hash1)
+	require.True(t, ok)
+
+	hash2, err := chain.UploadBlob(user, "dataName", []byte("data2"))
+	require.NoError(t, err)
+	hash3, err := fork.UploadBlob(user, "dataName", []byte("data3"))
+	require.NoError(t, err)
+
+	_, ok = chain.GetBlobInfo(hash3)
+	require.False(t, ok)
+	_, ok = fork.GetBlobInfo(hash2)
+	require.False(t, ok)
+	require.EqualValues(t, chain.State.BlockIndex(), fork.State.BlockIndex())
+	require.NotEqualValues(t, chain.State.Hash(), fork.State.Hash())
+	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, Supply-2)
+	fork.Env.AssertAddressBalance(user.Address(), balance.ColorIOTA, Supply-2)
+
+	// the blob cache of the registry is copied, not shared
+	blobHash := fork.Env.PutBlobDataIntoRegistry([]byte("fork data"))
+	ok, err = env.registry.HasBlob(blobHash)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func runSeeded(t *testing.T, env *Solo) *Chain {
+	chain := env.NewChain(nil, "chain1")
+	user := env.NewSignatureSchemeWithFunds()
+	_, err := chain.UploadBlob(user, "dataName", []byte("data1"))
+	require.NoError(t, err)
+	_, err = chain.UploadBlobOptimized(10, user, "dataName", []byte("data-datadatadatadatadatadatadatadata"))
+	require.NoError(t, err)
+	return chain
+}
+
+func TestSeededRuns(t *testing.T) {
+	seed := []byte("seed")
+	chain1 := runSeeded(t, New(t, false, false).WithSeed(seed))
+	chain2 := runSeeded(t, New(t, false, false).WithSeed(seed))
+	require.EqualValues(t, chain1.ChainID, chain2.ChainID)
+	require.EqualValues(t, chain1.State.Hash(), chain2.State.Hash())
+
+	chain3 := runSeeded(t, New(t, false, false).WithSeed([]byte("another seed")))
+	require.NotEqualValues(t, chain1.State.Hash(), chain3.State.Hash())
+}
+
+func TestRecordReplay(t *testing.T) {
+	env := New(t, false, false).WithSeed([]byte("seed"))
+	rec := env.StartRecording()
+	chain := runSeeded(t, env)
+
+	rec, err := RecordingFromBytes(rec.Bytes())
+	require.NoError(t, err)
+	require.EqualValues(t, []coretypes.ChainID{chain.ChainID}, rec.ChainIDs())
+
+	replayed := Replay(t, rec, false, false)
+	chainReplayed := replayed.GetChain(chain.ChainID)
+	require.NotNil(t, chainReplayed)
+	require.EqualValues(t, chain.State.Hash(), chainReplayed.State.Hash())
+	require.EqualValues(t, chain.StateTx.ID(), chainReplayed.StateTx.ID())
+
+	// the replayed chain continues to work
+	_, err = chainReplayed.UploadBlob(nil, "dataName", []byte("data2"))
+	require.NoError(t, err)
+}
+
+func TestReceipt(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	req := NewCallParams(root.Interface.Name, root.FuncSetDefaultFee,
+		root.ParamOwnerFee, 2,
+		root.ParamValidatorFee, 1,
+	)
+	_, err := chain.PostRequest(req, nil)
+	require.NoError(t, err)
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	req = NewCallParams(blob.Interface.Name, blob.FuncStoreBlob, "dataName", []byte("data1")).
+		WithTransfer(balance.ColorIOTA, 3)
+	receipt, err := chain.PostRequestWithReceipt(req, user)
+	require.NoError(t, err)
+	require.NoError(t, receipt.Error)
+	t.Logf("receipt:\n%s", receipt)
+
+	require.EqualValues(t, chain.State.BlockIndex(), receipt.BlockIndex)
+	require.EqualValues(t, 0, receipt.RequestID.Index())
+	require.EqualValues(t, balance.ColorIOTA, receipt.FeeColor)
+	require.EqualValues(t, 2, receipt.OwnerFee)
+	require.EqualValues(t, 1, receipt.ValidatorFee)
+
+	require.EqualValues(t, 2, receipt.BalanceDelta(chain.OriginatorAgentID, balance.ColorIOTA))
+	require.EqualValues(t, 1, receipt.BalanceDelta(userAgentID, balance.ColorIOTA))
+
+	blobHash, _, err := codec.DecodeHashValue(receipt.Result.MustGet(blob.ParamHash))
+	require.NoError(t, err)
+	blobPrefix := string(blob.Interface.Hname().Bytes())
+	found := false
+	for key := range receipt.StateWrites {
+		if strings.HasPrefix(string(key), blobPrefix) {
+			found = true
+		}
+	}
+	require.True(t, found)
+	require.EqualValues(t, 1, len(receipt.Events))
+	require.EqualValues(t, blob.Interface.Hname(), receipt.Events[0].Contract)
+	require.Contains(t, receipt.Events[0].Message, blobHash.String())
+	require.EqualValues(t, 0, len(receipt.PostedRequests))
+}
+
+func TestRunBatchOrder(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	chain.SetAutoBatching(false)
+
+	post := func(ownerFee int64) *RequestHandle {
+		req := NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, ownerFee)
+		h, err := chain.PostRequestAsync(req, nil)
+		require.NoError(t, err)
+		return h
+	}
+	ownerFee := func() int64 {
+		res, err := chain.CallView(root.Interface.Name, root.FuncGetFeeInfo, root.ParamHname, blob.Interface.Hname())
+		require.NoError(t, err)
+		fee, _, err := codec.DecodeInt64(res.MustGet(root.ParamOwnerFee))
+		require.NoError(t, err)
+		return fee
+	}
+
+	h1 := post(1)
+	h2 := post(2)
+	require.False(t, h1.Wait(100*time.Millisecond))
+	require.EqualValues(t, 2, len(chain.BacklogHandles()))
+
+	err := chain.RunBatch(h2, h1)
+	require.NoError(t, err)
+	require.True(t, h1.IsProcessed())
+	require.True(t, h2.IsProcessed())
+	require.EqualValues(t, chain.State.BlockIndex(), h1.BlockIndex())
+	require.EqualValues(t, h1.BlockIndex(), h2.BlockIndex())
+	_, err = h1.Result()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, ownerFee())
+	require.EqualValues(t, 0, len(chain.BacklogHandles()))
+
+	err = chain.RunBatch(h1)
+	require.Error(t, err)
+
+	h3 := post(3)
+	h4 := post(4)
+	err = chain.RunBatchInOrder(BatchOrderReverse, h3, h4)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, ownerFee())
+
+	post(5)
+	post(6)
+	err = chain.RunBatch()
+	require.NoError(t, err)
+	require.EqualValues(t, 6, ownerFee())
+
+	chain.SetAutoBatching(true)
+	h7 := post(7)
+	require.True(t, h7.Wait(5*time.Second))
+	require.EqualValues(t, 7, ownerFee())
+}
+
+func TestRunBatchNotSolid(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	chain.SetAutoBatching(false)
+
+	req, toUpload := NewCallParamsOptimized(blob.Interface.Name, blob.FuncStoreBlob, 10,
+		"dataName", []byte("data-datadatadatadatadatadatadatadata"))
+	h, err := chain.PostRequestAsync(req, nil)
+	require.NoError(t, err)
+
+	// the blob with the argument is not in the registry yet, the request stays in the backlog
+	require.Error(t, chain.RunBatch(h))
+	require.False(t, h.IsProcessed())
+	require.EqualValues(t, 1, len(chain.BacklogHandles()))
+
+	for _, v := range toUpload {
+		env.PutBlobDataIntoRegistry(v)
+	}
+	require.NoError(t, chain.RunBatch(h))
+	_, err = h.Result()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, len(chain.BacklogHandles()))
+}
+
+func TestNotSolidRequestRemoved(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	// the blob with the argument is never uploaded, the request is removed after MaxNotSolidAttempts
+	req, _ := NewCallParamsOptimized(blob.Interface.Name, blob.FuncStoreBlob, 10,
+		"dataName", []byte("data-datadatadatadatadatadatadatadata"))
+	h, err := chain.PostRequestAsync(req, nil)
+	require.NoError(t, err)
+	require.True(t, h.Wait(10*time.Second))
+	_, err = h.Result()
+	require.Error(t, err)
+	require.EqualValues(t, 0, h.BlockIndex())
+	require.EqualValues(t, 0, len(chain.BacklogHandles()))
+
+	chain.backlogMutex.Lock()
+	defer chain.backlogMutex.Unlock()
+	require.EqualValues(t, 0, len(chain.handles))
+	require.EqualValues(t, 0, len(chain.notSolidAttempts))
+}
+
+func TestTimeLockedRequest(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	req := NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 5).
+		WithTimeLock(env.LogicalTime().Add(10 * time.Minute))
+	_, err := chain.PostRequest(req, nil)
+	require.Error(t, err)
+
+	h, err := chain.PostRequestAsync(req, nil)
+	require.NoError(t, err)
+	chain.WaitForEmptyBacklog()
+	require.False(t, h.IsProcessed())
+	require.EqualValues(t, 1, len(chain.TimeLockedRequests()))
+	require.EqualValues(t, 1, len(env.TimeLockedRequests()[chain.ChainID]))
+	require.EqualValues(t, req.timelock, h.TimeLock().Unix())
+	require.Error(t, chain.RunBatch(h))
+
+	env.AdvanceClockBy(5 * time.Minute)
+	require.False(t, h.IsProcessed())
+
+	env.AdvanceClockBy(5*time.Minute + time.Second)
+	require.True(t, h.IsProcessed())
+	_, err = h.Result()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, len(chain.TimeLockedRequests()))
+	require.EqualValues(t, 0, len(env.TimeLockedRequests()))
+}
+
+func postDelayedOnLoad() {
+	exports := wasmlib.NewScExports()
+	exports.AddFunc("postDelayed", func(ctx *wasmlib.ScFuncContext) {
+		// the delay of 10 minutes locks the request, -1 means no delay
+		for _, delay := range []int64{10 * 60, -1} {
+			ctx.Post(&wasmlib.PostRequestParams{
+				ContractId: ctx.ContractId(),
+				Function:   wasmlib.NewScHname("increment"),
+				Transfer:   wasmlib.NewScTransfer(wasmlib.IOTA, 1),
+				Delay:      delay,
+			})
+		}
+	})
+	exports.AddFunc("increment", func(ctx *wasmlib.ScFuncContext) {
+		counter := ctx.State().GetInt(keyCounter)
+		counter.SetValue(counter.Value() + 1)
+	})
+	exports.AddView("getCounter", func(ctx *wasmlib.ScViewContext) {
+		ctx.Results().GetInt(keyCounter).SetValue(ctx.State().GetInt(keyCounter).Value())
+	})
+}
+
+func TestWasmPostDelay(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	err := chain.DeployGoContract(nil, "postDelayed", postDelayedOnLoad)
+	require.NoError(t, err)
+	getCounter := func() int64 {
+		res, err := chain.CallView("postDelayed", "getCounter")
+		require.NoError(t, err)
+		counter, _, err := codec.DecodeInt64(res.MustGet(kv.Key(keyCounter)))
+		require.NoError(t, err)
+		return counter
+	}
+
+	user := env.NewSignatureSchemeWithFunds()
+	req := NewCallParams("postDelayed", "postDelayed").WithTransfer(balance.ColorIOTA, 4)
+	_, err = chain.PostRequest(req, user)
+	require.NoError(t, err)
+	chain.WaitForEmptyBacklog()
+	require.EqualValues(t, 1, getCounter())
+	locked := chain.TimeLockedRequests()
+	require.EqualValues(t, 1, len(locked))
+	require.True(t, locked[0].TimeLock().After(env.LogicalTime().Add(9*time.Minute)))
+
+	env.AdvanceClockBy(9 * time.Minute)
+	require.EqualValues(t, 1, len(chain.TimeLockedRequests()))
+	require.EqualValues(t, 1, getCounter())
+
+	env.AdvanceClockBy(time.Minute + time.Second)
+	require.True(t, locked[0].IsProcessed())
+	require.EqualValues(t, 0, len(chain.TimeLockedRequests()))
+	require.EqualValues(t, 2, getCounter())
+}
+
+func TestInspectContractState(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user := env.NewSignatureSchemeWithFunds()
+	before := chain.State.BlockIndex()
+
+	data := []byte("data-datadatadatadatadatadatadatadata")
+	blobHash, err := chain.UploadBlob(user, "dataName", data)
+	require.NoError(t, err)
+	chain.RegisterStateCodec(blob.Interface.Name, "d", func(value []byte) (interface{}, error) {
+		return util.Uint32From4Bytes(value)
+	})
+
+	st := chain.InspectContractState(blob.Interface.Name)
+	require.EqualValues(t, chain.State.BlockIndex(), st.BlockIndex)
+	var dir *StateVar
+	for _, v := range st.Vars {
+		if v.Name == "d" {
+			dir = v
+		}
+	}
+	require.NotNil(t, dir)
+	require.EqualValues(t, StateMap, dir.Kind)
+	require.Len(t, dir.Elems, 1)
+	require.EqualValues(t, displayBytes(blobHash[:]), dir.Elems[0].Key)
+	require.EqualValues(t, len(data), dir.Elems[0].Value)
+	require.Contains(t, st.String(), displayBytes(blobHash[:]))
+	_, err = st.JSON()
+	require.NoError(t, err)
+
+	require.Empty(t, chain.InspectContractStateAt(blob.Interface.Name, before).Vars)
+	diff := chain.DiffContractState(blob.Interface.Name, before, chain.State.BlockIndex())
+	require.NotEmpty(t, diff.Changes)
+	found := false
+	for _, c := range diff.Changes {
+		if c.Var == "d" {
+			require.Nil(t, c.Old)
+			require.EqualValues(t, dir.Elems[0].Value, c.New)
+			found = true
+		}
+	}
+	require.True(t, found)
+}
+
+func TestInspectCollections(t *testing.T) {
+	d := dict.New()
+	arr := collections.NewArray32(d, "arr")
+	tlog := collections.NewTimestampedLog(d, "log")
+	for i := 0; i < 300; i++ {
+		require.NoError(t, arr.Push(codec.EncodeInt64(int64(i))))
+		require.NoError(t, tlog.Append(int64(i+1), codec.EncodeInt64(int64(i))))
+	}
+	m := collections.NewMap(d, "map")
+	require.NoError(t, m.SetAt([]byte("key1"), codec.EncodeInt64(1)))
+	raw := make(map[string][]byte)
+	for key, value := range d {
+		raw[string(key)] = value
+	}
+
+	cfg := &stateInspectConfig{codecs: map[string]StateCodec{
+		"arr": StateCodecInt64,
+		"log": StateCodecInt64,
+	}}
+	st := cfg.contractState("test", 0, raw)
+	require.Len(t, st.Vars, 3)
+	kinds := map[string]StateVarKind{"arr": StateArray32, "log": StateTimestampedLog, "map": StateMap}
+	for _, v := range st.Vars {
+		require.EqualValues(t, kinds[v.Name], v.Kind)
+		if v.Kind == StateMap {
+			continue
+		}
+		// the elements are in the order of the indices
+		require.Len(t, v.Elems, 300)
+		for i, elem := range v.Elems {
+			require.EqualValues(t, fmt.Sprintf("%d", i), elem.Key)
+			require.EqualValues(t, i, elem.Value)
+		}
+	}
+}
+
+func TestInspectContractStateAtBlocks(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user := env.NewSignatureSchemeWithFunds()
+
+	_, err := chain.UploadBlob(user, "dataName", []byte("data1"))
+	require.NoError(t, err)
+	block1 := chain.State.BlockIndex()
+	_, err = chain.UploadBlob(user, "dataName", []byte("data2"))
+	require.NoError(t, err)
+	_, err = chain.UploadBlob(user, "dataName", []byte("data3"))
+	require.NoError(t, err)
+
+	dirLen := func(st *ContractState) int {
+		for _, v := range st.Vars {
+			if v.Name == "d" {
+				require.EqualValues(t, StateMap, v.Kind)
+				return len(v.Elems)
+			}
+		}
+		return 0
+	}
+	// ascending, descending and repeated block indices give the same states
+	for _, i := range []uint32{0, 1, 2, 1, 0, 2, 2} {
+		require.EqualValues(t, i+1, dirLen(chain.InspectContractStateAt(blob.Interface.Name, block1+i)))
+	}
+
+	// the declared layout wins over the recognition of the collection
+	chain.RegisterStateLayout(blob.Interface.Name, "d", StateScalar)
+	require.EqualValues(t, 0, dirLen(chain.InspectContractStateAt(blob.Interface.Name, block1)))
+}
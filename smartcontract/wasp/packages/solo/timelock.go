@@ -0,0 +1,109 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"sort"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+)
+
+// TimeLockedRequests returns handles of the requests in the backlog of the chain which are time locked
+// at the current logical time, in the order they were added to the backlog
+func (ch *Chain) TimeLockedRequests() []*RequestHandle {
+	ch.chPosted.Wait()
+	now := ch.Env.LogicalTime().Unix()
+
+	ch.backlogMutex.Lock()
+	defer ch.backlogMutex.Unlock()
+
+	ret := make([]*RequestHandle, 0)
+	for _, ref := range ch.backlog {
+		if int64(ref.RequestSection().Timelock()) > now {
+			ret = append(ret, ch.handleNoLock(ref))
+		}
+	}
+	return ret
+}
+
+// TimeLockedRequests returns handles of time locked requests in the backlogs of all chains of the environment
+func (env *Solo) TimeLockedRequests() map[coretypes.ChainID][]*RequestHandle {
+	ret := make(map[coretypes.ChainID][]*RequestHandle)
+	for _, ch := range env.chainsList() {
+		if reqs := ch.TimeLockedRequests(); len(reqs) > 0 {
+			ret[ch.ChainID] = reqs
+		}
+	}
+	return ret
+}
+
+// TimeLock returns the time until which the request is locked. Zero time means the request is not time locked
+func (h *RequestHandle) TimeLock() time.Time {
+	tl := h.ref.RequestSection().Timelock()
+	if tl == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(tl), 0)
+}
+
+// unlockedBacklogLen is a thread-safe function to return number of requests in the backlog which are not time locked
+func (ch *Chain) unlockedBacklogLen() int {
+	ch.chPosted.Wait()
+	now := ch.Env.LogicalTime().Unix()
+
+	ch.backlogMutex.Lock()
+	defer ch.backlogMutex.Unlock()
+
+	ret := 0
+	for _, ref := range ch.backlog {
+		if int64(ref.RequestSection().Timelock()) <= now {
+			ret++
+		}
+	}
+	return ret
+}
+
+// runUnlockedRequests runs requests which are not time locked anymore in all chains with automatic batching.
+// It is called after the logical clock is advanced, so that requests unlocked by the clock are processed
+// before AdvanceClockBy or AdvanceClockTo returns
+func (env *Solo) runUnlockedRequests() {
+	for _, ch := range env.chainsList() {
+		ch.chPosted.Wait()
+		batch := ch.collateBatch()
+		if len(batch) > 0 {
+			if _, err := ch.runFromBacklog(batch, "unlocked"); err != nil {
+				ch.Log.Errorf("runBatch: %v", err)
+			}
+		}
+		if locked := ch.TimeLockedRequests(); len(locked) > 0 {
+			ch.Log.Infof("time locked requests in the backlog: %d, next unlocks at %v", len(locked), nextUnlock(locked))
+		}
+	}
+}
+
+// chainsList returns chains of the environment sorted by chain ID
+func (env *Solo) chainsList() []*Chain {
+	env.glbMutex.Lock()
+	defer env.glbMutex.Unlock()
+
+	ret := make([]*Chain, 0, len(env.chains))
+	for _, ch := range env.chains {
+		ret = append(ret, ch)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ChainID.String() < ret[j].ChainID.String()
+	})
+	return ret
+}
+
+func nextUnlock(handles []*RequestHandle) time.Time {
+	var ret time.Time
+	for _, h := range handles {
+		if tl := h.TimeLock(); ret.IsZero() || tl.Before(ret) {
+			ret = tl
+		}
+	}
+	return ret
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/vm"
+)
+
+// SetCallTracing turns on and off tracing of requests run by the VM. It is off by default.
+// The trace of the request lists all calls of entry points, with state accessed by each call
+// and the host calls of Wasm contracts. See GetCallTrace and vm.RequestTrace
+func (ch *Chain) SetCallTracing(on bool) {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	ch.callTracing = on
+	if on && ch.callTraces == nil {
+		ch.callTraces = make(map[coretypes.RequestID]*vm.RequestTrace)
+	}
+}
+
+// GetCallTrace returns the trace of the request, or nil if the request was not run with tracing on
+func (ch *Chain) GetCallTrace(reqID coretypes.RequestID) *vm.RequestTrace {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	return ch.callTraces[reqID]
+}
+
+// traceTask sets up the task to store traces of requests, if tracing is on. Must be called with runVMMutex locked
+func (ch *Chain) traceTask(task *vm.VMTask) {
+	if !ch.callTracing {
+		return
+	}
+	task.OnRequestTrace = func(trace *vm.RequestTrace) {
+		ch.callTraces[trace.RequestID] = trace
+	}
+}
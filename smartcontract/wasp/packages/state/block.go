@@ -183,7 +183,12 @@ func dbkeyBatch(stateIndex uint32) []byte {
 }
 
 func LoadBlock(chainID *coretypes.ChainID, stateIndex uint32) (Block, error) {
-	data, err := database.GetPartition(chainID).Get(dbkeyBatch(stateIndex))
+	return LoadBlockFromDB(database.GetPartition(chainID), stateIndex)
+}
+
+// LoadBlockFromDB loads the block with the given index from the given partition instead of the node database
+func LoadBlockFromDB(db kvstore.KVStore, stateIndex uint32) (Block, error) {
+	data, err := db.Get(dbkeyBatch(stateIndex))
 	if err == kvstore.ErrKeyNotFound {
 		return nil, nil
 	}
@@ -201,6 +201,11 @@ func LoadSolidState(chainID *coretypes.ChainID) (VirtualState, Block, bool, erro
 	return loadSolidState(getSCPartition(chainID), chainID)
 }
 
+// LoadSolidStateFromDB loads the solid state of the chain from the given partition instead of the node database
+func LoadSolidStateFromDB(db kvstore.KVStore, chainID *coretypes.ChainID) (VirtualState, Block, bool, error) {
+	return loadSolidState(db, chainID)
+}
+
 func loadSolidState(db kvstore.KVStore, chainID *coretypes.ChainID) (VirtualState, Block, bool, error) {
 	stateIndexBin, err := db.Get(dbprovider.MakeKey(dbprovider.ObjectTypeSolidStateIndex))
 	if err == kvstore.ErrKeyNotFound {
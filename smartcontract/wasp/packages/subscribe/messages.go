@@ -0,0 +1,91 @@
+package subscribe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/publisher"
+	"go.nanomsg.org/mangos/v3"
+	"go.nanomsg.org/mangos/v3/protocol/sub"
+)
+
+// HandshakeTimeout is the time to wait for the handshake message of the publisher
+var HandshakeTimeout = 10 * time.Second
+
+// SubscribeMessages connects to the publisher, negotiates the version of the message schema and
+// delivers decoded messages of the given types (of all types if none is given).
+// Returns the negotiated version. Fails if the publisher doesn't announce a common version within
+// HandshakeTimeout. The channel of messages is closed when the connection is closed
+func SubscribeMessages(host string, messages chan<- *publisher.Message, done <-chan bool, msgTypes ...string) (uint16, error) {
+	socket, err := sub.NewSocket()
+	if err != nil {
+		return 0, err
+	}
+	version, err := handshake(socket, host)
+	if err != nil {
+		socket.Close()
+		return 0, err
+	}
+	if len(msgTypes) == 0 {
+		msgTypes = []string{""}
+	}
+	for _, msgType := range msgTypes {
+		if err := socket.SetOption(mangos.OptionSubscribe, []byte(publisher.Topic(version, msgType))); err != nil {
+			socket.Close()
+			return 0, err
+		}
+	}
+	if err := socket.SetOption(mangos.OptionUnsubscribe, []byte(publisher.HelloTopic)); err != nil {
+		socket.Close()
+		return 0, err
+	}
+	if err := socket.SetOption(mangos.OptionRecvDeadline, time.Duration(0)); err != nil {
+		socket.Close()
+		return 0, err
+	}
+
+	go func() {
+		for {
+			buf, err := socket.Recv()
+			if err != nil {
+				close(messages)
+				return
+			}
+			// hello messages received before unsubscribing are skipped too
+			msg, err := publisher.DecodeMessage(buf)
+			if err != nil {
+				continue
+			}
+			messages <- msg
+		}
+	}()
+
+	go func() {
+		<-done
+		socket.Close()
+	}()
+
+	return version, nil
+}
+
+// handshake dials the publisher and waits for the hello message. Returns the negotiated version
+func handshake(socket mangos.Socket, host string) (uint16, error) {
+	if err := socket.SetOption(mangos.OptionSubscribe, []byte(publisher.HelloTopic)); err != nil {
+		return 0, err
+	}
+	if err := socket.SetOption(mangos.OptionRecvDeadline, HandshakeTimeout); err != nil {
+		return 0, err
+	}
+	if err := socket.Dial("tcp://" + host); err != nil {
+		return 0, fmt.Errorf("can't dial on sub socket %s: %s", host, err.Error())
+	}
+	buf, err := socket.Recv()
+	if err != nil {
+		return 0, fmt.Errorf("no handshake from the publisher %s: %v", host, err)
+	}
+	hello, err := publisher.DecodeHello(buf)
+	if err != nil {
+		return 0, err
+	}
+	return hello.NegotiateVersion()
+}
@@ -0,0 +1,70 @@
+package subscribe
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/publisher"
+	"github.com/stretchr/testify/require"
+	"go.nanomsg.org/mangos/v3/protocol/pub"
+)
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestSubscribeMessages(t *testing.T) {
+	port := freePort(t)
+	socket := publisher.NewPubSocket()
+	defer socket.Close()
+	require.NoError(t, socket.Listen(fmt.Sprintf("tcp://127.0.0.1:%d", port)))
+
+	messages := make(chan *publisher.Message, 10)
+	done := make(chan bool)
+	defer close(done)
+	version, err := SubscribeMessages(fmt.Sprintf("127.0.0.1:%d", port), messages, done, publisher.MsgVM)
+	require.NoError(t, err)
+	require.EqualValues(t, publisher.Version, version)
+
+	chainID := coretypes.ChainID{1, 2, 3}
+	// the subscription is effective after a short while, so messages are repeated until received
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, msg := range []*publisher.Message{
+			publisher.NewCommitteeMessage(&chainID, true),
+			publisher.NewVMMessage(&chainID, coretypes.Hn("game"), nil, "player moved"),
+		} {
+			data, err := msg.Encode()
+			require.NoError(t, err)
+			require.NoError(t, socket.Send(data))
+		}
+		select {
+		case msg := <-messages:
+			require.EqualValues(t, publisher.MsgVM, msg.Type)
+			require.EqualValues(t, "player moved", msg.Payload[publisher.PayloadMessage])
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	t.Fatal("message not received")
+}
+
+func TestSubscribeMessagesNoHandshake(t *testing.T) {
+	port := freePort(t)
+	socket, err := pub.NewSocket()
+	require.NoError(t, err)
+	defer socket.Close()
+	require.NoError(t, socket.Listen(fmt.Sprintf("tcp://127.0.0.1:%d", port)))
+
+	timeout := HandshakeTimeout
+	HandshakeTimeout = 200 * time.Millisecond
+	defer func() { HandshakeTimeout = timeout }()
+	_, err = SubscribeMessages(fmt.Sprintf("127.0.0.1:%d", port), make(chan *publisher.Message), make(chan bool))
+	require.Error(t, err)
+}
@@ -0,0 +1,674 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testutil
+
+// A deterministic simulator of the peering network.
+// It is used to reproduce the runs of network protocols in unit tests.
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/peering/group"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"go.dedis.ch/kyber/v3/util/random"
+)
+
+// SimTraceEvent is a kind of the entry in the trace of the simulated network.
+type SimTraceEvent string
+
+const (
+	SimSent      SimTraceEvent = "sent"
+	SimDelivered SimTraceEvent = "delivered"
+	SimDropped   SimTraceEvent = "dropped"
+	SimCrashed   SimTraceEvent = "crashed"
+	SimRestarted SimTraceEvent = "restarted"
+)
+
+// SimTraceEntry records a single event in the simulated network.
+type SimTraceEntry struct {
+	Time    time.Duration // Virtual time since the start of the simulation.
+	Seq     uint64        // Sequence number of the message between From and To, 0 for the node events.
+	Event   SimTraceEvent
+	From    string
+	To      string
+	MsgType byte
+	ChainID coretypes.ChainID
+	Reason  string // Why the message was dropped.
+}
+
+func (e *SimTraceEntry) String() string {
+	if e.Seq == 0 {
+		return fmt.Sprintf("%v %s %s", e.Time, e.Event, e.To)
+	}
+	ret := fmt.Sprintf("%v %s %s-%d->%s#%d", e.Time, e.Event, e.From, e.MsgType, e.To, e.Seq)
+	if e.Reason != "" {
+		ret += " (" + e.Reason + ")"
+	}
+	return ret
+}
+
+// SimMsgFilter selects the messages in the simulated network.
+// Empty From or To matches all nodes, zero MsgType matches all message types.
+type SimMsgFilter struct {
+	From    string
+	To      string
+	MsgType byte
+}
+
+func (f *SimMsgFilter) matches(from, to string, msgType byte) bool {
+	return (f.From == "" || f.From == from) &&
+		(f.To == "" || f.To == to) &&
+		(f.MsgType == 0 || f.MsgType == msgType)
+}
+
+type simDropRule struct {
+	filter SimMsgFilter
+	count  int // Number of messages still to drop, negative means unlimited.
+}
+
+// PeeringSimulator is a seeded, virtual-time simulation of the peering network.
+// Messages are not delivered by themselves: the test advances the simulation
+// by Step, RunFor or RunUntilIdle and the receive callbacks are called in the
+// goroutine of the test, one by one, in the order defined by the seed.
+// The run is reproducible as long as the messages are sent from the callbacks
+// or from the goroutine of the test. The delays and the order of the messages
+// don't depend on the order in which a node sends its messages to different
+// peers, e.g. when iterating over a map. Protocols sending from their own
+// goroutines, like the DKG, are run by calling Step from the goroutine of the
+// test whenever Pending is signaled. The order of the deliveries is then defined
+// by the seed, but the retries of such protocols still depend on the wall clock.
+type PeeringSimulator struct {
+	mutex      sync.Mutex
+	seed       int64
+	now        time.Duration
+	order      uint64 // Last sequence number of the timers.
+	queue      simQueue
+	nodes      []*simNode
+	providers  []*simNetworkProvider
+	delayFrom  time.Duration
+	delayTill  time.Duration
+	partitions map[string]int // Partition of each node, nil if the network is not partitioned.
+	dropRules  []*simDropRule
+	trace      []*SimTraceEntry
+	pending    chan struct{} // Signaled when an event is added to the queue.
+	log        *logger.Logger
+}
+
+// NewPeeringSimulator creates the simulated network of the nodes with the specified NetIDs.
+// The keys of the nodes and all the random decisions of the network are derived from the seed.
+func NewPeeringSimulator(netIDs []string, seed int64, log *logger.Logger) *PeeringSimulator {
+	return NewPeeringSimulatorWithSuite(netIDs, edwards25519.NewBlakeSHA256Ed25519(), seed, log)
+}
+
+// NewPeeringSimulatorWithSuite is the same as NewPeeringSimulator, but the keys
+// of the nodes are in the specified group, e.g. the one used by the DKG.
+func NewPeeringSimulatorWithSuite(netIDs []string, suite kyber.Group, seed int64, log *logger.Logger) *PeeringSimulator {
+	rnd := rand.New(rand.NewSource(seed))
+	sim := PeeringSimulator{
+		seed:      seed,
+		queue:     make(simQueue, 0),
+		nodes:     make([]*simNode, len(netIDs)),
+		providers: make([]*simNetworkProvider, len(netIDs)),
+		dropRules: make([]*simDropRule, 0),
+		trace:     make([]*SimTraceEntry, 0),
+		pending:   make(chan struct{}, 1),
+		log:       log,
+	}
+	for i := range netIDs {
+		secKey := suite.Scalar().Pick(random.New(rnd))
+		sim.nodes[i] = &simNode{
+			netID:   netIDs[i],
+			pubKey:  suite.Point().Mul(secKey, nil),
+			secKey:  secKey,
+			recvCbs: make([]*simCb, 0),
+			sent:    make(map[string]uint64),
+		}
+	}
+	for i := range sim.nodes {
+		sim.providers[i] = newSimNetworkProvider(sim.nodes[i], &sim)
+	}
+	return &sim
+}
+
+// NetworkProviders returns network providers for each of the nodes in the network.
+func (s *PeeringSimulator) NetworkProviders() []peering.NetworkProvider {
+	ret := make([]peering.NetworkProvider, len(s.providers))
+	for i := range s.providers {
+		ret[i] = s.providers[i]
+	}
+	return ret
+}
+
+// PubKeys returns the public keys of the nodes, in the order of the NetIDs.
+func (s *PeeringSimulator) PubKeys() []kyber.Point {
+	ret := make([]kyber.Point, len(s.nodes))
+	for i := range s.nodes {
+		ret[i] = s.nodes[i].pubKey
+	}
+	return ret
+}
+
+// SecKeys returns the private keys of the nodes, in the order of the NetIDs.
+func (s *PeeringSimulator) SecKeys() []kyber.Scalar {
+	ret := make([]kyber.Scalar, len(s.nodes))
+	for i := range s.nodes {
+		ret[i] = s.nodes[i].secKey
+	}
+	return ret
+}
+
+// SetDelay makes each message to be delivered after a random delay in the
+// specified range. Messages with different delays are delivered out of order.
+// By default the delay is zero and the messages are delivered in the order they were sent.
+func (s *PeeringSimulator) SetDelay(delayFrom, delayTill time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.delayFrom = delayFrom
+	s.delayTill = delayTill
+}
+
+// Partition splits the network into the specified groups of nodes.
+// Messages between the groups are dropped, including the ones in flight.
+// The nodes not mentioned in any group are isolated.
+func (s *PeeringSimulator) Partition(groups ...[]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.partitions = make(map[string]int)
+	for i := range s.nodes {
+		s.partitions[s.nodes[i].netID] = -1 - i
+	}
+	for i := range groups {
+		for _, netID := range groups[i] {
+			s.partitions[netID] = i
+		}
+	}
+}
+
+// Heal removes all the partitions.
+func (s *PeeringSimulator) Heal() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.partitions = nil
+}
+
+// Drop drops the next count messages matching the filter.
+// Negative count drops all the matching messages until ClearDrops is called.
+func (s *PeeringSimulator) Drop(filter SimMsgFilter, count int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.dropRules = append(s.dropRules, &simDropRule{filter: filter, count: count})
+}
+
+// ClearDrops removes all the rules added by Drop.
+func (s *PeeringSimulator) ClearDrops() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.dropRules = make([]*simDropRule, 0)
+}
+
+// Crash stops the node: all its receive callbacks are detached, messages to and from it are dropped.
+func (s *PeeringSimulator) Crash(netID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	node := s.nodeByNetID(netID)
+	node.crashed = true
+	node.recvCbs = make([]*simCb, 0)
+	s.traceNode(SimCrashed, netID)
+}
+
+// Restart makes the crashed node to communicate again. The callbacks have to be attached anew.
+func (s *PeeringSimulator) Restart(netID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.nodeByNetID(netID).crashed = false
+	s.traceNode(SimRestarted, netID)
+}
+
+// Now returns the virtual time elapsed since the start of the simulation.
+func (s *PeeringSimulator) Now() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.now
+}
+
+// AfterFunc calls the function in the goroutine advancing the simulation,
+// when the virtual time reaches now + delay. It is intended for the timers of the simulated protocol.
+func (s *PeeringSimulator) AfterFunc(delay time.Duration, f func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.order++
+	heap.Push(&s.queue, &simEvent{at: s.now + delay, order: s.order, timer: f})
+	s.notifyPending()
+}
+
+// Pending is signaled when a message or a timer is added to the simulation.
+// The test waits on it, when the nodes send messages from their own goroutines.
+func (s *PeeringSimulator) Pending() <-chan struct{} {
+	return s.pending
+}
+
+// Step processes the next pending event, advancing the virtual time up to it.
+// Returns false if there is nothing to process.
+func (s *PeeringSimulator) Step() bool {
+	s.mutex.Lock()
+	if s.queue.Len() == 0 {
+		s.mutex.Unlock()
+		return false
+	}
+	ev := heap.Pop(&s.queue).(*simEvent)
+	s.now = ev.at
+	if ev.timer != nil {
+		s.mutex.Unlock()
+		ev.timer()
+		return true
+	}
+	callbacks := s.deliver(ev)
+	s.mutex.Unlock()
+	for _, cb := range callbacks {
+		msg := ev.msg // Each callback gets its own copy, the receivers set e.g. the SenderIndex.
+		cb.callback(&peering.RecvEvent{
+			From: cb.destNP.senderByNetID(ev.from.netID),
+			Msg:  &msg,
+		})
+	}
+	return true
+}
+
+// RunFor processes all the events in the next period of the virtual time.
+func (s *PeeringSimulator) RunFor(period time.Duration) {
+	s.mutex.Lock()
+	till := s.now + period
+	s.mutex.Unlock()
+	for {
+		s.mutex.Lock()
+		hasNext := s.queue.Len() > 0 && s.queue[0].at <= till
+		s.mutex.Unlock()
+		if !hasNext || !s.Step() {
+			break
+		}
+	}
+	s.mutex.Lock()
+	s.now = till
+	s.mutex.Unlock()
+}
+
+// RunUntilIdle processes the events until there are none left, but not more than maxSteps.
+// Returns the number of the processed events.
+func (s *PeeringSimulator) RunUntilIdle(maxSteps int) int {
+	steps := 0
+	for steps < maxSteps && s.Step() {
+		steps++
+	}
+	return steps
+}
+
+// Trace returns all the events in the network since the start of the simulation.
+// The messages sent at once, e.g. in a single callback, are listed ordered by
+// the sender, the receiver and the sequence number.
+func (s *PeeringSimulator) Trace() []*SimTraceEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ret := make([]*SimTraceEntry, len(s.trace))
+	copy(ret, s.trace)
+	for i := 0; i < len(ret); {
+		j := i
+		for j < len(ret) && ret[j].Event == SimSent {
+			j++
+		}
+		if j == i {
+			i++
+			continue
+		}
+		sent := ret[i:j]
+		sort.SliceStable(sent, func(a, b int) bool {
+			if sent[a].From != sent[b].From {
+				return sent[a].From < sent[b].From
+			}
+			if sent[a].To != sent[b].To {
+				return sent[a].To < sent[b].To
+			}
+			return sent[a].Seq < sent[b].Seq
+		})
+		i = j
+	}
+	return ret
+}
+
+// TraceString returns the trace one entry per line, suitable to compare the runs.
+func (s *PeeringSimulator) TraceString() string {
+	lines := make([]string, 0)
+	for _, e := range s.Trace() {
+		lines = append(lines, e.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *PeeringSimulator) nodeByNetID(netID string) *simNode {
+	for i := range s.nodes {
+		if s.nodes[i].netID == netID {
+			return s.nodes[i]
+		}
+	}
+	panic(fmt.Sprintf("unknown node %s", netID))
+}
+
+// send enqueues the message. Assumes the simulator is locked.
+func (s *PeeringSimulator) send(from, to *simNode, msg *peering.PeerMessage) {
+	from.sent[to.netID]++
+	ev := &simEvent{at: s.now + s.delay(from, to, from.sent[to.netID]), from: from, to: to, msg: *msg, seq: from.sent[to.netID]}
+	if from.crashed {
+		s.traceMsg(SimDropped, ev, "sender crashed")
+		return
+	}
+	s.traceMsg(SimSent, ev, "")
+	heap.Push(&s.queue, ev)
+	s.notifyPending()
+}
+
+// notifyPending signals Pending without blocking. Assumes the simulator is locked.
+func (s *PeeringSimulator) notifyPending() {
+	select {
+	case s.pending <- struct{}{}:
+	default:
+	}
+}
+
+// delay of the message is derived from the seed and the identity of the message,
+// so it doesn't depend on the order the messages are sent.
+func (s *PeeringSimulator) delay(from, to *simNode, seq uint64) time.Duration {
+	if s.delayTill <= s.delayFrom {
+		return s.delayFrom
+	}
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d/%s/%s/%d", s.seed, from.netID, to.netID, seq)
+	rnd := rand.New(rand.NewSource(int64(h.Sum64())))
+	return s.delayFrom + time.Duration(rnd.Int63n(int64(s.delayTill-s.delayFrom)))
+}
+
+// deliver checks the message on arrival and returns the callbacks to call. Assumes the simulator is locked.
+func (s *PeeringSimulator) deliver(ev *simEvent) []*simCb {
+	if ev.to.crashed {
+		s.traceMsg(SimDropped, ev, "receiver crashed")
+		return nil
+	}
+	if s.partitions != nil && s.partitions[ev.from.netID] != s.partitions[ev.to.netID] {
+		s.traceMsg(SimDropped, ev, "partitioned")
+		return nil
+	}
+	if reason := s.dropReason(ev); reason != "" {
+		s.traceMsg(SimDropped, ev, reason)
+		return nil
+	}
+	s.traceMsg(SimDelivered, ev, "")
+	ret := make([]*simCb, 0)
+	for _, cb := range ev.to.recvCbs {
+		if cb.chainID == nil || *cb.chainID == ev.msg.ChainID {
+			ret = append(ret, cb)
+		}
+	}
+	return ret
+}
+
+// dropReason applies the rules added by Drop. Assumes the simulator is locked.
+func (s *PeeringSimulator) dropReason(ev *simEvent) string {
+	for _, rule := range s.dropRules {
+		if rule.count == 0 || !rule.filter.matches(ev.from.netID, ev.to.netID, ev.msg.MsgType) {
+			continue
+		}
+		if rule.count > 0 {
+			rule.count--
+		}
+		return "drop rule"
+	}
+	return ""
+}
+
+func (s *PeeringSimulator) traceMsg(event SimTraceEvent, ev *simEvent, reason string) {
+	s.trace = append(s.trace, &SimTraceEntry{
+		Time:    s.now,
+		Seq:     ev.seq,
+		Event:   event,
+		From:    ev.from.netID,
+		To:      ev.to.netID,
+		MsgType: ev.msg.MsgType,
+		ChainID: ev.msg.ChainID,
+		Reason:  reason,
+	})
+	s.log.Debugf("simulator: %s", s.trace[len(s.trace)-1].String())
+}
+
+func (s *PeeringSimulator) traceNode(event SimTraceEvent, netID string) {
+	s.trace = append(s.trace, &SimTraceEntry{Time: s.now, Event: event, To: netID})
+	s.log.Debugf("simulator: %s", s.trace[len(s.trace)-1].String())
+}
+
+// simEvent is a message in flight or a timer.
+type simEvent struct {
+	at    time.Duration
+	order uint64 // Timers only.
+	seq   uint64 // Messages only.
+	from  *simNode
+	to    *simNode
+	msg   peering.PeerMessage
+	timer func()
+}
+
+type simQueue []*simEvent
+
+func (q simQueue) Len() int { return len(q) }
+func (q simQueue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+	// Messages first, ordered by their identity, then timers in the order of scheduling.
+	if (q[i].timer == nil) != (q[j].timer == nil) {
+		return q[i].timer == nil
+	}
+	if q[i].timer != nil {
+		return q[i].order < q[j].order
+	}
+	if q[i].from.netID != q[j].from.netID {
+		return q[i].from.netID < q[j].from.netID
+	}
+	if q[i].to.netID != q[j].to.netID {
+		return q[i].to.netID < q[j].to.netID
+	}
+	return q[i].seq < q[j].seq
+}
+func (q simQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *simQueue) Push(x interface{}) { *q = append(*q, x.(*simEvent)) }
+func (q *simQueue) Pop() interface{} {
+	old := *q
+	ev := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return ev
+}
+
+// simNode stands for a node in the simulated network.
+type simNode struct {
+	netID   string
+	pubKey  kyber.Point
+	secKey  kyber.Scalar
+	crashed bool
+	recvCbs []*simCb
+	sent    map[string]uint64 // Number of messages sent to each node.
+}
+
+type simCb struct {
+	callback func(recv *peering.RecvEvent) // Receive callback.
+	destNP   *simNetworkProvider           // Destination node.
+	chainID  *coretypes.ChainID            // Only listen for specific chain msgs.
+}
+
+// simNetworkProvider is the view of the simulated network by a single node.
+type simNetworkProvider struct {
+	self    *simNode
+	sim     *PeeringSimulator
+	senders []*simSender // Senders for all the nodes.
+}
+
+func newSimNetworkProvider(self *simNode, sim *PeeringSimulator) *simNetworkProvider {
+	np := simNetworkProvider{
+		self:    self,
+		sim:     sim,
+		senders: make([]*simSender, len(sim.nodes)),
+	}
+	for i := range sim.nodes {
+		np.senders[i] = &simSender{node: sim.nodes[i], netProvider: &np}
+	}
+	return &np
+}
+
+// Run implements peering.NetworkProvider.
+func (p *simNetworkProvider) Run(stopCh <-chan struct{}) {
+	<-stopCh
+}
+
+// Self implements peering.NetworkProvider.
+func (p *simNetworkProvider) Self() peering.PeerSender {
+	return p.senderByNetID(p.self.netID)
+}
+
+// Group implements peering.NetworkProvider.
+func (p *simNetworkProvider) Group(peerAddrs []string) (peering.GroupProvider, error) {
+	peers := make([]peering.PeerSender, len(peerAddrs))
+	for i := range peerAddrs {
+		s := p.senderByNetID(peerAddrs[i])
+		if s == nil {
+			return nil, errors.New("unknown_node_location")
+		}
+		peers[i] = s
+	}
+	return group.NewPeeringGroupProvider(p, peers, p.sim.log), nil
+}
+
+// Attach implements peering.NetworkProvider.
+func (p *simNetworkProvider) Attach(chainID *coretypes.ChainID, callback func(recv *peering.RecvEvent)) interface{} {
+	p.sim.mutex.Lock()
+	defer p.sim.mutex.Unlock()
+	cb := &simCb{
+		callback: callback,
+		destNP:   p,
+		chainID:  chainID,
+	}
+	p.self.recvCbs = append(p.self.recvCbs, cb)
+	return cb
+}
+
+// Detach implements peering.NetworkProvider.
+func (p *simNetworkProvider) Detach(attachID interface{}) {
+	p.sim.mutex.Lock()
+	defer p.sim.mutex.Unlock()
+	for i := range p.self.recvCbs {
+		if p.self.recvCbs[i] == attachID {
+			p.self.recvCbs = append(p.self.recvCbs[:i], p.self.recvCbs[i+1:]...)
+			return
+		}
+	}
+}
+
+// PeerByNetID implements peering.NetworkProvider.
+func (p *simNetworkProvider) PeerByNetID(peerNetID string) (peering.PeerSender, error) {
+	if s := p.senderByNetID(peerNetID); s != nil {
+		return s, nil
+	}
+	return nil, errors.New("peer not found by NetID")
+}
+
+// PeerByPubKey implements peering.NetworkProvider.
+func (p *simNetworkProvider) PeerByPubKey(peerPub kyber.Point) (peering.PeerSender, error) {
+	for i := range p.senders {
+		if p.senders[i].node.pubKey.Equal(peerPub) {
+			return p.senders[i], nil
+		}
+	}
+	return nil, errors.New("peer not found by pubKey")
+}
+
+// PeerStatus implements peering.NetworkProvider.
+func (p *simNetworkProvider) PeerStatus() []peering.PeerStatusProvider {
+	peerStatus := make([]peering.PeerStatusProvider, len(p.senders))
+	for i := range peerStatus {
+		peerStatus[i] = p.senders[i]
+	}
+	return peerStatus
+}
+
+func (p *simNetworkProvider) senderByNetID(peerNetID string) *simSender {
+	for i := range p.senders {
+		if p.senders[i].node.netID == peerNetID {
+			return p.senders[i]
+		}
+	}
+	return nil
+}
+
+// simSender is a view of a remote node and implements the peering.PeerSender interface.
+type simSender struct {
+	node        *simNode
+	netProvider *simNetworkProvider
+}
+
+// NetID implements peering.PeerSender.
+func (p *simSender) NetID() string {
+	return p.node.netID
+}
+
+// PubKey implements peering.PeerSender.
+func (p *simSender) PubKey() kyber.Point {
+	return p.node.pubKey
+}
+
+// SendMsg implements peering.PeerSender.
+func (p *simSender) SendMsg(msg *peering.PeerMessage) {
+	sim := p.netProvider.sim
+	sim.mutex.Lock()
+	defer sim.mutex.Unlock()
+	sim.send(p.netProvider.self, p.node, msg)
+}
+
+// IsAlive implements peering.PeerSender.
+// The peer is alive if both nodes are running and are in the same partition.
+func (p *simSender) IsAlive() bool {
+	sim := p.netProvider.sim
+	sim.mutex.Lock()
+	defer sim.mutex.Unlock()
+	self := p.netProvider.self
+	if self.crashed || p.node.crashed {
+		return false
+	}
+	return sim.partitions == nil || sim.partitions[self.netID] == sim.partitions[p.node.netID]
+}
+
+// Await implements peering.PeerSender.
+func (p *simSender) Await(timeout time.Duration) error {
+	return nil
+}
+
+// IsInbound implements peering.PeerStatusProvider.
+func (p *simSender) IsInbound() bool {
+	return true // Not needed in tests.
+}
+
+// NumUsers implements peering.PeerStatusProvider.
+func (p *simSender) NumUsers() int {
+	return 0 // Not needed in tests.
+}
+
+// Close implements peering.PeerSender.
+func (p *simSender) Close() {
+	// Not needed in tests.
+}
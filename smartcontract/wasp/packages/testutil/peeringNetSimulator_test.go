@@ -0,0 +1,195 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testutil_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	simMsgPing = peering.FirstUserMsgCode
+	simMsgPong = peering.FirstUserMsgCode + 1
+)
+
+var simNetIDs = []string{"a", "b", "c", "d"}
+
+// runGossip makes each node to answer each ping with a pong to all the other nodes.
+func runGossip(t *testing.T, seed int64) (*testutil.PeeringSimulator, []int) {
+	log := testutil.WithLevel(testutil.NewLogger(t), logger.LevelInfo, false)
+	sim := testutil.NewPeeringSimulator(simNetIDs, seed, log)
+	sim.SetDelay(10*time.Millisecond, 100*time.Millisecond)
+	chainID := coretypes.NewRandomChainID()
+	received := make([]int, len(simNetIDs))
+	netProviders := sim.NetworkProviders()
+	for i := range netProviders {
+		i := i
+		grp, err := netProviders[i].Group(simNetIDs)
+		require.NoError(t, err)
+		netProviders[i].Attach(&chainID, func(recv *peering.RecvEvent) {
+			received[i]++
+			if recv.Msg.MsgType == simMsgPing {
+				grp.Broadcast(&peering.PeerMessage{ChainID: chainID, MsgType: simMsgPong}, false)
+			}
+		})
+	}
+	a, err := netProviders[0].Group(simNetIDs)
+	require.NoError(t, err)
+	a.Broadcast(&peering.PeerMessage{ChainID: chainID, MsgType: simMsgPing}, true)
+	require.EqualValues(t, 16, sim.RunUntilIdle(100))
+	return sim, received
+}
+
+func TestSimulatorDeterministic(t *testing.T) {
+	sim1, received1 := runGossip(t, 42)
+	sim2, received2 := runGossip(t, 42)
+	sim3, _ := runGossip(t, 43)
+	require.EqualValues(t, []int{4, 4, 4, 4}, received1)
+	require.EqualValues(t, received1, received2)
+	require.EqualValues(t, sim1.TraceString(), sim2.TraceString())
+	require.EqualValues(t, sim1.Now(), sim2.Now())
+	require.NotEqual(t, sim1.TraceString(), sim3.TraceString())
+	require.EqualValues(t, sim1.PubKeys(), sim2.PubKeys())
+}
+
+func TestSimulatorReorder(t *testing.T) {
+	log := testutil.NewLogger(t)
+	sim := testutil.NewPeeringSimulator([]string{"a", "b"}, 1, log)
+	sim.SetDelay(0, time.Second)
+	netProviders := sim.NetworkProviders()
+	order := make([]byte, 0)
+	netProviders[1].Attach(nil, func(recv *peering.RecvEvent) {
+		order = append(order, recv.Msg.MsgType)
+	})
+	b, err := netProviders[0].PeerByNetID("b")
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		b.SendMsg(&peering.PeerMessage{MsgType: simMsgPing + byte(i)})
+	}
+	sim.RunUntilIdle(100)
+	require.Len(t, order, 20)
+	inOrder := true
+	for i := 1; i < len(order); i++ {
+		inOrder = inOrder && order[i-1] < order[i]
+	}
+	require.False(t, inOrder)
+}
+
+func TestSimulatorPartitionAndDrop(t *testing.T) {
+	log := testutil.NewLogger(t)
+	sim := testutil.NewPeeringSimulator(simNetIDs, 1, log)
+	netProviders := sim.NetworkProviders()
+	received := make([]int, len(simNetIDs))
+	for i := range netProviders {
+		i := i
+		netProviders[i].Attach(nil, func(recv *peering.RecvEvent) {
+			received[i]++
+		})
+	}
+	broadcast := func(msgType byte) {
+		grp, err := netProviders[0].Group(simNetIDs)
+		require.NoError(t, err)
+		grp.Broadcast(&peering.PeerMessage{MsgType: msgType}, false)
+		sim.RunUntilIdle(100)
+	}
+	b, err := netProviders[0].PeerByNetID("b")
+	require.NoError(t, err)
+	c, err := netProviders[0].PeerByNetID("c")
+	require.NoError(t, err)
+
+	sim.Partition([]string{"a", "b"}, []string{"c"})
+	require.True(t, b.IsAlive())
+	require.False(t, c.IsAlive())
+	broadcast(simMsgPing)
+	require.EqualValues(t, []int{0, 1, 0, 0}, received)
+
+	sim.Heal()
+	sim.Drop(testutil.SimMsgFilter{MsgType: simMsgPong}, -1)
+	sim.Drop(testutil.SimMsgFilter{To: "d"}, 1)
+	broadcast(simMsgPong)
+	broadcast(simMsgPing)
+	broadcast(simMsgPing)
+	require.EqualValues(t, []int{0, 3, 2, 1}, received)
+
+	sim.ClearDrops()
+	broadcast(simMsgPong)
+	require.EqualValues(t, []int{0, 4, 3, 2}, received)
+
+	dropped := 0
+	for _, e := range sim.Trace() {
+		if e.Event == testutil.SimDropped {
+			dropped++
+		}
+	}
+	require.EqualValues(t, 6, dropped)
+}
+
+func TestSimulatorCrashRestart(t *testing.T) {
+	log := testutil.NewLogger(t)
+	sim := testutil.NewPeeringSimulator([]string{"a", "b"}, 1, log)
+	sim.SetDelay(time.Second, time.Second)
+	netProviders := sim.NetworkProviders()
+	received := 0
+	attach := func() {
+		netProviders[1].Attach(nil, func(recv *peering.RecvEvent) {
+			received++
+		})
+	}
+	attach()
+	b, err := netProviders[0].PeerByNetID("b")
+	require.NoError(t, err)
+
+	b.SendMsg(&peering.PeerMessage{MsgType: simMsgPing}) // Lost in flight.
+	sim.RunFor(500 * time.Millisecond)
+	sim.Crash("b")
+	require.False(t, b.IsAlive())
+	b.SendMsg(&peering.PeerMessage{MsgType: simMsgPing}) // Lost, b is down.
+	sim.RunFor(2 * time.Second)
+	require.EqualValues(t, 0, received)
+
+	sim.Restart("b")
+	b.SendMsg(&peering.PeerMessage{MsgType: simMsgPing}) // Nobody listens yet.
+	sim.RunFor(2 * time.Second)
+	attach()
+	b.SendMsg(&peering.PeerMessage{MsgType: simMsgPing})
+	sim.RunFor(2 * time.Second)
+	require.EqualValues(t, 1, received)
+	require.EqualValues(t, 6500*time.Millisecond, sim.Now())
+}
+
+func TestSimulatorTimers(t *testing.T) {
+	log := testutil.NewLogger(t)
+	sim := testutil.NewPeeringSimulator([]string{"a", "b"}, 1, log)
+	sim.SetDelay(100*time.Millisecond, 100*time.Millisecond)
+	netProviders := sim.NetworkProviders()
+	b, err := netProviders[0].PeerByNetID("b")
+	require.NoError(t, err)
+	// A retry timer of the protocol: resend until acknowledged.
+	acked := false
+	sent := 0
+	var retry func()
+	retry = func() {
+		if acked {
+			return
+		}
+		sent++
+		b.SendMsg(&peering.PeerMessage{MsgType: simMsgPing})
+		sim.AfterFunc(time.Second, retry)
+	}
+	netProviders[1].Attach(nil, func(recv *peering.RecvEvent) {
+		acked = true
+	})
+	sim.Drop(testutil.SimMsgFilter{MsgType: simMsgPing}, 2)
+	retry()
+	sim.RunUntilIdle(100)
+	require.True(t, acked)
+	require.EqualValues(t, 3, sent)
+	require.EqualValues(t, 3*time.Second, sim.Now())
+}
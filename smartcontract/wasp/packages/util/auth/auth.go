@@ -7,6 +7,8 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
+// AddAuthentication adds the authentication scheme of the config. Requests authenticated by the API
+// token or by the signature (see AddRoles) are not checked by the scheme
 func AddAuthentication(e *echo.Echo, config map[string]string) {
 	if len(config) == 0 {
 		return
@@ -24,7 +26,16 @@ func AddAuthentication(e *echo.Echo, config map[string]string) {
 }
 
 func addBasicAuth(e *echo.Echo, username string, password string) {
-	e.Use(middleware.BasicAuth(func(u, p string, c echo.Context) (bool, error) {
-		return u == username && p == password, nil
+	e.Use(middleware.BasicAuthWithConfig(middleware.BasicAuthConfig{
+		Skipper: isAuthenticated,
+		Validator: func(u, p string, c echo.Context) (bool, error) {
+			return u == username && p == password, nil
+		},
 	}))
 }
+
+// isAuthenticated checks if the request was authenticated by the middleware of AddRoles
+func isAuthenticated(c echo.Context) bool {
+	ok, _ := c.Get(contextKeyAuthenticated).(bool)
+	return ok
+}
@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/labstack/echo/v4"
+	"github.com/mr-tron/base58"
+)
+
+// Role is the permission level of the caller of the web API. Each role includes permissions of the lower roles
+type Role byte
+
+const (
+	// RolePublic allows read-only access to public data
+	RolePublic Role = iota
+	// RoleClient allows in addition to use resources of the node, e.g. to upload blobs and to wait for requests
+	RoleClient
+	// RoleAdmin allows in addition to manage the node
+	RoleAdmin
+)
+
+const (
+	HeaderAuthorization = "Authorization"
+	HeaderTimestamp     = "X-Wasp-Timestamp"
+	HeaderNonce         = "X-Wasp-Nonce"
+	HeaderSignature     = "X-Wasp-Signature"
+
+	bearerPrefix = "Bearer "
+
+	// signed requests with the timestamp further from the time of the node are rejected
+	MaxSignatureAge = 5 * time.Minute
+
+	contextKeyRole          = "auth.role"
+	contextKeyAuthenticated = "auth.authenticated"
+)
+
+var roleNames = map[Role]string{
+	RolePublic: "public",
+	RoleClient: "client",
+	RoleAdmin:  "admin",
+}
+
+func (r Role) String() string {
+	if s, ok := roleNames[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("role(%d)", r)
+}
+
+func RoleFromString(s string) (Role, error) {
+	for r, name := range roleNames {
+		if name == s {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown role '%s'", s)
+}
+
+// CredentialStore resolves roles of credentials. It is implemented by the registry
+type CredentialStore interface {
+	// TokenRole returns the role of the API token or false if the token is not valid
+	TokenRole(token string) (Role, bool, error)
+	// AddressRole returns the role of the owner of the ed25519 key or false if the key is not registered
+	AddressRole(addr *address.Address) (Role, bool, error)
+}
+
+type RolesConfig struct {
+	Store CredentialStore
+	// role of requests without credentials
+	DefaultRole Role
+	// requests without credentials from these addresses and from the loopback get the admin role
+	AdminWhitelist []net.IP
+}
+
+// AddRoles resolves the role of each request from its credentials. Requests with invalid credentials
+// are rejected with 401. The role is retrieved with GetRole and checked with RequireRole.
+// It must be added before AddAuthentication: requests with the API token or the signature don't
+// need the credentials of the basic authentication
+func AddRoles(e *echo.Echo, config RolesConfig) {
+	nonces := newNonceCache()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role, err := resolveRole(c, &config, nonces)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+			c.Set(contextKeyRole, role)
+			return next(c)
+		}
+	})
+}
+
+// GetRole returns the role of the request resolved by the middleware of AddRoles.
+// Without the middleware every request has the admin role
+func GetRole(c echo.Context) Role {
+	if role, ok := c.Get(contextKeyRole).(Role); ok {
+		return role
+	}
+	return RoleAdmin
+}
+
+// RequireRole rejects requests with a role lower than the given one: with 401 if the request has
+// no credentials, with 403 otherwise
+func RequireRole(role Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if GetRole(c) >= role {
+				return next(c)
+			}
+			if !hasCredentials(c.Request()) {
+				return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("%s role is required", role))
+			}
+			return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("%s role is required", role))
+		}
+	}
+}
+
+func hasCredentials(req *http.Request) bool {
+	return strings.HasPrefix(req.Header.Get(HeaderAuthorization), bearerPrefix) || req.Header.Get(HeaderSignature) != ""
+}
+
+func resolveRole(c echo.Context, config *RolesConfig, nonces *nonceCache) (Role, error) {
+	req := c.Request()
+	if auth := req.Header.Get(HeaderAuthorization); strings.HasPrefix(auth, bearerPrefix) {
+		role, ok, err := config.Store.TokenRole(strings.TrimPrefix(auth, bearerPrefix))
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, fmt.Errorf("invalid API token")
+		}
+		c.Set(contextKeyAuthenticated, true)
+		return role, nil
+	}
+	if req.Header.Get(HeaderSignature) != "" {
+		addr, err := verifySignedRequest(c, nonces)
+		if err != nil {
+			return 0, err
+		}
+		role, ok, err := config.Store.AddressRole(addr)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, fmt.Errorf("key of %s is not registered", addr.String())
+		}
+		c.Set(contextKeyAuthenticated, true)
+		return role, nil
+	}
+	if isWhitelisted(req.RemoteAddr, config.AdminWhitelist) {
+		return RoleAdmin, nil
+	}
+	return config.DefaultRole, nil
+}
+
+// isWhitelisted checks the address of the connection. Headers like X-Forwarded-For are not trusted
+func isWhitelisted(remoteAddr string, whitelist []net.IP) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	for _, whitelistedIP := range whitelist {
+		if ip.Equal(whitelistedIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySignedRequest checks the signature of the request and returns the address of the signer.
+// The nonce of each signer is accepted once
+func verifySignedRequest(c echo.Context, nonces *nonceCache) (*address.Address, error) {
+	req := c.Request()
+	ts, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s header", HeaderTimestamp)
+	}
+	now := time.Now()
+	if age := now.Sub(time.Unix(0, ts)); age > MaxSignatureAge || age < -MaxSignatureAge {
+		return nil, fmt.Errorf("signature has expired")
+	}
+	nonce := req.Header.Get(HeaderNonce)
+	if nonce == "" || len(nonce) > maxNonceLength {
+		return nil, fmt.Errorf("invalid %s header", HeaderNonce)
+	}
+	sigBytes, err := base58.Decode(req.Header.Get(HeaderSignature))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s header", HeaderSignature)
+	}
+	sig, _, err := signaturescheme.Ed25519SignatureFromBytes(sigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s header: %v", HeaderSignature, err)
+	}
+	var body []byte
+	if req.Body != nil {
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if !sig.IsValid(SignedRequestData(req.Method, req.URL.RequestURI(), ts, nonce, body)) {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	addr := sig.Address()
+	if !nonces.add(addr.String(), nonce, now) {
+		return nil, fmt.Errorf("signed request has already been used")
+	}
+	return &addr, nil
+}
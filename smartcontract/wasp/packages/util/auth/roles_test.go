@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+type testStore struct {
+	tokens map[string]Role
+	keys   map[address.Address]Role
+}
+
+func (s *testStore) TokenRole(token string) (Role, bool, error) {
+	role, ok := s.tokens[token]
+	return role, ok, nil
+}
+
+func (s *testStore) AddressRole(addr *address.Address) (Role, bool, error) {
+	role, ok := s.keys[*addr]
+	return role, ok, nil
+}
+
+func newTestServer(store CredentialStore) *echo.Echo {
+	e := echo.New()
+	AddRoles(e, RolesConfig{Store: store, DefaultRole: RolePublic})
+	e.POST("/client", func(c echo.Context) error {
+		body := new(bytes.Buffer)
+		_, _ = body.ReadFrom(c.Request().Body)
+		return c.String(http.StatusOK, body.String())
+	}, RequireRole(RoleClient))
+	e.GET("/admin", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, RequireRole(RoleAdmin))
+	return e
+}
+
+func serve(e *echo.Echo, req *http.Request, remoteAddr string) *httptest.ResponseRecorder {
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRoleFromString(t *testing.T) {
+	for _, r := range []Role{RolePublic, RoleClient, RoleAdmin} {
+		back, err := RoleFromString(r.String())
+		require.NoError(t, err)
+		require.EqualValues(t, r, back)
+	}
+	_, err := RoleFromString("root")
+	require.Error(t, err)
+}
+
+func TestToken(t *testing.T) {
+	e := newTestServer(&testStore{tokens: map[string]Role{"client-token": RoleClient}})
+
+	req := httptest.NewRequest(http.MethodPost, "/client", nil)
+	require.EqualValues(t, http.StatusUnauthorized, serve(e, req, "10.0.0.1:1234").Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/client", nil)
+	req.Header.Set(HeaderAuthorization, "Bearer client-token")
+	require.EqualValues(t, http.StatusOK, serve(e, req, "10.0.0.1:1234").Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set(HeaderAuthorization, "Bearer client-token")
+	require.EqualValues(t, http.StatusForbidden, serve(e, req, "10.0.0.1:1234").Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set(HeaderAuthorization, "Bearer wrong-token")
+	require.EqualValues(t, http.StatusUnauthorized, serve(e, req, "127.0.0.1:1234").Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	require.EqualValues(t, http.StatusOK, serve(e, req, "127.0.0.1:1234").Code)
+}
+
+func TestSignedRequest(t *testing.T) {
+	sigScheme := signaturescheme.ED25519(ed25519.GenerateKeyPair())
+	e := newTestServer(&testStore{keys: map[address.Address]Role{sigScheme.Address(): RoleClient}})
+
+	body := []byte(`{"data":"abc"}`)
+	req := httptest.NewRequest(http.MethodPost, "/client?x=1", bytes.NewReader(body))
+	SignRequest(req, body, sigScheme)
+	rec := serve(e, req, "10.0.0.1:1234")
+	require.EqualValues(t, http.StatusOK, rec.Code)
+	require.EqualValues(t, body, rec.Body.Bytes())
+
+	// tampered body
+	req = httptest.NewRequest(http.MethodPost, "/client?x=1", bytes.NewReader([]byte(`{"data":"xyz"}`)))
+	SignRequest(req, body, sigScheme)
+	require.EqualValues(t, http.StatusUnauthorized, serve(e, req, "10.0.0.1:1234").Code)
+
+	// unregistered key
+	other := signaturescheme.ED25519(ed25519.GenerateKeyPair())
+	req = httptest.NewRequest(http.MethodPost, "/client?x=1", bytes.NewReader(body))
+	SignRequest(req, body, other)
+	require.EqualValues(t, http.StatusUnauthorized, serve(e, req, "10.0.0.1:1234").Code)
+
+	// insufficient role
+	req = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	SignRequest(req, nil, sigScheme)
+	require.EqualValues(t, http.StatusForbidden, serve(e, req, "10.0.0.1:1234").Code)
+}
+
+func TestSignedRequestReplay(t *testing.T) {
+	sigScheme := signaturescheme.ED25519(ed25519.GenerateKeyPair())
+	e := newTestServer(&testStore{keys: map[address.Address]Role{sigScheme.Address(): RoleClient}})
+
+	body := []byte(`{"data":"abc"}`)
+	req := httptest.NewRequest(http.MethodPost, "/client", bytes.NewReader(body))
+	SignRequest(req, body, sigScheme)
+	require.EqualValues(t, http.StatusOK, serve(e, req, "10.0.0.1:1234").Code)
+
+	replayed := httptest.NewRequest(http.MethodPost, "/client", bytes.NewReader(body))
+	replayed.Header = req.Header.Clone()
+	require.EqualValues(t, http.StatusUnauthorized, serve(e, replayed, "10.0.0.1:1234").Code)
+
+	// without the nonce
+	req = httptest.NewRequest(http.MethodPost, "/client", bytes.NewReader(body))
+	SignRequest(req, body, sigScheme)
+	req.Header.Del(HeaderNonce)
+	require.EqualValues(t, http.StatusUnauthorized, serve(e, req, "10.0.0.1:1234").Code)
+}
+
+func TestNonceCache(t *testing.T) {
+	nc := newNonceCache()
+	now := time.Now()
+	require.True(t, nc.add("a", "1", now))
+	require.False(t, nc.add("a", "1", now))
+	require.True(t, nc.add("b", "1", now))
+	later := now.Add(3 * MaxSignatureAge)
+	require.True(t, nc.add("a", "2", later))
+	require.Len(t, nc.seen, 1)
+}
+
+func TestBasicAuthWithRoles(t *testing.T) {
+	e := echo.New()
+	AddRoles(e, RolesConfig{Store: &testStore{tokens: map[string]Role{"client-token": RoleClient}}, DefaultRole: RoleClient})
+	AddAuthentication(e, map[string]string{"scheme": "basic", "username": "wasp", "password": "secret"})
+	e.GET("/client", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, RequireRole(RoleClient))
+
+	req := httptest.NewRequest(http.MethodGet, "/client", nil)
+	require.EqualValues(t, http.StatusUnauthorized, serve(e, req, "10.0.0.1:1234").Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/client", nil)
+	req.SetBasicAuth("wasp", "secret")
+	require.EqualValues(t, http.StatusOK, serve(e, req, "10.0.0.1:1234").Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/client", nil)
+	req.Header.Set(HeaderAuthorization, "Bearer client-token")
+	require.EqualValues(t, http.StatusOK, serve(e, req, "10.0.0.1:1234").Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/client", nil)
+	req.Header.Set(HeaderAuthorization, "Bearer wrong-token")
+	require.EqualValues(t, http.StatusUnauthorized, serve(e, req, "10.0.0.1:1234").Code)
+}
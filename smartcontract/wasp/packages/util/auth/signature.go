@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/mr-tron/base58"
+)
+
+// maximum length of the nonce in the header
+const maxNonceLength = 64
+
+// SignedRequestData returns the data signed by the client: the method, the request URI, the timestamp
+// in nanoseconds, the nonce and the hash of the body of the request
+func SignedRequestData(method string, requestURI string, ts int64, nonce string, body []byte) []byte {
+	h := hashing.HashData(body)
+	return []byte(fmt.Sprintf("%s\n%s\n%d\n%s\n%s", method, requestURI, ts, nonce, h.String()))
+}
+
+// SignRequest adds the signature headers with a random nonce to the request. The body is the body of the request
+func SignRequest(req *http.Request, body []byte, sigScheme signaturescheme.SignatureScheme) {
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		panic(err)
+	}
+	ts := time.Now().UnixNano()
+	nonceStr := base58.Encode(nonce[:])
+	sig := sigScheme.Sign(SignedRequestData(req.Method, req.URL.RequestURI(), ts, nonceStr, body))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderNonce, nonceStr)
+	req.Header.Set(HeaderSignature, base58.Encode(sig.Bytes()))
+}
+
+// nonceCache keeps nonces of signed requests accepted within MaxSignatureAge, so a captured
+// request can't be replayed. Nonces older than the window are pruned, requests with them are
+// rejected by the timestamp anyway
+type nonceCache struct {
+	mutex     sync.Mutex
+	seen      map[string]time.Time
+	lastPrune time.Time
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{seen: make(map[string]time.Time)}
+}
+
+// add records the nonce of the signer. Returns false if the nonce was already used
+func (nc *nonceCache) add(signer string, nonce string, now time.Time) bool {
+	nc.mutex.Lock()
+	defer nc.mutex.Unlock()
+
+	if now.Sub(nc.lastPrune) > MaxSignatureAge/10 {
+		for key, ts := range nc.seen {
+			if now.Sub(ts) > 2*MaxSignatureAge {
+				delete(nc.seen, key)
+			}
+		}
+		nc.lastPrune = now
+	}
+	key := signer + "/" + nonce
+	if _, ok := nc.seen[key]; ok {
+		return false
+	}
+	nc.seen[key] = now
+	return true
+}
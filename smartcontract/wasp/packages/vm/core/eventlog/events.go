@@ -0,0 +1,237 @@
+package eventlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+const (
+	// VarEvents is the log of all typed events in the state of the eventlog contract
+	VarEvents = "__events"
+	// VarEventsByTopic is the prefix of the logs of event indices by contract and topic
+	VarEventsByTopic = "__eventsByTopic"
+	// VarEventsByRequest is the prefix of the logs of event indices by request ID
+	VarEventsByRequest = "__eventsByRequest"
+)
+
+// Event is the typed event emitted by a contract: the topic with the named parameters
+type Event struct {
+	Contract  coretypes.Hname
+	Topic     string
+	Params    dict.Dict
+	RequestID coretypes.RequestID
+	Timestamp int64
+}
+
+// eventsByTopicKey is the name of the index of the topic. The topic is hashed, so the names
+// of the indices have the same length and the name of one index can't be a prefix of another
+func eventsByTopicKey(contract coretypes.Hname, topic string) kv.Key {
+	topicHash := hashing.HashStrings(topic)
+	return kv.Key(VarEventsByTopic + string(contract.Bytes()) + string(topicHash[:]))
+}
+
+func eventsByRequestKey(reqID *coretypes.RequestID) kv.Key {
+	return kv.Key(VarEventsByRequest + string(reqID[:]))
+}
+
+// StoreEvent appends the event to the log of events and to the indices by topic and by request.
+// The state is the partition of the eventlog contract
+func StoreEvent(state kv.KVStore, ev *Event) {
+	events := collections.NewTimestampedLog(state, VarEvents)
+	index := util.Uint32To4Bytes(events.MustLen())
+	events.MustAppend(ev.Timestamp, ev.Bytes())
+	collections.NewTimestampedLog(state, eventsByTopicKey(ev.Contract, ev.Topic)).MustAppend(ev.Timestamp, index)
+	collections.NewTimestampedLog(state, eventsByRequestKey(&ev.RequestID)).MustAppend(ev.Timestamp, index)
+}
+
+// EventFilter selects events in the event log. Either Topic or RequestID must be specified
+type EventFilter struct {
+	Contract  coretypes.Hname
+	Topic     string               // if not empty, only events with the topic
+	RequestID *coretypes.RequestID // if not nil, only events emitted by the request
+	Key       kv.Key               // if not empty, only events with the parameter
+	Value     []byte               // if not nil, only events with the parameter Key equal to Value
+	FromTs    int64
+	ToTs      int64
+	MaxLast   uint32
+}
+
+// GetEvents returns the latest events matching the filter, in time descending order.
+// When the events are filtered by the parameter or by the request, only the latest
+// MaxScannedEvents entries of the index are checked, otherwise the latest MaxLast.
+// The state is the partition of the eventlog contract
+func GetEvents(state kv.KVStoreReader, f *EventFilter) ([]*Event, error) {
+	var index *collections.ImmutableTimestampedLog
+	switch {
+	case f.RequestID != nil:
+		index = collections.NewTimestampedLogReadOnly(state, eventsByRequestKey(f.RequestID))
+	case f.Topic != "":
+		index = collections.NewTimestampedLogReadOnly(state, eventsByTopicKey(f.Contract, f.Topic))
+	default:
+		return nil, fmt.Errorf("topic or request ID must be specified")
+	}
+	tts, err := index.TakeTimeSlice(f.FromTs, f.ToTs)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Event, 0)
+	if tts.IsEmpty() {
+		return ret, nil
+	}
+	maxScanned := f.MaxLast
+	if f.Key != "" || f.RequestID != nil {
+		maxScanned = MaxScannedEvents
+	}
+	events := collections.NewTimestampedLogReadOnly(state, VarEvents)
+	first, last := tts.FromToIndicesCapped(maxScanned)
+	for i := int64(last); i >= int64(first) && uint32(len(ret)) < f.MaxLast; i-- {
+		recs, err := index.LoadRecordsRaw(uint32(i), uint32(i), false)
+		if err != nil {
+			return nil, err
+		}
+		ev, err := loadEvent(events, recs[0])
+		if err != nil {
+			return nil, err
+		}
+		if f.matches(ev) {
+			ret = append(ret, ev)
+		}
+	}
+	return ret, nil
+}
+
+func loadEvent(events *collections.ImmutableTimestampedLog, rawIndexRecord []byte) (*Event, error) {
+	rec, err := collections.ParseRawLogRecord(rawIndexRecord)
+	if err != nil {
+		return nil, err
+	}
+	eventIndex, err := util.Uint32From4Bytes(rec.Data)
+	if err != nil {
+		return nil, err
+	}
+	recs, err := events.LoadRecordsRaw(eventIndex, eventIndex, false)
+	if err != nil {
+		return nil, err
+	}
+	if recs[0] == nil {
+		return nil, fmt.Errorf("inconsistency: event #%d not found", eventIndex)
+	}
+	if rec, err = collections.ParseRawLogRecord(recs[0]); err != nil {
+		return nil, err
+	}
+	return EventFromBytes(rec.Data)
+}
+
+func (f *EventFilter) matches(ev *Event) bool {
+	if ev.Contract != f.Contract {
+		return false
+	}
+	if f.Topic != "" && ev.Topic != f.Topic {
+		return false
+	}
+	if f.Key == "" {
+		return true
+	}
+	value := ev.Params.MustGet(f.Key)
+	if value == nil {
+		return false
+	}
+	return f.Value == nil || bytes.Equal(value, f.Value)
+}
+
+// String is the human readable form of the event, as published and stored in the timestamped log
+func (ev *Event) String() string {
+	keys := make([]string, 0)
+	ev.Params.MustIterateKeys("", func(key kv.Key) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	sort.Strings(keys)
+	params := make([]string, len(keys))
+	for i, k := range keys {
+		params[i] = fmt.Sprintf("%s: %x", k, ev.Params.MustGet(kv.Key(k)))
+	}
+	return fmt.Sprintf("%s{%s}", ev.Topic, strings.Join(params, ", "))
+}
+
+func (ev *Event) Bytes() []byte {
+	var buf bytes.Buffer
+	_ = ev.Write(&buf)
+	return buf.Bytes()
+}
+
+func EventFromBytes(data []byte) (*Event, error) {
+	ret := &Event{}
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (ev *Event) Write(w io.Writer) error {
+	if err := ev.Contract.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, ev.Topic); err != nil {
+		return err
+	}
+	if _, err := w.Write(ev.RequestID[:]); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, ev.Timestamp); err != nil {
+		return err
+	}
+	params := ev.Params
+	if params == nil {
+		params = dict.New()
+	}
+	return params.Write(w)
+}
+
+func (ev *Event) Read(r io.Reader) error {
+	var err error
+	if err = ev.Contract.Read(r); err != nil {
+		return err
+	}
+	if ev.Topic, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	if err = ev.RequestID.Read(r); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &ev.Timestamp); err != nil {
+		return err
+	}
+	ev.Params = dict.New()
+	return ev.Params.Read(r)
+}
+
+// DecodeEvents decodes the result of the FuncGetEvents view
+func DecodeEvents(res dict.Dict) ([]*Event, error) {
+	arr := collections.NewArrayReadOnly(res, ParamEvents)
+	n, err := arr.Len()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Event, n)
+	for i := uint16(0); i < n; i++ {
+		data, err := arr.GetAt(i)
+		if err != nil {
+			return nil, err
+		}
+		if ret[i], err = EventFromBytes(data); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
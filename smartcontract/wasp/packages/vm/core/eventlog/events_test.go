@@ -0,0 +1,65 @@
+package eventlog
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/stretchr/testify/require"
+)
+
+func storeEvents(state dict.Dict, contract coretypes.Hname, topic string, n int, params dict.Dict) {
+	// timestamps of the log must not decrease
+	ts := int64(collections.NewTimestampedLog(state, VarEvents).MustLen())
+	for i := 0; i < n; i++ {
+		StoreEvent(state, &Event{
+			Contract:  contract,
+			Topic:     topic,
+			Params:    params,
+			RequestID: coretypes.RequestID{byte(i)},
+			Timestamp: ts + int64(i) + 1,
+		})
+	}
+}
+
+func TestEventsByTopicKey(t *testing.T) {
+	state := dict.New()
+	contract := coretypes.Hn("test")
+	// the name of the index of the second topic would be a prefix of the keys in the index of the first one
+	topics := []string{"a", "a\x01\x00\x00\x00"}
+	storeEvents(state, contract, topics[0], 2, nil)
+	storeEvents(state, contract, topics[1], 3, nil)
+	for i, n := range []int{2, 3} {
+		events, err := GetEvents(state, &EventFilter{Contract: contract, Topic: topics[i], ToTs: 100, MaxLast: 50})
+		require.NoError(t, err)
+		require.Len(t, events, n)
+		for _, ev := range events {
+			require.EqualValues(t, topics[i], ev.Topic)
+		}
+	}
+	require.NotEqual(t, eventsByTopicKey(contract, "ab"), eventsByTopicKey(coretypes.Hn("test2"), "ab"))
+}
+
+func TestGetEventsScanCap(t *testing.T) {
+	state := dict.New()
+	contract := coretypes.Hn("test")
+	withKey := dict.New()
+	withKey.Set("k", []byte{1})
+	storeEvents(state, contract, "t", 10, withKey)
+	storeEvents(state, contract, "t", MaxScannedEvents, nil)
+
+	events, err := GetEvents(state, &EventFilter{Contract: contract, Topic: "t", ToTs: 100000, MaxLast: 20})
+	require.NoError(t, err)
+	require.Len(t, events, 20)
+
+	// the events with the parameter are older than the scanned ones
+	events, err = GetEvents(state, &EventFilter{Contract: contract, Topic: "t", Key: "k", ToTs: 100000, MaxLast: 20})
+	require.NoError(t, err)
+	require.Len(t, events, 0)
+
+	storeEvents(state, contract, "t", 1, withKey)
+	events, err = GetEvents(state, &EventFilter{Contract: contract, Topic: "t", Key: "k", ToTs: 100000, MaxLast: 20})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+}
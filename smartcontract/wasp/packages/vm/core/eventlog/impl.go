@@ -73,3 +73,67 @@ func getRecords(ctx coretypes.SandboxView) (dict.Dict, error) {
 	}
 	return ret, nil
 }
+
+// getEvents returns typed events, selected by the topic of the contract or by the request which emitted them.
+// In time descending order
+// Parameters:
+//	- ParamContractHname Hname of the contract which emitted the events
+//	- ParamTopic Topic of the events. Mandatory if ParamRequestID is not specified
+//	- ParamRequestID Filter param, ID of the request which emitted the events
+//	- ParamKey Filter param, only events with the parameter. Only the latest MaxScannedEvents events are checked
+//	- ParamValue Filter param, only events with the parameter ParamKey equal to the value
+//	- ParamFromTs From interval. Defaults to 0
+//	- ParamToTs To Interval. Defaults to now
+//	- ParamMaxLastRecords Max amount of events that you want to return. Defaults to 50
+func getEvents(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+	var err error
+	f := &EventFilter{}
+	if f.Contract, err = params.GetHname(ParamContractHname); err != nil {
+		return nil, err
+	}
+	if f.Topic, err = params.GetString(ParamTopic, ""); err != nil {
+		return nil, err
+	}
+	reqIDBytes, err := params.GetBytes(ParamRequestID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if reqIDBytes != nil {
+		reqID, err := coretypes.NewRequestIDFromBytes(reqIDBytes)
+		if err != nil {
+			return nil, err
+		}
+		f.RequestID = &reqID
+	}
+	key, err := params.GetString(ParamKey, "")
+	if err != nil {
+		return nil, err
+	}
+	f.Key = kv.Key(key)
+	if f.Value, err = params.GetBytes(ParamValue, nil); err != nil {
+		return nil, err
+	}
+	maxLast, err := params.GetInt64(ParamMaxLastRecords, DefaultMaxNumberOfRecords)
+	if err != nil {
+		return nil, err
+	}
+	f.MaxLast = uint32(maxLast)
+	if f.FromTs, err = params.GetInt64(ParamFromTs, 0); err != nil {
+		return nil, err
+	}
+	if f.ToTs, err = params.GetInt64(ParamToTs, ctx.GetTimestamp()); err != nil {
+		return nil, err
+	}
+
+	events, err := GetEvents(ctx.State(), f)
+	if err != nil {
+		return nil, err
+	}
+	ret := dict.New()
+	a := collections.NewArray(ret, ParamEvents)
+	for _, ev := range events {
+		a.MustPush(ev.Bytes())
+	}
+	return ret, nil
+}
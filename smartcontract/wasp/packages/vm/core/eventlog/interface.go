@@ -22,6 +22,7 @@ func init() {
 	Interface.WithFunctions(initialize, []coreutil.ContractFunctionInterface{
 		coreutil.ViewFunc(FuncGetRecords, getRecords),
 		coreutil.ViewFunc(FuncGetNumRecords, getNumRecords),
+		coreutil.ViewFunc(FuncGetEvents, getEvents),
 	})
 }
 
@@ -33,10 +34,18 @@ const (
 	ParamMaxLastRecords = "maxLastRecords"
 	ParamNumRecords     = "numRecords"
 	ParamRecords        = "records"
+	ParamTopic          = "topic"
+	ParamRequestID      = "requestID"
+	ParamKey            = "key"
+	ParamValue          = "value"
+	ParamEvents         = "events"
 
 	// function names
 	FuncGetRecords    = "getRecords"
 	FuncGetNumRecords = "getNumRecords"
+	FuncGetEvents     = "getEvents"
 
 	DefaultMaxNumberOfRecords = 50
+	// MaxScannedEvents is the number of the latest events checked by getEvents when filtering by parameter or request
+	MaxScannedEvents = 1000
 )
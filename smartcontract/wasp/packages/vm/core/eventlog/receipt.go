@@ -0,0 +1,174 @@
+package eventlog
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+const (
+	// VarRequestReceipts is the map of receipts of processed requests in the state of the eventlog contract
+	VarRequestReceipts = "__requestReceipts"
+	// VarRequestReceiptsByBlock is the map of concatenated IDs of requests with receipts by the block index
+	VarRequestReceiptsByBlock = "__requestReceiptsByBlock"
+	// VarRequestReceiptsFrom is the index of the oldest block which may have receipts in the state
+	VarRequestReceiptsFrom = "__requestReceiptsFrom"
+
+	// MaxReceiptResultSize limits the size of the encoded result kept in the receipt.
+	// Only the hash of a larger result is kept
+	MaxReceiptResultSize = 1024
+	// ReceiptRetentionBlocks is the number of the latest blocks which keep receipts of their requests.
+	// Receipts of older blocks are pruned
+	ReceiptRetentionBlocks = 1000
+)
+
+// RequestReceipt records the outcome of the request processed by the VM: the block it was processed in,
+// the error message (empty if the request succeeded), the gas used and the result returned by the entry point.
+// The result is nil if it is larger than MaxReceiptResultSize, its hash is always known
+type RequestReceipt struct {
+	BlockIndex uint32
+	Timestamp  int64
+	Error      string
+	GasUsed    uint64
+	ResultHash hashing.HashValue
+	Result     dict.Dict
+}
+
+// NewRequestReceipt creates the receipt. The result is kept if it isn't larger than MaxReceiptResultSize
+func NewRequestReceipt(blockIndex uint32, ts int64, errMsg string, result dict.Dict) *RequestReceipt {
+	if result == nil {
+		result = dict.New()
+	}
+	ret := &RequestReceipt{
+		BlockIndex: blockIndex,
+		Timestamp:  ts,
+		Error:      errMsg,
+		ResultHash: result.Hash(),
+	}
+	var buf bytes.Buffer
+	_ = result.Write(&buf)
+	if buf.Len() <= MaxReceiptResultSize {
+		ret.Result = result
+	}
+	return ret
+}
+
+// StoreRequestReceipt stores the receipt of the request and prunes receipts of blocks older than
+// ReceiptRetentionBlocks. The state is the partition of the eventlog contract
+func StoreRequestReceipt(state kv.KVStore, reqID *coretypes.RequestID, rec *RequestReceipt) {
+	collections.NewMap(state, VarRequestReceipts).MustSetAt(reqID[:], rec.Bytes())
+	byBlock := collections.NewMap(state, VarRequestReceiptsByBlock)
+	blockKey := util.Uint32To4Bytes(rec.BlockIndex)
+	byBlock.MustSetAt(blockKey, append(byBlock.MustGetAt(blockKey), reqID[:]...))
+	pruneRequestReceipts(state, rec.BlockIndex)
+}
+
+// pruneRequestReceipts deletes receipts of blocks before the retention window of the block
+func pruneRequestReceipts(state kv.KVStore, blockIndex uint32) {
+	if blockIndex < ReceiptRetentionBlocks {
+		return
+	}
+	keepFrom := blockIndex - ReceiptRetentionBlocks + 1
+	var from uint32
+	if data := state.MustGet(VarRequestReceiptsFrom); data != nil {
+		from = util.MustUint32From4Bytes(data)
+	}
+	if from >= keepFrom {
+		return
+	}
+	receipts := collections.NewMap(state, VarRequestReceipts)
+	byBlock := collections.NewMap(state, VarRequestReceiptsByBlock)
+	for idx := from; idx < keepFrom; idx++ {
+		blockKey := util.Uint32To4Bytes(idx)
+		reqIDs := byBlock.MustGetAt(blockKey)
+		for i := 0; i+coretypes.RequestIDLength <= len(reqIDs); i += coretypes.RequestIDLength {
+			receipts.MustDelAt(reqIDs[i : i+coretypes.RequestIDLength])
+		}
+		byBlock.MustDelAt(blockKey)
+	}
+	state.Set(VarRequestReceiptsFrom, util.Uint32To4Bytes(keepFrom))
+}
+
+// GetRequestReceipt returns the receipt of the request or nil if the request has no receipt.
+// The state is the partition of the eventlog contract
+func GetRequestReceipt(state kv.KVStoreReader, reqID *coretypes.RequestID) (*RequestReceipt, error) {
+	data, err := collections.NewMapReadOnly(state, VarRequestReceipts).GetAt(reqID[:])
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return RequestReceiptFromBytes(data)
+}
+
+func (rec *RequestReceipt) Bytes() []byte {
+	var buf bytes.Buffer
+	_ = rec.Write(&buf)
+	return buf.Bytes()
+}
+
+func RequestReceiptFromBytes(data []byte) (*RequestReceipt, error) {
+	ret := &RequestReceipt{}
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (rec *RequestReceipt) Write(w io.Writer) error {
+	if err := util.WriteUint32(w, rec.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, rec.Timestamp); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, rec.Error); err != nil {
+		return err
+	}
+	if err := util.WriteUint64(w, rec.GasUsed); err != nil {
+		return err
+	}
+	if _, err := w.Write(rec.ResultHash[:]); err != nil {
+		return err
+	}
+	if err := util.WriteBoolByte(w, rec.Result != nil); err != nil {
+		return err
+	}
+	if rec.Result == nil {
+		return nil
+	}
+	return rec.Result.Write(w)
+}
+
+func (rec *RequestReceipt) Read(r io.Reader) error {
+	var err error
+	if err = util.ReadUint32(r, &rec.BlockIndex); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &rec.Timestamp); err != nil {
+		return err
+	}
+	if rec.Error, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	if err = util.ReadUint64(r, &rec.GasUsed); err != nil {
+		return err
+	}
+	if err = util.ReadHashValue(r, &rec.ResultHash); err != nil {
+		return err
+	}
+	var hasResult bool
+	if err = util.ReadBoolByte(r, &hasResult); err != nil {
+		return err
+	}
+	if !hasResult {
+		rec.Result = nil
+		return nil
+	}
+	rec.Result = dict.New()
+	return rec.Result.Read(r)
+}
@@ -0,0 +1,58 @@
+package eventlog
+
+import (
+	"testing"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiptResultSize(t *testing.T) {
+	small := dict.New()
+	small.Set("a", []byte{1})
+	rec := NewRequestReceipt(1, 100, "", small)
+	back, err := RequestReceiptFromBytes(rec.Bytes())
+	require.NoError(t, err)
+	require.EqualValues(t, small, back.Result)
+	require.EqualValues(t, small.Hash(), back.ResultHash)
+
+	large := dict.New()
+	large.Set("a", make([]byte, MaxReceiptResultSize))
+	rec = NewRequestReceipt(1, 100, "failed", large)
+	rec.GasUsed = 1234
+	back, err = RequestReceiptFromBytes(rec.Bytes())
+	require.NoError(t, err)
+	require.Nil(t, back.Result)
+	require.EqualValues(t, large.Hash(), back.ResultHash)
+	require.EqualValues(t, "failed", back.Error)
+	require.EqualValues(t, 1234, back.GasUsed)
+}
+
+func TestReceiptPruning(t *testing.T) {
+	state := dict.New()
+	reqID := func(blockIndex uint32, i uint16) *coretypes.RequestID {
+		rid := coretypes.NewRequestID(valuetransaction.ID{byte(blockIndex), byte(blockIndex >> 8)}, i)
+		return &rid
+	}
+	for idx := uint32(0); idx < ReceiptRetentionBlocks+10; idx++ {
+		for i := uint16(0); i < 2; i++ {
+			StoreRequestReceipt(state, reqID(idx, i), NewRequestReceipt(idx, int64(idx), "", nil))
+		}
+	}
+	for _, idx := range []uint32{0, 5, 9} {
+		rec, err := GetRequestReceipt(state, reqID(idx, 1))
+		require.NoError(t, err)
+		require.Nil(t, rec)
+	}
+	for _, idx := range []uint32{10, ReceiptRetentionBlocks + 9} {
+		rec, err := GetRequestReceipt(state, reqID(idx, 1))
+		require.NoError(t, err)
+		require.NotNil(t, rec)
+		require.EqualValues(t, idx, rec.BlockIndex)
+	}
+	require.EqualValues(t, 2*ReceiptRetentionBlocks, collections.NewMap(state, VarRequestReceipts).MustLen())
+	require.EqualValues(t, ReceiptRetentionBlocks, collections.NewMap(state, VarRequestReceiptsByBlock).MustLen())
+}
@@ -135,6 +135,54 @@ func deployContract(ctx coretypes.Sandbox) (dict.Dict, error) {
 	return nil, nil
 }
 
+// upgradeContract replaces the program of the deployed contract. The state partition and the accounts
+// of the contract are kept and the 'init' constructor is not called.
+// Only the creator of the contract or the chain owner can upgrade it. Core contracts can't be upgraded.
+// The upgrade is recorded in the upgrade history of the contract, see getUpgradeHistory, and in the eventlog
+// Inputs:
+// - ParamName string, the name of the deployed contract
+// - ParamProgramHash HashValue is a hash of the new program, same as in deployContract
+// - ParamDescription string new description of the contract. The old one is kept if skipped
+func upgradeContract(ctx coretypes.Sandbox) (dict.Dict, error) {
+	ctx.Log().Debugf("root.upgradeContract.begin")
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert2.NewAssert(ctx.Log())
+
+	name := params.MustGetString(ParamName)
+	progHash := params.MustGetHashValue(ParamProgramHash)
+	hname := coretypes.Hn(name)
+	a.Require(!isCoreContract(hname), "root.upgradeContract: core contract '%s' can't be upgraded", name)
+
+	rec, err := FindContract(ctx.State(), hname)
+	if err != nil {
+		return nil, fmt.Errorf("root.upgradeContract: '%s': %v", name, err)
+	}
+	if ctx.Caller() != rec.Creator && !CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()) {
+		return nil, fmt.Errorf("root.upgradeContract: upgrade not permitted for: %s", ctx.Caller())
+	}
+	a.Require(progHash != rec.ProgramHash, "root.upgradeContract: '%s' already has program %s", name, progHash.String())
+
+	// calls to loads VM from binary to check if it loads successfully
+	err = ctx.DeployContract(progHash, "", "", nil)
+	a.Require(err == nil, "root.upgradeContract.fail: %v", err)
+
+	oldProgHash := rec.ProgramHash
+	rec.ProgramHash = progHash
+	rec.Description = params.MustGetString(ParamDescription, rec.Description)
+	collections.NewMap(ctx.State(), VarContractRegistry).MustSetAt(hname.Bytes(), EncodeContractRecord(rec))
+	upgradeHistory(ctx.State(), hname).MustPush(EncodeContractUpgrade(&ContractUpgrade{
+		OldProgramHash: oldProgHash,
+		ProgramHash:    progHash,
+		BlockIndex:     ctx.BlockIndex(),
+		Timestamp:      ctx.GetTimestamp(),
+		Caller:         ctx.Caller(),
+	}))
+
+	ctx.Event(fmt.Sprintf("[upgrade] name: %s hname: %s, old progHash: %s, new progHash: %s",
+		name, hname, oldProgHash.String(), progHash.String()))
+	return nil, nil
+}
+
 // findContract view finds and returns encoded record of the contract
 // Input:
 // - ParamHname
@@ -156,6 +204,25 @@ func findContract(ctx coretypes.SandboxView) (dict.Dict, error) {
 	return ret, nil
 }
 
+// getUpgradeHistory view returns the upgrade history of the contract
+// Input:
+// - ParamHname
+// Output:
+// - ParamData: Array32 of encoded ContractUpgrade records, the oldest first. Empty if the contract was never upgraded
+func getUpgradeHistory(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+	hname, err := params.GetHname(ParamHname)
+	if err != nil {
+		return nil, err
+	}
+	ret := dict.New()
+	err = collections.NewArray32(ret, ParamData).Extend(upgradeHistoryReadOnly(ctx.State(), hname))
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
 // getChainInfo view returns general info about the chain: chain ID, chain owner ID,
 // description and the whole contract registry
 // Input: none
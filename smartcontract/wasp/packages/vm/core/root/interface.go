@@ -30,7 +30,9 @@ var (
 func init() {
 	Interface.WithFunctions(initialize, []coreutil.ContractFunctionInterface{
 		coreutil.Func(FuncDeployContract, deployContract),
+		coreutil.Func(FuncUpgradeContract, upgradeContract),
 		coreutil.ViewFunc(FuncFindContract, findContract),
+		coreutil.ViewFunc(FuncGetUpgradeHistory, getUpgradeHistory),
 		coreutil.Func(FuncClaimChainOwnership, claimChainOwnership),
 		coreutil.Func(FuncDelegateChainOwnership, delegateChainOwnership),
 		coreutil.ViewFunc(FuncGetChainInfo, getChainInfo),
@@ -56,6 +58,7 @@ const (
 	VarContractRegistry      = "r"
 	VarDescription           = "d"
 	VarDeployPermissions     = "dep"
+	VarContractUpgrades      = "u"
 )
 
 // param variables
@@ -78,7 +81,9 @@ const (
 // function names
 const (
 	FuncDeployContract         = "deployContract"
+	FuncUpgradeContract        = "upgradeContract"
 	FuncFindContract           = "findContract"
+	FuncGetUpgradeHistory      = "getUpgradeHistory"
 	FuncGetChainInfo           = "getChainInfo"
 	FuncDelegateChainOwnership = "delegateChainOwnership"
 	FuncClaimChainOwnership    = "claimChainOwnership"
@@ -112,6 +117,19 @@ type ContractRecord struct {
 	Creator coretypes.AgentID
 }
 
+// ContractUpgrade is the record of the upgrade history of the contract, see FuncUpgradeContract
+type ContractUpgrade struct {
+	// Program hashes of the contract before and after the upgrade
+	OldProgramHash hashing.HashValue
+	ProgramHash    hashing.HashValue
+	// Index of the block which contains the upgrade
+	BlockIndex uint32
+	// Timestamp of the request which upgraded the contract, in nanoseconds
+	Timestamp int64
+	// The agentID which upgraded the contract
+	Caller coretypes.AgentID
+}
+
 // ChainInfo is an API structure which contains main properties of the chain in on place
 type ChainInfo struct {
 	ChainID             coretypes.ChainID
@@ -174,6 +192,54 @@ func (p *ContractRecord) Read(r io.Reader) error {
 	return nil
 }
 
+func (u *ContractUpgrade) Write(w io.Writer) error {
+	if _, err := w.Write(u.OldProgramHash[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(u.ProgramHash[:]); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, u.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, u.Timestamp); err != nil {
+		return err
+	}
+	if _, err := w.Write(u.Caller[:]); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *ContractUpgrade) Read(r io.Reader) error {
+	if err := util.ReadHashValue(r, &u.OldProgramHash); err != nil {
+		return err
+	}
+	if err := util.ReadHashValue(r, &u.ProgramHash); err != nil {
+		return err
+	}
+	if err := util.ReadUint32(r, &u.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &u.Timestamp); err != nil {
+		return err
+	}
+	if err := coretypes.ReadAgentID(r, &u.Caller); err != nil {
+		return err
+	}
+	return nil
+}
+
+func EncodeContractUpgrade(u *ContractUpgrade) []byte {
+	return util.MustBytes(u)
+}
+
+func DecodeContractUpgrade(data []byte) (*ContractUpgrade, error) {
+	ret := new(ContractUpgrade)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
 func EncodeContractRecord(p *ContractRecord) []byte {
 	return util.MustBytes(p)
 }
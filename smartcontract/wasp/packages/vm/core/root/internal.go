@@ -9,6 +9,9 @@ import (
 	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
 )
 
 // FindContract is an internal utility function which finds a contract in the KVStore
@@ -151,6 +154,24 @@ func storeAndInitContract(ctx coretypes.Sandbox, rec *ContractRecord, initParams
 	return err
 }
 
+// upgradeHistory is the array of encoded ContractUpgrade records of the contract, the oldest first
+func upgradeHistory(state kv.KVStore, hname coretypes.Hname) *collections.Array32 {
+	return collections.NewArray32(state, VarContractUpgrades+string(hname.Bytes()))
+}
+
+func upgradeHistoryReadOnly(state kv.KVStoreReader, hname coretypes.Hname) *collections.ImmutableArray32 {
+	return collections.NewArray32ReadOnly(state, VarContractUpgrades+string(hname.Bytes()))
+}
+
+// isCoreContract checks if the contract is one of the core contracts deployed with the chain
+func isCoreContract(hname coretypes.Hname) bool {
+	switch hname {
+	case Interface.Hname(), accounts.Interface.Hname(), blob.Interface.Hname(), eventlog.Interface.Hname():
+		return true
+	}
+	return false
+}
+
 // isAuthorizedToDeploy checks if caller is authorized to deploy smart contract
 func isAuthorizedToDeploy(ctx coretypes.Sandbox) bool {
 	if ctx.Caller() == ctx.ChainOwnerID() {
@@ -1,7 +1,11 @@
 package testcore
 
 import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
 	"github.com/iotaledger/wasp/packages/solo"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
@@ -85,3 +89,37 @@ func TestChainLogDeploy(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, recs, 0)
 }
+
+func getRequestReceipt(t *testing.T, chain *solo.Chain, reqID *coretypes.RequestID) *eventlog.RequestReceipt {
+	partition := subrealm.New(chain.State.Variables(), kv.Key(eventlog.Interface.Hname().Bytes()))
+	rec, err := eventlog.GetRequestReceipt(partition, reqID)
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	return rec
+}
+
+func TestRequestReceipt(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+	receipt, err := chain.PostRequestWithReceipt(req, nil)
+	require.NoError(t, err)
+	require.NoError(t, receipt.Error)
+
+	rec := getRequestReceipt(t, chain, &receipt.RequestID)
+	require.EqualValues(t, receipt.BlockIndex, rec.BlockIndex)
+	require.EqualValues(t, chain.State.Timestamp(), rec.Timestamp)
+	require.Empty(t, rec.Error)
+	require.True(t, rec.Result.IsEmpty())
+	require.EqualValues(t, rec.Result.Hash(), rec.ResultHash)
+
+	req = solo.NewCallParams(root.Interface.Name, "dummyEP")
+	receipt, err = chain.PostRequestWithReceipt(req, nil)
+	require.NoError(t, err)
+	require.Error(t, receipt.Error)
+
+	rec = getRequestReceipt(t, chain, &receipt.RequestID)
+	require.EqualValues(t, receipt.BlockIndex, rec.BlockIndex)
+	require.EqualValues(t, receipt.Error.Error(), rec.Error)
+}
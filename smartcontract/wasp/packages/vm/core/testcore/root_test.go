@@ -4,14 +4,17 @@
 package testcore
 
 import (
-	"github.com/iotaledger/wasp/packages/vm/core/testcore/sandbox_tests/test_sandbox_sc"
+	"strings"
 	"testing"
 
+	"github.com/iotaledger/wasp/contracts/examples_core/inccounter"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/solo"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/core/testcore/sandbox_tests/test_sandbox_sc"
 	"github.com/stretchr/testify/require"
 )
 
@@ -168,3 +171,69 @@ func TestChangeOwnerUnauthorized(t *testing.T) {
 	info, _ := chain.GetInfo()
 	require.EqualValues(t, chain.OriginatorAgentID, info.ChainOwnerID)
 }
+
+func TestUpgradeContract(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	name := "testInc"
+	err := chain.DeployContract(nil, name, inccounter.Interface.ProgramHash, inccounter.VarCounter, 17)
+	require.NoError(t, err)
+
+	user := env.NewSignatureSchemeWithFunds()
+	err = chain.UpgradeContract(user, name, test_sandbox_sc.Interface.ProgramHash)
+	require.Error(t, err)
+
+	err = chain.UpgradeContract(nil, name, test_sandbox_sc.Interface.ProgramHash, root.ParamDescription, "upgraded")
+	require.NoError(t, err)
+	upgradeBlock := chain.State.BlockIndex()
+	upgradeTimestamp := chain.State.Timestamp()
+	rec, err := chain.FindContract(name)
+	require.NoError(t, err)
+	require.EqualValues(t, test_sandbox_sc.Interface.ProgramHash, rec.ProgramHash)
+	require.EqualValues(t, "upgraded", rec.Description)
+	require.EqualValues(t, chain.OriginatorAgentID, rec.Creator)
+
+	err = chain.UpgradeContract(nil, name, inccounter.Interface.ProgramHash)
+	require.NoError(t, err)
+	ret, err := chain.CallView(name, inccounter.FuncGetCounter)
+	require.NoError(t, err)
+	counter, _, err := codec.DecodeInt64(ret.MustGet(inccounter.VarCounter))
+	require.NoError(t, err)
+	require.EqualValues(t, 17, counter)
+
+	recs, err := chain.GetEventLogRecordsString(root.Interface.Name)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, strings.Count(recs, "[upgrade]"))
+
+	history, err := chain.GetUpgradeHistory(name)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, len(history))
+	require.EqualValues(t, inccounter.Interface.ProgramHash, history[0].OldProgramHash)
+	require.EqualValues(t, test_sandbox_sc.Interface.ProgramHash, history[0].ProgramHash)
+	require.EqualValues(t, upgradeBlock, history[0].BlockIndex)
+	require.EqualValues(t, upgradeTimestamp, history[0].Timestamp)
+	require.EqualValues(t, chain.OriginatorAgentID, history[0].Caller)
+	require.EqualValues(t, test_sandbox_sc.Interface.ProgramHash, history[1].OldProgramHash)
+	require.EqualValues(t, inccounter.Interface.ProgramHash, history[1].ProgramHash)
+	require.EqualValues(t, chain.State.BlockIndex(), history[1].BlockIndex)
+	require.EqualValues(t, chain.State.Timestamp(), history[1].Timestamp)
+	require.True(t, history[1].Timestamp > history[0].Timestamp)
+
+	history, err = chain.GetUpgradeHistory(blob.Interface.Name)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, len(history))
+}
+
+func TestUpgradeCoreContract(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	err := chain.UpgradeContract(nil, blob.Interface.Name, test_sandbox_sc.Interface.ProgramHash)
+	require.Error(t, err)
+	rec, err := chain.FindContract(blob.Interface.Name)
+	require.NoError(t, err)
+	require.EqualValues(t, blob.Interface.ProgramHash, rec.ProgramHash)
+}
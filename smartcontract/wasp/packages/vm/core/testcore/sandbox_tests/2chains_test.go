@@ -82,3 +82,30 @@ func test2Chains(t *testing.T, w bool) {
 	chain2.AssertAccountBalance(accountsAgentID1, balance.ColorIOTA, 1) // !!!! TODO
 	chain2.AssertAccountBalance(accountsAgentID2, balance.ColorIOTA, 0)
 }
+
+func TestReceiptPostedRequest(t *testing.T) { run2(t, testReceiptPostedRequest) }
+func testReceiptPostedRequest(t *testing.T, w bool) {
+	env := solo.New(t, false, false)
+	chain1 := env.NewChain(nil, "ch1")
+	chain2 := env.NewChain(nil, "ch2")
+	setupTestSandboxSC(t, chain2, nil, w)
+
+	userWallet := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(test_sandbox_sc.Name, test_sandbox_sc.FuncWithdrawToChain,
+		test_sandbox_sc.ParamChainID, chain1.ChainID,
+	).WithTransfer(
+		balance.ColorIOTA, 3,
+	)
+	receipt, err := chain2.PostRequestWithReceipt(req, userWallet)
+	require.NoError(t, err)
+	require.NoError(t, receipt.Error)
+	require.EqualValues(t, chain2.State.BlockIndex(), receipt.BlockIndex)
+
+	require.EqualValues(t, 1, len(receipt.PostedRequests))
+	posted := receipt.PostedRequests[0].RequestSection()
+	require.EqualValues(t, accounts.Interface.ContractID(chain1.ChainID), posted.Target())
+	require.EqualValues(t, coretypes.Hn(accounts.FuncWithdrawToChain), posted.EntryPointCode())
+
+	chain1.WaitForEmptyBacklog()
+	chain2.WaitForEmptyBacklog()
+}
@@ -239,3 +239,58 @@ func testChainLogMultiple(t *testing.T, w bool) {
 	require.EqualValues(t, 1, strings.Count(strTest, "[Event]"))
 	require.EqualValues(t, 1, strings.Count(strTest, "33333"))
 }
+
+// the prebuilt wasm of the test contract does not emit typed events
+func TestEventlogTypedEvents(t *testing.T) { run2(t, testEventlogTypedEvents, true) }
+func testEventlogTypedEvents(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	setupTestSandboxSC(t, chain, nil, w)
+
+	receipts := make([]*solo.Receipt, 0)
+	for i := 1; i < 6; i++ {
+		req := solo.NewCallParams(SandboxSCName, test_sandbox_sc.FuncEventLogTypedEvent,
+			test_sandbox_sc.VarCounter, i,
+		)
+		receipt, err := chain.PostRequestWithReceipt(req, nil)
+		require.NoError(t, err)
+		receipts = append(receipts, receipt)
+	}
+
+	events, err := chain.GetEvents(SandboxSCName, test_sandbox_sc.TopicCounter)
+	require.NoError(t, err)
+	require.Len(t, events, 5)
+	require.EqualValues(t, codec.EncodeInt64(5), events[0].Params.MustGet(test_sandbox_sc.VarCounter))
+	require.EqualValues(t, codec.EncodeInt64(1), events[4].Params.MustGet(test_sandbox_sc.VarCounter))
+	require.EqualValues(t, coretypes.Hn(SandboxSCName), events[0].Contract)
+	require.EqualValues(t, receipts[4].RequestID, events[0].RequestID)
+
+	events, err = chain.GetEvents(SandboxSCName, test_sandbox_sc.TopicCounter,
+		eventlog.ParamKey, test_sandbox_sc.VarParity,
+		eventlog.ParamValue, "odd",
+	)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.EqualValues(t, codec.EncodeInt64(3), events[1].Params.MustGet(test_sandbox_sc.VarCounter))
+
+	events, err = chain.GetEvents(SandboxSCName, test_sandbox_sc.TopicCounter, eventlog.ParamMaxLastRecords, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	events, err = chain.GetEvents(SandboxSCName, "unknown")
+	require.NoError(t, err)
+	require.Len(t, events, 0)
+
+	events, err = chain.GetEventsByRequest(SandboxSCName, receipts[1].RequestID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.EqualValues(t, codec.EncodeInt64(2), events[0].Params.MustGet(test_sandbox_sc.VarCounter))
+
+	_, err = chain.CallView(eventlog.Interface.Name, eventlog.FuncGetEvents,
+		eventlog.ParamContractHname, coretypes.Hn(SandboxSCName),
+	)
+	require.Error(t, err)
+
+	strTest, err := chain.GetEventLogRecordsString(SandboxSCName)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, strings.Count(strTest, test_sandbox_sc.TopicCounter+"{"))
+}
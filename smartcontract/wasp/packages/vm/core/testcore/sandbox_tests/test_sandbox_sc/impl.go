@@ -29,6 +29,24 @@ func testEventLogGenericData(ctx coretypes.Sandbox) (dict.Dict, error) {
 	return nil, nil
 }
 
+// testEventLogTypedEvent emits the typed event with the counter and its parity
+func testEventLogTypedEvent(ctx coretypes.Sandbox) (dict.Dict, error) {
+	inc := ctx.Params().MustGet(VarCounter)
+	n, _, err := codec.DecodeInt64(inc)
+	if err != nil {
+		return nil, err
+	}
+	parity := "even"
+	if n%2 != 0 {
+		parity = "odd"
+	}
+	params := dict.New()
+	params.Set(VarCounter, inc)
+	params.Set(VarParity, []byte(parity))
+	ctx.EmitEvent(TopicCounter, params)
+	return nil, nil
+}
+
 func testEventLogEventData(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Event("[Event] - Testing Event...")
 	return nil, nil
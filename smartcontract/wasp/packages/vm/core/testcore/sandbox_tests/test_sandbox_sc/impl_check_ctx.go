@@ -16,6 +16,8 @@ func testCheckContextFromFullEP(ctx coretypes.Sandbox) (dict.Dict, error) {
 	a.Require(par.MustGetChainID(ParamChainID) == ctx.ContractID().ChainID(), "fail: chainID")
 	a.Require(par.MustGetAgentID(ParamChainOwnerID) == ctx.ChainOwnerID(), "fail: chainOwnerID")
 	a.Require(par.MustGetAgentID(ParamCaller) == ctx.Caller(), "fail: caller")
+	a.Require(par.MustGetChainID(ParamChainID) == ctx.ChainID(), "fail: ChainID()")
+	a.Require(ctx.BlockIndex() > 0, "fail: blockIndex")
 	a.Require(par.MustGetContractID(ParamContractID) == ctx.ContractID(), "fail: contractID")
 	a.Require(par.MustGetAgentID(ParamAgentID) == coretypes.NewAgentIDFromContractID(ctx.ContractID()), "fail: agentID")
 	a.Require(par.MustGetAgentID(ParamContractCreator) == ctx.ContractCreator(), "fail: creator")
@@ -28,6 +30,9 @@ func testCheckContextFromViewEP(ctx coretypes.SandboxView) (dict.Dict, error) {
 
 	a.Require(par.MustGetChainID(ParamChainID) == ctx.ContractID().ChainID(), "fail: chainID")
 	a.Require(par.MustGetAgentID(ParamChainOwnerID) == ctx.ChainOwnerID(), "fail: chainOwnerID")
+	a.Require(par.MustGetChainID(ParamChainID) == ctx.ChainID(), "fail: ChainID()")
+	a.Require(ctx.Caller() == coretypes.AgentID{}, "fail: caller")
+	a.Require(ctx.BlockIndex() > 0, "fail: blockIndex")
 	a.Require(par.MustGetContractID(ParamContractID) == ctx.ContractID(), "fail: contractID")
 	a.Require(par.MustGetAgentID(ParamAgentID) == coretypes.NewAgentIDFromContractID(ctx.ContractID()), "fail: agentID")
 	a.Require(par.MustGetAgentID(ParamContractCreator) == ctx.ContractCreator(), "fail: creator")
@@ -30,6 +30,7 @@ func init() {
 		coreutil.Func(FuncEventLogGenericData, testEventLogGenericData),
 		coreutil.Func(FuncEventLogEventData, testEventLogEventData),
 		coreutil.Func(FuncEventLogDeploy, testEventLogDeploy),
+		coreutil.Func(FuncEventLogTypedEvent, testEventLogTypedEvent),
 		coreutil.ViewFunc(FuncSandboxCall, testSandboxCall),
 
 		coreutil.Func(FuncPanicFullEP, testPanicFullEP),
@@ -64,6 +65,7 @@ const (
 	FuncEventLogGenericData = "testEventLogGenericData"
 	FuncEventLogEventData   = "testEventLogEventData"
 	FuncEventLogDeploy      = "testEventLogDeploy"
+	FuncEventLogTypedEvent  = "testEventLogTypedEvent"
 
 	//Function sandbox test
 	FuncChainOwnerIDView = "testChainOwnerIDView"
@@ -99,6 +101,8 @@ const (
 
 	//Variables
 	VarCounter              = "counter"
+	VarParity               = "parity"
+	TopicCounter            = "counter"
 	VarChainOwner           = "chainOwner"
 	VarContractID           = "contractID"
 	VarSandboxCall          = "sandboxCall"
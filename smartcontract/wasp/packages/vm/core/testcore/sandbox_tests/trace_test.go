@@ -0,0 +1,71 @@
+package sandbox_tests
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/testcore/sandbox_tests/test_sandbox_sc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallTrace(t *testing.T) { run2(t, testCallTrace) }
+func testCallTrace(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	cID, _ := setupTestSandboxSC(t, chain, nil, w)
+	chain.SetCallTracing(true)
+
+	req := solo.NewCallParams(SandboxSCName, test_sandbox_sc.FuncCallOnChain,
+		test_sandbox_sc.ParamIntParamValue, 2,
+		test_sandbox_sc.ParamHnameContract, cID.Hname(),
+		test_sandbox_sc.ParamHnameEP, coretypes.Hn(test_sandbox_sc.FuncRunRecursion),
+	)
+	receipt, err := chain.PostRequestWithReceipt(req, nil)
+	require.NoError(t, err)
+	require.NoError(t, receipt.Error)
+	trace := receipt.Trace
+	require.NotNil(t, trace)
+	require.True(t, trace == chain.GetCallTrace(receipt.RequestID))
+	t.Log(trace.String())
+
+	require.Len(t, trace.Calls, 1)
+	frame := trace.Calls[0]
+	require.EqualValues(t, chain.OriginatorAgentID, frame.Caller)
+	require.EqualValues(t, cID.Hname(), frame.Contract)
+	require.EqualValues(t, coretypes.Hn(test_sandbox_sc.FuncCallOnChain), frame.EntryPoint)
+	require.Contains(t, frame.State, vm.StateAccess{Op: vm.StateSet, Contract: cID.Hname(), Key: test_sandbox_sc.VarCounter})
+	require.NotZero(t, frame.ParamsSize)
+	if w {
+		require.NotEmpty(t, frame.HostCalls)
+	}
+	// callOnChain -> runRecursion(2) -> callOnChain -> runRecursion(1) -> callOnChain -> runRecursion(0)
+	depth := 1
+	for len(frame.Calls) > 0 {
+		require.Len(t, frame.Calls, 1)
+		require.EqualValues(t, coretypes.NewAgentIDFromContractID(cID), frame.Calls[0].Caller)
+		require.Empty(t, frame.Error)
+		frame = frame.Calls[0]
+		depth++
+	}
+	require.EqualValues(t, 6, depth)
+	require.EqualValues(t, coretypes.Hn(test_sandbox_sc.FuncRunRecursion), frame.EntryPoint)
+	require.NotEmpty(t, trace.State) // fees and the receipt of the request
+
+	req = solo.NewCallParams(SandboxSCName, test_sandbox_sc.FuncCallPanicFullEP)
+	receipt, err = chain.PostRequestWithReceipt(req, nil)
+	require.NoError(t, err)
+	require.Error(t, receipt.Error)
+	require.Len(t, receipt.Trace.Calls, 1)
+	require.Len(t, receipt.Trace.Calls[0].Calls, 1)
+	require.Contains(t, receipt.Trace.Calls[0].Error, "panic")
+	require.EqualValues(t, coretypes.Hn(test_sandbox_sc.FuncPanicFullEP), receipt.Trace.Calls[0].Calls[0].EntryPoint)
+	require.Contains(t, receipt.Trace.Calls[0].Calls[0].Error, "panic")
+
+	chain.SetCallTracing(false)
+	req = solo.NewCallParams(SandboxSCName, test_sandbox_sc.FuncDoNothing)
+	receipt, err = chain.PostRequestWithReceipt(req, nil)
+	require.NoError(t, err)
+	require.Nil(t, receipt.Trace)
+	require.Nil(t, chain.GetCallTrace(receipt.RequestID))
+}
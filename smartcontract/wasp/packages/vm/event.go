@@ -10,22 +10,31 @@ import (
 
 type ContractEventPublisher struct {
 	contractID coretypes.ContractID
+	requestID  *coretypes.RequestID
 	log        *logger.Logger
 }
 
-func NewContractEventPublisher(contractID coretypes.ContractID, log *logger.Logger) ContractEventPublisher {
+// NewContractEventPublisher creates the publisher of events emitted by the contract.
+// The request ID is nil when the contract is called as a view
+func NewContractEventPublisher(contractID coretypes.ContractID, requestID *coretypes.RequestID, log *logger.Logger) ContractEventPublisher {
 	return ContractEventPublisher{
 		contractID: contractID,
+		requestID:  requestID,
 		log:        log,
 	}
 }
 
 func (c ContractEventPublisher) Publish(msg string) {
 	c.log.Info(c.contractID.String() + "/event " + msg)
-	publisher.Publish("vmmsg", c.contractID.ChainID().String(), c.contractID.Hname().String(), msg)
+	c.publish(msg)
 }
 
 func (c ContractEventPublisher) Publishf(format string, args ...interface{}) {
 	c.log.Infof(c.contractID.String()+"/event "+format, args...)
-	publisher.Publish("vmmsg", c.contractID.ChainID().String(), c.contractID.Hname().String(), fmt.Sprintf(format, args...))
+	c.publish(fmt.Sprintf(format, args...))
+}
+
+func (c ContractEventPublisher) publish(msg string) {
+	chainID := c.contractID.ChainID()
+	publisher.PublishMessage(publisher.NewVMMessage(&chainID, c.contractID.Hname(), c.requestID, msg))
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/metrics"
 	"github.com/iotaledger/wasp/packages/vm/statetxbuilder"
 	"github.com/iotaledger/wasp/packages/vm/vmcontext"
 	"time"
@@ -27,7 +28,7 @@ func RunComputationsAsync(ctx *vm.VMTask) error {
 	}
 
 	// TODO 1 graceful shutdown of the running VM task (with daemon)
-	// TODO 2 timeout for VM. Gas limit
+	// each request runs with the gas budget ctx.GasBudget, see vmcontext.BurnGas
 
 	go runTask(ctx, txb)
 	return nil
@@ -52,6 +53,8 @@ func runTask(task *vm.VMTask, txb *statetxbuilder.Builder) {
 	var lastErr error
 	var lastStateUpdate state.StateUpdate
 
+	chainIDStr := task.ChainID.String()
+
 	// loop over the batch of requests and run each request on the VM.
 	// the result accumulates in the VMContext and in the list of stateUpdates
 	timestamp := task.Timestamp
@@ -59,8 +62,20 @@ func runTask(task *vm.VMTask, txb *statetxbuilder.Builder) {
 		if reqRef.RequestSection().SolidArgs() == nil {
 			task.Log.Panicf("inconsistency: request args have not been solidified")
 		}
+		runStart := time.Now()
 		vmctx.RunTheRequest(reqRef, timestamp)
 		lastStateUpdate, lastResult, lastErr = vmctx.GetResult()
+		metrics.RequestRun(chainIDStr, reqRef.RequestSection().Target().Hname().String(), time.Since(runStart), lastErr != nil)
+		if task.OnRequestFinish != nil {
+			task.OnRequestFinish(reqRef.RequestID(), lastResult, lastErr)
+		}
+		if task.OnRequestFees != nil {
+			feeColor, ownerFee, validatorFee := vmctx.GetFeesCharged()
+			task.OnRequestFees(reqRef.RequestID(), feeColor, ownerFee, validatorFee)
+		}
+		if task.OnRequestTrace != nil {
+			task.OnRequestTrace(vmctx.GetTrace())
+		}
 
 		stateUpdates = append(stateUpdates, lastStateUpdate)
 		if timestamp != 0 {
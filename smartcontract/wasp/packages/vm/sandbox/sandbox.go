@@ -10,6 +10,7 @@ import (
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm"
 	"github.com/iotaledger/wasp/packages/vm/vmcontext"
 )
 
@@ -59,6 +60,14 @@ func (s *sandbox) Caller() coretypes.AgentID {
 	return s.vmctx.Caller()
 }
 
+func (s *sandbox) ChainID() coretypes.ChainID {
+	return s.vmctx.ChainID()
+}
+
+func (s *sandbox) BlockIndex() uint32 {
+	return s.vmctx.BlockIndex()
+}
+
 // DeployContract deploys contract by the binary hash
 // and calls "init" endpoint (constructor) with provided parameters
 func (s *sandbox) DeployContract(programHash hashing.HashValue, name string, description string, initParams dict.Dict) error {
@@ -96,6 +105,15 @@ func (s *sandbox) Event(msg string) {
 	s.vmctx.EventPublisher().Publish(msg)
 }
 
+func (s *sandbox) EmitEvent(topic string, params dict.Dict) {
+	if topic == "" {
+		s.Log().Panicf("EmitEvent: event topic can't be empty")
+	}
+	msg := s.vmctx.StoreEvent(topic, params)
+	s.Log().Infof("eventlog::%s -> '%s'", s.vmctx.CurrentContractHname(), msg)
+	s.vmctx.EventPublisher().Publish(msg)
+}
+
 func (s *sandbox) IncomingTransfer() coretypes.ColoredBalances {
 	return s.vmctx.GetIncoming()
 }
@@ -107,3 +125,16 @@ func (s *sandbox) Balance(col balance.Color) int64 {
 func (s *sandbox) Balances() coretypes.ColoredBalances {
 	return s.vmctx.GetMyBalances()
 }
+
+func (s *sandbox) BurnGas(amount uint64) {
+	s.vmctx.BurnGas(amount)
+}
+
+func (s *sandbox) GasRemaining() uint64 {
+	return s.vmctx.GasRemaining()
+}
+
+// HostTracer is the tracer of host calls of Wasm contracts. Nil if tracing of requests is not enabled
+func (s *sandbox) HostTracer() vm.HostTracer {
+	return s.vmctx.HostTracer()
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm"
 	"github.com/iotaledger/wasp/packages/vm/vmcontext"
 )
 
@@ -38,6 +39,18 @@ func (s sandboxView) ContractID() coretypes.ContractID {
 	return s.vmctx.CurrentContractID()
 }
 
+func (s sandboxView) Caller() coretypes.AgentID {
+	return s.vmctx.Caller()
+}
+
+func (s sandboxView) ChainID() coretypes.ChainID {
+	return s.vmctx.ChainID()
+}
+
+func (s sandboxView) BlockIndex() uint32 {
+	return s.vmctx.BlockIndex()
+}
+
 func (s sandboxView) GetTimestamp() int64 {
 	return s.vmctx.Timestamp()
 }
@@ -65,3 +78,16 @@ func (s sandboxView) Balances() coretypes.ColoredBalances {
 func (s sandboxView) Log() coretypes.LogInterface {
 	return s.vmctx
 }
+
+func (s sandboxView) BurnGas(amount uint64) {
+	s.vmctx.BurnGas(amount)
+}
+
+func (s sandboxView) GasRemaining() uint64 {
+	return s.vmctx.GasRemaining()
+}
+
+// HostTracer is the tracer of host calls of Wasm contracts. Nil if tracing of requests is not enabled
+func (s sandboxView) HostTracer() vm.HostTracer {
+	return s.vmctx.HostTracer()
+}
@@ -14,6 +14,9 @@ import (
 	"github.com/iotaledger/wasp/packages/vm/processors"
 )
 
+// DefaultGasBudget is the gas budget of a request when VMTask.GasBudget is not set
+const DefaultGasBudget = uint64(100000000)
+
 type RequestRefWithFreeTokens struct {
 	sctransaction.RequestRef
 	FreeTokens coretypes.ColoredBalances
@@ -31,10 +34,18 @@ type VMTask struct {
 	ValidatorFeeTarget coretypes.AgentID
 	Requests           []RequestRefWithFreeTokens
 	Timestamp          int64
-	VirtualState       state.VirtualState // input immutable
-	Log                *logger.Logger
+	// gas budget for each request in the batch. 0 means DefaultGasBudget
+	GasBudget    uint64
+	VirtualState state.VirtualState // input immutable
+	Log          *logger.Logger
 	// call when finished
 	OnFinish func(callResult dict.Dict, callError error, vmError error)
+	// optional, called after each request of the batch is run
+	OnRequestFinish func(reqID *coretypes.RequestID, callResult dict.Dict, callError error)
+	// optional, called after each request of the batch is run with the fees charged for the request
+	OnRequestFees func(reqID *coretypes.RequestID, feeColor balance.Color, ownerFee, validatorFee int64)
+	// optional, if set the calls of each request are traced and the trace is passed after the request is run
+	OnRequestTrace func(trace *RequestTrace)
 	// outputs
 	ResultTransaction *sctransaction.Transaction
 	ResultBlock       state.Block
@@ -0,0 +1,107 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+)
+
+// RequestTrace is the record of what the VM did while processing the request.
+// It is collected only if VMTask.OnRequestTrace is set
+type RequestTrace struct {
+	RequestID coretypes.RequestID
+	// Calls are the top level call frames, i.e. the call of the request itself
+	Calls []*CallFrame
+	// State is the state accessed by the VM outside of calls, for example while handling fees
+	State []StateAccess
+}
+
+// CallFrame is the record of one call of an entry point, including the nested calls
+type CallFrame struct {
+	Caller     coretypes.AgentID
+	Contract   coretypes.Hname
+	EntryPoint coretypes.Hname
+	// ParamsSize is the total size of keys and values of the params
+	ParamsSize int
+	// Transfer is nil if no tokens were transferred with the call
+	Transfer coretypes.ColoredBalances
+	Result   dict.Dict
+	Error    string
+	Duration time.Duration
+	// State is the state accessed through the sandbox during the call, without nested calls
+	State []StateAccess
+	// HostCalls are the calls from the Wasm code to the host, for Wasm contracts only
+	HostCalls []string
+	Calls     []*CallFrame
+}
+
+// HostTracer records the calls from the Wasm code to the host into CallFrame.HostCalls
+type HostTracer interface {
+	TraceHostCall(call string)
+}
+
+// StateOp is the kind of state access
+type StateOp string
+
+const (
+	StateGet     = StateOp("get")
+	StateHas     = StateOp("has")
+	StateIterate = StateOp("iterate")
+	StateSet     = StateOp("set")
+	StateDel     = StateOp("del")
+)
+
+// StateAccess is one read or write of the state of a contract. Key is the prefix for StateIterate
+type StateAccess struct {
+	Op       StateOp
+	Contract coretypes.Hname
+	Key      kv.Key
+}
+
+func (a *StateAccess) String() string {
+	return fmt.Sprintf("%s %s %q", a.Op, a.Contract, string(a.Key))
+}
+
+// String is the human readable form of the trace, with nested calls indented
+func (t *RequestTrace) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "trace of request %s\n", t.RequestID.String())
+	for _, f := range t.Calls {
+		f.write(&buf, "  ")
+	}
+	for i := range t.State {
+		fmt.Fprintf(&buf, "  state: %s\n", t.State[i].String())
+	}
+	return buf.String()
+}
+
+func (f *CallFrame) write(buf *strings.Builder, indent string) {
+	fmt.Fprintf(buf, "%scall %s::%s from %s, params: %d bytes", indent, f.Contract, f.EntryPoint, f.Caller.String(), f.ParamsSize)
+	if f.Transfer != nil && f.Transfer.Len() > 0 {
+		fmt.Fprintf(buf, ", transfer:")
+		f.Transfer.IterateDeterministic(func(col balance.Color, bal int64) bool {
+			fmt.Fprintf(buf, " %s: %d", col.String(), bal)
+			return true
+		})
+	}
+	fmt.Fprintf(buf, ", duration: %v\n", f.Duration)
+	for i := range f.State {
+		fmt.Fprintf(buf, "%s  state: %s\n", indent, f.State[i].String())
+	}
+	for _, h := range f.HostCalls {
+		fmt.Fprintf(buf, "%s  host: %s\n", indent, h)
+	}
+	for _, c := range f.Calls {
+		c.write(buf, indent+"  ")
+	}
+	if f.Error != "" {
+		fmt.Fprintf(buf, "%s  error: %s\n", indent, f.Error)
+		return
+	}
+	fmt.Fprintf(buf, "%s  result: %d key(s)\n", indent, len(f.Result))
+}
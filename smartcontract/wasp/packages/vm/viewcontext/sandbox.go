@@ -21,19 +21,21 @@ func InitLogger() {
 
 type sandboxview struct {
 	vctx          *viewcontext
+	caller        coretypes.AgentID
 	contractHname coretypes.Hname
 	params        dict.Dict
 	state         kv.KVStore // TODO change to KVStoreReader when Writable store removed from wasmhost
 	events        vm.ContractEventPublisher
 }
 
-func newSandboxView(vctx *viewcontext, contractHname coretypes.Hname, params dict.Dict) *sandboxview {
+func newSandboxView(vctx *viewcontext, caller coretypes.AgentID, contractHname coretypes.Hname, params dict.Dict) *sandboxview {
 	return &sandboxview{
 		vctx:          vctx,
+		caller:        caller,
 		contractHname: contractHname,
 		params:        params,
 		state:         contractStateSubpartition(vctx.state, contractHname),
-		events:        vm.NewContractEventPublisher(coretypes.NewContractID(vctx.chainID, contractHname), vctx.log),
+		events:        vm.NewContractEventPublisher(coretypes.NewContractID(vctx.chainID, contractHname), nil, vctx.log),
 	}
 }
 
@@ -58,7 +60,7 @@ func (s *sandboxview) Balances() coretypes.ColoredBalances {
 }
 
 func (s *sandboxview) Call(contractHname coretypes.Hname, entryPoint coretypes.Hname, params dict.Dict) (dict.Dict, error) {
-	return s.vctx.CallView(contractHname, entryPoint, params)
+	return s.vctx.callView(coretypes.NewAgentIDFromContractID(s.ContractID()), contractHname, entryPoint, params)
 }
 
 func (s *sandboxview) ContractID() coretypes.ContractID {
@@ -73,6 +75,14 @@ func (s *sandboxview) ChainID() coretypes.ChainID {
 	return s.vctx.chainID
 }
 
+func (s *sandboxview) Caller() coretypes.AgentID {
+	return s.caller
+}
+
+func (s *sandboxview) BlockIndex() uint32 {
+	return s.vctx.blockIndex
+}
+
 var getChainInfoHname = coretypes.Hn(root.FuncGetChainInfo)
 
 func (s *sandboxview) ChainOwnerID() coretypes.AgentID {
@@ -95,3 +105,11 @@ func (s *sandboxview) ContractCreator() coretypes.AgentID {
 func (s *sandboxview) GetTimestamp() int64 {
 	return s.vctx.timestamp
 }
+
+func (s *sandboxview) BurnGas(amount uint64) {
+	s.vctx.BurnGas(amount)
+}
+
+func (s *sandboxview) GasRemaining() uint64 {
+	return s.vctx.gasRemaining
+}
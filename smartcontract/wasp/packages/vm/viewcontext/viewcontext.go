@@ -11,6 +11,7 @@ import (
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/kv/subrealm"
 	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/iotaledger/wasp/packages/vm/hardcoded"
@@ -22,7 +23,11 @@ type viewcontext struct {
 	state      kv.KVStore //buffered.BufferedKVStore
 	chainID    coretypes.ChainID
 	timestamp  int64
+	blockIndex uint32
 	log        *logger.Logger
+	// gas budget shared by all nested view calls of one query
+	gasRemaining uint64
+	nesting      int
 }
 
 func NewFromDB(chainID coretypes.ChainID, proc *processors.ProcessorCache) (*viewcontext, error) {
@@ -34,10 +39,10 @@ func NewFromDB(chainID coretypes.ChainID, proc *processors.ProcessorCache) (*vie
 	if !ok {
 		return nil, fmt.Errorf("solid state not found for chain %s", chainID.String())
 	}
-	return New(chainID, state_.Variables(), state_.Timestamp(), proc, nil), nil
+	return New(chainID, state_.Variables(), state_.Timestamp(), state_.BlockIndex(), proc, nil), nil
 }
 
-func New(chainID coretypes.ChainID, state kv.KVStore, ts int64, proc *processors.ProcessorCache, logSet *logger.Logger) *viewcontext {
+func New(chainID coretypes.ChainID, state kv.KVStore, ts int64, blockIndex uint32, proc *processors.ProcessorCache, logSet *logger.Logger) *viewcontext {
 	if logSet == nil {
 		logSet = logDefault
 	} else {
@@ -48,14 +53,34 @@ func New(chainID coretypes.ChainID, state kv.KVStore, ts int64, proc *processors
 		state:      state,
 		chainID:    chainID,
 		timestamp:  ts,
+		blockIndex: blockIndex,
 		log:        logSet,
 	}
 }
 
+// BurnGas charges gas to the budget of the view call. Panics when the budget is exhausted
+func (v *viewcontext) BurnGas(amount uint64) {
+	if amount > v.gasRemaining {
+		v.gasRemaining = 0
+		panic(coretypes.ErrGasBudgetExceeded)
+	}
+	v.gasRemaining -= amount
+}
+
 // CallView in viewcontext implements own panic catcher.
 func (v *viewcontext) CallView(contractHname coretypes.Hname, epCode coretypes.Hname, params dict.Dict) (dict.Dict, error) {
+	return v.callView(coretypes.AgentID{}, contractHname, epCode, params)
+}
+
+// callView calls the view on behalf of the caller. The caller of the view called from outside of the chain is the zero AgentID
+func (v *viewcontext) callView(caller coretypes.AgentID, contractHname coretypes.Hname, epCode coretypes.Hname, params dict.Dict) (dict.Dict, error) {
 	var ret dict.Dict
 	var err error
+	if v.nesting == 0 {
+		v.gasRemaining = vm.DefaultGasBudget
+	}
+	v.nesting++
+	defer func() { v.nesting-- }()
 	func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -67,12 +92,12 @@ func (v *viewcontext) CallView(contractHname coretypes.Hname, epCode coretypes.H
 				}
 			}
 		}()
-		ret, err = v.mustCallView(contractHname, epCode, params)
+		ret, err = v.mustCallView(caller, contractHname, epCode, params)
 	}()
 	return ret, err
 }
 
-func (v *viewcontext) mustCallView(contractHname coretypes.Hname, epCode coretypes.Hname, params dict.Dict) (dict.Dict, error) {
+func (v *viewcontext) mustCallView(caller coretypes.AgentID, contractHname coretypes.Hname, epCode coretypes.Hname, params dict.Dict) (dict.Dict, error) {
 	var err error
 	contractRecord, err := root.FindContract(contractStateSubpartition(v.state, root.Interface.Hname()), contractHname)
 	if err != nil {
@@ -96,7 +121,7 @@ func (v *viewcontext) mustCallView(contractHname coretypes.Hname, epCode coretyp
 	if !ep.IsView() {
 		return nil, fmt.Errorf("only view entry point can be called in this context")
 	}
-	return ep.CallView(newSandboxView(v, contractHname, params))
+	return ep.CallView(newSandboxView(v, caller, contractHname, params))
 }
 
 func contractStateSubpartition(state kv.KVStore, contractHname coretypes.Hname) kv.KVStore {
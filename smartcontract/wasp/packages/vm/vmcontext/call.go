@@ -28,7 +28,11 @@ func (vmctx *VMContext) Call(targetContract coretypes.Hname, epCode coretypes.Hn
 	return vmctx.callByProgramHash(targetContract, epCode, params, transfer, rec.ProgramHash)
 }
 
-func (vmctx *VMContext) callByProgramHash(targetContract coretypes.Hname, epCode coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances, progHash hashing.HashValue) (dict.Dict, error) {
+func (vmctx *VMContext) callByProgramHash(targetContract coretypes.Hname, epCode coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances, progHash hashing.HashValue) (ret dict.Dict, err error) {
+	if vmctx.tracer != nil {
+		vmctx.traceEnter(targetContract, epCode, params, transfer)
+		defer vmctx.traceExit(&ret, &err)
+	}
 	proc, err := vmctx.processors.GetOrCreateProcessorByProgramHash(progHash, vmctx.getBinary)
 	if err != nil {
 		return nil, err
@@ -64,7 +68,11 @@ func (vmctx *VMContext) callByProgramHash(targetContract coretypes.Hname, epCode
 	return ep.Call(NewSandbox(vmctx))
 }
 
-func (vmctx *VMContext) callNonViewByProgramHash(targetContract coretypes.Hname, epCode coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances, progHash hashing.HashValue) (dict.Dict, error) {
+func (vmctx *VMContext) callNonViewByProgramHash(targetContract coretypes.Hname, epCode coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances, progHash hashing.HashValue) (ret dict.Dict, err error) {
+	if vmctx.tracer != nil {
+		vmctx.traceEnter(targetContract, epCode, params, transfer)
+		defer vmctx.traceExit(&ret, &err)
+	}
 	proc, err := vmctx.processors.GetOrCreateProcessorByProgramHash(progHash, vmctx.getBinary)
 	if err != nil {
 		return nil, err
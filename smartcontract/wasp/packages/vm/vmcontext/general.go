@@ -48,6 +48,11 @@ func (vmctx *VMContext) Caller() coretypes.AgentID {
 	return vmctx.getCallContext().caller
 }
 
+// BlockIndex is the index of the block the current request will be stored in
+func (vmctx *VMContext) BlockIndex() uint32 {
+	return vmctx.virtualState.BlockIndex() + 1
+}
+
 func (vmctx *VMContext) Timestamp() int64 {
 	return vmctx.timestamp
 }
@@ -104,9 +109,24 @@ func (vmctx *VMContext) PostRequestToSelfWithDelay(entryPoint coretypes.Hname, a
 }
 
 func (vmctx *VMContext) EventPublisher() vm.ContractEventPublisher {
-	return vm.NewContractEventPublisher(vmctx.CurrentContractID(), vmctx.log)
+	return vm.NewContractEventPublisher(vmctx.CurrentContractID(), vmctx.reqRef.RequestID(), vmctx.log)
 }
 
 func (vmctx *VMContext) RequestID() coretypes.RequestID {
 	return *vmctx.reqRef.RequestID()
 }
+
+// BurnGas charges gas to the budget of the current request.
+// It panics with ErrGasBudgetExceeded when the budget is exhausted, which
+// is caught by the request panic catcher and causes the request to fall back
+func (vmctx *VMContext) BurnGas(amount uint64) {
+	if amount > vmctx.gasRemaining {
+		vmctx.gasRemaining = 0
+		panic(coretypes.ErrGasBudgetExceeded)
+	}
+	vmctx.gasRemaining -= amount
+}
+
+func (vmctx *VMContext) GasRemaining() uint64 {
+	return vmctx.gasRemaining
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
 	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
@@ -117,3 +118,44 @@ func (vmctx *VMContext) StoreToEventLog(contract coretypes.Hname, data []byte) {
 	vmctx.log.Debugf("StoreToEventLog/%s: data: '%s'", contract.String(), string(data))
 	eventlog.AppendToLog(vmctx.State(), vmctx.timestamp, contract, data)
 }
+
+// StoreEvent stores the typed event of the current contract in the eventlog.
+// The human readable form of the event is appended to the log of the contract too. It is returned
+func (vmctx *VMContext) StoreEvent(topic string, params dict.Dict) string {
+	ev := &eventlog.Event{
+		Contract:  vmctx.CurrentContractHname(),
+		Topic:     topic,
+		Params:    params.Clone(),
+		RequestID: vmctx.RequestID(),
+		Timestamp: vmctx.timestamp,
+	}
+	msg := ev.String()
+
+	vmctx.pushCallContext(eventlog.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	vmctx.log.Debugf("StoreEvent/%s: '%s'", ev.Contract.String(), msg)
+	eventlog.StoreEvent(vmctx.State(), ev)
+	eventlog.AppendToLog(vmctx.State(), vmctx.timestamp, ev.Contract, []byte(msg))
+	return msg
+}
+
+// maxReceiptErrorLength limits the size of the error message stored in the request receipt
+const maxReceiptErrorLength = 1024
+
+// storeRequestReceipt stores the outcome of the current request for the request status queries.
+// The request will be processed in the block following the current virtual state
+func (vmctx *VMContext) storeRequestReceipt(errMsg string) {
+	vmctx.pushCallContext(eventlog.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	if vmctx.lastError == nil {
+		errMsg = ""
+	}
+	if len(errMsg) > maxReceiptErrorLength {
+		errMsg = errMsg[:maxReceiptErrorLength]
+	}
+	rec := eventlog.NewRequestReceipt(vmctx.BlockIndex(), vmctx.timestamp, errMsg, vmctx.lastResult)
+	rec.GasUsed = vmctx.gasBudget - vmctx.gasRemaining
+	eventlog.StoreRequestReceipt(vmctx.State(), vmctx.reqRef.RequestID(), rec)
+}
@@ -32,6 +32,9 @@ type VMContext struct {
 	feeColor           balance.Color
 	ownerFee           int64
 	validatorFee       int64
+	// fees charged for the current request
+	ownerFeeCharged     int64 // mutated
+	validatorFeeCharged int64 // mutated
 	// request context
 	remainingAfterFees coretypes.ColoredBalances
 	entropy            hashing.HashValue // mutates with each request
@@ -39,10 +42,15 @@ type VMContext struct {
 	reqHname           coretypes.Hname
 	contractRecord     *root.ContractRecord
 	timestamp          int64
+	gasBudget          uint64
+	gasRemaining       uint64 // mutated
 	stateUpdate        state.StateUpdate
 	lastError          error     // mutated
 	lastResult         dict.Dict // mutated. Used only by 'solo'
 	callStack          []*callContext
+	// tracing of calls, see vm.RequestTrace
+	traceRequests bool
+	tracer        *tracer // nil if tracing is not enabled
 }
 
 type callContext struct {
@@ -56,14 +64,19 @@ type callContext struct {
 // NewVMContext a constructor
 func NewVMContext(task *vm.VMTask, txb *statetxbuilder.Builder) (*VMContext, error) {
 	ret := &VMContext{
-		processors:   task.Processors,
-		chainID:      task.ChainID,
-		balances:     task.Balances,
-		txBuilder:    txb,
-		virtualState: task.VirtualState.Clone(),
-		log:          task.Log,
-		entropy:      task.Entropy,
-		callStack:    make([]*callContext, 0),
+		processors:    task.Processors,
+		chainID:       task.ChainID,
+		balances:      task.Balances,
+		txBuilder:     txb,
+		virtualState:  task.VirtualState.Clone(),
+		log:           task.Log,
+		entropy:       task.Entropy,
+		gasBudget:     task.GasBudget,
+		callStack:     make([]*callContext, 0),
+		traceRequests: task.OnRequestTrace != nil,
+	}
+	if ret.gasBudget == 0 {
+		ret.gasBudget = vm.DefaultGasBudget
 	}
 	return ret, nil
 }
@@ -71,3 +84,8 @@ func NewVMContext(task *vm.VMTask, txb *statetxbuilder.Builder) (*VMContext, err
 func (vmctx *VMContext) GetResult() (state.StateUpdate, dict.Dict, error) {
 	return vmctx.stateUpdate, vmctx.lastResult, vmctx.lastError
 }
+
+// GetFeesCharged returns the color and the owner and validator fees charged for the last request
+func (vmctx *VMContext) GetFeesCharged() (balance.Color, int64, int64) {
+	return vmctx.feeColor, vmctx.ownerFeeCharged, vmctx.validatorFeeCharged
+}
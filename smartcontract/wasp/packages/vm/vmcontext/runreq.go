@@ -44,6 +44,10 @@ func (vmctx *VMContext) RunTheRequest(reqRef vm.RequestRefWithFreeTokens, timest
 			if r := recover(); r != nil {
 				vmctx.lastResult = nil
 				vmctx.lastError = fmt.Errorf("recovered from panic in VM: %v", r)
+				if r == coretypes.ErrGasBudgetExceeded {
+					// out of gas is a regular failure of the request
+					vmctx.lastError = coretypes.ErrGasBudgetExceeded
+				}
 				if dberr, ok := r.(buffered.DBError); ok {
 					// There was an error accessing the DB
 					// The world stops
@@ -122,6 +126,8 @@ func (vmctx *VMContext) mustHandleFees() {
 	}
 	transfer.AddToMap(remaining)
 	vmctx.remainingAfterFees = cbalances.NewFromMap(remaining)
+	vmctx.ownerFeeCharged = vmctx.ownerFee
+	vmctx.validatorFeeCharged = vmctx.validatorFee
 }
 
 // mustHandleFreeTokens free tokens accrued to the chain owner
@@ -178,6 +184,7 @@ func (vmctx *VMContext) mustRequestToEventLog(err error) {
 	msg := fmt.Sprintf("[req] %s: %s", vmctx.reqRef.RequestID().String(), e)
 	vmctx.log.Infof("eventlog -> '%s'", msg)
 	vmctx.StoreToEventLog(vmctx.reqHname, []byte(msg))
+	vmctx.storeRequestReceipt(e)
 }
 
 // mustGetBaseValues only makes sense if chain is already deployed
@@ -200,7 +207,13 @@ func (vmctx *VMContext) initRequestContext(reqRef vm.RequestRefWithFreeTokens, t
 	vmctx.stateUpdate = state.NewStateUpdate(reqRef.RequestID()).WithTimestamp(timestamp)
 	vmctx.callStack = vmctx.callStack[:0]
 	vmctx.entropy = hashing.HashData(vmctx.entropy[:])
+	vmctx.gasRemaining = vmctx.gasBudget
 	vmctx.remainingAfterFees = cbalances.NewFromMap(nil)
+	vmctx.ownerFeeCharged = 0
+	vmctx.validatorFeeCharged = 0
+	if vmctx.traceRequests {
+		vmctx.tracer = newTracer(*reqRef.RequestID())
+	}
 
 	vmctx.contractRecord, _ = vmctx.findContractByHname(vmctx.reqHname)
 }
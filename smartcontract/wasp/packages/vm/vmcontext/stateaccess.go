@@ -5,6 +5,7 @@ import (
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/buffered"
 	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm"
 )
 
 type stateWrapper struct {
@@ -12,6 +13,7 @@ type stateWrapper struct {
 	contractSubPartitionPrefix kv.Key
 	virtualState               state.VirtualState
 	stateUpdate                state.StateUpdate
+	tracer                     *tracer // nil if tracing is not enabled
 }
 
 func newStateWrapper(contractHname coretypes.Hname, virtualState state.VirtualState, stateUpdate state.StateUpdate) stateWrapper {
@@ -28,14 +30,23 @@ func (s *stateWrapper) addContractSubPartition(key kv.Key) kv.Key {
 }
 
 func (vmctx *VMContext) stateWrapper() stateWrapper {
-	return newStateWrapper(
+	ret := newStateWrapper(
 		vmctx.CurrentContractHname(),
 		vmctx.virtualState,
 		vmctx.stateUpdate,
 	)
+	ret.tracer = vmctx.tracer
+	return ret
+}
+
+func (s *stateWrapper) trace(op vm.StateOp, key kv.Key) {
+	if s.tracer != nil {
+		s.tracer.stateAccess(op, s.contractHname, key)
+	}
 }
 
 func (s stateWrapper) Has(name kv.Key) (bool, error) {
+	s.trace(vm.StateHas, name)
 	name = s.addContractSubPartition(name)
 	mut := s.stateUpdate.Mutations().Latest(name)
 	if mut != nil {
@@ -45,6 +56,7 @@ func (s stateWrapper) Has(name kv.Key) (bool, error) {
 }
 
 func (s stateWrapper) Iterate(prefix kv.Key, f func(kv.Key, []byte) bool) error {
+	s.trace(vm.StateIterate, prefix)
 	prefix = s.addContractSubPartition(prefix)
 	seen, done := s.stateUpdate.Mutations().IterateValues(prefix, func(key kv.Key, value []byte) bool {
 		return f(key[len(s.contractSubPartitionPrefix):], value)
@@ -62,6 +74,7 @@ func (s stateWrapper) Iterate(prefix kv.Key, f func(kv.Key, []byte) bool) error
 }
 
 func (s stateWrapper) IterateKeys(prefix kv.Key, f func(key kv.Key) bool) error {
+	s.trace(vm.StateIterate, prefix)
 	prefix = s.addContractSubPartition(prefix)
 	seen, done := s.stateUpdate.Mutations().IterateValues(prefix, func(key kv.Key, value []byte) bool {
 		return f(key[len(s.contractSubPartitionPrefix):])
@@ -79,6 +92,7 @@ func (s stateWrapper) IterateKeys(prefix kv.Key, f func(key kv.Key) bool) error
 }
 
 func (s stateWrapper) Get(name kv.Key) ([]byte, error) {
+	s.trace(vm.StateGet, name)
 	name = s.addContractSubPartition(name)
 	mut := s.stateUpdate.Mutations().Latest(name)
 	if mut != nil {
@@ -88,11 +102,13 @@ func (s stateWrapper) Get(name kv.Key) ([]byte, error) {
 }
 
 func (s stateWrapper) Del(name kv.Key) {
+	s.trace(vm.StateDel, name)
 	name = s.addContractSubPartition(name)
 	s.stateUpdate.Mutations().Add(buffered.NewMutationDel(name))
 }
 
 func (s stateWrapper) Set(name kv.Key, value []byte) {
+	s.trace(vm.StateSet, name)
 	name = s.addContractSubPartition(name)
 	s.stateUpdate.Mutations().Add(buffered.NewMutationSet(name, value))
 }
@@ -0,0 +1,120 @@
+package vmcontext
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm"
+)
+
+// tracer collects the trace of the current request. The VMContext has the tracer only if tracing is enabled
+type tracer struct {
+	trace *vm.RequestTrace
+	stack []*vm.CallFrame
+	start []time.Time
+}
+
+func newTracer(reqID coretypes.RequestID) *tracer {
+	return &tracer{
+		trace: &vm.RequestTrace{
+			RequestID: reqID,
+			Calls:     make([]*vm.CallFrame, 0),
+			State:     make([]vm.StateAccess, 0),
+		},
+		stack: make([]*vm.CallFrame, 0),
+		start: make([]time.Time, 0),
+	}
+}
+
+func (t *tracer) enter(caller coretypes.AgentID, contract coretypes.Hname, epCode coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances) {
+	frame := &vm.CallFrame{
+		Caller:     caller,
+		Contract:   contract,
+		EntryPoint: epCode,
+		Transfer:   transfer,
+		State:      make([]vm.StateAccess, 0),
+		HostCalls:  make([]string, 0),
+		Calls:      make([]*vm.CallFrame, 0),
+	}
+	for k, v := range params {
+		frame.ParamsSize += len(k) + len(v)
+	}
+	if len(t.stack) == 0 {
+		t.trace.Calls = append(t.trace.Calls, frame)
+	} else {
+		parent := t.stack[len(t.stack)-1]
+		parent.Calls = append(parent.Calls, frame)
+	}
+	t.stack = append(t.stack, frame)
+	t.start = append(t.start, time.Now())
+}
+
+func (t *tracer) exit(result dict.Dict, err error) {
+	last := len(t.stack) - 1
+	frame := t.stack[last]
+	frame.Duration = time.Since(t.start[last])
+	frame.Result = result
+	if err != nil {
+		frame.Error = err.Error()
+	}
+	t.stack = t.stack[:last]
+	t.start = t.start[:last]
+}
+
+func (t *tracer) stateAccess(op vm.StateOp, contract coretypes.Hname, key kv.Key) {
+	access := vm.StateAccess{Op: op, Contract: contract, Key: key}
+	if len(t.stack) == 0 {
+		t.trace.State = append(t.trace.State, access)
+		return
+	}
+	frame := t.stack[len(t.stack)-1]
+	frame.State = append(frame.State, access)
+}
+
+// TraceHostCall implements vm.HostTracer
+func (t *tracer) TraceHostCall(call string) {
+	if len(t.stack) == 0 {
+		return
+	}
+	frame := t.stack[len(t.stack)-1]
+	frame.HostCalls = append(frame.HostCalls, call)
+}
+
+// traceEnter starts the call frame of the trace. The caller is the agent which will be the caller of the new call context
+func (vmctx *VMContext) traceEnter(contract coretypes.Hname, epCode coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances) {
+	var caller coretypes.AgentID
+	if len(vmctx.callStack) == 0 {
+		caller = vmctx.reqRef.SenderAgentID()
+	} else {
+		caller = coretypes.NewAgentIDFromContractID(vmctx.CurrentContractID())
+	}
+	vmctx.tracer.enter(caller, contract, epCode, params, transfer)
+}
+
+// traceExit must be deferred directly by the traced call. The panic is recorded in the trace and passed on
+func (vmctx *VMContext) traceExit(result *dict.Dict, err *error) {
+	if r := recover(); r != nil {
+		vmctx.tracer.exit(nil, fmt.Errorf("panic: %v", r))
+		panic(r)
+	}
+	vmctx.tracer.exit(*result, *err)
+}
+
+// GetTrace returns the trace of the last request, or nil if tracing is not enabled
+func (vmctx *VMContext) GetTrace() *vm.RequestTrace {
+	if vmctx.tracer == nil {
+		return nil
+	}
+	return vmctx.tracer.trace
+}
+
+// HostTracer returns the tracer of host calls of Wasm contracts, or nil if tracing is not enabled
+func (vmctx *VMContext) HostTracer() vm.HostTracer {
+	if vmctx.tracer == nil {
+		return nil
+	}
+	return vmctx.tracer
+}
@@ -10,6 +10,9 @@ import (
 
 const (
 	OBJTYPE_ARRAY int32 = 0x20
+	// OBJTYPE_ARRAY2 is combined with OBJTYPE_ARRAY for an array of arrays,
+	// for example OBJTYPE_ARRAY2|OBJTYPE_ARRAY|OBJTYPE_INT is a 2D grid of ints
+	OBJTYPE_ARRAY2 int32 = 0x40
 
 	OBJTYPE_ADDRESS     int32 = 1
 	OBJTYPE_AGENT_ID    int32 = 2
@@ -55,7 +58,14 @@ func (host *KvStoreHost) Init(null HostObject, root HostObject, log *logger.Logg
 	host.objIdToObj = nil
 	host.keyIdToKey = [][]byte{[]byte("<null>")}
 	host.keyToKeyId = make(map[string]int32)
-	host.keyIdToKeyMap = make([][]byte, len(keyMap)+1)
+	// predefined keys are not contiguous, see KeyZzzzzzz
+	lowest := int32(0)
+	for _, v := range keyMap {
+		if v < lowest {
+			lowest = v
+		}
+	}
+	host.keyIdToKeyMap = make([][]byte, -lowest+1)
 	for k, v := range keyMap {
 		host.keyIdToKeyMap[-v] = []byte(k)
 	}
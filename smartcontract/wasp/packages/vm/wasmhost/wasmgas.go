@@ -0,0 +1,473 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmhost
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// GasExport is the name of the mutable i64 global that is added to
+// a Wasm module by InstrumentGas. It holds the gas left for the call.
+const GasExport = "wasp_gas_left"
+
+const (
+	sectionCustom = 0
+	sectionImport = 2
+	sectionGlobal = 6
+	sectionExport = 7
+	sectionCode   = 10
+
+	exportKindGlobal = 3
+)
+
+var wasmMagic = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+// InstrumentGas rewrites a Wasm module so that it meters its own execution.
+// A mutable i64 global is added and exported as GasExport. At the start of
+// every function body and at the start of every loop iteration the static
+// instruction count of that code region is subtracted from the global.
+// When the global drops below zero the code traps with 'unreachable'.
+// The host sets the global to the gas budget before calling into the module
+// and reads it back afterwards to find out how much gas was used.
+// Because only a global is added, no existing function, global or type
+// indexes change, so the rest of the module can be copied verbatim.
+func InstrumentGas(wasmData []byte) ([]byte, error) {
+	if len(wasmData) < len(wasmMagic) || string(wasmData[:len(wasmMagic)]) != string(wasmMagic) {
+		return nil, errors.New("InstrumentGas: not a Wasm module")
+	}
+
+	sections, err := splitSections(wasmData[len(wasmMagic):])
+	if err != nil {
+		return nil, err
+	}
+
+	importedGlobals := uint32(0)
+	definedGlobals := uint32(0)
+	for _, s := range sections {
+		switch s.id {
+		case sectionImport:
+			importedGlobals, err = countImportedGlobals(s.data)
+		case sectionGlobal:
+			r := newWasmReader(s.data)
+			definedGlobals, err = r.u32(), r.err
+		case sectionExport:
+			err = checkNoGasExport(s.data)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	gasGlobal := importedGlobals + definedGlobals
+
+	hasGlobals := false
+	hasExports := false
+	for i := range sections {
+		s := &sections[i]
+		switch s.id {
+		case sectionGlobal:
+			hasGlobals = true
+			s.data = appendGasGlobal(s.data)
+		case sectionExport:
+			hasExports = true
+			s.data = appendGasExport(s.data, gasGlobal)
+		case sectionCode:
+			s.data, err = instrumentCode(s.data, gasGlobal)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !hasGlobals {
+		sections = insertSection(sections, sectionGlobal, appendGasGlobal([]byte{0}))
+	}
+	if !hasExports {
+		sections = insertSection(sections, sectionExport, appendGasExport([]byte{0}, gasGlobal))
+	}
+
+	ret := append([]byte{}, wasmMagic...)
+	for _, s := range sections {
+		ret = append(ret, s.id)
+		ret = appendU32(ret, uint32(len(s.data)))
+		ret = append(ret, s.data...)
+	}
+	return ret, nil
+}
+
+type wasmSection struct {
+	id   byte
+	data []byte
+}
+
+func splitSections(data []byte) ([]wasmSection, error) {
+	ret := make([]wasmSection, 0)
+	r := newWasmReader(data)
+	for !r.eof() {
+		id := r.byte()
+		size := r.u32()
+		body := r.bytes(size)
+		if r.err != nil {
+			return nil, fmt.Errorf("InstrumentGas: invalid section: %v", r.err)
+		}
+		ret = append(ret, wasmSection{id: id, data: body})
+	}
+	return ret, nil
+}
+
+// sectionOrder is the mandatory order of non-custom sections
+// note that the data count section (12) goes before the code section (10)
+var sectionOrder = map[byte]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 12: 10, 10: 11, 11: 12}
+
+func insertSection(sections []wasmSection, id byte, data []byte) []wasmSection {
+	pos := len(sections)
+	for i, s := range sections {
+		if s.id != sectionCustom && sectionOrder[s.id] > sectionOrder[id] {
+			pos = i
+			break
+		}
+	}
+	ret := make([]wasmSection, 0, len(sections)+1)
+	ret = append(ret, sections[:pos]...)
+	ret = append(ret, wasmSection{id: id, data: data})
+	return append(ret, sections[pos:]...)
+}
+
+func countImportedGlobals(data []byte) (uint32, error) {
+	r := newWasmReader(data)
+	globals := uint32(0)
+	for n := r.u32(); n > 0 && r.err == nil; n-- {
+		r.bytes(r.u32()) // module name
+		r.bytes(r.u32()) // field name
+		switch kind := r.byte(); kind {
+		case 0: // function
+			r.u32()
+		case 1: // table
+			r.byte()
+			r.limits()
+		case 2: // memory
+			r.limits()
+		case 3: // global
+			r.byte()
+			r.byte()
+			globals++
+		default:
+			r.fail(fmt.Errorf("invalid import kind %d", kind))
+		}
+	}
+	if r.err != nil {
+		return 0, fmt.Errorf("InstrumentGas: invalid import section: %v", r.err)
+	}
+	return globals, nil
+}
+
+func checkNoGasExport(data []byte) error {
+	r := newWasmReader(data)
+	for n := r.u32(); n > 0 && r.err == nil; n-- {
+		name := r.bytes(r.u32())
+		r.byte()
+		r.u32()
+		if string(name) == GasExport {
+			return errors.New("InstrumentGas: module already instrumented")
+		}
+	}
+	if r.err != nil {
+		return fmt.Errorf("InstrumentGas: invalid export section: %v", r.err)
+	}
+	return nil
+}
+
+func appendGasGlobal(data []byte) []byte {
+	r := newWasmReader(data)
+	count := r.u32()
+	ret := appendU32(nil, count+1)
+	ret = append(ret, data[r.pos:]...)
+	// mutable i64 global initialized with i64.const 0
+	return append(ret, 0x7e, 0x01, 0x42, 0x00, 0x0b)
+}
+
+func appendGasExport(data []byte, gasGlobal uint32) []byte {
+	r := newWasmReader(data)
+	count := r.u32()
+	ret := appendU32(nil, count+1)
+	ret = append(ret, data[r.pos:]...)
+	ret = appendU32(ret, uint32(len(GasExport)))
+	ret = append(ret, GasExport...)
+	ret = append(ret, exportKindGlobal)
+	return appendU32(ret, gasGlobal)
+}
+
+func instrumentCode(data []byte, gasGlobal uint32) ([]byte, error) {
+	r := newWasmReader(data)
+	count := r.u32()
+	ret := appendU32(nil, count)
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		body := r.bytes(r.u32())
+		if r.err != nil {
+			break
+		}
+		instrumented, err := instrumentFunction(body, gasGlobal)
+		if err != nil {
+			return nil, fmt.Errorf("InstrumentGas: function %d: %v", i, err)
+		}
+		ret = appendU32(ret, uint32(len(instrumented)))
+		ret = append(ret, instrumented...)
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("InstrumentGas: invalid code section: %v", r.err)
+	}
+	return ret, nil
+}
+
+type wasmInstr struct {
+	start  int
+	end    int
+	opcode byte
+	region int
+}
+
+func instrumentFunction(body []byte, gasGlobal uint32) ([]byte, error) {
+	r := newWasmReader(body)
+	for n := r.u32(); n > 0 && r.err == nil; n-- {
+		r.u32()
+		r.byte()
+	}
+	codeStart := r.pos
+
+	// decode the instructions and assign each one to its metering region,
+	// which is the innermost enclosing loop, or the function body itself
+	instrs := make([]wasmInstr, 0)
+	regionCost := []int64{0}
+	regions := []int{0}
+	loops := []bool{false}
+	for len(loops) != 0 {
+		if r.eof() {
+			return nil, errors.New("unexpected end of code")
+		}
+		instr := wasmInstr{start: r.pos, region: regions[len(regions)-1]}
+		instr.opcode = r.byte()
+		r.immediates(instr.opcode)
+		if r.err != nil {
+			return nil, r.err
+		}
+		instr.end = r.pos
+		regionCost[instr.region]++
+		switch instr.opcode {
+		case 0x02, 0x04: // block, if
+			loops = append(loops, false)
+		case 0x03: // loop
+			loops = append(loops, true)
+			regions = append(regions, len(regionCost))
+			regionCost = append(regionCost, 0)
+		case 0x0b: // end
+			if loops[len(loops)-1] {
+				regions = regions[:len(regions)-1]
+			}
+			loops = loops[:len(loops)-1]
+		}
+		instrs = append(instrs, instr)
+	}
+	if !r.eof() {
+		return nil, errors.New("code after end of function")
+	}
+
+	ret := append([]byte{}, body[:codeStart]...)
+	ret = appendGasCharge(ret, gasGlobal, regionCost[0])
+	nextRegion := 1
+	for _, instr := range instrs {
+		ret = append(ret, body[instr.start:instr.end]...)
+		if instr.opcode == 0x03 {
+			ret = appendGasCharge(ret, gasGlobal, regionCost[nextRegion])
+			nextRegion++
+		}
+	}
+	return ret, nil
+}
+
+// appendGasCharge appends a stack neutral code sequence that subtracts cost
+// from the gas global and traps with 'unreachable' when it drops below zero
+func appendGasCharge(code []byte, gasGlobal uint32, cost int64) []byte {
+	code = append(code, 0x23)
+	code = appendU32(code, gasGlobal)
+	code = append(code, 0x42)
+	code = appendS64(code, cost)
+	code = append(code, 0x7d, 0x24)
+	code = appendU32(code, gasGlobal)
+	code = append(code, 0x23)
+	code = appendU32(code, gasGlobal)
+	return append(code, 0x42, 0x00, 0x53, 0x04, 0x40, 0x00, 0x0b)
+}
+
+type wasmReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func newWasmReader(data []byte) *wasmReader {
+	return &wasmReader{data: data}
+}
+
+func (r *wasmReader) eof() bool {
+	return r.err != nil || r.pos >= len(r.data)
+}
+
+func (r *wasmReader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+	r.pos = len(r.data)
+}
+
+func (r *wasmReader) byte() byte {
+	if r.pos >= len(r.data) {
+		r.fail(errors.New("unexpected end of data"))
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *wasmReader) bytes(size uint32) []byte {
+	if uint64(r.pos)+uint64(size) > uint64(len(r.data)) {
+		r.fail(errors.New("unexpected end of data"))
+		return nil
+	}
+	b := r.data[r.pos : r.pos+int(size)]
+	r.pos += int(size)
+	return b
+}
+
+func (r *wasmReader) u32() uint32 {
+	ret := uint64(0)
+	for shift := uint(0); shift < 35; shift += 7 {
+		b := r.byte()
+		ret |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return uint32(ret)
+		}
+	}
+	r.fail(errors.New("invalid LEB128 value"))
+	return 0
+}
+
+// skipLEB skips a signed or unsigned LEB128 value of at most maxBytes bytes
+func (r *wasmReader) skipLEB(maxBytes int) {
+	for i := 0; i < maxBytes; i++ {
+		if r.byte()&0x80 == 0 {
+			return
+		}
+	}
+	r.fail(errors.New("invalid LEB128 value"))
+}
+
+func (r *wasmReader) limits() {
+	if r.byte() != 0 {
+		r.u32()
+	}
+	r.u32()
+}
+
+// immediates skips the immediate arguments of the instruction with the given opcode
+func (r *wasmReader) immediates(opcode byte) {
+	switch {
+	case opcode == 0x02 || opcode == 0x03 || opcode == 0x04: // block, loop, if
+		r.blockType()
+	case opcode == 0x0c || opcode == 0x0d: // br, br_if
+		r.u32()
+	case opcode == 0x0e: // br_table
+		for n := r.u32(); n > 0 && r.err == nil; n-- {
+			r.u32()
+		}
+		r.u32()
+	case opcode == 0x10 || opcode == 0x12: // call, return_call
+		r.u32()
+	case opcode == 0x11 || opcode == 0x13: // call_indirect, return_call_indirect
+		r.u32()
+		r.u32()
+	case opcode == 0x1c: // select with types
+		r.bytes(r.u32())
+	case opcode >= 0x20 && opcode <= 0x26: // local, global and table access
+		r.u32()
+	case opcode >= 0x28 && opcode <= 0x3e: // memory load/store
+		r.u32()
+		r.u32()
+	case opcode == 0x3f || opcode == 0x40: // memory.size, memory.grow
+		r.u32()
+	case opcode == 0x41: // i32.const
+		r.skipLEB(5)
+	case opcode == 0x42: // i64.const
+		r.skipLEB(10)
+	case opcode == 0x43: // f32.const
+		r.bytes(4)
+	case opcode == 0x44: // f64.const
+		r.bytes(8)
+	case opcode == 0xd0: // ref.null
+		r.byte()
+	case opcode == 0xd2: // ref.func
+		r.u32()
+	case opcode == 0xfc:
+		r.miscImmediates(r.u32())
+	case opcode <= 0x01 || opcode == 0x05 || opcode == 0x0b || opcode == 0x0f ||
+		opcode == 0x1a || opcode == 0x1b || (opcode >= 0x45 && opcode <= 0xc4) || opcode == 0xd1:
+		// no immediates
+	default:
+		r.fail(fmt.Errorf("unsupported opcode 0x%02x", opcode))
+	}
+}
+
+func (r *wasmReader) blockType() {
+	if r.pos >= len(r.data) {
+		r.fail(errors.New("unexpected end of data"))
+		return
+	}
+	switch r.data[r.pos] {
+	case 0x40, 0x7f, 0x7e, 0x7d, 0x7c, 0x7b, 0x70, 0x6f:
+		r.pos++
+	default:
+		// type index as signed 33 bit value
+		r.skipLEB(5)
+	}
+}
+
+// miscImmediates skips the immediates of the 0xfc prefixed instructions
+func (r *wasmReader) miscImmediates(subOpcode uint32) {
+	switch {
+	case subOpcode <= 7: // saturating truncation
+	case subOpcode == 8: // memory.init
+		r.u32()
+		r.byte()
+	case subOpcode == 10: // memory.copy
+		r.byte()
+		r.byte()
+	case subOpcode == 11: // memory.fill
+		r.byte()
+	case subOpcode == 12 || subOpcode == 14: // table.init, table.copy
+		r.u32()
+		r.u32()
+	case subOpcode == 9 || subOpcode == 13 || (subOpcode >= 15 && subOpcode <= 17):
+		// data.drop, elem.drop, table.grow, table.size, table.fill
+		r.u32()
+	default:
+		r.fail(fmt.Errorf("unsupported opcode 0xfc %d", subOpcode))
+	}
+}
+
+func appendU32(buf []byte, v uint32) []byte {
+	var tmp [binary.MaxVarintLen32]byte
+	n := binary.PutUvarint(tmp[:], uint64(v))
+	return append(buf, tmp[:n]...)
+}
+
+func appendS64(buf []byte, v int64) []byte {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(buf, b)
+		}
+		buf = append(buf, b|0x80)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmhost
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// module with a single function: (func loop br 0 end)
+var loopModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	0x01, 0x04, 0x01, 0x60, 0x00, 0x00, // type section: func () -> ()
+	0x03, 0x02, 0x01, 0x00, // function section
+	0x0a, 0x09, 0x01, 0x07, 0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x0b, // code section
+}
+
+func TestInstrumentGasLoop(t *testing.T) {
+	wasmData, err := InstrumentGas(loopModule)
+	require.NoError(t, err)
+
+	sections, err := splitSections(wasmData[len(wasmMagic):])
+	require.NoError(t, err)
+	require.EqualValues(t, 5, len(sections))
+	require.EqualValues(t, []byte{1, 3, 6, 7, 10}, []byte{
+		sections[0].id, sections[1].id, sections[2].id, sections[3].id, sections[4].id,
+	})
+
+	// new global is mutable i64 with index 0
+	require.EqualValues(t, []byte{0x01, 0x7e, 0x01, 0x42, 0x00, 0x0b}, sections[2].data)
+	require.Error(t, checkNoGasExport(sections[3].data))
+
+	charge := func(cost byte) []byte {
+		return []byte{0x23, 0x00, 0x42, cost, 0x7d, 0x24, 0x00, 0x23, 0x00, 0x42, 0x00, 0x53, 0x04, 0x40, 0x00, 0x0b}
+	}
+	// function body: 'loop' and final 'end' charged to the function,
+	// 'br' and the 'end' of the loop charged to every loop iteration
+	body := []byte{0x00}
+	body = append(body, charge(2)...)
+	body = append(body, 0x03, 0x40)
+	body = append(body, charge(2)...)
+	body = append(body, 0x0c, 0x00, 0x0b, 0x0b)
+	expected := append([]byte{0x01, byte(len(body))}, body...)
+	require.EqualValues(t, expected, sections[4].data)
+}
+
+func TestInstrumentGasTwice(t *testing.T) {
+	wasmData, err := InstrumentGas(loopModule)
+	require.NoError(t, err)
+	_, err = InstrumentGas(wasmData)
+	require.Error(t, err)
+}
+
+func TestInstrumentGasContracts(t *testing.T) {
+	files, err := filepath.Glob("../../../contracts/rust/*/test/*_bg.wasm")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		wasmData, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		instrumented, err := InstrumentGas(wasmData)
+		require.NoError(t, err, file)
+		require.Greater(t, len(instrumented), len(wasmData))
+
+		// all instrumented function bodies must decode again
+		sections, err := splitSections(instrumented[len(wasmMagic):])
+		require.NoError(t, err)
+		for _, s := range sections {
+			if s.id == sectionCode {
+				_, err = instrumentCode(s.data, 0)
+				require.NoError(t, err, file)
+			}
+		}
+	}
+}
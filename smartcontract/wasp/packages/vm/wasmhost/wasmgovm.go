@@ -7,12 +7,22 @@ import (
 	"errors"
 	"github.com/iotaledger/wasp/packages/vm/wasmlib"
 	"strings"
+	"sync"
 )
 
+// goVMMutex serializes the Go contracts of all chains, because they share the host and
+// the export table of wasmlib. It is held while the code of a contract runs and released
+// during the calls into the host, which can call other contracts.
+var goVMMutex sync.Mutex
+
+// WasmGoVM runs Go contracts directly against the host. There is no Wasm code
+// to instrument, so gas is metered by counting the calls into the host instead.
 type WasmGoVM struct {
 	WasmVmBase
-	contract string
-	onLoad   map[string]func()
+	contract  string
+	exports   *wasmlib.ScExportTable
+	hostCalls uint64
+	onLoad    map[string]func()
 }
 
 func NewWasmGoVM(onLoad map[string]func()) *WasmGoVM {
@@ -23,7 +33,6 @@ func NewWasmGoVM(onLoad map[string]func()) *WasmGoVM {
 
 func (vm *WasmGoVM) LinkHost(impl WasmVM, host *WasmHost) error {
 	vm.WasmVmBase.LinkHost(impl, host)
-	wasmlib.ConnectHost(host)
 	return nil
 }
 
@@ -37,6 +46,9 @@ func (vm *WasmGoVM) LoadWasm(wasmData []byte) error {
 	if !ok {
 		return errors.New("WasmGoVM: unknown contract: " + vm.contract)
 	}
+	vm.exports = &wasmlib.ScExportTable{}
+	vm.enter()
+	defer vm.leave()
 	onLoad()
 	return nil
 }
@@ -50,6 +62,8 @@ func (vm *WasmGoVM) RunFunction(functionName string) error {
 }
 
 func (vm *WasmGoVM) RunScFunction(index int32) error {
+	vm.enter()
+	defer vm.leave()
 	wasmlib.ScCallEntrypoint(index)
 	return nil
 }
@@ -58,3 +72,77 @@ func (vm *WasmGoVM) UnsafeMemory() []byte {
 	// no need to communicate through Wasm mem pool
 	return nil
 }
+
+// HostCalls returns the number of calls into the host since the VM was created
+func (vm *WasmGoVM) HostCalls() uint64 {
+	return vm.hostCalls
+}
+
+// enter takes the wasmlib over for the code of this contract
+func (vm *WasmGoVM) enter() {
+	goVMMutex.Lock()
+	wasmlib.ConnectHost(vm)
+	wasmlib.ConnectExports(vm.exports)
+}
+
+func (vm *WasmGoVM) leave() {
+	goVMMutex.Unlock()
+}
+
+func (vm *WasmGoVM) burnGas(amount uint64) {
+	vm.hostCalls++
+	vm.host.BurnGas(GasPerHostCall + amount*GasPerByte)
+}
+
+// the wasmlib.ScHost implementation charges gas before passing the call to the host
+// and leaves the wasmlib to other contracts until the call returns
+
+func (vm *WasmGoVM) Exists(objId int32, keyId int32, typeId int32) bool {
+	vm.leave()
+	defer vm.enter()
+	vm.burnGas(0)
+	vm.host.traceHostCall("Exists(o%d,k%d,t%d)", objId, keyId, typeId)
+	return vm.host.Exists(objId, keyId, typeId)
+}
+
+func (vm *WasmGoVM) GetBytes(objId int32, keyId int32, typeId int32) []byte {
+	vm.leave()
+	defer vm.enter()
+	vm.burnGas(0)
+	bytes := vm.host.GetBytes(objId, keyId, typeId)
+	vm.host.traceHostCall("GetBytes(o%d,k%d,t%d) %d bytes", objId, keyId, typeId, len(bytes))
+	vm.host.BurnGas(uint64(len(bytes)) * GasPerByte)
+	return bytes
+}
+
+func (vm *WasmGoVM) GetKeyIdFromBytes(bytes []byte) int32 {
+	vm.leave()
+	defer vm.enter()
+	vm.burnGas(uint64(len(bytes)))
+	vm.host.traceHostCall("GetKeyId(%d bytes)", len(bytes))
+	return vm.host.GetKeyIdFromBytes(bytes)
+}
+
+func (vm *WasmGoVM) GetKeyIdFromString(key string) int32 {
+	vm.leave()
+	defer vm.enter()
+	vm.burnGas(uint64(len(key)))
+	vm.host.traceHostCall("GetKeyId('%s')", key)
+	return vm.host.GetKeyIdFromString(key)
+}
+
+func (vm *WasmGoVM) GetObjectId(objId int32, keyId int32, typeId int32) int32 {
+	vm.leave()
+	defer vm.enter()
+	vm.burnGas(0)
+	vm.host.traceHostCall("GetObjectId(o%d,k%d,t%d)", objId, keyId, typeId)
+	return vm.host.GetObjectId(objId, keyId, typeId)
+}
+
+func (vm *WasmGoVM) SetBytes(objId int32, keyId int32, typeId int32, value []byte) {
+	vm.leave()
+	defer vm.enter()
+	vm.burnGas(uint64(len(value)))
+	vm.host.traceHostCall("SetBytes(o%d,k%d,t%d) %d bytes", objId, keyId, typeId, len(value))
+	vm.host.SetBytes(objId, keyId, typeId, value)
+}
@@ -5,13 +5,24 @@ package wasmhost
 
 import (
 	"errors"
+	"fmt"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/vm"
+	"math"
 )
 
+// GasMeter keeps track of the gas budget of the current request
+type GasMeter interface {
+	BurnGas(amount uint64)
+	GasRemaining() uint64
+}
+
 type WasmHost struct {
 	KvStoreHost
 	vm          WasmVM
+	gas         GasMeter
+	tracer      vm.HostTracer
 	codeToFunc  map[uint32]string
 	funcToCode  map[string]uint32
 	funcToIndex map[string]int32
@@ -29,6 +40,42 @@ func (host *WasmHost) Init(null HostObject, root HostObject, log *logger.Logger)
 	host.funcToIndex = make(map[string]int32)
 }
 
+// BurnGas charges gas to the current gas meter, which will panic when the budget is exhausted
+func (host *WasmHost) BurnGas(amount uint64) {
+	if host.gas != nil {
+		host.gas.BurnGas(amount)
+	}
+}
+
+// GasRemaining returns the gas left in the budget, without a gas meter the budget is unlimited
+func (host *WasmHost) GasRemaining() uint64 {
+	if host.gas == nil {
+		return math.MaxInt64
+	}
+	return host.gas.GasRemaining()
+}
+
+// SetGasMeter sets the gas meter for the upcoming call and returns the previous one
+func (host *WasmHost) SetGasMeter(gas GasMeter) GasMeter {
+	saveGas := host.gas
+	host.gas = gas
+	return saveGas
+}
+
+// SetHostTracer sets the tracer of host calls for the upcoming call and returns the previous one.
+// Host calls are not traced when the tracer is nil
+func (host *WasmHost) SetHostTracer(tracer vm.HostTracer) vm.HostTracer {
+	saveTracer := host.tracer
+	host.tracer = tracer
+	return saveTracer
+}
+
+func (host *WasmHost) traceHostCall(format string, a ...interface{}) {
+	if host.tracer != nil {
+		host.tracer.TraceHostCall(fmt.Sprintf(format, a...))
+	}
+}
+
 func (host *WasmHost) FunctionFromCode(code uint32) string {
 	return host.codeToFunc[code]
 }
@@ -42,6 +42,14 @@ const (
 	// to the keys give this one a different value and make sure
 	// the client side in wasplib is updated accordingly
 	KeyZzzzzzz = int32(-37)
+
+	// Keys added after KeyZzzzzzz get the next lower values, so that the
+	// values of the keys above, and with them Wasm binaries built against
+	// the previous key table, stay valid
+	KeyBlockIndex = int32(-38)
+	KeyChainId    = int32(-39)
+	KeyEntropy    = int32(-40)
+	KeyRequestId  = int32(-41)
 )
 
 var keyMap = map[string]int32{
@@ -50,13 +58,16 @@ var keyMap = map[string]int32{
 	"balances":        KeyBalances,
 	"base58Bytes":     KeyBase58Bytes,
 	"base58String":    KeyBase58String,
+	"blockIndex":      KeyBlockIndex,
 	"call":            KeyCall,
 	"caller":          KeyCaller,
+	"chainId":         KeyChainId,
 	"chainOwnerId":    KeyChainOwnerId,
 	"color":           KeyColor,
 	"contractCreator": KeyContractCreator,
 	"contractId":      KeyContractId,
 	"deploy":          KeyDeploy,
+	"entropy":         KeyEntropy,
 	"event":           KeyEvent,
 	"exports":         KeyExports,
 	"hashBlake2b":     KeyHashBlake2b,
@@ -71,6 +82,7 @@ var keyMap = map[string]int32{
 	"params":          KeyParams,
 	"post":            KeyPost,
 	"random":          KeyRandom,
+	"requestId":       KeyRequestId,
 	"results":         KeyResults,
 	"return":          KeyReturn,
 	"state":           KeyState,
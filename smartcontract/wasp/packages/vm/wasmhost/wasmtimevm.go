@@ -6,10 +6,14 @@ package wasmhost
 import (
 	"errors"
 	"github.com/bytecodealliance/wasmtime-go"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"math"
 )
 
 type WasmTimeVM struct {
 	WasmVmBase
+	gas      *wasmtime.Global
+	gasSet   int64
 	instance *wasmtime.Instance
 	linker   *wasmtime.Linker
 	memory   *wasmtime.Memory
@@ -28,6 +32,8 @@ func (vm *WasmTimeVM) LinkHost(impl WasmVM, host *WasmHost) error {
 	vm.WasmVmBase.LinkHost(impl, host)
 	err := vm.linker.DefineFunc("wasplib", "hostGetBytes",
 		func(objId int32, keyId int32, typeId int32, stringRef int32, size int32) int32 {
+			vm.syncGas()
+			defer vm.resetGas()
 			return vm.HostGetBytes(objId, keyId, typeId, stringRef, size)
 		})
 	if err != nil {
@@ -35,6 +41,8 @@ func (vm *WasmTimeVM) LinkHost(impl WasmVM, host *WasmHost) error {
 	}
 	err = vm.linker.DefineFunc("wasplib", "hostGetKeyId",
 		func(keyRef int32, size int32) int32 {
+			vm.syncGas()
+			defer vm.resetGas()
 			return vm.HostGetKeyId(keyRef, size)
 		})
 	if err != nil {
@@ -42,6 +50,8 @@ func (vm *WasmTimeVM) LinkHost(impl WasmVM, host *WasmHost) error {
 	}
 	err = vm.linker.DefineFunc("wasplib", "hostGetObjectId",
 		func(objId int32, keyId int32, typeId int32) int32 {
+			vm.syncGas()
+			defer vm.resetGas()
 			return vm.HostGetObjectId(objId, keyId, typeId)
 		})
 	if err != nil {
@@ -49,6 +59,8 @@ func (vm *WasmTimeVM) LinkHost(impl WasmVM, host *WasmHost) error {
 	}
 	err = vm.linker.DefineFunc("wasplib", "hostSetBytes",
 		func(objId int32, keyId int32, typeId int32, stringRef int32, size int32) {
+			vm.syncGas()
+			defer vm.resetGas()
 			vm.HostSetBytes(objId, keyId, typeId, stringRef, size)
 		})
 	if err != nil {
@@ -66,7 +78,10 @@ func (vm *WasmTimeVM) LinkHost(impl WasmVM, host *WasmHost) error {
 }
 
 func (vm *WasmTimeVM) LoadWasm(wasmData []byte) error {
-	var err error
+	wasmData, err := InstrumentGas(wasmData)
+	if err != nil {
+		return err
+	}
 	vm.module, err = wasmtime.NewModule(vm.store.Engine, wasmData)
 	if err != nil {
 		return err
@@ -75,6 +90,14 @@ func (vm *WasmTimeVM) LoadWasm(wasmData []byte) error {
 	if err != nil {
 		return err
 	}
+	gas := vm.instance.GetExport(GasExport)
+	if gas == nil {
+		return errors.New("no gas export")
+	}
+	vm.gas = gas.Global()
+	if vm.gas == nil {
+		return errors.New("gas export not a global")
+	}
 	memory := vm.instance.GetExport("memory")
 	if memory == nil {
 		return errors.New("no memory export")
@@ -91,8 +114,9 @@ func (vm *WasmTimeVM) RunFunction(functionName string) error {
 	if export == nil {
 		return errors.New("unknown export function: '" + functionName + "'")
 	}
+	vm.resetGas()
 	_, err := export.Func().Call()
-	return err
+	return vm.checkGas(err)
 }
 
 func (vm *WasmTimeVM) RunScFunction(index int32) error {
@@ -101,11 +125,48 @@ func (vm *WasmTimeVM) RunScFunction(index int32) error {
 		return errors.New("unknown export function: 'on_call_entrypoint'")
 	}
 	frame := vm.PreCall()
+	defer vm.PostCall(frame)
+	vm.resetGas()
 	_, err := export.Func().Call(index)
-	vm.PostCall(frame)
-	return err
+	return vm.checkGas(err)
 }
 
 func (vm *WasmTimeVM) UnsafeMemory() []byte {
 	return vm.memory.UnsafeData()
 }
+
+// resetGas sets the Wasm gas counter to the remaining gas budget of the host
+func (vm *WasmTimeVM) resetGas() {
+	remaining := vm.host.GasRemaining()
+	if remaining > math.MaxInt64 {
+		remaining = math.MaxInt64
+	}
+	vm.gasSet = int64(remaining)
+	err := vm.gas.Set(wasmtime.ValI64(vm.gasSet))
+	if err != nil {
+		panic("resetGas: " + err.Error())
+	}
+}
+
+// syncGas charges the gas that the Wasm code used since the last reset to the host.
+// It is called around every host call so that nested calls share the same budget.
+func (vm *WasmTimeVM) syncGas() {
+	left := vm.gas.Get().I64()
+	if left < 0 {
+		left = 0
+	}
+	vm.host.BurnGas(uint64(vm.gasSet - left))
+	vm.gasSet = left
+}
+
+// checkGas syncs gas after a call and turns a trap caused by
+// an exhausted gas counter into ErrGasBudgetExceeded
+func (vm *WasmTimeVM) checkGas(err error) error {
+	outOfGas := vm.gas.Get().I64() < 0
+	vm.syncGas()
+	if outOfGas {
+		// the instrumented code trapped on 'unreachable' after the budget ran out
+		return coretypes.ErrGasBudgetExceeded
+	}
+	return err
+}
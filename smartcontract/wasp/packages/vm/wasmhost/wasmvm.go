@@ -8,6 +8,13 @@ import (
 	"fmt"
 )
 
+const (
+	// GasPerHostCall is the gas charged for every call from the Wasm code into the host
+	GasPerHostCall = 100
+	// GasPerByte is the gas charged for every byte that is passed between the Wasm code and the host
+	GasPerByte = 1
+)
+
 type WasmVM interface {
 	LinkHost(impl WasmVM, host *WasmHost) error
 	LoadWasm(wasmData []byte) error
@@ -46,9 +53,11 @@ func (vm *WasmVmBase) HostFdWrite(fd int32, iovs int32, size int32, written int3
 func (vm *WasmVmBase) HostGetBytes(objId int32, keyId int32, typeId int32, stringRef int32, size int32) int32 {
 	host := vm.host
 	host.TraceAll("HostGetBytes(o%d,k%d,t%d,r%d,s%d)", objId, keyId, typeId, stringRef, size)
+	host.BurnGas(GasPerHostCall)
 
 	// negative size means only check for existence
 	if size < 0 {
+		host.traceHostCall("Exists(o%d,k%d,t%d)", objId, keyId, typeId)
 		if host.Exists(objId, keyId, typeId) {
 			return 0
 		}
@@ -57,36 +66,45 @@ func (vm *WasmVmBase) HostGetBytes(objId int32, keyId int32, typeId int32, strin
 	}
 
 	bytes := host.GetBytes(objId, keyId, typeId)
+	host.traceHostCall("GetBytes(o%d,k%d,t%d) %d bytes", objId, keyId, typeId, len(bytes))
 	if bytes == nil {
 		return -1
 	}
+	host.BurnGas(uint64(len(bytes)) * GasPerByte)
 	return vm.vmSetBytes(stringRef, size, bytes)
 }
 
 func (vm *WasmVmBase) HostGetKeyId(keyRef int32, size int32) int32 {
 	host := vm.host
 	host.TraceAll("HostGetKeyId(r%d,s%d)", keyRef, size)
+	host.BurnGas(GasPerHostCall)
 	// non-negative size means original key was a string
 	if size >= 0 {
 		bytes := vm.vmGetBytes(keyRef, size)
+		host.traceHostCall("GetKeyId('%s')", string(bytes))
 		return host.GetKeyIdFromString(string(bytes))
 	}
 
 	// negative size means original key was a byte slice
 	bytes := vm.vmGetBytes(keyRef, -size-1)
+	host.traceHostCall("GetKeyId(%d bytes)", len(bytes))
 	return host.GetKeyIdFromBytes(bytes)
 }
 
 func (vm *WasmVmBase) HostGetObjectId(objId int32, keyId int32, typeId int32) int32 {
 	host := vm.host
 	host.TraceAll("HostGetObjectId(o%d,k%d,t%d)", objId, keyId, typeId)
+	host.BurnGas(GasPerHostCall)
+	host.traceHostCall("GetObjectId(o%d,k%d,t%d)", objId, keyId, typeId)
 	return host.GetObjectId(objId, keyId, typeId)
 }
 
 func (vm *WasmVmBase) HostSetBytes(objId int32, keyId int32, typeId int32, stringRef int32, size int32) {
 	host := vm.host
 	host.TraceAll("HostSetBytes(o%d,k%d,t%d,r%d,s%d)", objId, keyId, typeId, stringRef, size)
+	host.BurnGas(GasPerHostCall + uint64(size)*GasPerByte)
 	bytes := vm.vmGetBytes(stringRef, size)
+	host.traceHostCall("SetBytes(o%d,k%d,t%d) %d bytes", objId, keyId, typeId, size)
 	host.SetBytes(objId, keyId, typeId, bytes)
 }
 
@@ -168,6 +168,21 @@ func (ctx ScBaseContext) Balances() ScBalances {
 	return ScBalances{Root.GetMap(KeyBalances).Immutable()}
 }
 
+// index of the block of the state the function is called on, for funcs the block the request will be stored in
+func (ctx ScBaseContext) BlockIndex() int64 {
+	return Root.GetInt(KeyBlockIndex).Value()
+}
+
+// retrieve the agent id of the caller of the smart contract, the zero agent id for views called from outside of the chain
+func (ctx ScBaseContext) Caller() *ScAgentId {
+	return Root.GetAgentId(KeyCaller).Value()
+}
+
+// retrieve the id of the chain this contract lives on
+func (ctx ScBaseContext) ChainId() *ScChainId {
+	return Root.GetChainId(KeyChainId).Value()
+}
+
 // retrieve the agent id of the owner of the chain this contract lives on
 func (ctx ScBaseContext) ChainOwnerId() *ScAgentId {
 	return Root.GetAgentId(KeyChainOwnerId).Value()
@@ -253,11 +268,6 @@ func (ctx ScFuncContext) Call(hContract ScHname, hFunction ScHname, params *ScMu
 	return Root.GetMap(KeyReturn).Immutable()
 }
 
-// retrieve the agent id of the caller of the smart contract
-func (ctx ScFuncContext) Caller() *ScAgentId {
-	return Root.GetAgentId(KeyCaller).Value()
-}
-
 // calls a smart contract function on the current contract
 func (ctx ScFuncContext) CallSelf(hFunction ScHname, params *ScMutableMap, transfer balances) ScImmutableMap {
 	return ctx.Call(ctx.ContractId().Hname(), hFunction, params, transfer)
@@ -282,11 +292,29 @@ func (ctx ScBaseContext) Event(text string) {
 	Root.GetString(KeyEvent).SetValue(text)
 }
 
+// signals a typed event with the specified topic and parameters
+// the event is stored in the eventlog and can be queried by topic, parameter and request
+func (ctx ScFuncContext) EmitEvent(topic string, params *ScMutableMap) {
+	encode := NewBytesEncoder()
+	encode.String(topic)
+	if params != nil {
+		encode.Int(int64(params.objId))
+	} else {
+		encode.Int(0)
+	}
+	Root.GetBytes(KeyEvent).SetValue(encode.Data())
+}
+
 // quick check to see if the caller of the smart contract was the specified originator agent
 func (ctx ScFuncContext) From(originator *ScAgentId) bool {
 	return ctx.Caller().Equals(originator)
 }
 
+// 32 bytes of deterministic and unpredictably random data, based on the hash of the current state transaction
+func (ctx ScFuncContext) GetEntropy() *ScHash {
+	return Root.GetHash(KeyEntropy).Value()
+}
+
 // access the incoming balances for all token colors
 func (ctx ScFuncContext) Incoming() ScBalances {
 	return ScBalances{Root.GetMap(KeyIncoming).Immutable()}
@@ -311,6 +339,11 @@ func (ctx ScFuncContext) Post(par *PostRequestParams) {
 	Root.GetBytes(KeyPost).SetValue(encode.Data())
 }
 
+// retrieve the id of the request the function is called for
+func (ctx ScFuncContext) RequestId() *ScRequestId {
+	return NewScRequestIdFromBytes(Root.GetBytes(KeyRequestId).Value())
+}
+
 // access to mutable state storage
 func (ctx ScFuncContext) State() ScMutableMap {
 	return Root.GetMap(KeyState)
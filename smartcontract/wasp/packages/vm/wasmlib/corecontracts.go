@@ -39,9 +39,11 @@ const CoreRootFuncGrantDeployPermission = ScHname(0xf440263a)
 const CoreRootFuncRevokeDeployPermission = ScHname(0x850744f1)
 const CoreRootFuncSetContractFee = ScHname(0x8421a42b)
 const CoreRootFuncSetDefaultFee = ScHname(0x3310ecd0)
+const CoreRootFuncUpgradeContract = ScHname(0x00d30d5c)
 const CoreRootViewFindContract = ScHname(0xc145ca00)
 const CoreRootViewGetChainInfo = ScHname(0x434477e2)
 const CoreRootViewGetFeeInfo = ScHname(0x9fe54b48)
+const CoreRootViewGetUpgradeHistory = ScHname(0x09671a6a)
 
 const CoreRootParamChainOwner = Key("$$owner$$")
 const CoreRootParamDeployer = Key("$$deployer$$")
@@ -3,18 +3,30 @@
 
 package wasmlib
 
-var (
+// ScExportTable holds the entry points that on_load registered with AddFunc and AddView.
+// A Wasm contract has its own table, but Go contracts share this package, so they
+// connect their own table before they call into it.
+type ScExportTable struct {
 	funcs []func(ctx *ScFuncContext)
 	views []func(ctx *ScViewContext)
-)
+}
+
+var exportTable = &ScExportTable{}
+
+// ConnectExports makes table the current export table and returns the previous one
+func ConnectExports(table *ScExportTable) *ScExportTable {
+	oldTable := exportTable
+	exportTable = table
+	return oldTable
+}
 
 //export on_call_entrypoint
 func ScCallEntrypoint(index int32) {
 	if (index & 0x8000) != 0 {
-		views[index&0x7fff](&ScViewContext{})
+		exportTable.views[index&0x7fff](&ScViewContext{})
 		return
 	}
-	funcs[index](&ScFuncContext{})
+	exportTable.funcs[index](&ScFuncContext{})
 }
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
@@ -32,13 +44,19 @@ func NewScExports() ScExports {
 }
 
 func (ctx ScExports) AddFunc(name string, f func(ctx *ScFuncContext)) {
-	index := int32(len(funcs))
-	funcs = append(funcs, f)
+	index := int32(len(exportTable.funcs))
+	if index >= 0x8000 {
+		panic("too many exported funcs")
+	}
+	exportTable.funcs = append(exportTable.funcs, f)
 	ctx.exports.GetString(index).SetValue(name)
 }
 
 func (ctx ScExports) AddView(name string, f func(ctx *ScViewContext)) {
-	index := int32(len(views))
-	views = append(views, f)
+	index := int32(len(exportTable.views))
+	if index >= 0x8000 {
+		panic("too many exported views")
+	}
+	exportTable.views = append(exportTable.views, f)
 	ctx.exports.GetString(index | 0x8000).SetValue(name)
 }
@@ -275,6 +275,37 @@ func (hn ScHname) String() string {
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
+type ScRequestId struct {
+	id [34]byte
+}
+
+func NewScRequestIdFromBytes(bytes []byte) *ScRequestId {
+	o := &ScRequestId{}
+	if len(bytes) != len(o.id) {
+		logPanic("invalid request id length")
+	}
+	copy(o.id[:], bytes)
+	return o
+}
+
+func (o *ScRequestId) Bytes() []byte {
+	return o.id[:]
+}
+
+func (o *ScRequestId) Equals(other *ScRequestId) bool {
+	return o.id == other.id
+}
+
+func (o *ScRequestId) KeyId() Key32 {
+	return GetKeyIdFromBytes(o.Bytes())
+}
+
+func (o *ScRequestId) String() string {
+	return base58Encode(o.id[:])
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
 func logPanic(text string) {
 	ScBaseContext{}.Panic(text)
 }
@@ -8,6 +8,8 @@ import "encoding/binary"
 const (
 	// all TYPE_* values should exactly match the counterpart OBJTYPE_* values on the host!
 	TYPE_ARRAY int32 = 0x20
+	// TYPE_ARRAY2 is combined with TYPE_ARRAY for an array of arrays
+	TYPE_ARRAY2 int32 = 0x40
 
 	TYPE_ADDRESS     int32 = 1
 	TYPE_AGENT_ID    int32 = 2
@@ -107,6 +107,21 @@ func (o ScImmutableBytesArray) Length() int32 {
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
+type ScImmutableBytesArrayArray struct {
+	objId int32
+}
+
+func (o ScImmutableBytesArrayArray) GetBytesArray(index int32) ScImmutableBytesArray {
+	arrId := GetObjectId(o.objId, Key32(index), TYPE_BYTES|TYPE_ARRAY)
+	return ScImmutableBytesArray{objId: arrId}
+}
+
+func (o ScImmutableBytesArrayArray) Length() int32 {
+	return GetLength(o.objId)
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
 type ScImmutableChainId struct {
 	objId int32
 	keyId Key32
@@ -264,6 +279,21 @@ func (o ScImmutableIntArray) Length() int32 {
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
+type ScImmutableIntArrayArray struct {
+	objId int32
+}
+
+func (o ScImmutableIntArrayArray) GetIntArray(index int32) ScImmutableIntArray {
+	arrId := GetObjectId(o.objId, Key32(index), TYPE_INT|TYPE_ARRAY)
+	return ScImmutableIntArray{objId: arrId}
+}
+
+func (o ScImmutableIntArrayArray) Length() int32 {
+	return GetLength(o.objId)
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
 type ScImmutableMap struct {
 	objId int32
 }
@@ -295,6 +325,11 @@ func (o ScImmutableMap) GetBytesArray(key MapKey) ScImmutableBytesArray {
 	return ScImmutableBytesArray{objId: arrId}
 }
 
+func (o ScImmutableMap) GetBytesArrayArray(key MapKey) ScImmutableBytesArrayArray {
+	arrId := GetObjectId(o.objId, key.KeyId(), TYPE_BYTES|TYPE_ARRAY|TYPE_ARRAY2)
+	return ScImmutableBytesArrayArray{objId: arrId}
+}
+
 func (o ScImmutableMap) GetChainId(key MapKey) ScImmutableChainId {
 	return ScImmutableChainId{objId: o.objId, keyId: key.KeyId()}
 }
@@ -334,6 +369,11 @@ func (o ScImmutableMap) GetIntArray(key MapKey) ScImmutableIntArray {
 	return ScImmutableIntArray{objId: arrId}
 }
 
+func (o ScImmutableMap) GetIntArrayArray(key MapKey) ScImmutableIntArrayArray {
+	arrId := GetObjectId(o.objId, key.KeyId(), TYPE_INT|TYPE_ARRAY|TYPE_ARRAY2)
+	return ScImmutableIntArrayArray{objId: arrId}
+}
+
 func (o ScImmutableMap) GetMap(key MapKey) ScImmutableMap {
 	mapId := GetObjectId(o.objId, key.KeyId(), TYPE_MAP)
 	return ScImmutableMap{objId: mapId}
@@ -353,6 +393,11 @@ func (o ScImmutableMap) GetStringArray(key MapKey) ScImmutableStringArray {
 	return ScImmutableStringArray{objId: arrId}
 }
 
+func (o ScImmutableMap) GetStringArrayArray(key MapKey) ScImmutableStringArrayArray {
+	arrId := GetObjectId(o.objId, key.KeyId(), TYPE_STRING|TYPE_ARRAY|TYPE_ARRAY2)
+	return ScImmutableStringArrayArray{objId: arrId}
+}
+
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
 type ScImmutableMapArray struct {
@@ -404,3 +449,18 @@ func (o ScImmutableStringArray) GetString(index int32) ScImmutableString {
 func (o ScImmutableStringArray) Length() int32 {
 	return GetLength(o.objId)
 }
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
+type ScImmutableStringArrayArray struct {
+	objId int32
+}
+
+func (o ScImmutableStringArrayArray) GetStringArray(index int32) ScImmutableStringArray {
+	arrId := GetObjectId(o.objId, Key32(index), TYPE_STRING|TYPE_ARRAY)
+	return ScImmutableStringArray{objId: arrId}
+}
+
+func (o ScImmutableStringArrayArray) Length() int32 {
+	return GetLength(o.objId)
+}
@@ -59,4 +59,10 @@ const (
 	KeyValidBls        = Key32(-35)
 	KeyValidEd25519    = Key32(-36)
 	KeyZzzzzzz         = Key32(-37)
+
+	// keys added after KeyZzzzzzz, see wasmhost.KeyZzzzzzz
+	KeyBlockIndex = Key32(-38)
+	KeyChainId    = Key32(-39)
+	KeyEntropy    = Key32(-40)
+	KeyRequestId  = Key32(-41)
 )
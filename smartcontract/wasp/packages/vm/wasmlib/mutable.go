@@ -147,6 +147,29 @@ func (o ScMutableBytesArray) Length() int32 {
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
+type ScMutableBytesArrayArray struct {
+	objId int32
+}
+
+func (o ScMutableBytesArrayArray) Clear() {
+	SetClear(o.objId)
+}
+
+func (o ScMutableBytesArrayArray) GetBytesArray(index int32) ScMutableBytesArray {
+	arrId := GetObjectId(o.objId, Key32(index), TYPE_BYTES|TYPE_ARRAY)
+	return ScMutableBytesArray{objId: arrId}
+}
+
+func (o ScMutableBytesArrayArray) Immutable() ScImmutableBytesArrayArray {
+	return ScImmutableBytesArrayArray{objId: o.objId}
+}
+
+func (o ScMutableBytesArrayArray) Length() int32 {
+	return GetLength(o.objId)
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
 type ScMutableChainId struct {
 	objId int32
 	keyId Key32
@@ -354,6 +377,29 @@ func (o ScMutableIntArray) Length() int32 {
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
+type ScMutableIntArrayArray struct {
+	objId int32
+}
+
+func (o ScMutableIntArrayArray) Clear() {
+	SetClear(o.objId)
+}
+
+func (o ScMutableIntArrayArray) GetIntArray(index int32) ScMutableIntArray {
+	arrId := GetObjectId(o.objId, Key32(index), TYPE_INT|TYPE_ARRAY)
+	return ScMutableIntArray{objId: arrId}
+}
+
+func (o ScMutableIntArrayArray) Immutable() ScImmutableIntArrayArray {
+	return ScImmutableIntArrayArray{objId: o.objId}
+}
+
+func (o ScMutableIntArrayArray) Length() int32 {
+	return GetLength(o.objId)
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
 type ScMutableMap struct {
 	objId int32
 }
@@ -394,6 +440,11 @@ func (o ScMutableMap) GetBytesArray(key MapKey) ScMutableBytesArray {
 	return ScMutableBytesArray{objId: arrId}
 }
 
+func (o ScMutableMap) GetBytesArrayArray(key MapKey) ScMutableBytesArrayArray {
+	arrId := GetObjectId(o.objId, key.KeyId(), TYPE_BYTES|TYPE_ARRAY|TYPE_ARRAY2)
+	return ScMutableBytesArrayArray{objId: arrId}
+}
+
 func (o ScMutableMap) GetChainId(key MapKey) ScMutableChainId {
 	return ScMutableChainId{objId: o.objId, keyId: key.KeyId()}
 }
@@ -433,6 +484,11 @@ func (o ScMutableMap) GetIntArray(key MapKey) ScMutableIntArray {
 	return ScMutableIntArray{objId: arrId}
 }
 
+func (o ScMutableMap) GetIntArrayArray(key MapKey) ScMutableIntArrayArray {
+	arrId := GetObjectId(o.objId, key.KeyId(), TYPE_INT|TYPE_ARRAY|TYPE_ARRAY2)
+	return ScMutableIntArrayArray{objId: arrId}
+}
+
 func (o ScMutableMap) GetMap(key MapKey) ScMutableMap {
 	mapId := GetObjectId(o.objId, key.KeyId(), TYPE_MAP)
 	return ScMutableMap{objId: mapId}
@@ -452,6 +508,11 @@ func (o ScMutableMap) GetStringArray(key MapKey) ScMutableStringArray {
 	return ScMutableStringArray{objId: arrId}
 }
 
+func (o ScMutableMap) GetStringArrayArray(key MapKey) ScMutableStringArrayArray {
+	arrId := GetObjectId(o.objId, key.KeyId(), TYPE_STRING|TYPE_ARRAY|TYPE_ARRAY2)
+	return ScMutableStringArrayArray{objId: arrId}
+}
+
 func (o ScMutableMap) Immutable() ScImmutableMap {
 	return ScImmutableMap{objId: o.objId}
 }
@@ -527,3 +588,26 @@ func (o ScMutableStringArray) Immutable() ScImmutableStringArray {
 func (o ScMutableStringArray) Length() int32 {
 	return GetLength(o.objId)
 }
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
+type ScMutableStringArrayArray struct {
+	objId int32
+}
+
+func (o ScMutableStringArrayArray) Clear() {
+	SetClear(o.objId)
+}
+
+func (o ScMutableStringArrayArray) GetStringArray(index int32) ScMutableStringArray {
+	arrId := GetObjectId(o.objId, Key32(index), TYPE_STRING|TYPE_ARRAY)
+	return ScMutableStringArray{objId: arrId}
+}
+
+func (o ScMutableStringArrayArray) Immutable() ScImmutableStringArrayArray {
+	return ScImmutableStringArrayArray{objId: o.objId}
+}
+
+func (o ScMutableStringArrayArray) Length() int32 {
+	return GetLength(o.objId)
+}
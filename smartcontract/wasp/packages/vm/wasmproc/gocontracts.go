@@ -0,0 +1,55 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmproc
+
+import (
+	"sync"
+
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/vm/wasmhost"
+)
+
+// GoVMType is the VM type of the contracts written in Go against wasmlib and run by wasmhost.WasmGoVM.
+// The program binary of such contract in the blob is its name with the "go:" prefix, see GoContractBinary
+const GoVMType = "wasmgovm"
+
+var (
+	goContracts      = make(map[string]func())
+	goContractsMutex sync.RWMutex
+)
+
+// RegisterGoContract registers the on_load function of the Go contract under its name.
+// Registering the name again replaces the function for the processors created afterwards
+func RegisterGoContract(name string, onLoad func()) {
+	goContractsMutex.Lock()
+	defer goContractsMutex.Unlock()
+
+	goContracts[name] = onLoad
+}
+
+// GoContractBinary is the program binary of the registered Go contract, to be uploaded in the blob with GoVMType
+func GoContractBinary(name string) []byte {
+	return []byte("go:" + name)
+}
+
+// GetGoProcessor creates the processor of the registered Go contract from its program binary
+func GetGoProcessor(binaryCode []byte, logger *logger.Logger) (coretypes.Processor, error) {
+	goContractsMutex.RLock()
+	onLoad := make(map[string]func(), len(goContracts))
+	for name, f := range goContracts {
+		onLoad[name] = f
+	}
+	goContractsMutex.RUnlock()
+
+	vm, err := NewWasmProcessor(wasmhost.NewWasmGoVM(onLoad), logger)
+	if err != nil {
+		return nil, err
+	}
+	err = vm.LoadWasm(binaryCode)
+	if err != nil {
+		return nil, err
+	}
+	return vm, nil
+}
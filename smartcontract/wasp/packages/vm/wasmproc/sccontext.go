@@ -11,16 +11,20 @@ import (
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/packages/vm/wasmhost"
 )
 
 var typeIds = map[int32]int32{
 	wasmhost.KeyBalances:        wasmhost.OBJTYPE_MAP,
+	wasmhost.KeyBlockIndex:      wasmhost.OBJTYPE_INT,
 	wasmhost.KeyCall:            wasmhost.OBJTYPE_BYTES,
 	wasmhost.KeyCaller:          wasmhost.OBJTYPE_AGENT_ID,
+	wasmhost.KeyChainId:         wasmhost.OBJTYPE_CHAIN_ID,
 	wasmhost.KeyChainOwnerId:    wasmhost.OBJTYPE_AGENT_ID,
 	wasmhost.KeyContractCreator: wasmhost.OBJTYPE_AGENT_ID,
 	wasmhost.KeyDeploy:          wasmhost.OBJTYPE_BYTES,
+	wasmhost.KeyEntropy:         wasmhost.OBJTYPE_HASH,
 	wasmhost.KeyEvent:           wasmhost.OBJTYPE_STRING,
 	wasmhost.KeyExports:         wasmhost.OBJTYPE_STRING | wasmhost.OBJTYPE_ARRAY,
 	wasmhost.KeyContractId:      wasmhost.OBJTYPE_CONTRACT_ID,
@@ -30,6 +34,7 @@ var typeIds = map[int32]int32{
 	wasmhost.KeyPanic:           wasmhost.OBJTYPE_STRING,
 	wasmhost.KeyParams:          wasmhost.OBJTYPE_MAP,
 	wasmhost.KeyPost:            wasmhost.OBJTYPE_BYTES,
+	wasmhost.KeyRequestId:       wasmhost.OBJTYPE_BYTES,
 	wasmhost.KeyResults:         wasmhost.OBJTYPE_MAP,
 	wasmhost.KeyReturn:          wasmhost.OBJTYPE_MAP,
 	wasmhost.KeyState:           wasmhost.OBJTYPE_MAP,
@@ -55,24 +60,42 @@ func NewScContext(vm *wasmProcessor) *ScContext {
 }
 
 func (o *ScContext) Exists(keyId int32, typeId int32) bool {
-	if keyId == wasmhost.KeyExports {
+	switch keyId {
+	case wasmhost.KeyExports:
 		return o.vm.ctx == nil && o.vm.ctxView == nil
+	case wasmhost.KeyEntropy, wasmhost.KeyRequestId:
+		// only known in the context of a request
+		return o.vm.ctx != nil
 	}
 	return o.GetTypeId(keyId) > 0
 }
 
 func (o *ScContext) GetBytes(keyId int32, typeId int32) []byte {
 	switch keyId {
+	case wasmhost.KeyBlockIndex:
+		return codec.EncodeInt64(int64(o.vm.blockIndex()))
 	case wasmhost.KeyCaller:
-		return o.vm.ctx.Caller().Bytes()
+		return o.vm.caller().Bytes()
+	case wasmhost.KeyChainId:
+		return o.vm.chainID().Bytes()
 	case wasmhost.KeyChainOwnerId:
 		return o.vm.chainOwnerID().Bytes()
 	case wasmhost.KeyContractCreator:
 		return o.vm.contractCreator().Bytes()
 	case wasmhost.KeyContractId:
 		return o.vm.contractID().Bytes()
+	case wasmhost.KeyEntropy:
+		if o.vm.ctx != nil {
+			entropy := o.vm.ctx.GetEntropy()
+			return entropy[:]
+		}
+	case wasmhost.KeyRequestId:
+		if o.vm.ctx != nil {
+			reqID := o.vm.ctx.RequestID()
+			return reqID[:]
+		}
 	case wasmhost.KeyTimestamp:
-		return codec.EncodeInt64(o.vm.ctx.GetTimestamp())
+		return codec.EncodeInt64(o.vm.timestamp())
 	}
 	o.invalidKey(keyId)
 	return nil
@@ -110,6 +133,10 @@ func (o *ScContext) SetBytes(keyId int32, typeId int32, bytes []byte) {
 	case wasmhost.KeyDeploy:
 		o.processDeploy(bytes)
 	case wasmhost.KeyEvent:
+		if typeId == wasmhost.OBJTYPE_BYTES {
+			o.processEvent(bytes)
+			return
+		}
 		o.vm.ctx.Event(string(bytes))
 	case wasmhost.KeyLog:
 		o.vm.log().Infof(string(bytes))
@@ -167,6 +194,15 @@ func (o *ScContext) processDeploy(bytes []byte) {
 	}
 }
 
+// processEvent emits the typed event, encoded as the topic and the id of the params map
+func (o *ScContext) processEvent(bytes []byte) {
+	decode := NewBytesDecoder(bytes)
+	topic := string(decode.Bytes())
+	params := o.getParams(int32(decode.Int()))
+	o.Trace("EVENT '%s'", topic)
+	o.vm.ctx.EmitEvent(topic, params)
+}
+
 func (o *ScContext) processPost(bytes []byte) {
 	decode := NewBytesDecoder(bytes)
 	contract, err := coretypes.NewContractIDFromBytes(decode.Bytes())
@@ -184,12 +220,17 @@ func (o *ScContext) processPost(bytes []byte) {
 	if delay < -1 {
 		o.Panic("invalid delay: %d", delay)
 	}
+	// the delay is in seconds relative to the timestamp of the request, 0 and -1 mean no time lock
+	timeLock := uint32(0)
+	if delay > 0 {
+		timeLock = util.NanoSecToUnixSec(o.vm.ctx.GetTimestamp()) + uint32(delay)
+	}
 	o.vm.ctx.PostRequest(coretypes.PostRequestParams{
 		TargetContractID: contract,
 		EntryPoint:       function,
 		Params:           params,
 		Transfer:         transfer,
-		TimeLock:         uint32(delay),
+		TimeLock:         timeLock,
 	})
 }
 
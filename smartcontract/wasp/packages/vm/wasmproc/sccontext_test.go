@@ -0,0 +1,115 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmproc_test
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/wasmlib"
+	"github.com/stretchr/testify/require"
+)
+
+// the test contract returns the values of the context keys added after KeyZzzzzzz,
+// together with the caller and the chain owner, to check that the key table covers them
+
+const (
+	contextName = "context"
+
+	keyBlockIndex   = wasmlib.Key("blockIndex")
+	keyCaller       = wasmlib.Key("caller")
+	keyChainId      = wasmlib.Key("chainId")
+	keyChainOwnerId = wasmlib.Key("chainOwnerId")
+	keyEntropy      = wasmlib.Key("entropy")
+	keyReqId        = wasmlib.Key("reqId")
+)
+
+func contextOnLoad() {
+	exports := wasmlib.NewScExports()
+	exports.AddFunc("funcContext", funcContext)
+	exports.AddView("viewContext", viewContext)
+}
+
+func funcContext(ctx *wasmlib.ScFuncContext) {
+	r := ctx.Results()
+	r.GetInt(keyBlockIndex).SetValue(ctx.BlockIndex())
+	r.GetAgentId(keyCaller).SetValue(ctx.Caller())
+	r.GetChainId(keyChainId).SetValue(ctx.ChainId())
+	r.GetAgentId(keyChainOwnerId).SetValue(ctx.ChainOwnerId())
+	r.GetHash(keyEntropy).SetValue(ctx.GetEntropy())
+	r.GetBytes(keyReqId).SetValue(ctx.RequestId().Bytes())
+}
+
+func viewContext(ctx *wasmlib.ScViewContext) {
+	r := ctx.Results()
+	r.GetInt(keyBlockIndex).SetValue(ctx.BlockIndex())
+	r.GetAgentId(keyCaller).SetValue(ctx.Caller())
+	r.GetChainId(keyChainId).SetValue(ctx.ChainId())
+	r.GetAgentId(keyChainOwnerId).SetValue(ctx.ChainOwnerId())
+}
+
+func setupContext(t *testing.T) *solo.Chain {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	err := chain.DeployGoContract(nil, contextName, contextOnLoad)
+	require.NoError(t, err)
+	return chain
+}
+
+func TestFuncContext(t *testing.T) {
+	chain := setupContext(t)
+	user := chain.Env.NewSignatureSchemeWithFunds()
+
+	entropy := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		receipt, err := chain.PostRequestWithReceipt(solo.NewCallParams(contextName, "funcContext"), user)
+		require.NoError(t, err)
+		require.NoError(t, receipt.Error)
+		res := receipt.Result
+
+		blockIndex, _, err := codec.DecodeInt64(res.MustGet(kv.Key(keyBlockIndex)))
+		require.NoError(t, err)
+		require.EqualValues(t, receipt.BlockIndex, blockIndex)
+		caller, _, err := codec.DecodeAgentID(res.MustGet(kv.Key(keyCaller)))
+		require.NoError(t, err)
+		require.EqualValues(t, coretypes.NewAgentIDFromAddress(user.Address()), caller)
+		chainID, _, err := codec.DecodeChainID(res.MustGet(kv.Key(keyChainId)))
+		require.NoError(t, err)
+		require.EqualValues(t, chain.ChainID, chainID)
+		chainOwnerID, _, err := codec.DecodeAgentID(res.MustGet(kv.Key(keyChainOwnerId)))
+		require.NoError(t, err)
+		require.EqualValues(t, chain.OriginatorAgentID, chainOwnerID)
+		require.EqualValues(t, receipt.RequestID[:], res.MustGet(kv.Key(keyReqId)))
+
+		// the entropy differs between the blocks
+		e := res.MustGet(kv.Key(keyEntropy))
+		require.Len(t, e, 32)
+		require.False(t, entropy[string(e)])
+		entropy[string(e)] = true
+	}
+}
+
+func TestViewContext(t *testing.T) {
+	chain := setupContext(t)
+
+	res, err := chain.CallView(contextName, "viewContext")
+	require.NoError(t, err)
+
+	blockIndex, _, err := codec.DecodeInt64(res.MustGet(kv.Key(keyBlockIndex)))
+	require.NoError(t, err)
+	require.EqualValues(t, chain.State.BlockIndex(), blockIndex)
+	// a view called from outside of the chain has no caller
+	caller, _, err := codec.DecodeAgentID(res.MustGet(kv.Key(keyCaller)))
+	require.NoError(t, err)
+	require.EqualValues(t, coretypes.AgentID{}, caller)
+	chainID, _, err := codec.DecodeChainID(res.MustGet(kv.Key(keyChainId)))
+	require.NoError(t, err)
+	require.EqualValues(t, chain.ChainID, chainID)
+	chainOwnerID, _, err := codec.DecodeAgentID(res.MustGet(kv.Key(keyChainOwnerId)))
+	require.NoError(t, err)
+	require.EqualValues(t, chain.OriginatorAgentID, chainOwnerID)
+}
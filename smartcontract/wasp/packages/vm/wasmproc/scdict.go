@@ -124,9 +124,33 @@ func (o *ScDict) Exists(keyId int32, typeId int32) bool {
 	if o.typeId == (wasmhost.OBJTYPE_ARRAY | wasmhost.OBJTYPE_MAP) {
 		return uint32(keyId) <= uint32(len(o.objects))
 	}
+	if (o.typeId & wasmhost.OBJTYPE_ARRAY2) != 0 {
+		return uint32(keyId) < uint32(o.length)
+	}
 	return o.kvStore.MustHas(o.key(keyId, typeId))
 }
 
+// clear deletes the values of the nested object and of all objects nested in it
+func (o *ScDict) clear() {
+	key := kv.Key(o.NestedKey()[1:])
+	keys := []kv.Key{key}
+	o.kvStore.MustIterateKeys(key+".", func(k kv.Key) bool {
+		keys = append(keys, k)
+		return true
+	})
+	for _, k := range keys {
+		o.kvStore.Del(k)
+	}
+}
+
+// elemTypeId is the type of the elements of the array, which is an array type itself for an array of arrays
+func (o *ScDict) elemTypeId() int32 {
+	if (o.typeId & wasmhost.OBJTYPE_ARRAY2) != 0 {
+		return o.typeId &^ wasmhost.OBJTYPE_ARRAY2
+	}
+	return o.typeId &^ wasmhost.OBJTYPE_ARRAY
+}
+
 func (o *ScDict) FindOrMakeObjectId(keyId int32, factory ObjFactory) int32 {
 	objId, ok := o.objects[keyId]
 	if ok {
@@ -158,7 +182,7 @@ func (o *ScDict) GetObjectId(keyId int32, typeId int32) int32 {
 
 func (o *ScDict) GetTypeId(keyId int32) int32 {
 	if (o.typeId & wasmhost.OBJTYPE_ARRAY) != 0 {
-		return o.typeId &^ wasmhost.OBJTYPE_ARRAY
+		return o.elemTypeId()
 	}
 	//TODO incomplete, currently only contains used field types
 	typeId, ok := o.types[keyId]
@@ -215,13 +239,11 @@ func (o *ScDict) SetBytes(keyId int32, typeId int32, bytes []byte) {
 	//}
 
 	if keyId == wasmhost.KeyLength {
-		if o.kvStore != nil {
+		if o.isRoot {
 			//TODO this goes wrong for state, should clear map tree instead
 			o.kvStore = dict.New()
-			//if (o.typeId & wasmhost.OBJTYPE_ARRAY) != 0 {
-			//	key := o.NestedKey()[1:]
-			//	o.kvStore.Del(kv.Key(key))
-			//}
+		} else {
+			o.clear()
 		}
 		o.objects = make(map[int32]int32)
 		o.length = 0
@@ -254,7 +276,7 @@ func (o *ScDict) validate(keyId int32, typeId int32) {
 	}
 	if (o.typeId & wasmhost.OBJTYPE_ARRAY) != 0 {
 		// actually array
-		arrayTypeId := o.typeId &^ wasmhost.OBJTYPE_ARRAY
+		arrayTypeId := o.elemTypeId()
 		if typeId == wasmhost.OBJTYPE_BYTES {
 			switch arrayTypeId {
 			case wasmhost.OBJTYPE_ADDRESS:
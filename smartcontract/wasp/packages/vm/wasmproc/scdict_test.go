@@ -0,0 +1,173 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmproc_test
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/wasmlib"
+	"github.com/stretchr/testify/require"
+)
+
+// the test contract keeps a 2D grid of ints and a map of moves keyed by request ID in its state
+
+const (
+	gridName = "grid"
+
+	keyCol       = wasmlib.Key("col")
+	keyGrid      = wasmlib.Key("grid")
+	keyRowLength = wasmlib.Key("rowLength")
+	keyMoves     = wasmlib.Key("moves")
+	keyRequestId = wasmlib.Key("requestId")
+	keyRow       = wasmlib.Key("row")
+	keySize      = wasmlib.Key("size")
+	keyValue     = wasmlib.Key("value")
+)
+
+func gridOnLoad() {
+	exports := wasmlib.NewScExports()
+	exports.AddFunc("init", gridInit)
+	exports.AddFunc("clearRow", gridClearRow)
+	exports.AddFunc("move", gridMove)
+	exports.AddView("getCell", gridGetCell)
+	exports.AddView("getMove", gridGetMove)
+}
+
+func gridInit(ctx *wasmlib.ScFuncContext) {
+	size := int32(ctx.Params().GetInt(keySize).Value())
+	grid := ctx.State().GetIntArrayArray(keyGrid)
+	for r := int32(0); r < size; r++ {
+		row := grid.GetIntArray(r)
+		for c := int32(0); c < size; c++ {
+			row.GetInt(c).SetValue(0)
+		}
+	}
+}
+
+func gridClearRow(ctx *wasmlib.ScFuncContext) {
+	row := int32(ctx.Params().GetInt(keyRow).Value())
+	ctx.State().GetIntArrayArray(keyGrid).GetIntArray(row).Clear()
+}
+
+func gridMove(ctx *wasmlib.ScFuncContext) {
+	p := ctx.Params()
+	row := p.GetInt(keyRow).Value()
+	col := p.GetInt(keyCol).Value()
+	grid := ctx.State().GetIntArrayArray(keyGrid)
+	ctx.Require(row < int64(grid.Length()), "invalid row")
+	cells := grid.GetIntArray(int32(row))
+	ctx.Require(col < int64(cells.Length()), "invalid col")
+	cells.GetInt(int32(col)).SetValue(p.GetInt(keyValue).Value())
+
+	move := ctx.State().GetMap(keyMoves).GetMap(ctx.RequestId())
+	move.GetInt(keyRow).SetValue(row)
+	move.GetInt(keyCol).SetValue(col)
+	ctx.Results().GetBytes(keyRequestId).SetValue(ctx.RequestId().Bytes())
+}
+
+func gridGetCell(ctx *wasmlib.ScViewContext) {
+	p := ctx.Params()
+	grid := ctx.State().GetIntArrayArray(keyGrid)
+	row := grid.GetIntArray(int32(p.GetInt(keyRow).Value()))
+	ctx.Results().GetInt(keyRowLength).SetValue(int64(row.Length()))
+	col := int32(p.GetInt(keyCol).Value())
+	if col < row.Length() {
+		ctx.Results().GetInt(keyValue).SetValue(row.GetInt(col).Value())
+	}
+}
+
+func gridGetMove(ctx *wasmlib.ScViewContext) {
+	requestId := wasmlib.NewScRequestIdFromBytes(ctx.Params().GetBytes(keyRequestId).Value())
+	move := ctx.State().GetMap(keyMoves).GetMap(requestId)
+	ctx.Results().GetInt(keyRow).SetValue(move.GetInt(keyRow).Value())
+	ctx.Results().GetInt(keyCol).SetValue(move.GetInt(keyCol).Value())
+}
+
+func setupGrid(t *testing.T, size int) *solo.Chain {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	err := chain.DeployGoContract(nil, gridName, gridOnLoad, string(keySize), size)
+	require.NoError(t, err)
+	return chain
+}
+
+func getInt(t *testing.T, res dict.Dict, key wasmlib.Key) int64 {
+	v, _, err := codec.DecodeInt64(res.MustGet(kv.Key(key)))
+	require.NoError(t, err)
+	return v
+}
+
+func getCell(t *testing.T, chain *solo.Chain, row, col int) (int64, int64) {
+	res, err := chain.CallView(gridName, "getCell", string(keyRow), row, string(keyCol), col)
+	require.NoError(t, err)
+	return getInt(t, res, keyValue), getInt(t, res, keyRowLength)
+}
+
+func TestArrayOfArrays(t *testing.T) {
+	chain := setupGrid(t, 12)
+
+	_, err := chain.PostRequest(solo.NewCallParams(gridName, "move",
+		string(keyRow), 11, string(keyCol), 3, string(keyValue), 42), nil)
+	require.NoError(t, err)
+
+	value, length := getCell(t, chain, 11, 3)
+	require.EqualValues(t, 42, value)
+	require.EqualValues(t, 12, length)
+	value, _ = getCell(t, chain, 11, 2)
+	require.EqualValues(t, 0, value)
+	value, _ = getCell(t, chain, 3, 11)
+	require.EqualValues(t, 0, value)
+
+	_, err = chain.PostRequest(solo.NewCallParams(gridName, "move",
+		string(keyRow), 12, string(keyCol), 0, string(keyValue), 1), nil)
+	require.Error(t, err)
+}
+
+func TestClearNestedArray(t *testing.T) {
+	chain := setupGrid(t, 3)
+
+	for row := 0; row < 3; row++ {
+		_, err := chain.PostRequest(solo.NewCallParams(gridName, "move",
+			string(keyRow), row, string(keyCol), 1, string(keyValue), row+1), nil)
+		require.NoError(t, err)
+	}
+	_, err := chain.PostRequest(solo.NewCallParams(gridName, "clearRow", string(keyRow), 1), nil)
+	require.NoError(t, err)
+
+	// the cleared row is empty, the other rows are kept
+	_, length := getCell(t, chain, 1, 0)
+	require.EqualValues(t, 0, length)
+	value, length := getCell(t, chain, 0, 1)
+	require.EqualValues(t, 1, value)
+	require.EqualValues(t, 3, length)
+	value, _ = getCell(t, chain, 2, 1)
+	require.EqualValues(t, 3, value)
+}
+
+func TestMapOfMaps(t *testing.T) {
+	chain := setupGrid(t, 4)
+
+	res1, err := chain.PostRequest(solo.NewCallParams(gridName, "move",
+		string(keyRow), 1, string(keyCol), 2, string(keyValue), 7), nil)
+	require.NoError(t, err)
+	res2, err := chain.PostRequest(solo.NewCallParams(gridName, "move",
+		string(keyRow), 3, string(keyCol), 0, string(keyValue), 8), nil)
+	require.NoError(t, err)
+
+	for _, m := range []struct {
+		res      dict.Dict
+		row, col int64
+	}{{res1, 1, 2}, {res2, 3, 0}} {
+		reqID := m.res.MustGet(kv.Key(keyRequestId))
+		require.Len(t, reqID, 34)
+		res, err := chain.CallView(gridName, "getMove", string(keyRequestId), reqID)
+		require.NoError(t, err)
+		require.EqualValues(t, m.row, getInt(t, res, keyRow))
+		require.EqualValues(t, m.col, getInt(t, res, keyCol))
+	}
+}
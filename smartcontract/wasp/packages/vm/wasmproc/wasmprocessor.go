@@ -8,6 +8,7 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm"
 	"github.com/iotaledger/wasp/packages/vm/wasmhost"
 )
 
@@ -63,8 +64,12 @@ func (host *wasmProcessor) call(ctx coretypes.Sandbox, ctxView coretypes.Sandbox
 	host.ctx = ctx
 	host.ctxView = ctxView
 	host.nesting++
+	saveGas := host.SetGasMeter(host.gasMeter())
+	saveTracer := host.SetHostTracer(host.hostTracer())
 
 	defer func() {
+		host.SetHostTracer(saveTracer)
+		host.SetGasMeter(saveGas)
 		host.nesting--
 		if host.nesting == 0 {
 			host.Trace("Finalizing calls")
@@ -132,6 +137,27 @@ func (host *wasmProcessor) IsView() bool {
 	return host.WasmHost.IsView(host.function)
 }
 
+func (host *wasmProcessor) blockIndex() uint32 {
+	if host.ctx != nil {
+		return host.ctx.BlockIndex()
+	}
+	return host.ctxView.BlockIndex()
+}
+
+func (host *wasmProcessor) caller() coretypes.AgentID {
+	if host.ctx != nil {
+		return host.ctx.Caller()
+	}
+	return host.ctxView.Caller()
+}
+
+func (host *wasmProcessor) chainID() coretypes.ChainID {
+	if host.ctx != nil {
+		return host.ctx.ChainID()
+	}
+	return host.ctxView.ChainID()
+}
+
 func (host *wasmProcessor) chainOwnerID() coretypes.AgentID {
 	if host.ctx != nil {
 		return host.ctx.ChainOwnerID()
@@ -153,6 +179,29 @@ func (host *wasmProcessor) contractID() coretypes.ContractID {
 	return host.ctxView.ContractID()
 }
 
+func (host *wasmProcessor) gasMeter() wasmhost.GasMeter {
+	if host.ctx != nil {
+		return host.ctx
+	}
+	return host.ctxView
+}
+
+// hostTracerProvider is implemented by the sandboxes which can trace host calls, see vm.RequestTrace
+type hostTracerProvider interface {
+	HostTracer() vm.HostTracer
+}
+
+func (host *wasmProcessor) hostTracer() vm.HostTracer {
+	var ctx interface{} = host.ctxView
+	if host.ctx != nil {
+		ctx = host.ctx
+	}
+	if provider, ok := ctx.(hostTracerProvider); ok {
+		return provider.HostTracer()
+	}
+	return nil
+}
+
 func (host *wasmProcessor) log() coretypes.LogInterface {
 	if host.ctx != nil {
 		return host.ctx.Log()
@@ -174,6 +223,13 @@ func (host *wasmProcessor) state() kv.KVStore {
 	return NewScViewState(host.ctxView)
 }
 
+func (host *wasmProcessor) timestamp() int64 {
+	if host.ctx != nil {
+		return host.ctx.GetTimestamp()
+	}
+	return host.ctxView.GetTimestamp()
+}
+
 func (host *wasmProcessor) utils() coretypes.Utils {
 	if host.ctx != nil {
 		return host.ctx.Utils()
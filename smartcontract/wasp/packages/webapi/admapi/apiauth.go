@@ -0,0 +1,142 @@
+package admapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	registry_pkg "github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/util/auth"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/registry"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addAPIAuthEndpoints(adm echoswagger.ApiGroup) {
+	exampleToken := model.APIToken{
+		ID:          "3nDdt7bDGE5",
+		Role:        auth.RoleClient.String(),
+		Description: "dApp backend",
+	}
+	exampleKey := model.APIKey{
+		Address:     "6Fsh5Mhz3zJbzR8Dyr3SPNTJgU5UyHRqWxCmBz4LgsvC",
+		Role:        auth.RoleAdmin.String(),
+		Description: "operator",
+	}
+
+	adm.POST(routes.PostAPIToken(), handlePostAPIToken).
+		SetSummary("Create a new API token").
+		AddParamBody(model.APITokenRequest{Role: "client", Description: "dApp backend"}, "APITokenRequest", "Request parameters", true).
+		AddResponse(http.StatusCreated, "API token, including the token itself", exampleToken, nil)
+
+	adm.GET(routes.ListAPITokens(), handleListAPITokens).
+		SetSummary("Get the list of API tokens").
+		AddResponse(http.StatusOK, "API tokens", []model.APIToken{exampleToken}, nil)
+
+	adm.DELETE(routes.DeleteAPIToken(":id"), handleDeleteAPIToken).
+		SetSummary("Revoke the API token").
+		AddParamPath("", "id", "Token ID")
+
+	adm.POST(routes.PutAPIKey(), handlePutAPIKey).
+		SetSummary("Register the ed25519 key allowed to sign requests").
+		AddParamBody(exampleKey, "APIKey", "API key", true)
+
+	adm.GET(routes.ListAPIKeys(), handleListAPIKeys).
+		SetSummary("Get the list of registered API keys").
+		AddResponse(http.StatusOK, "API keys", []model.APIKey{exampleKey}, nil)
+
+	adm.DELETE(routes.DeleteAPIKey(":address"), handleDeleteAPIKey).
+		SetSummary("Revoke the API key").
+		AddParamPath("", "address", "Address of the key (base58)")
+}
+
+func handlePostAPIToken(c echo.Context) error {
+	var req model.APITokenRequest
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	role, err := auth.RoleFromString(req.Role)
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	rec, token, err := registry.DefaultRegistry().NewAPIToken(role, req.Description)
+	if err != nil {
+		return err
+	}
+	ret := model.NewAPIToken(rec)
+	ret.Token = token
+	return c.JSON(http.StatusCreated, ret)
+}
+
+func handleListAPITokens(c echo.Context) error {
+	lst, err := registry.DefaultRegistry().GetAPITokens()
+	if err != nil {
+		return err
+	}
+	ret := make([]*model.APIToken, len(lst))
+	for i := range ret {
+		ret[i] = model.NewAPIToken(lst[i])
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+func handleDeleteAPIToken(c echo.Context) error {
+	ok, err := registry.DefaultRegistry().DeleteAPIToken(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return httperrors.NotFound(fmt.Sprintf("API token not found: %s", c.Param("id")))
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func handlePutAPIKey(c echo.Context) error {
+	var req model.APIKey
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	role, err := auth.RoleFromString(req.Role)
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	err = registry.DefaultRegistry().SaveAPIKey(&registry_pkg.APIKey{
+		Address:     req.Address.Address(),
+		Role:        role,
+		Description: req.Description,
+	})
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+func handleListAPIKeys(c echo.Context) error {
+	lst, err := registry.DefaultRegistry().GetAPIKeys()
+	if err != nil {
+		return err
+	}
+	ret := make([]*model.APIKey, len(lst))
+	for i := range ret {
+		ret[i] = model.NewAPIKey(lst[i])
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+func handleDeleteAPIKey(c echo.Context) error {
+	addr, err := address.FromBase58(c.Param("address"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid address: %s", c.Param("address")))
+	}
+	ok, err := registry.DefaultRegistry().DeleteAPIKey(&addr)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return httperrors.NotFound(fmt.Sprintf("API key not found: %s", addr.String()))
+	}
+	return c.NoContent(http.StatusOK)
+}
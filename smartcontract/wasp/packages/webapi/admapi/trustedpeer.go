@@ -0,0 +1,79 @@
+package admapi
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/dkg"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/iotaledger/wasp/plugins/registry"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+	"go.dedis.ch/kyber/v3"
+)
+
+// peerTruster is implemented by the network providers which check the keys of the peers (see peering/tcp).
+// Other providers don't keep trusted keys, the records are only saved in the registry for them
+type peerTruster interface {
+	TrustPeer(peerNetID string, peerPub kyber.Point) error
+	AllowKeyRotation(peerNetID string) error
+}
+
+func addTrustedPeerEndpoints(adm echoswagger.ApiGroup) {
+	example := model.TrustedPeer{
+		NetID:       "wasp1:4000",
+		PubKey:      base64.StdEncoding.EncodeToString([]byte("key")),
+		KeyRotation: false,
+	}
+
+	adm.POST(routes.PutTrustedPeer(), handlePutTrustedPeer).
+		SetSummary("Trust the public key of the committee peer").
+		AddParamBody(example, "TrustedPeer", "Trusted peer", true)
+
+	adm.GET(routes.ListTrustedPeers(), handleListTrustedPeers).
+		SetSummary("Get the list of trusted peers").
+		AddResponse(http.StatusOK, "Trusted peers", []model.TrustedPeer{example}, nil)
+}
+
+func handlePutTrustedPeer(c echo.Context) error {
+	var req model.TrustedPeer
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	tp, err := req.TrustedPeer(dkg.DefaultNode().GroupSuite())
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid public key of peer %s: %v", req.NetID, err))
+	}
+	if truster, ok := peering.DefaultNetworkProvider().(peerTruster); ok {
+		if err := truster.TrustPeer(tp.NetID, tp.PubKey); err != nil {
+			return err
+		}
+		if tp.KeyRotation {
+			if err := truster.AllowKeyRotation(tp.NetID); err != nil {
+				return err
+			}
+		}
+	} else if err := registry.DefaultRegistry().SaveTrustedPeer(tp); err != nil {
+		return err
+	}
+	log.Infof("trusted key of peer %s saved", tp.NetID)
+	return c.NoContent(http.StatusCreated)
+}
+
+func handleListTrustedPeers(c echo.Context) error {
+	lst, err := registry.DefaultRegistry().GetTrustedPeers()
+	if err != nil {
+		return err
+	}
+	ret := make([]*model.TrustedPeer, len(lst))
+	for i := range ret {
+		if ret[i], err = model.NewTrustedPeer(lst[i]); err != nil {
+			return err
+		}
+	}
+	return c.JSON(http.StatusOK, ret)
+}
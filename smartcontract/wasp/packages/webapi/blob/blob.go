@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/util/auth"
 	"github.com/iotaledger/wasp/packages/webapi/httperrors"
 	"github.com/iotaledger/wasp/packages/webapi/model"
 	"github.com/iotaledger/wasp/packages/webapi/routes"
@@ -16,7 +17,7 @@ import (
 func AddEndpoints(server echoswagger.ApiRouter) {
 	example := model.NewBlobInfo(true, hashing.RandomHash(nil))
 
-	server.GET(routes.PutBlob(), handlePutBlob).
+	server.GET(routes.PutBlob(), handlePutBlob, auth.RequireRole(auth.RoleClient)).
 		SetSummary("Upload a blob to the registry").
 		AddResponse(http.StatusOK, "Blob properties", example, nil)
 
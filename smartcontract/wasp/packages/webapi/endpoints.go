@@ -1,11 +1,10 @@
 package webapi
 
 import (
-	"net"
-
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/webapi/admapi"
 	"github.com/iotaledger/wasp/packages/webapi/blob"
+	"github.com/iotaledger/wasp/packages/webapi/events"
 	"github.com/iotaledger/wasp/packages/webapi/info"
 	"github.com/iotaledger/wasp/packages/webapi/request"
 	"github.com/iotaledger/wasp/packages/webapi/state"
@@ -14,7 +13,7 @@ import (
 
 var log *logger.Logger
 
-func Init(server echoswagger.ApiRoot, adminWhitelist []net.IP) {
+func Init(server echoswagger.ApiRoot) {
 	log = logger.NewLogger("WebAPI")
 
 	server.SetRequestContentType("application/json")
@@ -25,8 +24,9 @@ func Init(server echoswagger.ApiRoot, adminWhitelist []net.IP) {
 	info.AddEndpoints(pub)
 	request.AddEndpoints(pub)
 	state.AddEndpoints(pub)
+	events.AddEndpoints(pub)
 
 	adm := server.Group("admin", "").SetDescription("Admin endpoints")
-	admapi.AddEndpoints(adm, adminWhitelist)
+	admapi.AddEndpoints(adm)
 	log.Infof("added web api endpoints")
 }
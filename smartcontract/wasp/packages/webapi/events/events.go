@@ -0,0 +1,339 @@
+// Package events streams the events of the chain published by the node as Server-Sent Events.
+// Each event is a JSON-encoded model.ChainEvent. Events of the 'state' type carry the block index as
+// the event ID, so a client reconnecting with the Last-Event-ID header resumes from the next block.
+// When the 'state' event is filtered out by the type or by the contract, its block index is still sent
+// as an event with the ID only, so clients with any filter can resume
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/publisher"
+	"github.com/iotaledger/wasp/packages/util/auth"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+const (
+	// number of live events buffered for the slow client. The stream is closed when the buffer overflows,
+	// the client is expected to reconnect and resume
+	eventBufferSize   = 1000
+	keepAlivePeriod   = 15 * time.Second
+	headerLastEventID = "Last-Event-ID"
+)
+
+func AddEndpoints(server echoswagger.ApiRouter) {
+	server.GET(routes.ChainEvents(":chainID"), handleChainEvents, auth.RequireRole(auth.RoleClient)).
+		SetSummary("Stream events of the chain (Server-Sent Events)").
+		SetDescription("Each event is a JSON-encoded ChainEvent. The stream resumes from the block index given by "+
+			"'fromBlock' or from the block after the one in the Last-Event-ID header").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamQuery("", "contract", "Hname of the contract (hex). Only events of the contract are streamed", false).
+		AddParamQuery("", "type", "Comma separated types of events: request_in, request_out, state, vmmsg", false).
+		AddParamQuery(uint32(0), "fromBlock", "Block index to resume from. Events of stored blocks are replayed first", false).
+		AddResponse(http.StatusOK, "Stream of events", model.ChainEvent{}, nil)
+}
+
+func handleChainEvents(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+	}
+	if chains.GetChain(chainID) == nil {
+		return httperrors.NotFound(fmt.Sprintf("Chain not found: %s", chainID))
+	}
+	flt, err := parseFilter(&chainID, c)
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("Connection", "keep-alive")
+	resp.WriteHeader(http.StatusOK)
+	resp.Flush()
+
+	// live events are collected before the replay so nothing is lost between the replay and the live stream
+	live := make(chan *model.ChainEvent, eventBufferSize)
+	overflow := make(chan struct{})
+	var overflowOnce sync.Once
+	closure := events.NewClosure(func(msg *publisher.Message) {
+		ev, ok := chainEvent(msg)
+		if !ok || !flt.tracks(ev) {
+			return
+		}
+		select {
+		case live <- ev:
+		default:
+			overflowOnce.Do(func() { close(overflow) })
+		}
+	})
+	publisher.MessageEvent.Attach(closure)
+	defer publisher.MessageEvent.Detach(closure)
+
+	var lastReplayed *uint32
+	var replayedVM map[string]bool
+	if flt.fromBlock != nil {
+		lastReplayed, replayedVM, err = replay(&chainID, flt, func(ev *model.ChainEvent) error {
+			return writeEvent(resp, ev)
+		}, func(blockIndex uint32) error {
+			return writeEventID(resp, blockIndex)
+		})
+		if err != nil {
+			return writeError(resp, err)
+		}
+	}
+	var blocks *contractBlocks
+	if flt.contract != nil {
+		blocks = &contractBlocks{chainID: &chainID, contract: *flt.contract}
+	}
+
+	keepAlive := time.NewTicker(keepAlivePeriod)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case ev := <-live:
+			if alreadyReplayed(ev, lastReplayed, replayedVM) {
+				continue
+			}
+			err := sendLive(ev, flt, blocks, func(ev *model.ChainEvent) error {
+				return writeEvent(resp, ev)
+			}, func(blockIndex uint32) error {
+				return writeEventID(resp, blockIndex)
+			})
+			if err != nil {
+				return nil
+			}
+		case <-overflow:
+			return writeError(resp, fmt.Errorf("too many events, reconnect to resume"))
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(resp, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			resp.Flush()
+		case <-c.Request().Context().Done():
+			return nil
+		}
+	}
+}
+
+// alreadyReplayed checks if the live event was sent by the replay. Messages of the VM are published
+// while the request is processed, before its block is stored, so they are recognized by the request ID
+func alreadyReplayed(ev *model.ChainEvent, lastReplayed *uint32, replayedVM map[string]bool) bool {
+	if lastReplayed == nil {
+		return false
+	}
+	switch ev.Type {
+	case "request_in":
+		return false
+	case "vmmsg":
+		return ev.RequestID != "" && replayedVM[ev.RequestID]
+	}
+	return ev.BlockIndex <= *lastReplayed
+}
+
+// sendLive sends the live event if it passes the filter. The 'state' event filtered out by the type or
+// by the contract is sent as the event ID only
+func sendLive(ev *model.ChainEvent, flt *filter, blocks *contractBlocks, send func(ev *model.ChainEvent) error, sendID func(blockIndex uint32) error) error {
+	pass := flt.matches(ev)
+	if pass && blocks != nil && (ev.Type == "state" || ev.Type == "request_out") {
+		pass = blocks.contains(ev)
+	}
+	switch {
+	case pass:
+		return send(ev)
+	case ev.Type == "state":
+		return sendID(ev.BlockIndex)
+	}
+	return nil
+}
+
+// contractBlocks checks live 'state' and 'request_out' events against the stored block when the stream
+// is filtered by the contract. Events of the last checked block are cached
+type contractBlocks struct {
+	chainID  *coretypes.ChainID
+	contract coretypes.Hname
+	index    uint32
+	events   []*model.ChainEvent
+}
+
+// contains checks if the block of the event has the same event for the contract. Events of blocks
+// which can't be loaded are dropped
+func (cb *contractBlocks) contains(ev *model.ChainEvent) bool {
+	if cb.events == nil || cb.index != ev.BlockIndex {
+		evs, err := contractBlock(cb.chainID, ev.BlockIndex, cb.contract)
+		if err != nil {
+			return false
+		}
+		cb.index, cb.events = ev.BlockIndex, evs
+	}
+	for _, e := range cb.events {
+		if e.Type == ev.Type && e.RequestID == ev.RequestID {
+			return true
+		}
+	}
+	return false
+}
+
+func writeEvent(resp *echo.Response, ev *model.ChainEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if ev.Type == "state" {
+		if _, err := fmt.Fprintf(resp, "id: %d\n", ev.BlockIndex); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(resp, "event: %s\ndata: %s\n\n", ev.Type, data); err != nil {
+		return err
+	}
+	resp.Flush()
+	return nil
+}
+
+// writeEventID sends the event with the ID only. It doesn't dispatch the event on the client,
+// but updates its last event ID
+func writeEventID(resp *echo.Response, blockIndex uint32) error {
+	if _, err := fmt.Fprintf(resp, "id: %d\n\n", blockIndex); err != nil {
+		return err
+	}
+	resp.Flush()
+	return nil
+}
+
+// writeError sends the 'error' event and closes the stream
+func writeError(resp *echo.Response, err error) error {
+	data, _ := json.Marshal(err.Error())
+	fmt.Fprintf(resp, "event: error\ndata: %s\n\n", data)
+	resp.Flush()
+	return nil
+}
+
+type filter struct {
+	chainID   *coretypes.ChainID
+	contract  *coretypes.Hname
+	types     map[string]bool
+	fromBlock *uint32
+}
+
+func parseFilter(chainID *coretypes.ChainID, c echo.Context) (*filter, error) {
+	ret := &filter{chainID: chainID}
+	if s := c.QueryParam("contract"); s != "" {
+		hname, err := coretypes.HnameFromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid contract hname: %s", s)
+		}
+		ret.contract = &hname
+	}
+	if s := c.QueryParam("type"); s != "" {
+		ret.types = make(map[string]bool)
+		for _, t := range strings.Split(s, ",") {
+			ret.types[strings.TrimSpace(t)] = true
+		}
+	}
+	fromBlock := c.QueryParam("fromBlock")
+	if fromBlock == "" {
+		if lastID := c.Request().Header.Get(headerLastEventID); lastID != "" {
+			n, err := strconv.ParseUint(lastID, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %s", headerLastEventID, lastID)
+			}
+			fromBlock = strconv.FormatUint(n+1, 10)
+		}
+	}
+	if fromBlock != "" {
+		n, err := strconv.ParseUint(fromBlock, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block index: %s", fromBlock)
+		}
+		idx := uint32(n)
+		ret.fromBlock = &idx
+	}
+	return ret, nil
+}
+
+// matches checks the chain, the type and the contract. 'vmmsg' and 'request_in' events carry the contract,
+// 'state' and 'request_out' events are checked against the stored block (see contractBlock)
+func (f *filter) matches(ev *model.ChainEvent) bool {
+	if ev.ChainID != model.NewChainID(f.chainID) {
+		return false
+	}
+	if f.types != nil && !f.types[ev.Type] {
+		return false
+	}
+	if f.contract != nil && ev.Contract != "" && ev.Contract != f.contract.String() {
+		return false
+	}
+	return true
+}
+
+// tracks checks if the live event is needed by the stream: it matches the filter, or it is the 'state' event
+// of the chain, which is sent at least as the event ID
+func (f *filter) tracks(ev *model.ChainEvent) bool {
+	if ev.Type == "state" {
+		return ev.ChainID == model.NewChainID(f.chainID)
+	}
+	return f.matches(ev)
+}
+
+// chainEvent converts the message of the publisher into the event. Other types of messages are ignored
+func chainEvent(msg *publisher.Message) (*model.ChainEvent, bool) {
+	ret := &model.ChainEvent{
+		Type:      msg.Type,
+		ChainID:   model.ChainID(msg.ChainID),
+		Contract:  msg.Contract,
+		RequestID: msg.RequestID,
+	}
+	if msg.BlockIndex != nil {
+		ret.BlockIndex = *msg.BlockIndex
+	}
+	switch msg.Type {
+	case publisher.MsgRequestIn:
+	case publisher.MsgRequestOut:
+		reqIndex, err := msg.Uint(publisher.PayloadRequestIndex)
+		if err != nil {
+			return nil, false
+		}
+		blockSize, err := msg.Uint(publisher.PayloadBlockSize)
+		if err != nil {
+			return nil, false
+		}
+		ret.RequestIndex = uint16(reqIndex)
+		ret.BlockSize = uint16(blockSize)
+
+	case publisher.MsgState:
+		blockSize, err := msg.Uint(publisher.PayloadBlockSize)
+		if err != nil {
+			return nil, false
+		}
+		ts, err := strconv.ParseInt(msg.Payload[publisher.PayloadTimestamp], 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		ret.BlockSize = uint16(blockSize)
+		ret.StateTxID = msg.Payload[publisher.PayloadStateTxID]
+		ret.StateHash = msg.Payload[publisher.PayloadStateHash]
+		ret.Timestamp = ts
+
+	case publisher.MsgVM:
+		ret.Message = msg.Payload[publisher.PayloadMessage]
+
+	default:
+		return nil, false
+	}
+	return ret, true
+}
@@ -0,0 +1,187 @@
+package events
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/publisher"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainEvent(t *testing.T) {
+	chainID := coretypes.ChainID{1, 2, 3}
+	txid := valuetransaction.ID{4, 5, 6}
+	rid := coretypes.NewRequestID(txid, 2)
+
+	ev, ok := chainEvent(publisher.NewRequestOutMessage(&chainID, &rid, 17, 1, 3))
+	require.True(t, ok)
+	require.EqualValues(t, rid.Base58(), ev.RequestID)
+	require.EqualValues(t, 17, ev.BlockIndex)
+	require.EqualValues(t, 1, ev.RequestIndex)
+	require.EqualValues(t, 3, ev.BlockSize)
+
+	ev, ok = chainEvent(publisher.NewVMMessage(&chainID, coretypes.Hn("game"), &rid, "player moved"))
+	require.True(t, ok)
+	require.EqualValues(t, "player moved", ev.Message)
+	require.EqualValues(t, rid.Base58(), ev.RequestID)
+
+	hash := hashing.HashStrings("state")
+	ev, ok = chainEvent(publisher.NewStateMessage(&chainID, 5, 2, &txid, &hash, 100))
+	require.True(t, ok)
+	require.EqualValues(t, 5, ev.BlockIndex)
+	require.EqualValues(t, 2, ev.BlockSize)
+	require.EqualValues(t, 100, ev.Timestamp)
+	require.EqualValues(t, hash.String(), ev.StateHash)
+
+	_, ok = chainEvent(publisher.NewChainRecordMessage(&chainID, &balance.ColorIOTA))
+	require.False(t, ok)
+}
+
+func TestFilter(t *testing.T) {
+	chainID := coretypes.ChainID{1, 2, 3}
+	game := coretypes.Hn("game")
+	flt := &filter{
+		chainID:  &chainID,
+		contract: &game,
+		types:    map[string]bool{"vmmsg": true, "state": true},
+	}
+	ev, _ := chainEvent(publisher.NewVMMessage(&chainID, game, nil, "moved"))
+	require.True(t, flt.matches(ev))
+	ev, _ = chainEvent(publisher.NewVMMessage(&chainID, coretypes.Hn("other"), nil, "moved"))
+	require.False(t, flt.matches(ev))
+	otherChain := coretypes.ChainID{9}
+	ev, _ = chainEvent(publisher.NewVMMessage(&otherChain, game, nil, "moved"))
+	require.False(t, flt.matches(ev))
+	txid := valuetransaction.ID{4}
+	hash := hashing.HashStrings("state")
+	ev, _ = chainEvent(publisher.NewStateMessage(&chainID, 5, 1, &txid, &hash, 100))
+	require.True(t, flt.matches(ev))
+	rid := coretypes.NewRequestID(txid, 0)
+	ev, _ = chainEvent(publisher.NewRequestInMessage(&chainID, &rid, game))
+	require.False(t, flt.matches(ev))
+	flt.types["request_in"] = true
+	require.True(t, flt.matches(ev))
+	ev, _ = chainEvent(publisher.NewRequestInMessage(&chainID, &rid, coretypes.Hn("other")))
+	require.False(t, flt.matches(ev))
+}
+
+func TestBlockEvents(t *testing.T) {
+	chainID := coretypes.ChainID{1, 2, 3}
+	game := coretypes.Hn("game")
+	other := coretypes.Hn("other")
+	rid1 := coretypes.NewRequestID(valuetransaction.ID{4}, 0)
+	rid2 := coretypes.NewRequestID(valuetransaction.ID{5}, 0)
+	block, err := state.NewBlock([]state.StateUpdate{
+		state.NewStateUpdate(&rid1).WithTimestamp(100),
+		state.NewStateUpdate(&rid2).WithTimestamp(101),
+	})
+	require.NoError(t, err)
+	block.WithBlockIndex(3)
+
+	eventLog := dict.New()
+	eventlog.AppendToLog(eventLog, 100, game, []byte("moved"))
+	eventlog.AppendToLog(eventLog, 100, game, []byte(vmRequestRecordPrefix+rid1.String()+": Ok"))
+	eventlog.AppendToLog(eventLog, 101, other, []byte(vmRequestRecordPrefix+rid2.String()+": Ok"))
+
+	evs := blockEvents(&chainID, block, eventLog, []coretypes.Hname{game, other}, nil)
+	require.Len(t, evs, 4)
+	require.EqualValues(t, "vmmsg", evs[0].Type)
+	require.EqualValues(t, rid1.Base58(), evs[0].RequestID)
+	require.EqualValues(t, 3, evs[0].BlockIndex)
+	require.EqualValues(t, "state", evs[1].Type)
+	require.EqualValues(t, "request_out", evs[2].Type)
+	require.EqualValues(t, "request_out", evs[3].Type)
+
+	evs = blockEvents(&chainID, block, eventLog, []coretypes.Hname{other}, &other)
+	require.Len(t, evs, 2)
+	require.EqualValues(t, "state", evs[0].Type)
+	require.EqualValues(t, "request_out", evs[1].Type)
+	require.EqualValues(t, rid2.Base58(), evs[1].RequestID)
+
+	third := coretypes.Hn("third")
+	evs = blockEvents(&chainID, block, eventLog, []coretypes.Hname{third}, &third)
+	require.Len(t, evs, 0)
+}
+
+func TestAlreadyReplayed(t *testing.T) {
+	last := uint32(5)
+	replayedVM := map[string]bool{"req1": true}
+	require.False(t, alreadyReplayed(&model.ChainEvent{Type: "state", BlockIndex: 5}, nil, nil))
+	require.True(t, alreadyReplayed(&model.ChainEvent{Type: "state", BlockIndex: 5}, &last, replayedVM))
+	require.False(t, alreadyReplayed(&model.ChainEvent{Type: "request_out", BlockIndex: 6}, &last, replayedVM))
+	require.True(t, alreadyReplayed(&model.ChainEvent{Type: "vmmsg", RequestID: "req1"}, &last, replayedVM))
+	require.False(t, alreadyReplayed(&model.ChainEvent{Type: "vmmsg", RequestID: "req2"}, &last, replayedVM))
+	require.False(t, alreadyReplayed(&model.ChainEvent{Type: "vmmsg"}, &last, replayedVM))
+	require.False(t, alreadyReplayed(&model.ChainEvent{Type: "request_in", BlockIndex: 1}, &last, replayedVM))
+}
+
+func TestResumeWithTypeFilter(t *testing.T) {
+	chainID := coretypes.ChainID{1, 2, 3}
+	game := coretypes.Hn("game")
+	flt := &filter{chainID: &chainID, types: map[string]bool{"vmmsg": true}}
+
+	var sent []string
+	send := func(ev *model.ChainEvent) error {
+		sent = append(sent, ev.Type)
+		return nil
+	}
+	sendID := func(blockIndex uint32) error {
+		sent = append(sent, fmt.Sprintf("id:%d", blockIndex))
+		return nil
+	}
+
+	// the 'state' event filtered out by the type is sent as the event ID
+	txid := valuetransaction.ID{4}
+	rid1 := coretypes.NewRequestID(txid, 0)
+	hash := hashing.HashStrings("state")
+	for _, msg := range []*publisher.Message{
+		publisher.NewVMMessage(&chainID, game, &rid1, "moved"),
+		publisher.NewRequestOutMessage(&chainID, &rid1, 3, 0, 1),
+		publisher.NewStateMessage(&chainID, 3, 1, &txid, &hash, 100),
+	} {
+		ev, _ := chainEvent(msg)
+		if !flt.tracks(ev) {
+			continue
+		}
+		require.NoError(t, sendLive(ev, flt, nil, send, sendID))
+	}
+	require.EqualValues(t, []string{"vmmsg", "id:3"}, sent)
+
+	// the client reconnects with the last event ID
+	req := httptest.NewRequest(http.MethodGet, "/?type=vmmsg", nil)
+	req.Header.Set(headerLastEventID, "3")
+	flt, err := parseFilter(&chainID, echo.New().NewContext(req, httptest.NewRecorder()))
+	require.NoError(t, err)
+	require.EqualValues(t, 4, *flt.fromBlock)
+
+	rid2 := coretypes.NewRequestID(valuetransaction.ID{5}, 0)
+	block, err := state.NewBlock([]state.StateUpdate{state.NewStateUpdate(&rid2).WithTimestamp(200)})
+	require.NoError(t, err)
+	block.WithBlockIndex(4)
+	eventLog := dict.New()
+	eventlog.AppendToLog(eventLog, 200, game, []byte("moved again"))
+
+	sent = nil
+	vmRequests := make(map[string]bool)
+	evs := blockEvents(&chainID, block, eventLog, []coretypes.Hname{game}, nil)
+	require.NoError(t, replayBlock(4, evs, flt, vmRequests, send, sendID))
+	require.EqualValues(t, []string{"vmmsg", "id:4"}, sent)
+	require.True(t, vmRequests[rid2.Base58()])
+
+	// with the 'state' type the event ID is sent with the event itself
+	flt.types["state"] = true
+	sent = nil
+	require.NoError(t, replayBlock(4, evs, flt, vmRequests, send, sendID))
+	require.EqualValues(t, []string{"vmmsg", "state"}, sent)
+}
@@ -0,0 +1,209 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// prefix of the records stored in the eventlog by the VM itself. They are not published as 'vmmsg'
+const vmRequestRecordPrefix = "[req] "
+
+// replay reconstructs events of the stored blocks, starting from the block index in the filter up to the
+// solid state. 'vmmsg' events are taken from the eventlog by timestamps of requests in the block.
+// The block index of the 'state' event filtered out is sent with 'sendID'.
+// Returns the index of the last replayed block and the request IDs of the replayed 'vmmsg' events,
+// or nil if there were no blocks to replay
+func replay(chainID *coretypes.ChainID, flt *filter, send func(ev *model.ChainEvent) error, sendID func(blockIndex uint32) error) (*uint32, map[string]bool, error) {
+	solidState, _, ok, err := state.LoadSolidState(chainID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok || *flt.fromBlock > solidState.BlockIndex() {
+		return nil, nil, nil
+	}
+	vars := solidState.Variables()
+	contracts, err := eventContracts(vars, flt)
+	if err != nil {
+		return nil, nil, err
+	}
+	eventLog := subrealm.New(vars, kv.Key(eventlog.Interface.Hname().Bytes()))
+	lastIndex := solidState.BlockIndex()
+	vmRequests := make(map[string]bool)
+	for idx := *flt.fromBlock; idx <= lastIndex; idx++ {
+		block, err := state.LoadBlock(chainID, idx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if block == nil {
+			return nil, nil, fmt.Errorf("block #%d not found", idx)
+		}
+		evs := blockEvents(chainID, block, eventLog, contracts, flt.contract)
+		if err := replayBlock(idx, evs, flt, vmRequests, send, sendID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return &lastIndex, vmRequests, nil
+}
+
+// replayBlock sends the events of the block which match the filter and collects the request IDs of 'vmmsg'
+// events. If the 'state' event of the block is not sent, the block index is sent with 'sendID'
+func replayBlock(blockIndex uint32, evs []*model.ChainEvent, flt *filter, vmRequests map[string]bool, send func(ev *model.ChainEvent) error, sendID func(blockIndex uint32) error) error {
+	stateSent := false
+	for _, ev := range evs {
+		if !flt.matches(ev) {
+			continue
+		}
+		switch ev.Type {
+		case "vmmsg":
+			vmRequests[ev.RequestID] = true
+		case "state":
+			stateSent = true
+		}
+		if err := send(ev); err != nil {
+			return err
+		}
+	}
+	if stateSent {
+		return nil
+	}
+	return sendID(blockIndex)
+}
+
+// blockEvents returns events of the block in the order they are published. If the contract is not nil,
+// only requests sent to the contract are returned and the 'state' event is returned only if the block
+// contains requests or messages of the contract
+func blockEvents(chainID *coretypes.ChainID, block state.Block, eventLog kv.KVStore, contracts []coretypes.Hname, contract *coretypes.Hname) []*model.ChainEvent {
+	ret := make([]*model.ChainEvent, 0)
+	targeted := make(map[coretypes.RequestID]bool)
+	block.ForEach(func(_ uint16, stateUpd state.StateUpdate) bool {
+		ts := stateUpd.Timestamp()
+		if ts == 0 {
+			return true
+		}
+		reqID := stateUpd.RequestID()
+		reqRecord := vmRequestRecordPrefix + reqID.String() + ":"
+		for _, hname := range contracts {
+			for _, rec := range contractRecords(eventLog, hname, ts) {
+				if strings.HasPrefix(string(rec.Data), vmRequestRecordPrefix) {
+					if strings.HasPrefix(string(rec.Data), reqRecord) {
+						targeted[*reqID] = true
+					}
+					continue
+				}
+				ret = append(ret, &model.ChainEvent{
+					Type:       "vmmsg",
+					ChainID:    model.NewChainID(chainID),
+					Contract:   hname.String(),
+					RequestID:  reqID.Base58(),
+					BlockIndex: block.StateIndex(),
+					Timestamp:  rec.Timestamp,
+					Message:    string(rec.Data),
+					Replayed:   true,
+				})
+			}
+		}
+		return true
+	})
+	if contract != nil && len(ret) == 0 && len(targeted) == 0 {
+		return ret
+	}
+	ret = append(ret, &model.ChainEvent{
+		Type:       "state",
+		ChainID:    model.NewChainID(chainID),
+		BlockIndex: block.StateIndex(),
+		BlockSize:  block.Size(),
+		StateTxID:  block.StateTransactionID().String(),
+		Timestamp:  block.Timestamp(),
+		Replayed:   true,
+	})
+	for i, reqid := range block.RequestIDs() {
+		if contract != nil && !targeted[*reqid] {
+			continue
+		}
+		ret = append(ret, &model.ChainEvent{
+			Type:         "request_out",
+			ChainID:      model.NewChainID(chainID),
+			RequestID:    reqid.Base58(),
+			BlockIndex:   block.StateIndex(),
+			RequestIndex: uint16(i),
+			BlockSize:    block.Size(),
+			Replayed:     true,
+		})
+	}
+	return ret
+}
+
+// contractRecords returns records of the contract in the eventlog with the timestamp
+func contractRecords(eventLog kv.KVStore, contract coretypes.Hname, ts int64) []*collections.TimestampedLogRecord {
+	tlog := collections.NewTimestampedLogReadOnly(eventLog, kv.Key(contract.Bytes()))
+	tsl, err := tlog.TakeTimeSlice(ts, ts)
+	if err != nil || tsl.IsEmpty() {
+		return nil
+	}
+	first, last := tsl.FromToIndices()
+	recs, err := tlog.LoadRecordsRaw(first, last, false)
+	if err != nil {
+		return nil
+	}
+	ret := make([]*collections.TimestampedLogRecord, 0, len(recs))
+	for _, raw := range recs {
+		rec, err := collections.ParseRawLogRecord(raw)
+		if err != nil {
+			continue
+		}
+		ret = append(ret, rec)
+	}
+	return ret
+}
+
+// contractBlock returns the events of the stored block relevant to the contract. It is used to check
+// live 'state' and 'request_out' events, which don't carry the contract
+func contractBlock(chainID *coretypes.ChainID, blockIndex uint32, contract coretypes.Hname) ([]*model.ChainEvent, error) {
+	solidState, _, ok, err := state.LoadSolidState(chainID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || blockIndex > solidState.BlockIndex() {
+		return nil, fmt.Errorf("block #%d is not solid", blockIndex)
+	}
+	block, err := state.LoadBlock(chainID, blockIndex)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockIndex)
+	}
+	eventLog := subrealm.New(solidState.Variables(), kv.Key(eventlog.Interface.Hname().Bytes()))
+	return blockEvents(chainID, block, eventLog, []coretypes.Hname{contract}, &contract), nil
+}
+
+// eventContracts returns the contract of the filter or all contracts deployed on the chain
+func eventContracts(vars kv.KVStore, flt *filter) ([]coretypes.Hname, error) {
+	if flt.contract != nil {
+		return []coretypes.Hname{*flt.contract}, nil
+	}
+	if flt.types != nil && !flt.types["vmmsg"] {
+		return nil, nil
+	}
+	registry, err := root.DecodeContractRegistry(collections.NewMapReadOnly(
+		subrealm.New(vars, kv.Key(root.Interface.Hname().Bytes())), root.VarContractRegistry))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]coretypes.Hname, 0, len(registry))
+	for hname := range registry {
+		ret = append(ret, hname)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret, nil
+}
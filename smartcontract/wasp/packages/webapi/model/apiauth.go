@@ -0,0 +1,42 @@
+package model
+
+import (
+	"github.com/iotaledger/wasp/packages/registry"
+)
+
+// APITokenRequest is a POST request for creating new API token.
+type APITokenRequest struct {
+	Role        string `json:"role" swagger:"desc(Role of the token: public, client or admin)"`
+	Description string `json:"description"`
+}
+
+// APIToken is the API token record. The token itself is returned only once, when the token is created.
+type APIToken struct {
+	ID          string `json:"id"`
+	Role        string `json:"role"`
+	Description string `json:"description"`
+	Token       string `json:"token,omitempty" swagger:"desc(The token, to be used in the 'Authorization: Bearer <token>' header)"`
+}
+
+// APIKey is the record of the ed25519 key which may sign requests.
+type APIKey struct {
+	Address     Address `json:"address" swagger:"desc(Address of the key (base58-encoded))"`
+	Role        string  `json:"role" swagger:"desc(Role of the key: public, client or admin)"`
+	Description string  `json:"description"`
+}
+
+func NewAPIToken(rec *registry.APIToken) *APIToken {
+	return &APIToken{
+		ID:          rec.ID,
+		Role:        rec.Role.String(),
+		Description: rec.Description,
+	}
+}
+
+func NewAPIKey(rec *registry.APIKey) *APIKey {
+	return &APIKey{
+		Address:     NewAddress(&rec.Address),
+		Role:        rec.Role.String(),
+		Description: rec.Description,
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+)
+
+// ViewCall is a call of the view function in the batch
+type ViewCall struct {
+	Contract coretypes.Hname `swagger:"desc(Hname of the contract)"`
+	Function string          `swagger:"desc(Name of the view function)"`
+	Params   dict.Dict       `swagger:"desc(Parameters of the call)"`
+}
+
+// CallViewsRequest is the batch of view calls executed against the same state of the chain
+type CallViewsRequest struct {
+	StateIndex *uint32    `swagger:"desc(If set, the calls fail with 409 unless the state index of the chain is the given one)"`
+	Calls      []ViewCall `swagger:"desc(View calls)"`
+}
+
+// CallViewsResponse contains results of all calls of the batch, in the order of the calls
+type CallViewsResponse struct {
+	StateIndex uint32      `swagger:"desc(Index of the block the calls were executed against)"`
+	Results    []dict.Dict `swagger:"desc(Results of the calls)"`
+}
+
+func NewViewCall(contract coretypes.Hname, function string, params dict.Dict) ViewCall {
+	return ViewCall{
+		Contract: contract,
+		Function: function,
+		Params:   params,
+	}
+}
@@ -0,0 +1,18 @@
+package model
+
+// ChainEvent is the message published by the node (see publisher.Message) in the form streamed by the webapi.
+// Fields not related to the type of the event are empty
+type ChainEvent struct {
+	Type         string  `swagger:"desc(Type of the event: request_in, request_out, state or vmmsg)"`
+	ChainID      ChainID `swagger:"desc(ChainID (base58))"`
+	Contract     string  `swagger:"desc(Hname of the contract which emitted the message (vmmsg) or the target of the request (request_in))"`
+	RequestID    string  `swagger:"desc(Request ID (base58) (request_in, request_out, vmmsg))"`
+	BlockIndex   uint32  `swagger:"desc(Index of the block (request_out, state, replayed vmmsg))"`
+	RequestIndex uint16  `swagger:"desc(Index of the request in the block (request_out))"`
+	BlockSize    uint16  `swagger:"desc(Number of requests in the block (request_out, state))"`
+	StateTxID    string  `swagger:"desc(ID of the state transaction (state))"`
+	StateHash    string  `swagger:"desc(Hash of the state, empty for replayed events (state))"`
+	Timestamp    int64   `swagger:"desc(Timestamp of the block or of the request in nanoseconds (state, vmmsg))"`
+	Message      string  `swagger:"desc(Message emitted by the contract (vmmsg))"`
+	Replayed     bool    `swagger:"desc(True if the event is reconstructed from stored blocks when resuming the stream)"`
+}
@@ -1,13 +1,38 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+)
 
 type WaitRequestProcessedParams struct {
 	Timeout time.Duration `swagger:"desc(Timeout in nanoseconds),default(30 seconds)"`
 }
 
+// RequestStatusResponse is the processing status of the request. Details of the processing are known only
+// for processed requests with a receipt in the state (see eventlog.RequestReceipt). Receipts of old blocks are pruned
 type RequestStatusResponse struct {
-	IsProcessed bool `swagger:"desc(True if the request has been processed)"`
+	IsProcessed bool              `swagger:"desc(True if the request has been processed)"`
+	HasReceipt  bool              `swagger:"desc(True if the details of the processing are known)"`
+	BlockIndex  uint32            `swagger:"desc(Index of the block the request was processed in)"`
+	Timestamp   time.Time         `swagger:"desc(Timestamp of the request call)"`
+	Error       string            `swagger:"desc(Error message of the VM. Empty if the request succeeded)"`
+	GasUsed     uint64            `swagger:"desc(Gas used by the request)"`
+	ResultHash  hashing.HashValue `swagger:"desc(Hash of the result returned by the entry point)"`
+	Result      dict.Dict         `swagger:"desc(Result returned by the entry point. Null if the result is too large to be kept in the receipt)"`
+	// Trace is known only if the node traces requests (see parameter vm.traceRequests) and has run the request
+	Trace *RequestTrace `swagger:"desc(Trace of the calls made while processing the request. Null if not known)"`
+}
+
+// Err returns the error of the VM as a Go error, or nil if the request succeeded
+func (r *RequestStatusResponse) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+	return fmt.Errorf("request failed: %s", r.Error)
 }
 
 const WaitRequestProcessedDefaultTimeout = 30 * time.Second
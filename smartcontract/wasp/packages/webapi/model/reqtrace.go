@@ -0,0 +1,78 @@
+package model
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm"
+)
+
+// RequestTrace is the trace of the calls made by the VM while processing the request (see vm.RequestTrace)
+type RequestTrace struct {
+	Calls []*CallFrame  `swagger:"desc(Call of the request with the nested calls)"`
+	State []StateAccess `swagger:"desc(State accessed by the VM outside of calls)"`
+}
+
+type CallFrame struct {
+	Caller     string          `swagger:"desc(Agent ID of the caller)"`
+	Contract   string          `swagger:"desc(Hname of the called contract)"`
+	EntryPoint string          `swagger:"desc(Hname of the called entry point)"`
+	ParamsSize int             `swagger:"desc(Total size of the params in bytes)"`
+	Transfer   map[Color]int64 `swagger:"desc(Tokens transferred with the call)"`
+	Result     dict.Dict       `swagger:"desc(Result returned by the entry point)"`
+	Error      string          `swagger:"desc(Error of the call. Empty if the call succeeded)"`
+	Duration   int64           `swagger:"desc(Duration of the call in nanoseconds)"`
+	State      []StateAccess   `swagger:"desc(State accessed during the call, without nested calls)"`
+	HostCalls  []string        `swagger:"desc(Calls from the Wasm code to the host)"`
+	Calls      []*CallFrame    `swagger:"desc(Nested calls)"`
+}
+
+type StateAccess struct {
+	Op       string `swagger:"desc(Kind of access: get, has, iterate, set or del)"`
+	Contract string `swagger:"desc(Hname of the contract which owns the state)"`
+	Key      Bytes  `swagger:"desc(Key or key prefix (base64))"`
+}
+
+func NewRequestTrace(trace *vm.RequestTrace) *RequestTrace {
+	return &RequestTrace{
+		Calls: newCallFrames(trace.Calls),
+		State: newStateAccesses(trace.State),
+	}
+}
+
+func newCallFrames(frames []*vm.CallFrame) []*CallFrame {
+	ret := make([]*CallFrame, len(frames))
+	for i, f := range frames {
+		ret[i] = &CallFrame{
+			Caller:     f.Caller.String(),
+			Contract:   f.Contract.String(),
+			EntryPoint: f.EntryPoint.String(),
+			ParamsSize: f.ParamsSize,
+			Result:     f.Result,
+			Error:      f.Error,
+			Duration:   f.Duration.Nanoseconds(),
+			State:      newStateAccesses(f.State),
+			HostCalls:  f.HostCalls,
+			Calls:      newCallFrames(f.Calls),
+		}
+		if f.Transfer != nil {
+			ret[i].Transfer = make(map[Color]int64)
+			f.Transfer.IterateDeterministic(func(col balance.Color, bal int64) bool {
+				ret[i].Transfer[NewColor(&col)] = bal
+				return true
+			})
+		}
+	}
+	return ret
+}
+
+func newStateAccesses(accesses []vm.StateAccess) []StateAccess {
+	ret := make([]StateAccess, len(accesses))
+	for i, a := range accesses {
+		ret[i] = StateAccess{
+			Op:       string(a.Op),
+			Contract: a.Contract.String(),
+			Key:      NewBytes([]byte(a.Key)),
+		}
+	}
+	return ret
+}
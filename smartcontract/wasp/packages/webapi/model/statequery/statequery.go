@@ -1,43 +1,61 @@
-// +build ignore
-
+// Package statequery defines the typed query of the solid state of the chain. Each key query reads a value
+// in the state partition of one contract: a scalar, a slice of an array, a page of a map, an element of a map
+// or a slice of a timestamped log
 package statequery
 
 import (
+	"bytes"
+	"container/heap"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv"
-	"github.com/iotaledger/wasp/packages/kv/buffered"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
 	"github.com/iotaledger/wasp/packages/webapi/model"
 )
 
+const (
+	// DefaultPageSize is the number of map entries returned when the limit is not specified
+	DefaultPageSize = 100
+	// MaxPageSize is the maximum number of values returned by one key query
+	MaxPageSize = 1000
+)
+
 type Request struct {
 	QueryGeneralData bool
-	KeyQueries       []*KeyQuery
+	// StateIndex, if not nil, is the index of the state the query must be executed on.
+	// The query fails with 409 Conflict if the solid state has another index. It is used to get consistent
+	// results of several pages: the StateIndex of the results of the first page is passed with the next ones
+	StateIndex *uint32
+	KeyQueries []*KeyQuery
 }
 
 type Results struct {
+	// StateIndex is the index of the state the queries were executed on
+	StateIndex      uint32
 	KeyQueryResults []*QueryResult
-	byKey           map[kv.Key]*QueryResult
+	byKey           map[string]*QueryResult
 
 	// returned only when QueryGeneralData = true
-	StateIndex uint32
-	Timestamp  time.Time
-	StateHash  *hashing.HashValue
-	StateTxId  model.ValueTxID
-	Requests   []*coretypes.RequestID
+	Timestamp time.Time
+	StateHash hashing.HashValue
+	StateTxId model.ValueTxID
+	Requests  []*coretypes.RequestID
 }
 
 type KeyQuery struct {
-	Key    []byte
-	Type   ValueType
-	Params json.RawMessage // one of MapQueryParams, ArrayQueryParams, ...
+	// Contract is the hname of the contract. The key is in its state partition
+	Contract coretypes.Hname
+	Key      []byte
+	Type     ValueType
+	Params   json.RawMessage // one of MapQueryParams, ArrayQueryParams, ...
 }
 
 type ValueType string
@@ -59,14 +77,18 @@ type TLogSliceQueryParams struct {
 	ToTs   int64
 }
 
-// TLogSliceDataQueryParams request data for the slice
+// TLogSliceDataQueryParams request data for the slice of indices FromIndex..ToIndex, both inclusive.
+// At most MaxPageSize records are returned
 type TLogSliceDataQueryParams struct {
 	FromIndex  uint32
 	ToIndex    uint32
 	Descending bool
 }
 
+// MapQueryParams request the page of the map. Entries are ordered by their keys, the page starts with
+// the key From (or the first key after it). If Limit is 0, DefaultPageSize entries are returned
 type MapQueryParams struct {
+	From  []byte
 	Limit uint32
 }
 
@@ -74,15 +96,17 @@ type MapElementQueryParams struct {
 	Key []byte
 }
 
+// ArrayQueryParams request values with indices From..To-1. At most MaxPageSize values are returned
 type ArrayQueryParams struct {
 	From uint16
 	To   uint16
 }
 
 type QueryResult struct {
-	Key   []byte
-	Type  ValueType
-	Value json.RawMessage // one of []byte, MapResult, ArrayResult, ...
+	Contract coretypes.Hname
+	Key      []byte
+	Type     ValueType
+	Value    json.RawMessage // one of []byte, MapResult, ArrayResult, ...
 }
 
 type KeyValuePair struct {
@@ -90,9 +114,11 @@ type KeyValuePair struct {
 	Value []byte
 }
 
+// MapResult is the page of the map. Next is the key the next page starts with, nil for the last page
 type MapResult struct {
 	Len     uint32
 	Entries []KeyValuePair
+	Next    []byte
 }
 
 type MapElementResult struct {
@@ -124,68 +150,53 @@ func (q *Request) AddGeneralData() {
 	q.QueryGeneralData = true
 }
 
-func (q *Request) AddScalar(key kv.Key) {
-	q.KeyQueries = append(q.KeyQueries, &KeyQuery{
-		Key:    []byte(key),
-		Type:   ValueTypeScalar,
-		Params: nil,
-	})
+// WithStateIndex requires the query to be executed on the state with the index
+func (q *Request) WithStateIndex(stateIndex uint32) *Request {
+	q.StateIndex = &stateIndex
+	return q
 }
 
-func (q *Request) AddArray(key kv.Key, from uint16, to uint16) {
-	p := &ArrayQueryParams{From: from, To: to}
-	params, _ := json.Marshal(p)
-	q.KeyQueries = append(q.KeyQueries, &KeyQuery{
-		Key:    []byte(key),
-		Type:   ValueTypeArray,
-		Params: json.RawMessage(params),
-	})
+func (q *Request) AddScalar(contract coretypes.Hname, key kv.Key) {
+	q.addKeyQuery(contract, key, ValueTypeScalar, nil)
 }
 
-func (q *Request) AddMap(key kv.Key, limit uint32) {
-	p := &MapQueryParams{Limit: limit}
-	params, _ := json.Marshal(p)
-	q.KeyQueries = append(q.KeyQueries, &KeyQuery{
-		Key:    []byte(key),
-		Type:   ValueTypeMap,
-		Params: json.RawMessage(params),
-	})
+func (q *Request) AddArray(contract coretypes.Hname, key kv.Key, from uint16, to uint16) {
+	q.addKeyQuery(contract, key, ValueTypeArray, &ArrayQueryParams{From: from, To: to})
 }
 
-func (q *Request) AddMapElement(mapKey kv.Key, elemKey []byte) {
-	p := &MapElementQueryParams{Key: elemKey}
-	params, _ := json.Marshal(p)
-	q.KeyQueries = append(q.KeyQueries, &KeyQuery{
-		Key:    []byte(mapKey),
-		Type:   ValueTypeMapElement,
-		Params: json.RawMessage(params),
-	})
+func (q *Request) AddMap(contract coretypes.Hname, key kv.Key, from []byte, limit uint32) {
+	q.addKeyQuery(contract, key, ValueTypeMap, &MapQueryParams{From: from, Limit: limit})
+}
+
+func (q *Request) AddMapElement(contract coretypes.Hname, mapKey kv.Key, elemKey []byte) {
+	q.addKeyQuery(contract, mapKey, ValueTypeMapElement, &MapElementQueryParams{Key: elemKey})
 }
 
-func (q *Request) AddTLogSlice(key kv.Key, fromTs, toTs int64) {
-	p := TLogSliceQueryParams{
+func (q *Request) AddTLogSlice(contract coretypes.Hname, key kv.Key, fromTs, toTs int64) {
+	q.addKeyQuery(contract, key, ValueTypeTLogSlice, &TLogSliceQueryParams{
 		FromTs: fromTs,
 		ToTs:   toTs,
-	}
-	params, _ := json.Marshal(p)
-	q.KeyQueries = append(q.KeyQueries, &KeyQuery{
-		Key:    []byte(key),
-		Type:   ValueTypeTLogSlice,
-		Params: json.RawMessage(params),
 	})
 }
 
-func (q *Request) AddTLogSliceData(key kv.Key, fromIndex, toIndex uint32, descending bool) {
-	p := TLogSliceDataQueryParams{
+func (q *Request) AddTLogSliceData(contract coretypes.Hname, key kv.Key, fromIndex, toIndex uint32, descending bool) {
+	q.addKeyQuery(contract, key, ValueTypeTLogSliceData, &TLogSliceDataQueryParams{
 		FromIndex:  fromIndex,
 		ToIndex:    toIndex,
 		Descending: descending,
+	})
+}
+
+func (q *Request) addKeyQuery(contract coretypes.Hname, key kv.Key, t ValueType, p interface{}) {
+	var params json.RawMessage
+	if p != nil {
+		params, _ = json.Marshal(p)
 	}
-	params, _ := json.Marshal(p)
 	q.KeyQueries = append(q.KeyQueries, &KeyQuery{
-		Key:    []byte(key),
-		Type:   ValueTypeTLogSliceData,
-		Params: json.RawMessage(params),
+		Contract: contract,
+		Key:      []byte(key),
+		Type:     t,
+		Params:   params,
 	})
 }
 
@@ -219,6 +230,14 @@ func (r *QueryResult) MustAddress() address.Address {
 	return v
 }
 
+func (r *QueryResult) MustAgentID() (coretypes.AgentID, bool) {
+	v, ok, err := codec.DecodeAgentID(r.MustBytes())
+	if err != nil {
+		panic(err)
+	}
+	return v, ok
+}
+
 func (r *QueryResult) MustHashValue() *hashing.HashValue {
 	v, _, err := codec.DecodeHashValue(r.MustBytes())
 	if err != nil {
@@ -275,21 +294,24 @@ func (r *QueryResult) MustTLogSliceDataResult() *TLogSliceDataResult {
 	return &sr
 }
 
-func (r *Results) Get(key kv.Key) *QueryResult {
+// Get returns the result of the query of the key in the state partition of the contract
+func (r *Results) Get(contract coretypes.Hname, key kv.Key) *QueryResult {
 	if r.byKey == nil {
-		r.byKey = make(map[kv.Key]*QueryResult)
+		r.byKey = make(map[string]*QueryResult)
 		for _, qr := range r.KeyQueryResults {
-			r.byKey[kv.Key(qr.Key)] = qr
+			r.byKey[string(qr.Contract.Bytes())+string(qr.Key)] = qr
 		}
 	}
-	return r.byKey[key]
+	return r.byKey[string(contract.Bytes())+string(key)]
 }
 
-func (q *KeyQuery) Execute(vars buffered.BufferedKVStore) (*QueryResult, error) {
+// Execute executes the query on the variables of the state of the chain
+func (q *KeyQuery) Execute(vars kv.KVStore) (*QueryResult, error) {
+	partition := subrealm.New(vars, kv.Key(q.Contract.Bytes()))
 	key := kv.Key(q.Key)
 	switch q.Type {
 	case ValueTypeScalar:
-		value, err := vars.Get(key)
+		value, err := partition.Get(key)
 		if err != nil {
 			return nil, err
 		}
@@ -299,17 +321,17 @@ func (q *KeyQuery) Execute(vars buffered.BufferedKVStore) (*QueryResult, error)
 		var params ArrayQueryParams
 		err := json.Unmarshal(q.Params, &params)
 		if err != nil {
-			return nil, err
+			return nil, invalidQuery(err)
 		}
 
-		arr := collections.NewArray(vars, string(key))
+		arr := collections.NewArrayReadOnly(partition, string(key))
 
 		size, err := arr.Len()
 		if err != nil {
 			return nil, err
 		}
 		values := make([][]byte, 0)
-		for i := params.From; i < size && i < params.To; i++ {
+		for i := params.From; i < size && i < params.To && len(values) < MaxPageSize; i++ {
 			v, err := arr.GetAt(i)
 			if err != nil {
 				return nil, err
@@ -322,40 +344,63 @@ func (q *KeyQuery) Execute(vars buffered.BufferedKVStore) (*QueryResult, error)
 		var params MapQueryParams
 		err := json.Unmarshal(q.Params, &params)
 		if err != nil {
-			return nil, err
+			return nil, invalidQuery(err)
+		}
+		limit := int(params.Limit)
+		if limit == 0 {
+			limit = DefaultPageSize
+		}
+		if limit > MaxPageSize {
+			limit = MaxPageSize
 		}
 
-		m := collections.NewMap(vars, string(key))
+		m := collections.NewMapReadOnly(partition, string(key))
 
-		entries := make([]KeyValuePair, 0)
+		// the order of iteration is not defined, so the page keeps the smallest keys starting from the
+		// cursor 'From', and one more key which starts the next page
+		page := make(mapPage, 0, limit+1)
 		err = m.Iterate(func(elemKey []byte, value []byte) bool {
-			entries = append(entries, KeyValuePair{Key: elemKey, Value: value})
-			return len(entries) < int(params.Limit)
+			if bytes.Compare(elemKey, params.From) < 0 {
+				return true
+			}
+			if len(page) <= limit {
+				heap.Push(&page, newKeyValuePair(elemKey, value))
+			} else if bytes.Compare(elemKey, page[0].Key) < 0 {
+				page[0] = newKeyValuePair(elemKey, value)
+				heap.Fix(&page, 0)
+			}
+			return true
 		})
 		if err != nil {
 			return nil, err
 		}
-		n, err := m.Len()
-		if err != nil {
+		sort.Slice(page, func(i, j int) bool {
+			return bytes.Compare(page[i].Key, page[j].Key) < 0
+		})
+		ret := MapResult{Entries: page}
+		if len(page) > limit {
+			ret.Entries, ret.Next = page[:limit], page[limit].Key
+		}
+		if ret.Len, err = m.Len(); err != nil {
 			return nil, err
 		}
-		return q.makeResult(MapResult{Len: n, Entries: entries})
+		return q.makeResult(ret)
 
 	case ValueTypeMapElement:
 		var params MapElementQueryParams
 		err := json.Unmarshal(q.Params, &params)
 		if err != nil {
-			return nil, err
+			return nil, invalidQuery(err)
 		}
 
-		m := collections.NewMap(vars, string(key))
+		m := collections.NewMapReadOnly(partition, string(key))
 
 		v, err := m.GetAt(params.Key)
 		if err != nil {
 			return nil, err
 		}
 		if v == nil {
-			return nil, nil
+			return q.makeResult(nil)
 		}
 		return q.makeResult(MapElementResult{Value: v})
 
@@ -363,10 +408,10 @@ func (q *KeyQuery) Execute(vars buffered.BufferedKVStore) (*QueryResult, error)
 		var params TLogSliceQueryParams
 		err := json.Unmarshal(q.Params, &params)
 		if err != nil {
-			return nil, err
+			return nil, invalidQuery(err)
 		}
 
-		tlog := collections.NewTimestampedLog(vars, key)
+		tlog := collections.NewTimestampedLogReadOnly(partition, key)
 
 		tsl, err := tlog.TakeTimeSlice(params.FromTs, params.ToTs)
 		if err != nil {
@@ -387,12 +432,29 @@ func (q *KeyQuery) Execute(vars buffered.BufferedKVStore) (*QueryResult, error)
 		var params TLogSliceDataQueryParams
 		err := json.Unmarshal(q.Params, &params)
 		if err != nil {
-			return nil, err
+			return nil, invalidQuery(err)
 		}
 
-		tlog := collections.NewTimestampedLog(vars, key)
+		tlog := collections.NewTimestampedLogReadOnly(partition, key)
 
-		ret := TLogSliceDataResult{}
+		ret := TLogSliceDataResult{Values: make([][]byte, 0)}
+		n, err := tlog.Len()
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 || params.FromIndex > params.ToIndex || params.FromIndex >= n {
+			return q.makeResult(ret)
+		}
+		if params.ToIndex >= n {
+			params.ToIndex = n - 1
+		}
+		if params.ToIndex-params.FromIndex >= MaxPageSize {
+			if params.Descending {
+				params.FromIndex = params.ToIndex - MaxPageSize + 1
+			} else {
+				params.ToIndex = params.FromIndex + MaxPageSize - 1
+			}
+		}
 		ret.Values, err = tlog.LoadRecordsRaw(params.FromIndex, params.ToIndex, params.Descending)
 		if err != nil {
 			return nil, err
@@ -400,7 +462,42 @@ func (q *KeyQuery) Execute(vars buffered.BufferedKVStore) (*QueryResult, error)
 		return q.makeResult(ret)
 	}
 
-	return nil, fmt.Errorf("No handler for type %s", q.Type)
+	return nil, invalidQuery(fmt.Errorf("No handler for type %s", q.Type))
+}
+
+// InvalidQueryError is returned by KeyQuery.Execute if the query is not valid.
+// Other errors returned by Execute are errors of reading the state
+type InvalidQueryError struct {
+	Err error
+}
+
+func (e *InvalidQueryError) Error() string {
+	return e.Err.Error()
+}
+
+func invalidQuery(err error) error {
+	return &InvalidQueryError{Err: err}
+}
+
+// mapPage is the heap of map entries with the greatest key on top
+type mapPage []KeyValuePair
+
+func newKeyValuePair(key, value []byte) KeyValuePair {
+	return KeyValuePair{
+		Key:   append([]byte{}, key...),
+		Value: append([]byte{}, value...),
+	}
+}
+
+func (p mapPage) Len() int            { return len(p) }
+func (p mapPage) Less(i, j int) bool  { return bytes.Compare(p[i].Key, p[j].Key) > 0 }
+func (p mapPage) Swap(i, j int)       { p[i], p[j] = p[j], p[i] }
+func (p *mapPage) Push(x interface{}) { *p = append(*p, x.(KeyValuePair)) }
+func (p *mapPage) Pop() interface{} {
+	old := *p
+	ret := old[len(old)-1]
+	*p = old[:len(old)-1]
+	return ret
 }
 
 func (q *KeyQuery) makeResult(value interface{}) (*QueryResult, error) {
@@ -409,8 +506,9 @@ func (q *KeyQuery) makeResult(value interface{}) (*QueryResult, error) {
 		return nil, err
 	}
 	return &QueryResult{
-		Key:   q.Key,
-		Type:  q.Type,
-		Value: json.RawMessage(b),
+		Contract: q.Contract,
+		Key:      q.Key,
+		Type:     q.Type,
+		Value:    json.RawMessage(b),
 	}, nil
 }
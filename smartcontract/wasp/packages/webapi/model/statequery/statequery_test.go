@@ -0,0 +1,155 @@
+package statequery
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/stretchr/testify/require"
+)
+
+func execute(t *testing.T, vars kv.KVStore, q *Request) *Results {
+	ret := &Results{}
+	for _, kq := range q.KeyQueries {
+		res, err := kq.Execute(vars)
+		require.NoError(t, err)
+		ret.KeyQueryResults = append(ret.KeyQueryResults, res)
+	}
+	return ret
+}
+
+func TestKeyQueries(t *testing.T) {
+	contract := coretypes.Hn("test")
+	other := coretypes.Hn("other")
+	vars := dict.New()
+	partition := subrealm.New(vars, kv.Key(contract.Bytes()))
+	partition.Set("counter", codec.EncodeInt64(42))
+	subrealm.New(vars, kv.Key(other.Bytes())).Set("counter", codec.EncodeInt64(7))
+
+	arr := collections.NewArray(partition, "arr")
+	for i := 0; i < 5; i++ {
+		arr.MustPush([]byte{byte(i)})
+	}
+	m := collections.NewMap(partition, "map")
+	for i := 0; i < 5; i++ {
+		m.MustSetAt([]byte(fmt.Sprintf("k%d", i)), []byte{byte(i)})
+	}
+	tlog := collections.NewTimestampedLog(partition, "log")
+	for i := 0; i < 5; i++ {
+		tlog.MustAppend(int64(10+i), []byte{byte(i)})
+	}
+
+	q := NewRequest()
+	q.AddScalar(contract, "counter")
+	q.AddScalar(other, "counter")
+	q.AddArray(contract, "arr", 1, 3)
+	q.AddMap(contract, "map", nil, 2)
+	q.AddMapElement(contract, "map", []byte("k3"))
+	q.AddMapElement(contract, "map", []byte("nope"))
+	q.AddTLogSlice(contract, "log", 11, 13)
+	q.AddTLogSliceData(contract, "log", 3, 100, true)
+	res := execute(t, vars, q)
+
+	n, ok := res.Get(contract, "counter").MustInt64()
+	require.True(t, ok)
+	require.EqualValues(t, 42, n)
+	n, _ = res.Get(other, "counter").MustInt64()
+	require.EqualValues(t, 7, n)
+
+	ar := res.Get(contract, "arr").MustArrayResult()
+	require.EqualValues(t, 5, ar.Len)
+	require.EqualValues(t, [][]byte{{1}, {2}}, ar.Values)
+
+	mr := res.KeyQueryResults[3].MustMapResult()
+	require.EqualValues(t, 5, mr.Len)
+	require.Len(t, mr.Entries, 2)
+	require.EqualValues(t, "k0", mr.Entries[0].Key)
+	require.EqualValues(t, "k2", mr.Next)
+
+	require.EqualValues(t, []byte{3}, res.KeyQueryResults[4].MustMapElementResult())
+	require.Nil(t, res.KeyQueryResults[5].MustMapElementResult())
+
+	sl := res.KeyQueryResults[6].MustTLogSliceResult()
+	require.True(t, sl.IsNotEmpty)
+	require.EqualValues(t, 1, sl.FirstIndex)
+	require.EqualValues(t, 3, sl.LastIndex)
+
+	data := res.KeyQueryResults[7].MustTLogSliceDataResult()
+	require.Len(t, data.Values, 2)
+	rec, err := collections.ParseRawLogRecord(data.Values[0])
+	require.NoError(t, err)
+	require.EqualValues(t, 14, rec.Timestamp)
+}
+
+func TestMapPages(t *testing.T) {
+	contract := coretypes.Hn("test")
+	vars := dict.New()
+	m := collections.NewMap(subrealm.New(vars, kv.Key(contract.Bytes())), "map")
+	for i := 0; i < 25; i++ {
+		m.MustSetAt([]byte(fmt.Sprintf("k%02d", i)), []byte{byte(i)})
+	}
+
+	seen := make(map[string]bool)
+	var from []byte
+	for pages := 1; ; pages++ {
+		q := NewRequest()
+		q.AddMap(contract, "map", from, 10)
+		mr := execute(t, vars, q).KeyQueryResults[0].MustMapResult()
+		for _, e := range mr.Entries {
+			require.False(t, seen[string(e.Key)])
+			seen[string(e.Key)] = true
+		}
+		if mr.Next == nil {
+			require.EqualValues(t, 3, pages)
+			break
+		}
+		from = mr.Next
+	}
+	require.Len(t, seen, 25)
+}
+
+func TestMapPageFrom(t *testing.T) {
+	contract := coretypes.Hn("test")
+	vars := dict.New()
+	m := collections.NewMap(subrealm.New(vars, kv.Key(contract.Bytes())), "map")
+	for i := 24; i >= 0; i-- {
+		m.MustSetAt([]byte(fmt.Sprintf("k%02d", i)), []byte{byte(i)})
+	}
+
+	q := NewRequest()
+	q.AddMap(contract, "map", []byte("k055"), 3)
+	q.AddMap(contract, "map", []byte("k22"), 3)
+	res := execute(t, vars, q)
+
+	mr := res.KeyQueryResults[0].MustMapResult()
+	require.EqualValues(t, 25, mr.Len)
+	require.Len(t, mr.Entries, 3)
+	for i, e := range mr.Entries {
+		require.EqualValues(t, fmt.Sprintf("k%02d", i+6), string(e.Key))
+		require.EqualValues(t, []byte{byte(i + 6)}, e.Value)
+	}
+	require.EqualValues(t, "k09", string(mr.Next))
+
+	mr = res.KeyQueryResults[1].MustMapResult()
+	require.Len(t, mr.Entries, 3)
+	require.Nil(t, mr.Next)
+}
+
+func TestInvalidQuery(t *testing.T) {
+	q := NewRequest()
+	q.AddScalar(coretypes.Hn("test"), "x")
+	q.KeyQueries[0].Type = "unknown"
+	_, err := q.KeyQueries[0].Execute(dict.New())
+	require.IsType(t, &InvalidQueryError{}, err)
+
+	q = NewRequest()
+	q.AddMap(coretypes.Hn("test"), "map", nil, 10)
+	q.KeyQueries[0].Params = []byte("{")
+	_, err = q.KeyQueries[0].Execute(dict.New())
+	require.IsType(t, &InvalidQueryError{}, err)
+}
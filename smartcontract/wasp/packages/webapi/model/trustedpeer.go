@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/base64"
+
+	"github.com/iotaledger/wasp/packages/peering"
+	"go.dedis.ch/kyber/v3"
+)
+
+// TrustedPeer is the record of the committee peer whose key is accepted by the TCP peering.
+type TrustedPeer struct {
+	NetID       string `json:"netID" swagger:"desc(NetID of the peer, host:port)"`
+	PubKey      string `json:"pubKey" swagger:"desc(Public key of the peer (base64-encoded))"`
+	KeyRotation bool   `json:"keyRotation" swagger:"desc(The peer is allowed to replace its key once)"`
+}
+
+func NewTrustedPeer(tp *peering.TrustedPeer) (*TrustedPeer, error) {
+	pubKey, err := tp.PubKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &TrustedPeer{
+		NetID:       tp.NetID,
+		PubKey:      base64.StdEncoding.EncodeToString(pubKey),
+		KeyRotation: tp.KeyRotation,
+	}, nil
+}
+
+// TrustedPeer decodes the public key of the peer as a point of the suite
+func (tp *TrustedPeer) TrustedPeer(suite kyber.Group) (*peering.TrustedPeer, error) {
+	b, err := base64.StdEncoding.DecodeString(tp.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	pubKey := suite.Point()
+	if err := pubKey.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return &peering.TrustedPeer{
+		NetID:       tp.NetID,
+		PubKey:      pubKey,
+		KeyRotation: tp.KeyRotation,
+	}, nil
+}
@@ -8,6 +8,11 @@ import (
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/wasp/packages/chain"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/util/auth"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
 	"github.com/iotaledger/wasp/packages/webapi/httperrors"
 	"github.com/iotaledger/wasp/packages/webapi/model"
 	"github.com/iotaledger/wasp/packages/webapi/routes"
@@ -23,11 +28,12 @@ func AddEndpoints(server echoswagger.ApiRouter) {
 		AddParamPath("", "reqID", "Request ID (base58)").
 		AddResponse(http.StatusOK, "Request status", model.RequestStatusResponse{}, nil)
 
-	server.GET(routes.WaitRequestProcessed(":chainID", ":reqID"), handleWaitRequestProcessed).
+	server.GET(routes.WaitRequestProcessed(":chainID", ":reqID"), handleWaitRequestProcessed, auth.RequireRole(auth.RoleClient)).
 		SetSummary("Wait until the given request has been processed by the node").
 		AddParamPath("", "chainID", "ChainID (base58)").
 		AddParamPath("", "reqID", "Request ID (base58)").
-		AddParamBody(model.WaitRequestProcessedParams{}, "Params", "Optional parameters", false)
+		AddParamBody(model.WaitRequestProcessedParams{}, "Params", "Optional parameters", false).
+		AddResponse(http.StatusOK, "Request status", model.RequestStatusResponse{}, nil)
 }
 
 func handleRequestStatus(c echo.Context) error {
@@ -35,16 +41,42 @@ func handleRequestStatus(c echo.Context) error {
 	if err != nil {
 		return err
 	}
-	var isProcessed bool
-	switch ch.GetRequestProcessingStatus(reqID) {
-	case chain.RequestProcessingStatusCompleted:
-		isProcessed = true
-	case chain.RequestProcessingStatusBacklog:
-		isProcessed = false
+	ret, err := requestStatus(ch, reqID)
+	if err != nil {
+		return err
 	}
-	return c.JSON(http.StatusOK, model.RequestStatusResponse{
-		IsProcessed: isProcessed,
-	})
+	return c.JSON(http.StatusOK, ret)
+}
+
+// requestStatus returns the status of the request with the details from its receipt in the solid state
+func requestStatus(ch chain.Chain, reqID *coretypes.RequestID) (*model.RequestStatusResponse, error) {
+	ret := &model.RequestStatusResponse{}
+	if ch.GetRequestProcessingStatus(reqID) != chain.RequestProcessingStatusCompleted {
+		return ret, nil
+	}
+	ret.IsProcessed = true
+	solidState, _, exist, err := state.LoadSolidState(ch.ID())
+	if err != nil || !exist {
+		return ret, err
+	}
+	partition := subrealm.New(solidState.Variables(), kv.Key(eventlog.Interface.Hname().Bytes()))
+	rec, err := eventlog.GetRequestReceipt(partition, reqID)
+	if err != nil || rec == nil {
+		return ret, err
+	}
+	ret.HasReceipt = true
+	ret.BlockIndex = rec.BlockIndex
+	ret.Timestamp = time.Unix(0, rec.Timestamp)
+	ret.Error = rec.Error
+	ret.GasUsed = rec.GasUsed
+	ret.ResultHash = rec.ResultHash
+	ret.Result = rec.Result
+	if traces := ch.RequestTraces(); traces != nil {
+		if trace := traces.Get(reqID); trace != nil {
+			ret.Trace = model.NewRequestTrace(trace)
+		}
+	}
+	return ret, nil
 }
 
 func handleWaitRequestProcessed(c echo.Context) error {
@@ -64,7 +96,7 @@ func handleWaitRequestProcessed(c echo.Context) error {
 
 	if ch.GetRequestProcessingStatus(reqID) == chain.RequestProcessingStatusCompleted {
 		// request is already processed, no need to wait
-		return nil
+		return respondRequestStatus(c, ch, reqID)
 	}
 
 	// subscribe to event
@@ -79,16 +111,24 @@ func handleWaitRequestProcessed(c echo.Context) error {
 
 	select {
 	case <-requestProcessed:
-		return nil
+		return respondRequestStatus(c, ch, reqID)
 	case <-time.After(req.Timeout):
 		// check again, in case event was triggered just before we subscribed
 		if ch.GetRequestProcessingStatus(reqID) == chain.RequestProcessingStatusCompleted {
-			return nil
+			return respondRequestStatus(c, ch, reqID)
 		}
 		return httperrors.Timeout("Timeout while waiting for request to be processed")
 	}
 }
 
+func respondRequestStatus(c echo.Context, ch chain.Chain, reqID *coretypes.RequestID) error {
+	ret, err := requestStatus(ch, reqID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
 func parseParams(c echo.Context) (chain.Chain, *coretypes.RequestID, error) {
 	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
 	if err != nil {
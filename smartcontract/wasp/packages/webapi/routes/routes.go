@@ -8,6 +8,10 @@ func CallView(contractID string, hname string) string {
 	return "/contract/" + contractID + "/callview/" + hname
 }
 
+func CallViews(chainID string) string {
+	return "/chain/" + chainID + "/callviews"
+}
+
 func RequestStatus(chainID string, reqID string) string {
 	return "/chain/" + chainID + "/request/" + reqID + "/status"
 }
@@ -20,6 +24,10 @@ func StateQuery(chainID string) string {
 	return "/chain/" + chainID + "/state/query"
 }
 
+func ChainEvents(chainID string) string {
+	return "/chain/" + chainID + "/events"
+}
+
 func PutBlob() string {
 	return "/blob/put"
 }
@@ -60,6 +68,38 @@ func DKSharesGet(sharedAddress string) string {
 	return "/adm/dks/" + sharedAddress
 }
 
+func PostAPIToken() string {
+	return "/adm/apitokens"
+}
+
+func ListAPITokens() string {
+	return "/adm/apitokens"
+}
+
+func DeleteAPIToken(id string) string {
+	return "/adm/apitoken/" + id
+}
+
+func PutAPIKey() string {
+	return "/adm/apikey"
+}
+
+func ListAPIKeys() string {
+	return "/adm/apikeys"
+}
+
+func DeleteAPIKey(address string) string {
+	return "/adm/apikey/" + address
+}
+
+func PutTrustedPeer() string {
+	return "/adm/trustedpeer"
+}
+
+func ListTrustedPeers() string {
+	return "/adm/trustedpeers"
+}
+
 func DumpState(contractID string) string {
 	return "/adm/contract/" + contractID + "/dumpstate"
 }
@@ -8,14 +8,25 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm/processors"
 	"github.com/iotaledger/wasp/packages/vm/viewcontext"
 	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
 	"github.com/iotaledger/wasp/packages/webapi/routes"
 	"github.com/iotaledger/wasp/plugins/chains"
 	"github.com/labstack/echo/v4"
 	"github.com/pangpanglabs/echoswagger/v2"
 )
 
+// chainProcessors returns processors of the active chain or nil if the chain is not active. Replaced in tests
+var chainProcessors = func(chainID coretypes.ChainID) *processors.ProcessorCache {
+	chain := chains.GetChain(chainID)
+	if chain == nil {
+		return nil
+	}
+	return chain.Processors()
+}
+
 func AddEndpoints(server echoswagger.ApiRouter) {
 	dictExample := dict.Dict{
 		kv.Key("key1"): []byte("value1"),
@@ -27,6 +38,16 @@ func AddEndpoints(server echoswagger.ApiRouter) {
 		AddParamPath("getInfo", "fname", "Function name").
 		AddParamBody(dictExample, "params", "Parameters", false).
 		AddResponse(http.StatusOK, "Result", dictExample, nil)
+
+	server.GET(routes.CallViews(":chainID"), handleCallViews).
+		SetSummary("Call several view functions against the same state of the chain").
+		SetDescription("Returns results of all calls or fails if any of the calls fails. "+
+			"Returns 409 Conflict if the state index is not the requested one or the state changes during the calls").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamBody(model.CallViewsRequest{}, "calls", "View calls", true).
+		AddResponse(http.StatusOK, "Results", model.CallViewsResponse{}, nil)
+
+	addStateQueryEndpoint(server)
 }
 
 func handleCallView(c echo.Context) error {
@@ -62,3 +83,47 @@ func handleCallView(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, ret)
 }
+
+func handleCallViews(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+	}
+
+	var req model.CallViewsRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+
+	procs := chainProcessors(chainID)
+	if procs == nil {
+		return httperrors.NotFound(fmt.Sprintf("Chain not found: %s", chainID))
+	}
+
+	solidState, _, exist, err := loadSolidState(&chainID)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return httperrors.NotFound(fmt.Sprintf("State not found with address %s", chainID.String()))
+	}
+	if req.StateIndex != nil && *req.StateIndex != solidState.BlockIndex() {
+		return httperrors.Conflict(fmt.Sprintf("State index is %d, requested %d", solidState.BlockIndex(), *req.StateIndex))
+	}
+
+	vctx := viewcontext.New(chainID, solidState.Variables(), solidState.Timestamp(), solidState.BlockIndex(), procs, nil)
+	ret := &model.CallViewsResponse{
+		StateIndex: solidState.BlockIndex(),
+		Results:    make([]dict.Dict, len(req.Calls)),
+	}
+	for i, call := range req.Calls {
+		ret.Results[i], err = vctx.CallView(call.Contract, coretypes.Hn(call.Function), call.Params)
+		if err != nil {
+			return httperrors.BadRequest(fmt.Sprintf("View call #%d %s.%s failed: %v", i, call.Contract, call.Function, err))
+		}
+	}
+	if err := checkStateIndex(&chainID, ret.StateIndex); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ret)
+}
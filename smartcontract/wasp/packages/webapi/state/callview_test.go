@@ -0,0 +1,87 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm/processors"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// mockChain replaces the chain and its solid state. Each load of the solid state returns the next index
+func mockChain(t *testing.T, chainID *coretypes.ChainID, indices ...uint32) {
+	prevLoad, prevProcessors := loadSolidState, chainProcessors
+	t.Cleanup(func() { loadSolidState, chainProcessors = prevLoad, prevProcessors })
+
+	procs := processors.MustNew()
+	chainProcessors = func(id coretypes.ChainID) *processors.ProcessorCache {
+		if id != *chainID {
+			return nil
+		}
+		return procs
+	}
+	loadSolidState = func(id *coretypes.ChainID) (state.VirtualState, state.Block, bool, error) {
+		vs := state.NewVirtualState(mapdb.NewMapDB(), id)
+		vs.ApplyBlockIndex(indices[0])
+		if len(indices) > 1 {
+			indices = indices[1:]
+		}
+		return vs, nil, true, nil
+	}
+}
+
+func callViews(t *testing.T, chainID *coretypes.ChainID, req *model.CallViewsRequest) *httptest.ResponseRecorder {
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	e := echo.New()
+	// errors are reported the same way as by the web API plugin
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		if he, ok := err.(*httperrors.HTTPError); ok {
+			err = c.JSON(he.Code, he)
+		}
+		e.DefaultHTTPErrorHandler(err, c)
+	}
+	e.GET(routes.CallViews(":chainID"), handleCallViews)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, routes.CallViews(chainID.String()), bytes.NewReader(body)))
+	return rec
+}
+
+func TestCallViewsStateIndex(t *testing.T) {
+	chainID := coretypes.ChainID{1, 2, 3}
+	mockChain(t, &chainID, 5)
+
+	idx := uint32(5)
+	rec := callViews(t, &chainID, &model.CallViewsRequest{StateIndex: &idx})
+	require.EqualValues(t, http.StatusOK, rec.Code)
+	var resp model.CallViewsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.EqualValues(t, 5, resp.StateIndex)
+
+	idx = 4
+	rec = callViews(t, &chainID, &model.CallViewsRequest{StateIndex: &idx})
+	require.EqualValues(t, http.StatusConflict, rec.Code)
+
+	other := coretypes.ChainID{9}
+	rec = callViews(t, &other, &model.CallViewsRequest{})
+	require.EqualValues(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCallViewsStateChanged(t *testing.T) {
+	chainID := coretypes.ChainID{1, 2, 3}
+	// the state is committed while the views are called
+	mockChain(t, &chainID, 5, 6)
+
+	rec := callViews(t, &chainID, &model.CallViewsRequest{})
+	require.EqualValues(t, http.StatusConflict, rec.Code)
+}
@@ -1,5 +1,3 @@
-// +build ignore
-
 // access to the solid state of the smart contract
 package state
 
@@ -18,10 +16,14 @@ import (
 	"github.com/pangpanglabs/echoswagger/v2"
 )
 
+// loadSolidState loads the solid state of the chain from the database of the node. Replaced in tests
+var loadSolidState = state.LoadSolidState
+
 func addStateQueryEndpoint(server echoswagger.ApiRouter) {
 	server.GET(routes.StateQuery(":chainID"), handleStateQuery).
-		SetDeprecated().
 		SetSummary("Query the chain state").
+		SetDescription("Reads values in the state partitions of contracts. "+
+			"Returns 409 Conflict if the state index is not the requested one or the state changes during the query").
 		AddParamPath("", "chainID", "ChainID (base58)").
 		AddParamBody(statequery.Request{}, "query", "Query parameters", true).
 		AddResponse(http.StatusOK, "Query result", statequery.Results{}, nil)
@@ -38,33 +40,55 @@ func handleStateQuery(c echo.Context) error {
 		return httperrors.BadRequest("Failed parsing query request params")
 	}
 
-	// TODO serialize access to solid state
-	state, batch, exist, err := state.LoadSolidState(&chainID)
+	state, batch, exist, err := loadSolidState(&chainID)
 	if err != nil {
 		return err
 	}
 	if !exist {
 		return httperrors.NotFound(fmt.Sprintf("State not found with address %s", chainID.String()))
 	}
-	txid := batch.StateTransactionID()
+	if req.StateIndex != nil && *req.StateIndex != state.BlockIndex() {
+		return httperrors.Conflict(fmt.Sprintf("State index is %d, requested %d", state.BlockIndex(), *req.StateIndex))
+	}
 	ret := &statequery.Results{
+		StateIndex:      state.BlockIndex(),
 		KeyQueryResults: make([]*statequery.QueryResult, len(req.KeyQueries)),
-
-		StateIndex: state.BlockIndex(),
-		Timestamp:  time.Unix(0, state.Timestamp()),
-		StateHash:  state.Hash(),
-		StateTxId:  model.NewValueTxID(&txid),
-		Requests:   make([]*coretypes.RequestID, len(batch.RequestIDs())),
 	}
-	copy(ret.Requests, batch.RequestIDs())
+	if req.QueryGeneralData {
+		txid := batch.StateTransactionID()
+		ret.Timestamp = time.Unix(0, state.Timestamp())
+		ret.StateHash = state.Hash()
+		ret.StateTxId = model.NewValueTxID(&txid)
+		ret.Requests = make([]*coretypes.RequestID, len(batch.RequestIDs()))
+		copy(ret.Requests, batch.RequestIDs())
+	}
 	vars := state.Variables()
 	for i, q := range req.KeyQueries {
 		result, err := q.Execute(vars)
+		if _, ok := err.(*statequery.InvalidQueryError); ok {
+			return httperrors.BadRequest(fmt.Sprintf("Query of key %x failed: %v", q.Key, err))
+		}
 		if err != nil {
-			return err
+			// reading the state failed, reported as 500 Internal Server Error
+			return fmt.Errorf("query of key %x failed: %v", q.Key, err)
 		}
 		ret.KeyQueryResults[i] = result
 	}
-
+	if err := checkStateIndex(&chainID, ret.StateIndex); err != nil {
+		return err
+	}
 	return c.JSON(http.StatusOK, ret)
 }
+
+// checkStateIndex checks that the solid state was not committed while the queries were executed.
+// Values of variables are read from the database, so results of such queries may be inconsistent
+func checkStateIndex(chainID *coretypes.ChainID, stateIndex uint32) error {
+	state, _, exist, err := loadSolidState(chainID)
+	if err != nil {
+		return err
+	}
+	if !exist || state.BlockIndex() != stateIndex {
+		return httperrors.Conflict(fmt.Sprintf("State index %d changed during the query", stateIndex))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/wasp/packages/metrics"
+	"github.com/iotaledger/wasp/packages/parameters"
+	"github.com/labstack/echo/v4"
+)
+
+// PluginName is the name of the Metrics plugin. The plugin is disabled by default
+const PluginName = "Metrics"
+
+var (
+	log *logger.Logger
+)
+
+func Init() *node.Plugin {
+	return node.NewPlugin(PluginName, node.Disabled, configure, run)
+}
+
+func configure(*node.Plugin) {
+	log = logger.NewLogger(PluginName)
+}
+
+func run(_ *node.Plugin) {
+	log.Infof("Starting %s ...", PluginName)
+	if err := daemon.BackgroundWorker(PluginName, worker); err != nil {
+		log.Errorf("Error starting as daemon: %s", err)
+	}
+}
+
+func worker(shutdownSignal <-chan struct{}) {
+	server := echo.New()
+	server.HideBanner = true
+	server.HidePort = true
+	server.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		bindAddr := parameters.GetString(parameters.MetricsBindAddress)
+		log.Infof("%s started, bind-address=%s", PluginName, bindAddr)
+		if err := server.Start(bindAddr); err != nil {
+			if !errors.Is(err, http.ErrServerClosed) {
+				log.Errorf("Error serving: %s", err)
+			}
+		}
+	}()
+
+	// stop if we are shutting down or the server could not be started
+	select {
+	case <-shutdownSignal:
+	case <-stopped:
+	}
+
+	log.Infof("Stopping %s ...", PluginName)
+	defer log.Infof("Stopping %s ... done", PluginName)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Errorf("Error stopping: %s", err)
+	}
+}
@@ -0,0 +1,71 @@
+package mqttpublisher
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/wasp/packages/parameters"
+	"github.com/iotaledger/wasp/packages/publisher"
+	"github.com/iotaledger/wasp/packages/publisher/mqttpub"
+)
+
+// PluginName is the name of the MQTT Publisher plugin. The plugin is disabled by default
+const PluginName = "MQTTPublisher"
+
+var (
+	log *logger.Logger
+)
+
+func Init() *node.Plugin {
+	return node.NewPlugin(PluginName, node.Disabled, configure, run)
+}
+
+func configure(_ *node.Plugin) {
+	log = logger.NewLogger(PluginName)
+}
+
+func run(_ *node.Plugin) {
+	pub, err := mqttpub.New(mqttpub.Config{
+		BrokerURL:   parameters.GetString(parameters.MQTTBroker),
+		ClientID:    parameters.GetString(parameters.MQTTClientID),
+		Username:    parameters.GetString(parameters.MQTTUsername),
+		Password:    parameters.GetString(parameters.MQTTPassword),
+		TopicPrefix: parameters.GetString(parameters.MQTTTopicPrefix),
+		QoS:         byte(parameters.GetInt(parameters.MQTTQoS)),
+		RetainState: parameters.GetBool(parameters.MQTTRetainState),
+	}, log)
+	if err != nil {
+		log.Errorf("failed to initialize MQTT publisher: %v", err)
+		return
+	}
+	log.Infof("MQTT publisher is connected to %s", parameters.GetString(parameters.MQTTBroker))
+
+	messages := make(chan *publisher.Message, 100)
+	err = daemon.BackgroundWorker(PluginName, func(shutdownSignal <-chan struct{}) {
+		for {
+			select {
+			case msg := <-messages:
+				if err := pub.Publish(msg); err != nil {
+					log.Errorf("Failed to publish message: %v", err)
+				}
+			case <-shutdownSignal:
+				pub.Close()
+				return
+			}
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	publisher.MessageEvent.Attach(events.NewClosure(func(msg *publisher.Message) {
+		select {
+		case messages <- msg:
+		case <-time.After(1 * time.Second):
+			log.Warnf("Failed to publish message: [%s %s]", msg.Type, msg.ChainID)
+		}
+	}))
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/iotaledger/wasp/packages/parameters"
 	"github.com/iotaledger/wasp/packages/publisher"
 	"go.nanomsg.org/mangos/v3"
-	"go.nanomsg.org/mangos/v3/protocol/pub"
 	_ "go.nanomsg.org/mangos/v3/transport/all"
 )
 
@@ -65,24 +64,34 @@ func run(_ *node.Plugin) {
 		panic(err)
 	}
 
-	publisher.Event.Attach(events.NewClosure(func(msgType string, parts []string) {
-		msg := msgType + " " + strings.Join(parts, " ")
-		select {
-		case messages <- []byte(msg):
-		case <-time.After(1 * time.Second):
-			log.Warnf("Failed to publish message: [%s]", msg)
+	if parameters.GetBool(parameters.NanomsgLegacyMessages) {
+		// the legacy form of messages is published for subscribers which don't perform the handshake
+		publisher.Event.Attach(events.NewClosure(func(msgType string, parts []string) {
+			publish(messages, []byte(msgType+" "+strings.Join(parts, " ")))
+		}))
+	}
+	publisher.MessageEvent.Attach(events.NewClosure(func(msg *publisher.Message) {
+		data, err := msg.Encode()
+		if err != nil {
+			log.Errorf("Failed to encode message: %v", err)
+			return
 		}
+		publish(messages, data)
 	}))
 }
 
-func openSocket(port int) (mangos.Socket, error) {
-	socket, err := pub.NewSocket()
-	if err != nil {
-		return nil, err
+func publish(messages chan<- []byte, msg []byte) {
+	select {
+	case messages <- msg:
+	case <-time.After(1 * time.Second):
+		log.Warnf("Failed to publish message: [%s]", string(msg))
 	}
+}
 
+func openSocket(port int) (mangos.Socket, error) {
+	socket := publisher.NewPubSocket()
 	url := fmt.Sprintf("tcp://:%d", port)
-	if err = socket.Listen(url); err != nil {
+	if err := socket.Listen(url); err != nil {
 		return nil, err
 	}
 	return socket, nil
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/hive.go/daemon"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
@@ -15,6 +16,7 @@ import (
 	"github.com/iotaledger/wasp/packages/util/auth"
 	"github.com/iotaledger/wasp/packages/webapi"
 	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/plugins/registry"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/pangpanglabs/echoswagger/v2"
@@ -57,9 +59,18 @@ func configure(*node.Plugin) {
 		Format: `${time_rfc3339_nano} ${remote_ip} ${method} ${uri} ${status} error="${error}"` + "\n",
 	}))
 
+	defaultRole, err := auth.RoleFromString(parameters.GetString(parameters.WebAPIDefaultRole))
+	if err != nil {
+		panic(err)
+	}
+	auth.AddRoles(Server.Echo(), auth.RolesConfig{
+		Store:          credentialStore{},
+		DefaultRole:    defaultRole,
+		AdminWhitelist: adminWhitelist(),
+	})
 	auth.AddAuthentication(Server.Echo(), parameters.GetStringToString(parameters.WebAPIAuth))
 
-	webapi.Init(Server, adminWhitelist())
+	webapi.Init(Server)
 }
 
 func customHTTPErrorHandler(err error, c echo.Context) {
@@ -76,6 +87,17 @@ func customHTTPErrorHandler(err error, c echo.Context) {
 	c.Echo().DefaultHTTPErrorHandler(err, c)
 }
 
+// credentialStore delegates to the registry, which is initialized after this plugin is configured
+type credentialStore struct{}
+
+func (credentialStore) TokenRole(token string) (auth.Role, bool, error) {
+	return registry.DefaultRegistry().TokenRole(token)
+}
+
+func (credentialStore) AddressRole(addr *address.Address) (auth.Role, bool, error) {
+	return registry.DefaultRegistry().AddressRole(addr)
+}
+
 func adminWhitelist() []net.IP {
 	r := make([]net.IP, 0)
 	for _, ip := range parameters.GetStringSlice(parameters.WebAPIAdminWhitelist) {
@@ -14,6 +14,8 @@ import (
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
 	"github.com/stretchr/testify/require"
 )
 
@@ -124,6 +126,23 @@ func TestDeployContractOnly(t *testing.T) {
 	rec, err := root.DecodeContractRecord(recb)
 	check(err, t)
 	require.EqualValues(t, description, rec.Description)
+
+	// the same view together with the view of the contract, against the same state
+	status, err := chain.Cluster.WaspClient(0).StateQuery(&chain.ChainID, &statequery.Request{})
+	check(err, t)
+	results, stateIndex, err := chainclient.New(clu.Level1Client(), clu.WaspClient(0), chain.ChainID, nil).CallViews(
+		model.NewViewCall(root.Interface.Hname(), root.FuncFindContract, dict.FromGoMap(map[kv.Key][]byte{
+			root.ParamHname: hname.Bytes(),
+		})),
+		model.NewViewCall(hname, inccounter.FuncGetCounter, nil),
+	)
+	check(err, t)
+	require.Len(t, results, 2)
+	require.EqualValues(t, status.StateIndex, stateIndex)
+	require.EqualValues(t, recb, results[0].MustGet(root.ParamData))
+	counterValue, _, err := codec.DecodeInt64(results[1].MustGet(inccounter.VarCounter))
+	check(err, t)
+	require.EqualValues(t, 42, counterValue)
 }
 
 func TestDeployContractAndSpawn(t *testing.T) {
@@ -0,0 +1,73 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+// schema creates contract schemas and generates typed Go clients from them:
+//   schema init <core contract name>                       prints the schema seeded from the core contract interface
+//   schema gen <schema file> <package name> [<output file>]  generates the client package
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
+	"github.com/iotaledger/wasp/packages/schema"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+)
+
+var coreContracts = map[string]*coreutil.ContractInterface{
+	root.Interface.Name:     root.Interface,
+	accounts.Interface.Name: accounts.Interface,
+	blob.Interface.Name:     blob.Interface,
+	eventlog.Interface.Name: eventlog.Interface,
+}
+
+func usage() {
+	fmt.Printf("usage:\n")
+	fmt.Printf("  schema init <core contract name>\n")
+	fmt.Printf("  schema gen <schema file> <package name> [<output file>]\n")
+	os.Exit(1)
+}
+
+func check(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	if len(os.Args) < 3 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "init":
+		ci, ok := coreContracts[os.Args[2]]
+		if !ok {
+			check(fmt.Errorf("unknown core contract '%s'", os.Args[2]))
+		}
+		check(schema.FromContractInterface(ci).Write(os.Stdout))
+
+	case "gen":
+		if len(os.Args) < 4 {
+			usage()
+		}
+		s, err := schema.Load(os.Args[2])
+		check(err)
+		src, err := schema.Generate(s, os.Args[3])
+		check(err)
+		if len(os.Args) < 5 {
+			_, err = os.Stdout.Write(src)
+			check(err)
+			return
+		}
+		check(ioutil.WriteFile(os.Args[4], src, 0644))
+
+	default:
+		usage()
+	}
+}
@@ -19,21 +19,22 @@ func InitCommands(commands map[string]func([]string), flags *pflag.FlagSet) {
 }
 
 var subcmds = map[string]func([]string){
-	"list":            listCmd,
-	"deploy":          deployCmd,
-	"info":            infoCmd,
-	"list-contracts":  listContractsCmd,
-	"deploy-contract": deployContractCmd,
-	"list-accounts":   listAccountsCmd,
-	"balance":         balanceCmd,
-	"list-blobs":      listBlobsCmd,
-	"store-blob":      storeBlobCmd,
-	"show-blob":       showBlobCmd,
-	"log":             logCmd,
-	"post-request":    postRequestCmd,
-	"call-view":       callViewCmd,
-	"activate":        activateCmd,
-	"deactivate":      deactivateCmd,
+	"list":             listCmd,
+	"deploy":           deployCmd,
+	"info":             infoCmd,
+	"list-contracts":   listContractsCmd,
+	"deploy-contract":  deployContractCmd,
+	"upgrade-contract": upgradeContractCmd,
+	"list-accounts":    listAccountsCmd,
+	"balance":          balanceCmd,
+	"list-blobs":       listBlobsCmd,
+	"store-blob":       storeBlobCmd,
+	"show-blob":        showBlobCmd,
+	"log":              logCmd,
+	"post-request":     postRequestCmd,
+	"call-view":        callViewCmd,
+	"activate":         activateCmd,
+	"deactivate":       deactivateCmd,
 }
 
 func chainCmd(args []string) {
@@ -78,9 +78,12 @@ func GoshimmerClient() level1.Level1Client {
 }
 
 func WaspClient() *client.WaspClient {
-	// TODO: add authentication for /adm
 	log.Verbose("using Wasp host %s\n", WaspApi())
-	return client.NewWaspClient(WaspApi())
+	c := client.NewWaspClient(WaspApi())
+	if token := viper.GetString("wasp.token"); token != "" {
+		c.WithToken(token)
+	}
+	return c
 }
 
 func WaspApi() string {